@@ -2,89 +2,311 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
 	_ "github.com/insider/insider-messaging/docs" // Import docs for swagger
 	"github.com/insider/insider-messaging/internal/api"
 	"github.com/insider/insider-messaging/internal/db"
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/events"
+	"github.com/insider/insider-messaging/internal/grpcapi"
 	"github.com/insider/insider-messaging/internal/repo"
 	"github.com/insider/insider-messaging/internal/scheduler"
+	"github.com/insider/insider-messaging/internal/selfcheck"
 	"github.com/insider/insider-messaging/internal/service"
 	"github.com/insider/insider-messaging/pkg/config"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
+	"github.com/insider/insider-messaging/pkg/version"
 )
 
+// dbStatsInterval is how often the database connections gauge is refreshed
+const dbStatsInterval = 15 * time.Second
+
 // @title Insider Messaging API
 // @version 1.0
 // @description A messaging service API for sending messages via webhooks
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey AdminKeyAuth
+// @in header
+// @name X-Admin-Key
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	migrateDown := flag.Bool("migrate-down", false, "roll back the most recent database migration and exit")
+	flag.Parse()
 
 	// Initialize logger
-	log := logger.New().WithComponent("main")
+	baseLogger, err := logger.NewFromEnv()
+	if err != nil {
+		logger.New().Error("Failed to configure logger", "error", err)
+		os.Exit(1)
+	}
+	log := baseLogger.WithComponent("main")
 
-	log.Info("Starting Insider Messaging Service", "version", "v0.1.0")
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
 
-	// Initialize database connection (optional for development)
-	var database *db.DB
-	if cfg.DatabaseURL != "" {
-		var err error
-		database, err = db.New(cfg.DatabaseURL)
-		if err != nil {
-			log.Warn("Failed to connect to database, running without database", "error", err)
-		} else {
-			defer database.Close()
-			log.Info("Connected to database successfully")
-
-			// Run database migrations
-			if err := database.RunMigrations(); err != nil {
-				log.Error("Failed to run database migrations", "error", err)
-				os.Exit(1)
-			}
-			log.Info("Database migrations completed successfully")
+	if *migrateDown {
+		if err := rollbackMigration(cfg, log); err != nil {
+			log.Error("Failed to roll back migration", "error", err)
+			os.Exit(1)
 		}
-	} else {
-		log.Info("No database URL configured, running without database")
+		return
+	}
+
+	log.Info("Starting Insider Messaging Service",
+		"version", version.Version,
+		"commit", version.Commit,
+		"build_date", version.BuildDate,
+	)
+
+	// Invalid bucket overrides would panic in Prometheus, so they are only used
+	// once the config validates; otherwise the config self-check reports them
+	var metricsOpts metrics.MetricsOptions
+	if cfg.Validate() == nil {
+		metricsOpts = metrics.MetricsOptions{
+			WebhookBuckets:  cfg.MetricsWebhookBuckets,
+			DatabaseBuckets: cfg.MetricsDatabaseBuckets,
+			HTTPBuckets:     cfg.MetricsHTTPBuckets,
+		}
+	}
+	appMetrics := metrics.New(metricsOpts)
+
+	// Verify configuration and dependencies up front. Each check sets up the
+	// state later checks and the rest of startup rely on; failures of checks not
+	// listed in SELFCHECK_CRITICAL only degrade the service, e.g. falling back
+	// to the in-memory repository when the database is unreachable.
+	var (
+		database        *db.DB
+		redisCache      *repo.RedisCacheRepository
+		webhookClient   service.WebhookClient
+		webhookOpts     = []service.WebhookClientOption{service.WithWebhookMetrics(appMetrics)}
+		displayLocation *time.Location
+	)
+	critical := make(map[string]bool, len(cfg.SelfCheckCritical))
+	for _, name := range cfg.SelfCheckCritical {
+		critical[name] = true
+	}
+
+	report := selfcheck.Run(context.Background(),
+		selfcheck.Check{Name: "config", Critical: critical["config"], Run: func(ctx context.Context) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			if cfg.WebhookPayloadTemplate != "" {
+				transformer, err := service.NewTemplateTransformer(cfg.WebhookPayloadTemplate)
+				if err != nil {
+					return fmt.Errorf("WEBHOOK_PAYLOAD_TEMPLATE: %w", err)
+				}
+				webhookOpts = append(webhookOpts, service.WithPayloadTransformer(transformer))
+			}
+			// Timestamps are stored in UTC and only converted for API output
+			var err error
+			displayLocation, err = time.LoadLocation(cfg.DisplayTimezone)
+			return err
+		}},
+		selfcheck.Check{Name: "database", Critical: critical["database"], Run: func(ctx context.Context) error {
+			if cfg.DatabaseURL == "" {
+				return selfcheck.Skip("no database URL configured")
+			}
+			// The database may still be starting alongside the service
+			conn, err := db.NewWithRetry(cfg.DatabaseURL, cfg.DBConnectAttempts, cfg.DBConnectBackoff, log.WithComponent("db").Logger,
+				db.WithPool(cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime))
+			if err != nil {
+				return err
+			}
+			database = conn
+			return nil
+		}},
+		selfcheck.Check{Name: "migrations", Critical: critical["migrations"], Run: func(ctx context.Context) error {
+			if database == nil {
+				return selfcheck.Skip("no database connection")
+			}
+			return database.RunMigrations()
+		}},
+		selfcheck.Check{Name: "redis", Critical: critical["redis"], Run: func(ctx context.Context) error {
+			if database == nil {
+				return selfcheck.Skip("cache is only used with a database")
+			}
+			cache, err := repo.NewRedisCacheRepository(cfg.RedisURL, cfg.RedisTTL,
+				repo.WithOperationTimeout(cfg.CacheOperationTimeout),
+				repo.WithFailureCooldown(cfg.CacheFailureCooldown),
+			)
+			if err != nil {
+				return err
+			}
+			redisCache = cache
+			return nil
+		}},
+		selfcheck.Check{Name: "webhook_client", Critical: critical["webhook_client"], Run: func(ctx context.Context) error {
+			if cfg.WebhookURL != "" {
+				if u, err := url.Parse(cfg.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("WEBHOOK_URL %q is not an absolute http or https URL", cfg.WebhookURL)
+				}
+			}
+			webhookClient = service.NewWebhookClient(cfg, log.WithComponent("webhook"), webhookOpts...)
+			return nil
+		}},
+	)
+	report.Log(log.Logger)
+	if !report.Ready() {
+		os.Exit(1)
+	}
+	if database != nil {
+		defer database.Close()
+	}
+	if displayLocation == nil {
+		displayLocation = time.UTC
+	}
+
+	// Initialize lifecycle event sinks
+	sinks, err := events.SinksFromConfig(cfg, log.Logger, appMetrics)
+	if err != nil {
+		log.Error("Invalid event sink configuration", "error", err)
+		os.Exit(1)
+	}
+	eventBus := events.NewBus(log.WithComponent("events").Logger, appMetrics, sinks...)
+	defer eventBus.Close()
+	if len(sinks) > 0 {
+		log.Info("Event sinks enabled", "sinks", cfg.EventSinks)
+	}
+
+	// Email and SMS deliverers are placeholders until providers are integrated;
+	// the webhook client is registered for the webhook channel by the service
+	deliverers := service.NewDelivererRegistry()
+	deliverers.Register(domain.ChannelEmail, service.NewEmailDeliverer(log.WithComponent("email").Logger))
+	deliverers.Register(domain.ChannelSMS, service.NewSMSDeliverer(log.WithComponent("sms").Logger))
+
+	serviceOpts := []service.ServiceOption{
+		service.WithConfig(cfg),
+		service.WithMetrics(appMetrics),
+		service.WithEventBus(eventBus),
+		service.WithDelivererRegistry(deliverers),
 	}
 
 	// Initialize message repository and service
 	var messageRepo repo.MessageRepository
 	var messageService service.MessageService
 
+	// Dependencies probed by the health check
+	var serverOpts []api.ServerOption
+
+	// Signalled on new messages when LISTEN_NOTIFY is enabled
+	var newMessageWakeups <-chan struct{}
+
 	if database != nil {
 		log.Info("Using PostgreSQL database")
-		messageRepo = repo.NewMessageRepository(database.DB)
-
-		// Try to initialize Redis cache
-		redisCache, err := repo.NewRedisCacheRepository(cfg.RedisURL, cfg.RedisTTL)
-		if err != nil {
-			log.Warn("Failed to connect to Redis, proceeding without cache", "error", err)
-			messageService = service.NewMessageService(messageRepo, log.Logger)
-		} else {
-			log.Info("Redis cache initialized successfully")
-			messageService = service.NewMessageServiceWithCache(messageRepo, redisCache, log.Logger)
+		var repoOpts []repo.MessageRepositoryOption
+		if cfg.ListenNotify {
+			listener, err := repo.ListenForNewMessages(cfg.DatabaseURL, log.WithComponent("listener").Logger)
+			if err != nil {
+				log.Error("Failed to listen for new messages, relying on the interval", "error", err)
+			} else {
+				defer listener.Close()
+				newMessageWakeups = listener.Wakeups()
+				repoOpts = append(repoOpts, repo.WithNewMessageNotify())
+				log.Info("Listening for new messages", "channel", repo.NewMessageChannel)
+			}
 		}
+		messageRepo = repo.NewMessageRepository(database.DB, repoOpts...)
+		serverOpts = append(serverOpts, api.WithDependency("database", database))
 	} else {
 		// Use in-memory repository for development
 		log.Info("Using in-memory repository for development")
 		messageRepo = repo.NewInMemoryMessageRepository()
-		messageService = service.NewMessageService(messageRepo, log.Logger)
+	}
+
+	if redisCache != nil {
+		serverOpts = append(serverOpts, api.WithDependency("redis", redisCache))
+		messageService = service.NewMessageServiceWithCacheAndWebhook(messageRepo, redisCache, webhookClient, log.Logger, serviceOpts...)
+	} else {
+		messageService = service.NewMessageServiceWithWebhook(messageRepo, webhookClient, log.Logger, serviceOpts...)
+	}
+
+	// Keep the queue-depth and connection gauges current whether or not the
+	// scheduler is running
+	reportersCtx, stopReporters := context.WithCancel(context.Background())
+	var reporters sync.WaitGroup
+	queueDepthReporter := service.NewQueueDepthReporter(messageRepo, appMetrics, cfg.QueueDepthInterval, log.WithComponent("queue-depth").Logger)
+	reporters.Add(1)
+	go func() {
+		defer reporters.Done()
+		queueDepthReporter.Run(reportersCtx)
+	}()
+	if database != nil {
+		reporters.Add(1)
+		go func() {
+			defer reporters.Done()
+			database.ReportConnections(reportersCtx, appMetrics, dbStatsInterval)
+		}()
 	}
 
 	// Initialize scheduler with adapter
-	schedulerAdapter := service.NewSchedulerAdapter(messageService)
+	schedulerAdapter := service.NewSchedulerAdapter(messageService, cfg.BatchSize)
 	schedulerConfig := scheduler.DefaultConfig()
+	schedulerConfig.Metrics = appMetrics
+	schedulerConfig.MaxInFlightRuns = cfg.MaxInFlightRuns
+	schedulerConfig.Wakeup = newMessageWakeups
+	if cfg.ArchiveAfter > 0 {
+		schedulerConfig.ArchiveInterval = cfg.ArchiveInterval
+	}
+	if cfg.MessageRetention > 0 {
+		schedulerConfig.RetentionInterval = cfg.RetentionInterval
+	}
+	if redisCache != nil && cfg.LeaderLockTTL > 0 {
+		schedulerConfig.LeaderLock = redisCache
+		schedulerConfig.LeaderLockTTL = cfg.LeaderLockTTL
+	}
 	messageScheduler := scheduler.NewScheduler(schedulerAdapter, log, schedulerConfig)
 
+	// Start delivering on boot when configured; a failure here leaves the
+	// scheduler stopped so it can still be started through the API
+	if cfg.AutoStart {
+		log.Info("Auto-starting scheduler")
+		if err := messageScheduler.Start(context.Background()); err != nil {
+			log.Error("Failed to auto-start scheduler", "error", err)
+		}
+	} else {
+		log.Info("Scheduler auto-start disabled, start it via POST /api/v1/scheduler/start")
+	}
+
+	if cfg.APIKey == "" {
+		log.Warn("API_KEY is not set, /api/v1 is served without authentication")
+	}
+	if cfg.AdminAPIKey == "" {
+		log.Info("ADMIN_API_KEY is not set, the external worker claim, ack and nack routes are disabled")
+	}
+
 	// Create HTTP server
-	server := api.NewServer(log, messageService, messageScheduler)
+	serverOpts = append(serverOpts,
+		api.WithDisplayLocation(displayLocation),
+		api.WithSelfCheckReport(report),
+		api.WithAPIKey(cfg.APIKey),
+		api.WithAdminAPIKey(cfg.AdminAPIKey),
+		api.WithRateLimit(cfg.RateLimitRPS, cfg.RateLimitBurst),
+		api.WithDeliveryWebhook(cfg.DeliveryWebhookSecret, cfg.DeliveryWebhookTolerance),
+		api.WithMetricsHandler(appMetrics.Handler()),
+		api.WithMetricsAuth(cfg.MetricsAuthUser, cfg.MetricsAuthPass),
+	)
+	server := api.NewServer(log, messageService, messageScheduler, serverOpts...)
 
 	// Create HTTP server instance
 	httpServer := &http.Server{
@@ -101,6 +323,24 @@ func main() {
 		}
 	}()
 
+	// Serve the gRPC API on its own port when enabled
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort != "" {
+		listener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Error("Failed to listen for gRPC", "error", err, "port", cfg.GRPCPort)
+			os.Exit(1)
+		}
+		grpcServer = grpcapi.NewServer(log, messageService, grpcapi.WithAPIKey(cfg.APIKey)).NewGRPCServer()
+		go func() {
+			log.Info("Starting gRPC server", "port", cfg.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+				log.Error("Failed to serve gRPC", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -108,15 +348,110 @@ func main() {
 
 	log.Info("Shutting down server...")
 
+	stopReporters()
+	reporters.Wait()
+
 	// Create a deadline for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
 	// Attempt graceful shutdown
-	if err := httpServer.Shutdown(ctx); err != nil {
+	if err := shutdown(ctx, log, httpServer, grpcServer, messageScheduler); err != nil {
 		log.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
 	}
 
 	log.Info("Server exited")
 }
+
+// shutdown stops the scheduler, waiting for its in-flight runs and their
+// webhook deliveries to finish, while the HTTP and gRPC servers drain open
+// requests. All of them give up once ctx is done. A nil grpcServer means the
+// gRPC API is disabled. It returns the HTTP server's shutdown error, if any.
+func shutdown(ctx context.Context, log *logger.Logger, httpServer *http.Server, grpcServer *grpc.Server, messageScheduler *scheduler.Scheduler) error {
+	var stopped sync.WaitGroup
+	if grpcServer != nil {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			stopGRPCServer(ctx, log, grpcServer)
+		}()
+	}
+	if messageScheduler.IsRunning() {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			stopScheduler(ctx, log, messageScheduler)
+		}()
+	}
+
+	err := httpServer.Shutdown(ctx)
+	stopped.Wait()
+	return err
+}
+
+// stopGRPCServer waits for in-flight gRPC calls to finish, cancelling them
+// once ctx is done
+func stopGRPCServer(ctx context.Context, log *logger.Logger, grpcServer *grpc.Server) {
+	drained := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Info("gRPC server stopped")
+	case <-ctx.Done():
+		grpcServer.Stop()
+		<-drained
+		log.Warn("gRPC server forced to stop", "error", ctx.Err())
+	}
+}
+
+// stopScheduler waits for the scheduler's in-flight runs to finish, giving up
+// once ctx is done so a stuck delivery cannot hold shutdown past its deadline
+func stopScheduler(ctx context.Context, log *logger.Logger, messageScheduler *scheduler.Scheduler) {
+	drained := make(chan error, 1)
+	go func() {
+		drained <- messageScheduler.Stop()
+	}()
+
+	select {
+	case err := <-drained:
+		if err != nil {
+			log.Error("Failed to stop scheduler", "error", err)
+			return
+		}
+		log.Info("Scheduler stopped, in-flight deliveries drained")
+	case <-ctx.Done():
+		log.Warn("Scheduler did not drain before the shutdown deadline", "error", ctx.Err())
+	}
+}
+
+// rollbackMigration rolls back the most recently applied migration without
+// starting the service
+func rollbackMigration(cfg *config.Config, log *logger.Logger) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.DatabaseURL == "" {
+		return fmt.Errorf("no database URL configured")
+	}
+	database, err := db.NewWithRetry(cfg.DatabaseURL, cfg.DBConnectAttempts, cfg.DBConnectBackoff, log.WithComponent("db").Logger,
+		db.WithPool(cfg.DBMaxOpenConns, cfg.DBMaxIdleConns, cfg.DBConnMaxLifetime))
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := database.RollbackMigration(); err != nil {
+		return err
+	}
+	version, err := database.MigrationVersion()
+	if err != nil {
+		return err
+	}
+	log.Info("Rolled back migration", "version", version)
+	return nil
+}
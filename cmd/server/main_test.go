@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/insider/insider-messaging/internal/scheduler"
+	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowMessageService is a scheduler.MessageService whose processing runs take
+// a while, so shutdown has an in-flight run to wait for
+type slowMessageService struct {
+	started  chan struct{}
+	finished chan struct{}
+}
+
+func (s *slowMessageService) ProcessPendingMessages(ctx context.Context) (int, error) {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+	select {
+	case <-time.After(50 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	select {
+	case s.finished <- struct{}{}:
+	default:
+	}
+	return 0, nil
+}
+
+func (s *slowMessageService) RetryFailedMessages(ctx context.Context) error {
+	return nil
+}
+
+func (s *slowMessageService) ArchiveOldMessages(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *slowMessageService) PurgeOldMessages(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestShutdown_StopsScheduler(t *testing.T) {
+	log := logger.New().WithComponent("main-test")
+	service := &slowMessageService{started: make(chan struct{}, 1), finished: make(chan struct{}, 1)}
+	messageScheduler := scheduler.NewScheduler(service, log, &scheduler.Config{
+		ProcessingInterval: 5 * time.Millisecond,
+		RetryInterval:      time.Hour,
+	})
+	require.NoError(t, messageScheduler.Start(context.Background()))
+
+	select {
+	case <-service.started:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler never started a processing run")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, shutdown(ctx, log, &http.Server{}, nil, messageScheduler))
+
+	assert.False(t, messageScheduler.IsRunning())
+	select {
+	case <-service.finished:
+	default:
+		t.Fatal("shutdown returned before the in-flight processing run finished")
+	}
+}
+
+// stuckMessageService is a scheduler.MessageService whose processing runs
+// ignore cancellation and only return once release is closed
+type stuckMessageService struct {
+	slowMessageService
+	release chan struct{}
+}
+
+func (s *stuckMessageService) ProcessPendingMessages(ctx context.Context) (int, error) {
+	select {
+	case s.started <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return 0, nil
+}
+
+func TestShutdown_SchedulerDeadline(t *testing.T) {
+	log := logger.New().WithComponent("main-test")
+	service := &stuckMessageService{
+		slowMessageService: slowMessageService{started: make(chan struct{}, 1)},
+		release:            make(chan struct{}),
+	}
+	defer close(service.release)
+	messageScheduler := scheduler.NewScheduler(service, log, &scheduler.Config{
+		ProcessingInterval: 5 * time.Millisecond,
+		RetryInterval:      time.Hour,
+	})
+	require.NoError(t, messageScheduler.Start(context.Background()))
+
+	select {
+	case <-service.started:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler never started a processing run")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, shutdown(ctx, log, &http.Server{}, nil, messageScheduler))
+	assert.Less(t, time.Since(start), time.Second, "shutdown must give up on the scheduler at the deadline")
+}
+
+func TestShutdown_SchedulerNotRunning(t *testing.T) {
+	log := logger.New().WithComponent("main-test")
+	messageScheduler := scheduler.NewScheduler(&slowMessageService{}, log, scheduler.DefaultConfig())
+
+	require.NoError(t, shutdown(context.Background(), log, &http.Server{}, nil, messageScheduler))
+	assert.False(t, messageScheduler.IsRunning())
+}
+
+func TestShutdown_StopsGRPCServer(t *testing.T) {
+	log := logger.New().WithComponent("main-test")
+	messageScheduler := scheduler.NewScheduler(&slowMessageService{}, log, scheduler.DefaultConfig())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	served := make(chan error, 1)
+	go func() {
+		served <- grpcServer.Serve(listener)
+	}()
+
+	require.NoError(t, shutdown(context.Background(), log, &http.Server{}, grpcServer, messageScheduler))
+
+	select {
+	case err := <-served:
+		// Serve reports ErrServerStopped when shutdown won the race to start
+		if err != nil {
+			assert.ErrorIs(t, err, grpc.ErrServerStopped)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("gRPC server still serving after shutdown")
+	}
+}
@@ -17,7 +17,12 @@ const docTemplate = `{
     "paths": {
         "/api/v1/messages": {
             "get": {
-                "description": "Retrieves a list of messages with pagination",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves messages of one status, or of every status, newest first with pagination. With recipient, lists only messages sent to exactly that address. With from and/or to, lists only messages created in that range; a missing from means the epoch and a missing to means now. Recipient and the date range cannot be combined with each other or with status. With cursor, pages are read by keyset instead of offset, highest ID first, and next_cursor is returned while more messages remain; a cursor can only be combined with status and limit. Offset pages report total_pages and whether has_next and has_prev pages exist.",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,6 +34,31 @@ const docTemplate = `{
                 ],
                 "summary": "Get messages",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "default": "all",
+                        "description": "Status to list: all, pending, sent, failed, dead_letter, sending, cancelled or expired",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent to this exact address",
+                        "name": "recipient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages created at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages created at or before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "default": 0,
@@ -36,6 +66,12 @@ const docTemplate = `{
                         "name": "offset",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page; empty to start keyset pagination from the newest message",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "default": 50,
@@ -51,11 +87,28 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Creates a new message to be sent",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new message to be sent over its channel: webhook (the default, needs webhook_url), email or sms (recipient in E.164 format). A webhook message may carry a payload_template that renders its webhook body; one that does not parse or render valid JSON is rejected with 400. With ttl_seconds or expires_at, a message still undelivered when it expires is marked expired instead of being sent. A retried request carrying the same Idempotency-Key returns the original message with 200 instead of creating another. With dry_run=true or an X-Dry-Run: true header the message is validated and returned with 200 and dry_run set, but neither stored nor sent; the recipient's daily limit is not checked. Responds 429 with Retry-After when the client exceeds RATE_LIMIT_RPS or the recipient its daily limit.",
                 "consumes": [
                     "application/json"
                 ],
@@ -75,9 +128,33 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.CreateMessageRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate the message without storing or sending it",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client key that makes retries of this create safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Same as dry_run",
+                        "name": "X-Dry-Run",
+                        "in": "header"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Message previously created with this Idempotency-Key, or a dry run's message with dry_run set",
+                        "schema": {
+                            "$ref": "#/definitions/api.DryRunMessageResponse"
+                        }
+                    },
                     "201": {
                         "description": "Created",
                         "schema": {
@@ -87,23 +164,32 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/messages/retry": {
+        "/api/v1/messages/bulk": {
             "post": {
-                "description": "Retries all failed messages",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates up to 500 messages in one transaction. Each message is validated on its own: invalid ones and ones over their recipient's daily limit are reported in their result while the rest are created. Responds 201 when at least one message was created and 400 when none was.",
                 "consumes": [
                     "application/json"
                 ],
@@ -113,14 +199,72 @@ const docTemplate = `{
                 "tags": [
                     "messages"
                 ],
-                "summary": "Retry failed messages",
+                "summary": "Create messages in bulk",
                 "parameters": [
                     {
-                        "description": "Retry parameters",
-                        "name": "retry",
+                        "description": "Messages to create",
+                        "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.RetryRequest"
+                            "$ref": "#/definitions/api.BulkCreateMessagesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkCreateMessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.BulkCreateMessagesResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/claim": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": [],
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves up to limit messages that are due for delivery to sending and returns them to an external worker, which must report each outcome through ack or nack. Messages not reported within the claim lease become claimable again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Claim messages for delivery",
+                "parameters": [
+                    {
+                        "description": "Maximum messages to claim, between 1 and 100 (default 10)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ClaimMessagesRequest"
                         }
                     }
                 ],
@@ -135,23 +279,32 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/messages/sent": {
+        "/api/v1/messages/dead-letter": {
             "get": {
-                "description": "Retrieves a list of sent messages with pagination",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves messages that exhausted their retries, including their last error",
                 "consumes": [
                     "application/json"
                 ],
@@ -161,7 +314,7 @@ const docTemplate = `{
                 "tags": [
                     "messages"
                 ],
-                "summary": "Get sent messages",
+                "summary": "Get dead-letter messages",
                 "parameters": [
                     {
                         "type": "integer",
@@ -182,15 +335,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.PaginatedResponse"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/messages/{id}": {
+        "/api/v1/messages/failed": {
             "get": {
-                "description": "Retrieves a specific message by ID",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves messages whose delivery failed, most recently failed first, with their last error and retry count",
                 "consumes": [
                     "application/json"
                 ],
@@ -200,50 +365,53 @@ const docTemplate = `{
                 "tags": [
                     "messages"
                 ],
-                "summary": "Get a specific message",
+                "summary": "Get failed messages",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Message ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.MessageResponse"
+                            "$ref": "#/definitions/api.PaginatedResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/scheduler/start": {
-            "post": {
-                "description": "Starts the message processing scheduler",
+        "/api/v1/messages/recent": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves messages of any status created within the given duration, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -251,9 +419,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scheduler"
+                    "messages"
+                ],
+                "summary": "Get recent messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "5m",
+                        "description": "Look-back window as a Go duration, up to 24h",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of messages",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Start the message scheduler",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -265,16 +449,26 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/scheduler/stop": {
+        "/api/v1/messages/retry": {
             "post": {
-                "description": "Stops the message processing scheduler",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retries all failed messages",
                 "consumes": [
                     "application/json"
                 ],
@@ -282,9 +476,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scheduler"
+                    "messages"
+                ],
+                "summary": "Retry failed messages",
+                "parameters": [
+                    {
+                        "description": "Retry parameters",
+                        "name": "retry",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.RetryRequest"
+                        }
+                    }
                 ],
-                "summary": "Stop the message scheduler",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -296,16 +500,26 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/healthz": {
+        "/api/v1/messages/sent": {
             "get": {
-                "description": "Returns the health status of the service",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a list of sent messages with pagination, most recently sent first unless sort or order is given",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,44 +527,1721 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "messages"
+                ],
+                "summary": "Get sent messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sent_at",
+                            "created_at"
+                        ],
+                        "type": "string",
+                        "default": "sent_at",
+                        "description": "Timestamp to order by",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort direction",
+                        "name": "order",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health check endpoint",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.HealthResponse"
+                            "$ref": "#/definitions/api.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.CreateMessageRequest": {
-            "type": "object",
+        },
+        "/api/v1/messages/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how many messages there are in each status; statuses with no messages are reported as 0",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Count messages by status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves a specific message by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a specific message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the recipient, content or webhook URL of a message that has not been picked up for delivery. The edited message is validated as on create; a message that is no longer pending cannot be edited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Edit a pending message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/ack": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": [],
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks a message claimed by an external worker as sent, optionally storing the receiver's message ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Acknowledge a claimed message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Receiver's reference for the delivered message",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.AckMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/attempts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves every webhook request made to deliver a message, oldest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Get a message's webhook attempts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookAttemptsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Withdraws a pending, failed or claimed message so it is not delivered. A webhook request in flight for the message on this instance is aborted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Cancel a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/nack": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": [],
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records a failed delivery by an external worker. The message is retried with the usual backoff, or dead-lettered once its retries are exhausted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Report a failed delivery of a claimed message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the delivery failed",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.NackMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/requeue": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resets a failed or dead-lettered message back to pending with a fresh retry budget. A message in any other status is rejected with 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Requeue a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/resend": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queues a sent message for redelivery as a new pending message with its own ID and a resent_from reference. The original message is left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Resend a sent message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/messages/{id}/send": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delivers a pending, failed or dead-lettered message immediately and returns it with the outcome recorded; a failed delivery is reported in the message's status and error_message rather than as an error. The message is locked while it is sent, so a processing run never delivers it at the same time. A sent message is only delivered again with force=true; a failed forced resend leaves it sent and answers 502. Cancelled, expired and claimed messages are rejected with 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "messages"
+                ],
+                "summary": "Send a message now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Deliver the message even if it was already sent",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler/config": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the processing and retry intervals as Go durations. A running scheduler picks them up without a restart.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Change the scheduler intervals",
+                "parameters": [
+                    {
+                        "description": "New processing and retry intervals",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateSchedulerConfigRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler/start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Starts the message processing scheduler",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Start the message scheduler",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reports whether the scheduler is running, its intervals and when each loop last ran",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Get the message scheduler status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler/stop": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops the message processing scheduler",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Stop the message scheduler",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/scheduler/trigger": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Runs one processing cycle immediately, whether or not the scheduler is running, and reports how many messages were processed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Run a processing cycle now",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/success-rate": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the ratio of sent to sent plus dead-lettered messages over a time window, optionally per webhook host",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get delivery success rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "1h",
+                        "description": "Time window as a Go duration, between 1m and 720h",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include a per-webhook-host breakdown",
+                        "name": "by_host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SuccessRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/suppressions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the recipients and webhook hosts whose deliveries are currently on hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "List suppressions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Holds deliveries to a recipient (an email address or E.164 phone number) or a webhook host, for a duration or until removed. Messages for the key stay pending, or failed, until then. Suppressing a host pauses it as POST /api/v1/webhooks/pause does.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Suppress a recipient or webhook host",
+                "parameters": [
+                    {
+                        "description": "Recipient or host to suppress and optional duration as a Go duration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateSuppressionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Suppression"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/suppressions/{key}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lifts the suppression of a recipient or webhook host so its messages are delivered on the next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Remove a suppression",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Suppressed recipient or host",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/delivery": {
+            "post": {
+                "description": "Accepts a provider's signed report that a message was delivered or failed. The X-Signature-256 header must be \"sha256=\" followed by the hex HMAC-SHA256 of the X-Signature-Timestamp value, a dot and the raw body, keyed with DELIVERY_WEBHOOK_SECRET; reports signed outside DELIVERY_WEBHOOK_TOLERANCE are rejected. A failed report retries or dead-letters the message as usual; repeated reports are no-ops.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Receive a delivery status report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sha256=\u003chex HMAC-SHA256 of timestamp.body\u003e",
+                        "name": "X-Signature-256",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signing time in Unix seconds",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Delivery outcome",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeliveryStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Holds deliveries to a webhook host, for a duration or until resumed. Messages for the host keep their current status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Pause deliveries to a webhook host",
+                "parameters": [
+                    {
+                        "description": "Host to pause and optional duration as a Go duration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.PauseHostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PausedHost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/paused": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the webhook hosts whose deliveries are currently on hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List paused webhook hosts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/webhooks/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lifts a pause so messages for the webhook host are delivered on the next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Resume deliveries to a webhook host",
+                "parameters": [
+                    {
+                        "description": "Host to resume",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ResumeHostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Probes each dependency (database, redis) and reports whether the scheduler is running and the startup self-check result. Responds 503 when any dependency is unavailable or a critical self-check failed. /healthz is an alias kept for compatibility.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Reports that the process is up and serving requests. It does not probe dependencies, so an outage of the database or Redis does not get the service restarted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.LivenessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Probes each dependency (database, redis) and reports whether the scheduler is running and the startup self-check result. Responds 503 when any dependency is unavailable or a critical self-check failed. /healthz is an alias kept for compatibility.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Returns the version, git commit and build date the binary was built with. Builds without injected values report \"dev\" and \"unknown\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build metadata",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.VersionResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.APIError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "MESSAGE_NOT_FOUND"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Message not found"
+                }
+            }
+        },
+        "api.AckMessageRequest": {
+            "type": "object",
+            "properties": {
+                "provider_message_id": {
+                    "type": "string",
+                    "example": "abc123"
+                }
+            }
+        },
+        "api.BulkCreateMessagesRequest": {
+            "type": "object",
+            "required": [
+                "messages"
+            ],
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CreateMessageRequest"
+                    }
+                }
+            }
+        },
+        "api.BulkCreateMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BulkCreateResult"
+                    }
+                }
+            }
+        },
+        "api.BulkCreateResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "recipient must be a valid email address"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "message": {
+                    "$ref": "#/definitions/api.MessageResponse"
+                }
+            }
+        },
+        "api.ClaimMessagesRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "api.CreateMessageRequest": {
+            "type": "object",
             "required": [
                 "content",
-                "recipient",
-                "webhook_url"
+                "recipient"
+            ],
+            "properties": {
+                "channel": {
+                    "description": "Defaults to webhook",
+                    "type": "string",
+                    "enum": [
+                        "webhook",
+                        "email",
+                        "sms"
+                    ],
+                    "example": "webhook"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "Hello, World!"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "payload_template": {
+                    "description": "PayloadTemplate is a Go text/template rendering the webhook body from\nthe payload fields; the default payload is sent when empty",
+                    "type": "string",
+                    "example": "{\"to\":{{json .Recipient}},\"text\":{{json .Content}}}"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "ttl_seconds": {
+                    "description": "TTLSeconds and ExpiresAt drop the message as expired when it is still\nundelivered after that many seconds or at that time; at most one may\nbe set, and neither means the message never expires",
+                    "type": "integer",
+                    "example": 86400
+                },
+                "webhook_url": {
+                    "description": "Required for the webhook channel",
+                    "type": "string",
+                    "example": "https://example.com/webhook"
+                }
+            }
+        },
+        "api.CreateSuppressionRequest": {
+            "type": "object",
+            "required": [
+                "key"
+            ],
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "30m"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "api.DeliveryStatusRequest": {
+            "type": "object",
+            "required": [
+                "message_id",
+                "status"
             ],
             "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "recipient unreachable"
+                },
+                "message_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "provider_message_id": {
+                    "type": "string",
+                    "example": "abc123"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "sent",
+                        "failed"
+                    ],
+                    "example": "sent"
+                }
+            }
+        },
+        "api.DryRunMessageResponse": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "example": "webhook"
+                },
                 "content": {
                     "type": "string",
-                    "example": "Hello, World!"
+                    "example": "Hello, World!"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "webhook delivery failed with status 500"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "failed_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "idempotency_key": {
+                    "type": "string",
+                    "example": "order-42"
+                },
+                "max_retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "next_retry_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:02:00Z"
+                },
+                "payload_template": {
+                    "type": "string",
+                    "example": "{\"to\":{{json .Recipient}},\"text\":{{json .Content}}}"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "provider_message_id": {
+                    "type": "string",
+                    "example": "abc123"
                 },
                 "recipient": {
                     "type": "string",
                     "example": "user@example.com"
                 },
+                "resent_from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "retry_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "sent_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "sent"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
                 "webhook_url": {
                     "type": "string",
                     "example": "https://example.com/webhook"
                 }
             }
         },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/api.APIError"
+                }
+            }
+        },
         "api.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "dependencies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "scheduler": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "self_check": {
+                    "$ref": "#/definitions/selfcheck.Report"
+                },
+                "service": {
+                    "type": "string",
+                    "example": "insider-messaging"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v0.1.0"
+                }
+            }
+        },
+        "api.LivenessResponse": {
             "type": "object",
             "properties": {
                 "service": {
@@ -370,6 +2261,10 @@ const docTemplate = `{
         "api.MessageResponse": {
             "type": "object",
             "properties": {
+                "channel": {
+                    "type": "string",
+                    "example": "webhook"
+                },
                 "content": {
                     "type": "string",
                     "example": "Hello, World!"
@@ -378,14 +2273,58 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
                 },
+                "error_message": {
+                    "type": "string",
+                    "example": "webhook delivery failed with status 500"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-01-02T00:00:00Z"
+                },
+                "failed_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "idempotency_key": {
+                    "type": "string",
+                    "example": "order-42"
+                },
+                "max_retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "next_retry_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:02:00Z"
+                },
+                "payload_template": {
+                    "type": "string",
+                    "example": "{\"to\":{{json .Recipient}},\"text\":{{json .Content}}}"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "provider_message_id": {
+                    "type": "string",
+                    "example": "abc123"
+                },
                 "recipient": {
                     "type": "string",
                     "example": "user@example.com"
                 },
+                "resent_from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "retry_count": {
+                    "type": "integer",
+                    "example": 0
+                },
                 "sent_at": {
                     "type": "string",
                     "example": "2023-01-01T00:01:00Z"
@@ -394,12 +2333,28 @@ const docTemplate = `{
                     "type": "string",
                     "example": "sent"
                 },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
                 "webhook_url": {
                     "type": "string",
                     "example": "https://example.com/webhook"
                 }
             }
         },
+        "api.NackMessageRequest": {
+            "type": "object",
+            "required": [
+                "error"
+            ],
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "receiver returned 503"
+                }
+            }
+        },
         "api.PaginatedResponse": {
             "type": "object",
             "properties": {
@@ -409,6 +2364,14 @@ const docTemplate = `{
                         "$ref": "#/definitions/api.MessageResponse"
                     }
                 },
+                "has_next": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "has_prev": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "limit": {
                     "type": "integer",
                     "example": 10
@@ -420,6 +2383,38 @@ const docTemplate = `{
                 "total": {
                     "type": "integer",
                     "example": 100
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "api.PauseHostRequest": {
+            "type": "object",
+            "required": [
+                "host"
+            ],
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "30m"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "api.partner.com"
+                }
+            }
+        },
+        "api.ResumeHostRequest": {
+            "type": "object",
+            "required": [
+                "host"
+            ],
+            "properties": {
+                "host": {
+                    "type": "string",
+                    "example": "api.partner.com"
                 }
             }
         },
@@ -430,6 +2425,238 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "api.UpdateMessageRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Hello again!"
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "webhook_url": {
+                    "type": "string",
+                    "example": "https://example.com/webhook"
+                }
+            }
+        },
+        "api.UpdateSchedulerConfigRequest": {
+            "type": "object",
+            "required": [
+                "processing_interval",
+                "retry_interval"
+            ],
+            "properties": {
+                "processing_interval": {
+                    "type": "string",
+                    "example": "10s"
+                },
+                "retry_interval": {
+                    "type": "string",
+                    "example": "1m"
+                }
+            }
+        },
+        "api.VersionResponse": {
+            "type": "object",
+            "properties": {
+                "build_date": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "1c68b1d"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v0.1.0"
+                }
+            }
+        },
+        "api.WebhookAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T00:01:00Z"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string",
+                    "example": "webhook delivery failed with status 500"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "api.WebhookAttemptsResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookAttemptResponse"
+                    }
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.HostSuccessRate": {
+            "type": "object",
+            "properties": {
+                "dead_lettered": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "host": {
+                    "type": "string",
+                    "example": "webhook.site"
+                },
+                "sent": {
+                    "type": "integer",
+                    "example": 49
+                },
+                "success_rate": {
+                    "type": "number",
+                    "example": 0.98
+                }
+            }
+        },
+        "domain.PausedHost": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string",
+                    "example": "api.partner.com"
+                },
+                "paused_at": {
+                    "type": "string"
+                },
+                "until": {
+                    "description": "Until is when the pause lapses on its own; nil means until resumed",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SuccessRate": {
+            "type": "object",
+            "properties": {
+                "dead_lettered": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.HostSuccessRate"
+                    }
+                },
+                "sent": {
+                    "type": "integer",
+                    "example": 98
+                },
+                "success_rate": {
+                    "type": "number",
+                    "example": 0.98
+                },
+                "window": {
+                    "type": "string",
+                    "example": "1h0m0s"
+                }
+            }
+        },
+        "domain.Suppression": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "recipient",
+                        "host"
+                    ],
+                    "example": "recipient"
+                },
+                "until": {
+                    "description": "Until is when the suppression lapses on its own; nil means until removed",
+                    "type": "string"
+                }
+            }
+        },
+        "selfcheck.Report": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/selfcheck.Result"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "selfcheck.Result": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean"
+                },
+                "duration": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminKeyAuth": {
+            "type": "apiKey",
+            "name": "X-Admin-Key",
+            "in": "header"
+        },
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/service"
+)
+
+// maxDeliveryReportSize bounds the body read to verify a delivery report
+const maxDeliveryReportSize = 64 << 10
+
+// WithDeliveryWebhook serves POST /api/v1/webhooks/delivery, accepting delivery
+// reports signed with secret whose signing time is within tolerance of now. An
+// empty secret leaves the endpoint unregistered.
+func WithDeliveryWebhook(secret string, tolerance time.Duration) ServerOption {
+	return func(s *Server) {
+		s.deliverySecret = secret
+		s.deliveryTolerance = tolerance
+	}
+}
+
+// DeliveryStatusRequest is a provider's report of a message's delivery outcome
+type DeliveryStatusRequest struct {
+	MessageID         int64  `json:"message_id" binding:"required" example:"1"`
+	Status            string `json:"status" binding:"required,oneof=sent failed" example:"sent" enums:"sent,failed"`
+	ProviderMessageID string `json:"provider_message_id,omitempty" example:"abc123"`
+	Error             string `json:"error,omitempty" example:"recipient unreachable"`
+}
+
+// defaultDeliveryError is recorded for failed reports that carry no error text
+const defaultDeliveryError = "provider reported delivery failure"
+
+// deliverySignature returns the X-Signature-256 value a delivery report must
+// carry: the hex-encoded HMAC-SHA256, keyed with secret, of the
+// X-Signature-Timestamp value, a dot and the body, prefixed with "sha256=".
+// Covering the timestamp stops a captured report from being replayed with a
+// fresh one.
+func deliverySignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyDeliverySignature checks that timestamp, in Unix seconds, is within
+// tolerance of now and that signature matches the body signed at that time
+func verifyDeliverySignature(secret string, tolerance time.Duration, now time.Time, timestamp, signature string, body []byte) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid signature timestamp")
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > tolerance || skew < -tolerance {
+		return errors.New("signature timestamp outside tolerance")
+	}
+
+	expected := deliverySignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// verifyDeliveryReport rejects a delivery report with 401 unless its
+// X-Signature-Timestamp is within the tolerance and its X-Signature-256
+// matches the body. The body is restored for the handler.
+func (s *Server) verifyDeliveryReport(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDeliveryReportSize))
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	timestamp := c.GetHeader(service.SignatureTimestampHeader)
+	signature := c.GetHeader(service.SignatureHeader)
+	if err := verifyDeliverySignature(s.deliverySecret, s.deliveryTolerance, time.Now(), timestamp, signature, body); err != nil {
+		s.requestLogger(c).Warn("Rejected delivery report", "error", err)
+		abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Next()
+}
+
+// receiveDeliveryStatus godoc
+// @Summary Receive a delivery status report
+// @Description Accepts a provider's signed report that a message was delivered or failed. The X-Signature-256 header must be "sha256=" followed by the hex HMAC-SHA256 of the X-Signature-Timestamp value, a dot and the raw body, keyed with DELIVERY_WEBHOOK_SECRET; reports signed outside DELIVERY_WEBHOOK_TOLERANCE are rejected. A failed report retries or dead-letters the message as usual; repeated reports are no-ops.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param X-Signature-256 header string true "sha256=<hex HMAC-SHA256 of timestamp.body>"
+// @Param X-Signature-Timestamp header string true "Signing time in Unix seconds"
+// @Param request body DeliveryStatusRequest true "Delivery outcome"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/webhooks/delivery [post]
+func (s *Server) receiveDeliveryStatus(c *gin.Context) {
+	var req DeliveryStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid delivery report body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "message_id and a status of sent or failed are required")
+		return
+	}
+
+	errorMsg := req.Error
+	if req.Status == string(domain.MessageStatusFailed) && errorMsg == "" {
+		errorMsg = defaultDeliveryError
+	}
+
+	message, err := s.messageService.RecordDeliveryStatus(c.Request.Context(), req.MessageID, domain.MessageStatus(req.Status), req.ProviderMessageID, errorMsg)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to record delivery status", "message_id", req.MessageID, "status", req.Status, "error", err)
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageNotSent):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotSent, "Message has not been sent")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to record delivery status")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testDeliverySecret = "receipt-s3cret"
+
+// signedDeliveryRequest builds a delivery report signed with secret at signedAt
+func signedDeliveryRequest(secret string, signedAt time.Time, body string) *http.Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	req, _ := http.NewRequest("POST", "/api/v1/webhooks/delivery", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(service.SignatureTimestampHeader, timestamp)
+	req.Header.Set(service.SignatureHeader, deliverySignature(secret, timestamp, []byte(body)))
+	return req
+}
+
+func TestReceiveDeliveryStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sentBody := `{"message_id":1,"status":"sent","provider_message_id":"abc123"}`
+	unauthorized := `{"error":{"code":"UNAUTHORIZED","message":"unauthorized"}}`
+
+	tests := []struct {
+		name           string
+		request        func() *http.Request
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "valid sent report",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now(), sentBody)
+			},
+			mockSetup: func(m *MockMessageService) {
+				m.On("RecordDeliveryStatus", mock.Anything, int64(1), domain.MessageStatusSent, "abc123", "").
+					Return(&domain.Message{ID: 1, Status: domain.MessageStatusSent, MaxRetries: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"recipient":"","content":"","webhook_url":"","status":"sent","retry_count":0,"max_retries":3,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "failed report without error text",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now(), `{"message_id":2,"status":"failed"}`)
+			},
+			mockSetup: func(m *MockMessageService) {
+				m.On("RecordDeliveryStatus", mock.Anything, int64(2), domain.MessageStatusFailed, "", defaultDeliveryError).
+					Return(&domain.Message{ID: 2, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":2,"recipient":"","content":"","webhook_url":"","status":"failed","retry_count":1,"max_retries":3,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "expired timestamp",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now().Add(-10*time.Minute), sentBody)
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   unauthorized,
+		},
+		{
+			name: "timestamp in the future",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now().Add(10*time.Minute), sentBody)
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   unauthorized,
+		},
+		{
+			name: "bad signature",
+			request: func() *http.Request {
+				return signedDeliveryRequest("wrong-secret", time.Now(), sentBody)
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   unauthorized,
+		},
+		{
+			name: "replayed signature with a fresh timestamp",
+			request: func() *http.Request {
+				req := signedDeliveryRequest(testDeliverySecret, time.Now().Add(-time.Hour), sentBody)
+				req.Header.Set(service.SignatureTimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+				return req
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   unauthorized,
+		},
+		{
+			name: "missing signature headers",
+			request: func() *http.Request {
+				req, _ := http.NewRequest("POST", "/api/v1/webhooks/delivery", bytes.NewBufferString(sentBody))
+				return req
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   unauthorized,
+		},
+		{
+			name: "invalid status",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now(), `{"message_id":1,"status":"pending"}`)
+			},
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"message_id and a status of sent or failed are required"}}`,
+		},
+		{
+			name: "message not found",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now(), sentBody)
+			},
+			mockSetup: func(m *MockMessageService) {
+				m.On("RecordDeliveryStatus", mock.Anything, int64(1), domain.MessageStatusSent, "abc123", "").
+					Return(nil, domain.ErrMessageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name: "message never sent",
+			request: func() *http.Request {
+				return signedDeliveryRequest(testDeliverySecret, time.Now(), sentBody)
+			},
+			mockSetup: func(m *MockMessageService) {
+				m.On("RecordDeliveryStatus", mock.Anything, int64(1), domain.MessageStatusSent, "abc123", "").
+					Return(nil, domain.ErrMessageNotSent)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_SENT","message":"Message has not been sent"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			// The API key does not apply: reports authenticate with their signature
+			server := createTestServerWithMock(mockService,
+				WithAPIKey("key-123"),
+				WithDeliveryWebhook(testDeliverySecret, 5*time.Minute),
+			)
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, tt.request())
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReceiveDeliveryStatus_DisabledWithoutSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockMessageService{}
+	server := createTestServerWithMock(mockService)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, signedDeliveryRequest("", time.Now(), `{"message_id":1,"status":"sent"}`))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertNotCalled(t, "RecordDeliveryStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+// Error codes let clients branch on what went wrong without parsing messages
+const (
+	ErrCodeInvalidRequestBody      = "INVALID_REQUEST_BODY"
+	ErrCodeValidationFailed        = "VALIDATION_FAILED"
+	ErrCodeInvalidMessageID        = "INVALID_MESSAGE_ID"
+	ErrCodeMessageNotFound         = "MESSAGE_NOT_FOUND"
+	ErrCodeMessageAlreadySent      = "MESSAGE_ALREADY_SENT"
+	ErrCodeMessageNotSent          = "MESSAGE_NOT_SENT"
+	ErrCodeMessageNotPending       = "MESSAGE_NOT_PENDING"
+	ErrCodeMessageNotCancellable   = "MESSAGE_NOT_CANCELLABLE"
+	ErrCodeMessageNotRequeueable   = "MESSAGE_NOT_REQUEUEABLE"
+	ErrCodeMessageNotSendable      = "MESSAGE_NOT_SENDABLE"
+	ErrCodeDeliveryFailed          = "DELIVERY_FAILED"
+	ErrCodeMessageNotClaimed       = "MESSAGE_NOT_CLAIMED"
+	ErrCodeHostNotPaused           = "HOST_NOT_PAUSED"
+	ErrCodeNotSuppressed           = "NOT_SUPPRESSED"
+	ErrCodeSchedulerUnavailable    = "SCHEDULER_UNAVAILABLE"
+	ErrCodeSchedulerAlreadyRunning = "SCHEDULER_ALREADY_RUNNING"
+	ErrCodeSchedulerNotRunning     = "SCHEDULER_NOT_RUNNING"
+	ErrCodeProcessingInProgress    = "PROCESSING_IN_PROGRESS"
+	ErrCodeRecipientLimitExceeded  = "RECIPIENT_LIMIT_EXCEEDED"
+	ErrCodeRateLimited             = "RATE_LIMITED"
+	ErrCodeUnauthorized            = "UNAUTHORIZED"
+	ErrCodeForbidden               = "FORBIDDEN"
+	ErrCodeInternal                = "INTERNAL_ERROR"
+)
+
+// APIError describes a failed request
+type APIError struct {
+	Code    string      `json:"code" example:"MESSAGE_NOT_FOUND"`
+	Message string      `json:"message" example:"Message not found"`
+	Details interface{} `json:"details,omitempty" swaggertype:"object"`
+}
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// FieldErrorResponse describes why one request field was rejected; a
+// VALIDATION_FAILED error lists them in its details
+type FieldErrorResponse struct {
+	Field   string `json:"field" example:"recipient"`
+	Message string `json:"message" example:"recipient is required"`
+}
+
+// respondError answers status with an error of the given code and message
+func respondError(c *gin.Context, status int, code, message string) {
+	respondErrorDetails(c, status, code, message, nil)
+}
+
+// respondErrorDetails answers status with an error carrying details
+func respondErrorDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, ErrorResponse{Error: APIError{Code: code, Message: message, Details: details}})
+}
+
+// abortWithError answers status with an error and stops the handler chain
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: APIError{Code: code, Message: message}})
+}
+
+// respondValidationError answers 400 with the error message and every rejected field
+func respondValidationError(c *gin.Context, err *domain.ValidationError) {
+	var fields []FieldErrorResponse
+	for _, field := range err.Fields {
+		fields = append(fields, FieldErrorResponse{Field: field.Field, Message: field.Message})
+	}
+	if len(fields) == 0 {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Message)
+		return
+	}
+	respondErrorDetails(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Message, fields)
+}
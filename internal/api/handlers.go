@@ -1,15 +1,29 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/insider/insider-messaging/internal/scheduler"
+	"github.com/insider/insider-messaging/internal/selfcheck"
 	"github.com/insider/insider-messaging/internal/service"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/version"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -20,24 +34,139 @@ type Server struct {
 	logger         *logger.Logger
 	messageService service.MessageService
 	scheduler      *scheduler.Scheduler
+	location       *time.Location
+	dependencies   []dependency
+	selfCheck      *selfcheck.Report
+	apiKey         string
+	adminAPIKey    string
+
+	// Per-client-IP limit on message creation; zero rateLimitRPS disables it
+	rateLimitRPS   float64
+	rateLimitBurst int
+
+	// Signed delivery reports; an empty deliverySecret disables the receiver
+	deliverySecret    string
+	deliveryTolerance time.Duration
+
+	// Prometheus scrape endpoint; nil metricsHandler leaves /metrics
+	// unregistered, and empty credentials leave it open
+	metricsHandler http.Handler
+	metricsUser    string
+	metricsPass    string
+}
+
+// HealthChecker is a dependency the health check probes, such as the database
+// or the Redis cache
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// dependency is a named HealthChecker
+type dependency struct {
+	name    string
+	checker HealthChecker
+}
+
+// healthCheckTimeout bounds how long the health check waits on each dependency
+const healthCheckTimeout = 2 * time.Second
+
+// ServerOption configures optional server behavior
+type ServerOption func(*Server)
+
+// WithDisplayLocation sets the time zone message timestamps are reported in.
+// Timestamps are always stored in UTC; this only affects API output.
+func WithDisplayLocation(loc *time.Location) ServerOption {
+	return func(s *Server) {
+		if loc != nil {
+			s.location = loc
+		}
+	}
+}
+
+// WithDependency adds a dependency to the health check under name. The health
+// check reports 503 while any dependency is unhealthy.
+func WithDependency(name string, checker HealthChecker) ServerOption {
+	return func(s *Server) {
+		if checker != nil {
+			s.dependencies = append(s.dependencies, dependency{name: name, checker: checker})
+		}
+	}
+}
+
+// WithSelfCheckReport sets the startup self-check result reported by the
+// readiness check; a report with a failed critical check makes it respond 503
+func WithSelfCheckReport(report *selfcheck.Report) ServerOption {
+	return func(s *Server) {
+		s.selfCheck = report
+	}
+}
+
+// WithAPIKey requires every /api/v1 request to carry key in the X-API-Key
+// header. An empty key leaves the API unauthenticated.
+func WithAPIKey(key string) ServerOption {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithAdminAPIKey requires the external worker routes (claim, ack and nack) to
+// carry key in the X-Admin-Key header, on top of any API key. An empty key
+// disables those routes.
+func WithAdminAPIKey(key string) ServerOption {
+	return func(s *Server) {
+		s.adminAPIKey = key
+	}
+}
+
+// WithRateLimit limits message creation to rps requests per second per client
+// IP with bursts of up to burst. A zero rps disables the limit.
+func WithRateLimit(rps float64, burst int) ServerOption {
+	return func(s *Server) {
+		s.rateLimitRPS = rps
+		s.rateLimitBurst = burst
+	}
+}
+
+// WithMetricsHandler serves handler, typically the Prometheus handler, at /metrics
+func WithMetricsHandler(handler http.Handler) ServerOption {
+	return func(s *Server) {
+		s.metricsHandler = handler
+	}
+}
+
+// WithMetricsAuth requires HTTP basic auth with user and pass on /metrics.
+// Empty credentials leave the endpoint open.
+func WithMetricsAuth(user, pass string) ServerOption {
+	return func(s *Server) {
+		s.metricsUser = user
+		s.metricsPass = pass
+	}
 }
 
 // NewServer creates a new HTTP server
-func NewServer(log *logger.Logger, messageService service.MessageService, sched *scheduler.Scheduler) *Server {
+func NewServer(log *logger.Logger, messageService service.MessageService, sched *scheduler.Scheduler, opts ...ServerOption) *Server {
 	// Set Gin mode based on environment
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
 
+	apiLogger := log.WithComponent("api")
+
 	// Add middleware
 	router.Use(gin.Recovery())
+	router.Use(RequestIDMiddleware(apiLogger))
 	router.Use(LoggerMiddleware(log))
 
 	server := &Server{
 		router:         router,
-		logger:         log.WithComponent("api"),
+		logger:         apiLogger,
 		messageService: messageService,
 		scheduler:      sched,
+		location:       time.UTC,
+	}
+
+	for _, opt := range opts {
+		opt(server)
 	}
 
 	server.setupRoutes()
@@ -46,58 +175,368 @@ func NewServer(log *logger.Logger, messageService service.MessageService, sched
 
 // setupRoutes configures all the routes
 func (s *Server) setupRoutes() {
-	// Health check endpoint
-	s.router.GET("/healthz", s.healthCheck)
+	// Health check endpoints: liveness for restarts, readiness for routing
+	// traffic; /healthz is kept as an alias of readiness
+	s.router.GET("/livez", s.livenessCheck)
+	s.router.GET("/readyz", s.readinessCheck)
+	s.router.GET("/healthz", s.readinessCheck)
+
+	// Build metadata
+	s.router.GET("/version", s.getVersion)
+
+	// Prometheus metrics, behind basic auth when credentials are configured
+	if s.metricsHandler != nil {
+		metricsHandlers := []gin.HandlerFunc{gin.WrapH(s.metricsHandler)}
+		if s.metricsUser != "" || s.metricsPass != "" {
+			metricsHandlers = append([]gin.HandlerFunc{BasicAuthMiddleware(s.metricsUser, s.metricsPass)}, metricsHandlers...)
+		}
+		s.router.GET("/metrics", metricsHandlers...)
+	}
 
 	// Swagger documentation
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Delivery reports come from the provider, which authenticates with a
+	// signature rather than the API key
+	if s.deliverySecret != "" {
+		s.router.POST("/api/v1/webhooks/delivery", s.verifyDeliveryReport, s.receiveDeliveryStatus)
+	}
+
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
+	if s.apiKey != "" {
+		v1.Use(AuthMiddleware(s.apiKey))
+	}
 	{
 		// Scheduler routes (to be implemented)
 		scheduler := v1.Group("/scheduler")
 		{
+			scheduler.GET("/status", s.getSchedulerStatus)
 			scheduler.POST("/start", s.startScheduler)
 			scheduler.POST("/stop", s.stopScheduler)
+			scheduler.POST("/trigger", s.triggerScheduler)
+			scheduler.PUT("/config", s.updateSchedulerConfig)
+		}
+
+		// Message creation is rate limited per client when configured; single
+		// and bulk creates share one budget
+		createHandlers := []gin.HandlerFunc{s.createMessage}
+		bulkCreateHandlers := []gin.HandlerFunc{s.createMessagesBulk}
+		if s.rateLimitRPS > 0 {
+			limit := RateLimitMiddleware(s.rateLimitRPS, s.rateLimitBurst)
+			createHandlers = append([]gin.HandlerFunc{limit}, createHandlers...)
+			bulkCreateHandlers = append([]gin.HandlerFunc{limit}, bulkCreateHandlers...)
 		}
 
 		// Messages routes (to be implemented)
 		messages := v1.Group("/messages")
 		{
-			messages.POST("", s.createMessage)
+			messages.POST("", createHandlers...)
+			messages.POST("/bulk", bulkCreateHandlers...)
 			messages.GET("", s.getMessages)
 			messages.GET("/:id", s.getMessage)
+			messages.PATCH("/:id", s.updateMessage)
+			messages.GET("/:id/attempts", s.getMessageAttempts)
 			messages.GET("/sent", s.getSentMessages)
+			messages.GET("/stats", s.getMessageStats)
+			messages.GET("/failed", s.getFailedMessages)
+			messages.GET("/dead-letter", s.getDeadLetterMessages)
+			messages.GET("/recent", s.getRecentMessages)
 			messages.POST("/retry", s.retryFailedMessages)
+			messages.POST("/:id/requeue", s.requeueMessage)
+			messages.POST("/:id/resend", s.resendMessage)
+			messages.POST("/:id/send", s.sendMessageNow)
+			messages.POST("/:id/cancel", s.cancelMessage)
+
+			// Pull-based delivery for external workers, which hold the admin key
+			admin := AdminAuthMiddleware(s.adminAPIKey)
+			messages.POST("/claim", admin, s.claimMessages)
+			messages.POST("/:id/ack", admin, s.ackMessage)
+			messages.POST("/:id/nack", admin, s.nackMessage)
+		}
+
+		// Stats routes
+		stats := v1.Group("/stats")
+		{
+			stats.GET("/success-rate", s.getSuccessRate)
+		}
+
+		// Webhook host routes
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.GET("/paused", s.getPausedHosts)
+			webhooks.POST("/pause", s.pauseHost)
+			webhooks.POST("/resume", s.resumeHost)
+		}
+
+		// Suppression routes
+		suppressions := v1.Group("/suppressions")
+		{
+			suppressions.GET("", s.getSuppressions)
+			suppressions.POST("", s.createSuppression)
+			suppressions.DELETE("/:key", s.deleteSuppression)
 		}
 	}
 }
 
-// HealthResponse represents the health check response
-type HealthResponse struct {
+// Health statuses reported by the health checks, overall and per dependency
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+// Scheduler states reported by the readiness check
+const (
+	schedulerStateRunning = "running"
+	schedulerStateStopped = "stopped"
+)
+
+// LivenessResponse represents the liveness check response
+type LivenessResponse struct {
 	Status  string `json:"status" example:"ok"`
 	Service string `json:"service" example:"insider-messaging"`
 	Version string `json:"version" example:"v0.1.0"`
 }
 
-// healthCheck godoc
-// @Summary Health check endpoint
-// @Description Returns the health status of the service
+// HealthResponse represents the readiness check response
+type HealthResponse struct {
+	Status       string            `json:"status" example:"ok"`
+	Service      string            `json:"service" example:"insider-messaging"`
+	Version      string            `json:"version" example:"v0.1.0"`
+	Dependencies map[string]string `json:"dependencies"`
+	Scheduler    string            `json:"scheduler" example:"running"`
+	SelfCheck    *selfcheck.Report `json:"self_check,omitempty"`
+}
+
+// livenessCheck godoc
+// @Summary Liveness check endpoint
+// @Description Reports that the process is up and serving requests. It does not probe dependencies, so an outage of the database or Redis does not get the service restarted.
+// @Tags health
+// @Accept json
+// @Produce json
+// @Success 200 {object} LivenessResponse
+// @Router /livez [get]
+func (s *Server) livenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, LivenessResponse{
+		Status:  healthStatusOK,
+		Service: "insider-messaging",
+		Version: version.Version,
+	})
+}
+
+// VersionResponse represents the build metadata response
+type VersionResponse struct {
+	Version   string `json:"version" example:"v0.1.0"`
+	Commit    string `json:"commit" example:"1c68b1d"`
+	BuildDate string `json:"build_date" example:"2024-01-01T12:00:00Z"`
+}
+
+// getVersion godoc
+// @Summary Build metadata
+// @Description Returns the version, git commit and build date the binary was built with. Builds without injected values report "dev" and "unknown".
+// @Tags health
+// @Produce json
+// @Success 200 {object} VersionResponse
+// @Router /version [get]
+func (s *Server) getVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, VersionResponse{
+		Version:   version.Version,
+		Commit:    version.Commit,
+		BuildDate: version.BuildDate,
+	})
+}
+
+// readinessCheck godoc
+// @Summary Readiness check endpoint
+// @Description Probes each dependency (database, redis) and reports whether the scheduler is running and the startup self-check result. Responds 503 when any dependency is unavailable or a critical self-check failed. /healthz is an alias kept for compatibility.
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /readyz [get]
 // @Router /healthz [get]
-func (s *Server) healthCheck(c *gin.Context) {
+func (s *Server) readinessCheck(c *gin.Context) {
 	response := HealthResponse{
-		Status:  "ok",
-		Service: "insider-messaging",
-		Version: "v0.1.0",
+		Status:       healthStatusOK,
+		Service:      "insider-messaging",
+		Version:      version.Version,
+		Dependencies: s.checkDependencies(c.Request.Context()),
+		Scheduler:    schedulerStateStopped,
+		SelfCheck:    s.selfCheck,
+	}
+	if s.scheduler != nil && s.scheduler.IsRunning() {
+		response.Scheduler = schedulerStateRunning
 	}
 
-	s.logger.Info("Health check requested")
-	c.JSON(http.StatusOK, response)
+	code := http.StatusOK
+	for _, status := range response.Dependencies {
+		if status != healthStatusOK {
+			response.Status = healthStatusUnavailable
+			code = http.StatusServiceUnavailable
+		}
+	}
+	if s.selfCheck != nil && !s.selfCheck.Ready() {
+		response.Status = healthStatusUnavailable
+		code = http.StatusServiceUnavailable
+	}
+
+	s.requestLogger(c).Info("Readiness check requested", "status", response.Status)
+	c.JSON(code, response)
+}
+
+// checkDependencies probes every dependency concurrently, each with its own
+// timeout, and returns their statuses by name
+func (s *Server) checkDependencies(ctx context.Context) map[string]string {
+	statuses := make(map[string]string, len(s.dependencies))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, dep := range s.dependencies {
+		wg.Add(1)
+		go func(dep dependency) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			status := healthStatusOK
+			if err := dep.checker.Health(checkCtx); err != nil {
+				s.logger.Error("Health check dependency unavailable", "dependency", dep.name, "error", err)
+				status = healthStatusUnavailable
+			}
+
+			mu.Lock()
+			statuses[dep.name] = status
+			mu.Unlock()
+		}(dep)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// getSchedulerStatus godoc
+// @Summary Get the message scheduler status
+// @Description Reports whether the scheduler is running, its intervals and when each loop last ran
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/scheduler/status [get]
+func (s *Server) getSchedulerStatus(c *gin.Context) {
+	if s.scheduler == nil {
+		s.requestLogger(c).Error("Scheduler not initialized")
+		respondError(c, http.StatusInternalServerError, ErrCodeSchedulerUnavailable, "Scheduler not available")
+		return
+	}
+
+	c.JSON(http.StatusOK, s.scheduler.GetStatus())
+}
+
+// triggerScheduler godoc
+// @Summary Run a processing cycle now
+// @Description Runs one processing cycle immediately, whether or not the scheduler is running, and reports how many messages were processed
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/scheduler/trigger [post]
+func (s *Server) triggerScheduler(c *gin.Context) {
+	if s.scheduler == nil {
+		s.requestLogger(c).Error("Scheduler not initialized")
+		respondError(c, http.StatusInternalServerError, ErrCodeSchedulerUnavailable, "Scheduler not available")
+		return
+	}
+
+	processed, err := s.scheduler.TriggerProcessing(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, scheduler.ErrProcessingInProgress) {
+			s.requestLogger(c).Warn("Processing run already in progress")
+			respondError(c, http.StatusConflict, ErrCodeProcessingInProgress, "A processing run is already in progress")
+			return
+		}
+
+		s.requestLogger(c).Error("Triggered processing run failed", "error", err)
+		respondErrorDetails(c, http.StatusInternalServerError, ErrCodeInternal, "Processing run failed", err.Error())
+		return
+	}
+
+	s.requestLogger(c).Info("Triggered processing run completed", "processed", processed)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Processing run completed",
+		"processed": processed,
+	})
+}
+
+// UpdateSchedulerConfigRequest represents the request body for changing scheduler intervals
+type UpdateSchedulerConfigRequest struct {
+	ProcessingInterval string `json:"processing_interval" binding:"required" example:"10s"`
+	RetryInterval      string `json:"retry_interval" binding:"required" example:"1m"`
+}
+
+// updateSchedulerConfig godoc
+// @Summary Change the scheduler intervals
+// @Description Sets the processing and retry intervals as Go durations. A running scheduler picks them up without a restart.
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Param request body UpdateSchedulerConfigRequest true "New processing and retry intervals"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/scheduler/config [put]
+func (s *Server) updateSchedulerConfig(c *gin.Context) {
+	if s.scheduler == nil {
+		s.requestLogger(c).Error("Scheduler not initialized")
+		respondError(c, http.StatusInternalServerError, ErrCodeSchedulerUnavailable, "Scheduler not available")
+		return
+	}
+
+	var req UpdateSchedulerConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid scheduler config request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "processing_interval and retry_interval are required")
+		return
+	}
+
+	processingInterval, err := time.ParseDuration(req.ProcessingInterval)
+	if err != nil || processingInterval <= 0 {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "processing_interval must be a positive duration such as 10s")
+		return
+	}
+
+	retryInterval, err := time.ParseDuration(req.RetryInterval)
+	if err != nil || retryInterval <= 0 {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "retry_interval must be a positive duration such as 1m")
+		return
+	}
+
+	err = s.scheduler.UpdateConfig(&scheduler.Config{
+		ProcessingInterval: processingInterval,
+		RetryInterval:      retryInterval,
+	})
+	if err != nil {
+		if errors.Is(err, scheduler.ErrInvalidInterval) {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+			return
+		}
+
+		s.requestLogger(c).Error("Failed to update scheduler config", "error", err)
+		respondErrorDetails(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to update scheduler config", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scheduler config updated",
+		"status":  s.scheduler.GetStatus(),
+	})
 }
 
 // startScheduler godoc
@@ -107,36 +546,29 @@ func (s *Server) healthCheck(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Security ApiKeyAuth
 // @Router /api/v1/scheduler/start [post]
 func (s *Server) startScheduler(c *gin.Context) {
 	if s.scheduler == nil {
-		s.logger.Error("Scheduler not initialized")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Scheduler not available",
-		})
+		s.requestLogger(c).Error("Scheduler not initialized")
+		respondError(c, http.StatusInternalServerError, ErrCodeSchedulerUnavailable, "Scheduler not available")
 		return
 	}
 
 	if s.scheduler.IsRunning() {
-		s.logger.Warn("Scheduler is already running")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":  "Scheduler is already running",
-			"status": s.scheduler.GetStatus(),
-		})
+		s.requestLogger(c).Warn("Scheduler is already running")
+		respondErrorDetails(c, http.StatusBadRequest, ErrCodeSchedulerAlreadyRunning, "Scheduler is already running", gin.H{"status": s.scheduler.GetStatus()})
 		return
 	}
 
 	if err := s.scheduler.Start(c.Request.Context()); err != nil {
-		s.logger.Error("Failed to start scheduler", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to start scheduler",
-			"details": err.Error(),
-		})
+		s.requestLogger(c).Error("Failed to start scheduler", "error", err)
+		respondErrorDetails(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to start scheduler", err.Error())
 		return
 	}
 
-	s.logger.Info("Scheduler started successfully")
+	s.requestLogger(c).Info("Scheduler started successfully")
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Scheduler started successfully",
 		"status":  s.scheduler.GetStatus(),
@@ -150,36 +582,29 @@ func (s *Server) startScheduler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Security ApiKeyAuth
 // @Router /api/v1/scheduler/stop [post]
 func (s *Server) stopScheduler(c *gin.Context) {
 	if s.scheduler == nil {
-		s.logger.Error("Scheduler not initialized")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Scheduler not available",
-		})
+		s.requestLogger(c).Error("Scheduler not initialized")
+		respondError(c, http.StatusInternalServerError, ErrCodeSchedulerUnavailable, "Scheduler not available")
 		return
 	}
 
 	if !s.scheduler.IsRunning() {
-		s.logger.Warn("Scheduler is not running")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":  "Scheduler is not running",
-			"status": s.scheduler.GetStatus(),
-		})
+		s.requestLogger(c).Warn("Scheduler is not running")
+		respondErrorDetails(c, http.StatusBadRequest, ErrCodeSchedulerNotRunning, "Scheduler is not running", gin.H{"status": s.scheduler.GetStatus()})
 		return
 	}
 
 	if err := s.scheduler.Stop(); err != nil {
-		s.logger.Error("Failed to stop scheduler", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to stop scheduler",
-			"details": err.Error(),
-		})
+		s.requestLogger(c).Error("Failed to stop scheduler", "error", err)
+		respondErrorDetails(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to stop scheduler", err.Error())
 		return
 	}
 
-	s.logger.Info("Scheduler stopped successfully")
+	s.requestLogger(c).Info("Scheduler stopped successfully")
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Scheduler stopped successfully",
 		"status":  s.scheduler.GetStatus(),
@@ -190,26 +615,121 @@ func (s *Server) stopScheduler(c *gin.Context) {
 type CreateMessageRequest struct {
 	Recipient  string `json:"recipient" binding:"required" example:"user@example.com"`
 	Content    string `json:"content" binding:"required" example:"Hello, World!"`
-	WebhookURL string `json:"webhook_url" binding:"required" example:"https://example.com/webhook"`
+	WebhookURL string `json:"webhook_url,omitempty" example:"https://example.com/webhook"` // Required for the webhook channel
+	Priority   int    `json:"priority,omitempty" example:"0"`
+	Channel    string `json:"channel,omitempty" enums:"webhook,email,sms" example:"webhook"` // Defaults to webhook
+
+	// PayloadTemplate is a Go text/template rendering the webhook body from
+	// the payload fields; the default payload is sent when empty
+	PayloadTemplate string `json:"payload_template,omitempty" example:"{\"to\":{{json .Recipient}},\"text\":{{json .Content}}}"`
+
+	// TTLSeconds and ExpiresAt drop the message as expired when it is still
+	// undelivered after that many seconds or at that time; at most one may
+	// be set, and neither means the message never expires
+	TTLSeconds int        `json:"ttl_seconds,omitempty" example:"86400"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2023-01-02T00:00:00Z"`
 }
 
-// MessageResponse represents a message in API responses
+// MessageResponse represents a message in API responses. Every endpoint that
+// returns messages uses this shape; timestamps are RFC 3339 in the configured
+// display time zone (UTC by default).
 type MessageResponse struct {
-	ID         int64   `json:"id" example:"1"`
-	Recipient  string  `json:"recipient" example:"user@example.com"`
-	Content    string  `json:"content" example:"Hello, World!"`
-	Status     string  `json:"status" example:"sent"`
-	WebhookURL string  `json:"webhook_url" example:"https://example.com/webhook"`
-	CreatedAt  string  `json:"created_at" example:"2023-01-01T00:00:00Z"`
-	SentAt     *string `json:"sent_at,omitempty" example:"2023-01-01T00:01:00Z"`
+	ID                int64   `json:"id" example:"1"`
+	Recipient         string  `json:"recipient" example:"user@example.com"`
+	Content           string  `json:"content" example:"Hello, World!"`
+	WebhookURL        string  `json:"webhook_url" example:"https://example.com/webhook"`
+	Status            string  `json:"status" example:"sent"`
+	RetryCount        int     `json:"retry_count" example:"0"`
+	MaxRetries        int     `json:"max_retries" example:"3"`
+	Priority          int     `json:"priority" example:"0"`
+	Channel           string  `json:"channel,omitempty" example:"webhook"`
+	CreatedAt         string  `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	UpdatedAt         string  `json:"updated_at" example:"2023-01-01T00:01:00Z"`
+	SentAt            *string `json:"sent_at,omitempty" example:"2023-01-01T00:01:00Z"`
+	FailedAt          *string `json:"failed_at,omitempty" example:"2023-01-01T00:01:00Z"`
+	NextRetryAt       *string `json:"next_retry_at,omitempty" example:"2023-01-01T00:02:00Z"`
+	ErrorMessage      *string `json:"error_message,omitempty" example:"webhook delivery failed with status 500"`
+	ProviderMessageID *string `json:"provider_message_id,omitempty" example:"abc123"`
+	ResentFrom        *int64  `json:"resent_from,omitempty" example:"1"`
+	IdempotencyKey    *string `json:"idempotency_key,omitempty" example:"order-42"`
+	PayloadTemplate   string  `json:"payload_template,omitempty" example:"{\"to\":{{json .Recipient}},\"text\":{{json .Content}}}"`
+	ExpiresAt         *string `json:"expires_at,omitempty" example:"2023-01-02T00:00:00Z"`
+}
+
+// toMessageResponse maps a domain message to its API representation, reporting
+// timestamps in loc
+func toMessageResponse(message *domain.Message, loc *time.Location) MessageResponse {
+	return MessageResponse{
+		ID:                message.ID,
+		Recipient:         message.Recipient,
+		Content:           message.Content,
+		WebhookURL:        message.WebhookURL,
+		Status:            string(message.Status),
+		RetryCount:        message.RetryCount,
+		MaxRetries:        message.MaxRetries,
+		Priority:          message.Priority,
+		Channel:           string(message.Channel),
+		CreatedAt:         formatTimestamp(message.CreatedAt, loc),
+		UpdatedAt:         formatTimestamp(message.UpdatedAt, loc),
+		SentAt:            formatOptionalTimestamp(message.SentAt, loc),
+		FailedAt:          formatOptionalTimestamp(message.FailedAt, loc),
+		NextRetryAt:       formatOptionalTimestamp(message.NextRetryAt, loc),
+		ErrorMessage:      message.ErrorMessage,
+		ProviderMessageID: message.ProviderMessageID,
+		ResentFrom:        message.ResentFrom,
+		IdempotencyKey:    message.IdempotencyKey,
+		PayloadTemplate:   message.PayloadTemplate,
+		ExpiresAt:         formatOptionalTimestamp(message.ExpiresAt, loc),
+	}
+}
+
+// toMessageResponses maps a list of domain messages, never returning nil so
+// empty lists encode as []
+func toMessageResponses(messages []*domain.Message, loc *time.Location) []MessageResponse {
+	responses := make([]MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, toMessageResponse(message, loc))
+	}
+	return responses
+}
+
+// formatTimestamp formats t as RFC 3339 in loc. UTC renders with a Z suffix,
+// any other zone with its +hh:mm offset at that instant.
+func formatTimestamp(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// formatOptionalTimestamp formats t as RFC 3339 in loc, or returns nil when t is unset
+func formatOptionalTimestamp(t *time.Time, loc *time.Location) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := formatTimestamp(*t, loc)
+	return &formatted
 }
 
 // PaginatedResponse represents a paginated API response
 type PaginatedResponse struct {
-	Data  []MessageResponse `json:"data"`
-	Total int               `json:"total" example:"100"`
-	Page  int               `json:"page" example:"1"`
-	Limit int               `json:"limit" example:"10"`
+	Data       []MessageResponse `json:"data"`
+	Total      int               `json:"total" example:"100"`
+	Page       int               `json:"page" example:"1"`
+	Limit      int               `json:"limit" example:"10"`
+	TotalPages int               `json:"total_pages" example:"10"`
+	HasNext    bool              `json:"has_next" example:"true"`
+	HasPrev    bool              `json:"has_prev" example:"false"`
+}
+
+// pageInfo reports how many pages of limit items total spans and whether items
+// remain after or before the page starting at offset
+func pageInfo(total, offset, limit int) (totalPages int, hasNext, hasPrev bool) {
+	totalPages = total / limit
+	if total%limit != 0 {
+		totalPages++
+	}
+	return totalPages, offset < total-limit, offset > 0
 }
 
 // RetryResponse represents the response for retry operations
@@ -218,230 +738,1559 @@ type RetryResponse struct {
 	Count   int    `json:"count" example:"5"`
 }
 
+// DryRunMessageResponse is the message a dry-run create would have stored
+type DryRunMessageResponse struct {
+	MessageResponse
+	DryRun bool `json:"dry_run" example:"true"`
+}
+
+// dryRunHeader asks for a create to be validated without storing the message
+const dryRunHeader = "X-Dry-Run"
+
+// isDryRun reports whether the dry_run query parameter or X-Dry-Run header
+// asks for a dry run; the query parameter wins when both are set
+func isDryRun(c *gin.Context) (bool, error) {
+	value := c.Query("dry_run")
+	if value == "" {
+		value = c.GetHeader(dryRunHeader)
+	}
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
 // createMessage godoc
 // @Summary Create a new message
-// @Description Creates a new message to be sent
+// @Description Creates a new message to be sent over its channel: webhook (the default, needs webhook_url), email or sms (recipient in E.164 format). A webhook message may carry a payload_template that renders its webhook body; one that does not parse or render valid JSON is rejected with 400. With ttl_seconds or expires_at, a message still undelivered when it expires is marked expired instead of being sent. A retried request carrying the same Idempotency-Key returns the original message with 200 instead of creating another. With dry_run=true or an X-Dry-Run: true header the message is validated and returned with 200 and dry_run set, but neither stored nor sent; the recipient's daily limit is not checked. Responds 429 with Retry-After when the client exceeds RATE_LIMIT_RPS or the recipient its daily limit.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body CreateMessageRequest true "Message data"
+// @Param dry_run query bool false "Validate the message without storing or sending it"
+// @Param Idempotency-Key header string false "Client key that makes retries of this create safe"
+// @Param X-Dry-Run header bool false "Same as dry_run"
+// @Success 200 {object} DryRunMessageResponse "Message previously created with this Idempotency-Key, or a dry run's message with dry_run set"
 // @Success 201 {object} MessageResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
 // @Router /api/v1/messages [post]
 func (s *Server) createMessage(c *gin.Context) {
 	var req CreateMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		s.logger.Error("Invalid request body", "error", err)
+		s.requestLogger(c).Error("Invalid request body", "error", err)
 
-		// Check for specific validation errors
-		errorMsg := err.Error()
-		if strings.Contains(errorMsg, "Recipient") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Recipient is required"})
-			return
-		}
-		if strings.Contains(errorMsg, "Content") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Content is required"})
-			return
-		}
-		if strings.Contains(errorMsg, "WebhookURL") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL is required"})
+		var validationErr *domain.ValidationError
+		if errors.As(bindingValidationError(err, req), &validationErr) {
+			respondValidationError(c, validationErr)
 			return
 		}
 
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
 		return
 	}
 
-	message, err := s.messageService.CreateMessage(c.Request.Context(), &domain.CreateMessageRequest{
+	createReq := &domain.CreateMessageRequest{
 		Recipient:  req.Recipient,
 		Content:    req.Content,
 		WebhookURL: req.WebhookURL,
 		MaxRetries: 3, // Default max retries
-	})
+		Priority:   req.Priority,
+		Channel:    domain.Channel(req.Channel),
+
+		PayloadTemplate: req.PayloadTemplate,
+		TTL:             time.Duration(req.TTLSeconds) * time.Second,
+		ExpiresAt:       req.ExpiresAt,
+	}
+
+	dryRun, err := isDryRun(c)
 	if err != nil {
-		s.logger.Error("Failed to create message", "error", err, "recipient", req.Recipient)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create message"})
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "dry_run must be a boolean")
+		return
+	}
+	if dryRun {
+		s.validateMessage(c, createReq)
 		return
 	}
 
-	s.logger.Info("Message created successfully", "message_id", message.ID, "recipient", req.Recipient)
-	c.JSON(http.StatusCreated, message)
-}
+	var message *domain.Message
+	created := true
+	if key := c.GetHeader(idempotencyKeyHeader); key != "" {
+		message, created, err = s.messageService.CreateMessageIdempotent(c.Request.Context(), createReq, key)
+	} else {
+		message, err = s.messageService.CreateMessage(c.Request.Context(), createReq)
+	}
+	if err != nil {
+		s.requestLogger(c).Error("Failed to create message", "error", err, "recipient", req.Recipient)
 
-// getMessages godoc
-// @Summary Get messages
-// @Description Retrieves a list of messages with pagination
-// @Tags messages
-// @Accept json
-// @Produce json
-// @Param offset query int false "Offset for pagination" default(0)
-// @Param limit query int false "Items per page" default(50)
-// @Success 200 {object} map[string]interface{}
-// @Router /api/v1/messages [get]
-func (s *Server) getMessages(c *gin.Context) {
-	// Parse pagination parameters
-	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, validationErr)
+			return
+		}
+
+		if s.respondRecipientLimit(c, err) {
+			return
+		}
 
-	if offset < 0 {
-		offset = 0
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to create message")
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 50
+
+	if !created {
+		s.requestLogger(c).Info("Returning message for repeated idempotency key", "message_id", message.ID)
+		c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+		return
 	}
 
-	messages, total, err := s.messageService.GetSentMessages(c.Request.Context(), offset, limit)
+	s.requestLogger(c).Info("Message created successfully", "message_id", message.ID, "recipient", req.Recipient)
+	c.JSON(http.StatusCreated, toMessageResponse(message, s.location))
+}
+
+// validateMessage responds with the message createReq would create, or with
+// the errors that would reject it, without storing anything
+func (s *Server) validateMessage(c *gin.Context, createReq *domain.CreateMessageRequest) {
+	message, err := s.messageService.ValidateMessage(c.Request.Context(), createReq)
 	if err != nil {
-		s.logger.Error("Failed to get messages", "error", err, "offset", offset, "limit", limit)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get messages"})
+		s.requestLogger(c).Info("Dry-run message rejected", "error", err, "recipient", createReq.Recipient)
+
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, validationErr)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to validate message")
 		return
 	}
 
-	s.logger.Info("Messages retrieved successfully", "count", len(messages), "total", total, "offset", offset)
-	c.JSON(http.StatusOK, gin.H{
-		"messages": messages,
-		"total":    total,
-		"offset":   offset,
-		"limit":    limit,
+	c.JSON(http.StatusOK, DryRunMessageResponse{
+		MessageResponse: toMessageResponse(message, s.location),
+		DryRun:          true,
 	})
 }
 
-// getMessage godoc
-// @Summary Get a specific message
-// @Description Retrieves a specific message by ID
+// idempotencyKeyHeader carries the client key that deduplicates retried creates
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxBulkMessages caps how many messages one bulk create may carry
+const maxBulkMessages = 500
+
+// BulkCreateMessagesRequest represents the request body for creating many messages
+type BulkCreateMessagesRequest struct {
+	Messages []CreateMessageRequest `json:"messages" binding:"required"`
+}
+
+// BulkCreateResult reports the outcome of one message of a bulk create
+type BulkCreateResult struct {
+	Index   int              `json:"index" example:"0"`
+	Message *MessageResponse `json:"message,omitempty"`
+	Error   string           `json:"error,omitempty" example:"recipient must be a valid email address"`
+}
+
+// BulkCreateMessagesResponse represents the response for a bulk create, with
+// one result per submitted message in request order
+type BulkCreateMessagesResponse struct {
+	Created int                `json:"created" example:"2"`
+	Failed  int                `json:"failed" example:"1"`
+	Results []BulkCreateResult `json:"results"`
+}
+
+// createMessagesBulk godoc
+// @Summary Create messages in bulk
+// @Description Creates up to 500 messages in one transaction. Each message is validated on its own: invalid ones and ones over their recipient's daily limit are reported in their result while the rest are created. Responds 201 when at least one message was created and 400 when none was.
 // @Tags messages
 // @Accept json
 // @Produce json
-// @Param id path int true "Message ID"
-// @Success 200 {object} MessageResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /api/v1/messages/{id} [get]
-func (s *Server) getMessage(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		s.logger.Error("Invalid message ID", "id", idStr, "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+// @Param request body BulkCreateMessagesRequest true "Messages to create"
+// @Success 201 {object} BulkCreateMessagesResponse
+// @Failure 400 {object} BulkCreateMessagesResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/bulk [post]
+func (s *Server) createMessagesBulk(c *gin.Context) {
+	var req BulkCreateMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid bulk create request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
 		return
 	}
-
-	message, err := s.messageService.GetMessage(c.Request.Context(), id)
-	if err != nil {
-		s.logger.Error("Failed to get message", "message_id", id, "error", err)
-		if err == domain.ErrMessageNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message"})
-		}
+	if len(req.Messages) == 0 {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "messages must not be empty")
+		return
+	}
+	if len(req.Messages) > maxBulkMessages {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "at most 500 messages may be created per request")
+		return
+	}
+
+	reqs := make([]*domain.CreateMessageRequest, len(req.Messages))
+	for i, m := range req.Messages {
+		reqs[i] = &domain.CreateMessageRequest{
+			Recipient:  m.Recipient,
+			Content:    m.Content,
+			WebhookURL: m.WebhookURL,
+			MaxRetries: 3, // Default max retries
+			Priority:   m.Priority,
+			Channel:    domain.Channel(m.Channel),
+
+			PayloadTemplate: m.PayloadTemplate,
+			TTL:             time.Duration(m.TTLSeconds) * time.Second,
+			ExpiresAt:       m.ExpiresAt,
+		}
+	}
+
+	results, err := s.messageService.CreateMessages(c.Request.Context(), reqs)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to create messages", "error", err, "count", len(reqs))
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to create messages")
+		return
+	}
+
+	response := BulkCreateMessagesResponse{Results: make([]BulkCreateResult, len(results))}
+	for i, result := range results {
+		response.Results[i].Index = i
+		if result.Err != nil {
+			response.Failed++
+			response.Results[i].Error = result.Err.Error()
+			continue
+		}
+		message := toMessageResponse(result.Message, s.location)
+		response.Created++
+		response.Results[i].Message = &message
+	}
+
+	s.requestLogger(c).Info("Bulk create completed", "created", response.Created, "failed", response.Failed)
+
+	status := http.StatusCreated
+	if response.Created == 0 {
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, response)
+}
+
+// getMessages godoc
+// @Summary Get messages
+// @Description Retrieves messages of one status, or of every status, newest first with pagination. With recipient, lists only messages sent to exactly that address. With from and/or to, lists only messages created in that range; a missing from means the epoch and a missing to means now. Recipient and the date range cannot be combined with each other or with status. With cursor, pages are read by keyset instead of offset, highest ID first, and next_cursor is returned while more messages remain; a cursor can only be combined with status and limit. Offset pages report total_pages and whether has_next and has_prev pages exist.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param status query string false "Status to list: all, pending, sent, failed, dead_letter, sending, cancelled or expired" default(all)
+// @Param recipient query string false "Only messages sent to this exact address"
+// @Param from query string false "Only messages created at or after this RFC3339 time"
+// @Param to query string false "Only messages created at or before this RFC3339 time"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param cursor query string false "next_cursor of the previous page; empty to start keyset pagination from the newest message"
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages [get]
+func (s *Server) getMessages(c *gin.Context) {
+	// Parse pagination parameters
+	offset, err := queryInt(c, "offset", 0, 0, math.MaxInt)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	limit, err := queryInt(c, "limit", 50, 1, maxPageLimit)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+
+	// "all" lists every status and is passed on as the empty status
+	var status domain.MessageStatus
+	if param := c.DefaultQuery("status", "all"); param != "all" {
+		status = domain.MessageStatus(param)
+		if !status.IsValid() {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "status must be one of all, pending, sent, failed, dead_letter, sending, cancelled, expired")
+			return
+		}
+	}
+
+	if cursor, byCursor := c.GetQuery("cursor"); byCursor {
+		for _, param := range []string{"offset", "recipient", "from", "to"} {
+			if _, ok := c.GetQuery(param); ok {
+				respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "cursor cannot be combined with offset, recipient, from or to")
+				return
+			}
+		}
+		s.getMessagesAfterCursor(c, status, cursor, limit)
+		return
+	}
+
+	recipient, byRecipient := c.GetQuery("recipient")
+	if byRecipient {
+		if recipient == "" {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "recipient must not be empty")
+			return
+		}
+		if status != "" {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "recipient cannot be combined with status")
+			return
+		}
+	}
+
+	from, to, byDate, err := parseCreatedRange(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	if byDate && (byRecipient || status != "") {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "from and to cannot be combined with status or recipient")
+		return
+	}
+
+	var messages []*domain.Message
+	var total int
+	switch {
+	case byRecipient:
+		messages, total, err = s.messageService.GetMessagesByRecipient(c.Request.Context(), recipient, offset, limit)
+	case byDate:
+		messages, total, err = s.messageService.GetMessagesByDateRange(c.Request.Context(), from, to, offset, limit)
+	default:
+		messages, total, err = s.messageService.ListMessages(c.Request.Context(), status, offset, limit)
+	}
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get messages", "error", err, "status", status, "recipient", recipient, "offset", offset, "limit", limit)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get messages")
+		return
+	}
+
+	totalPages, hasNext, hasPrev := pageInfo(total, offset, limit)
+	s.requestLogger(c).Info("Messages retrieved successfully", "count", len(messages), "total", total, "offset", offset)
+	c.JSON(http.StatusOK, gin.H{
+		"messages":    toMessageResponses(messages, s.location),
+		"total":       total,
+		"offset":      offset,
+		"limit":       limit,
+		"total_pages": totalPages,
+		"has_next":    hasNext,
+		"has_prev":    hasPrev,
+	})
+}
+
+// getMessagesAfterCursor answers a keyset page of messages with the given
+// status. An empty cursor starts from the newest message.
+func (s *Server) getMessagesAfterCursor(c *gin.Context, status domain.MessageStatus, cursor string, limit int) {
+	cursorID, err := decodeCursor(cursor)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "cursor is invalid")
+		return
+	}
+
+	messages, nextCursorID, err := s.messageService.ListMessagesAfterCursor(c.Request.Context(), status, cursorID, limit)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get messages", "error", err, "status", status, "cursor_id", cursorID, "limit", limit)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get messages")
+		return
+	}
+
+	response := gin.H{
+		"messages": toMessageResponses(messages, s.location),
+		"limit":    limit,
+	}
+	if nextCursorID != 0 {
+		response["next_cursor"] = encodeCursor(nextCursorID)
+	}
+
+	s.requestLogger(c).Info("Messages retrieved successfully", "count", len(messages), "cursor_id", cursorID)
+	c.JSON(http.StatusOK, response)
+}
+
+// encodeCursor turns the last message ID of a page into an opaque cursor
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeCursor returns the message ID encoded by encodeCursor, or 0 for an
+// empty cursor
+func decodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("cursor ID must be positive")
+	}
+
+	return id, nil
+}
+
+// maxPageLimit is the largest page size the message list endpoints serve
+const maxPageLimit = 100
+
+// queryInt reads the integer query parameter name, returning def when the
+// parameter is absent and an error naming it when it is not an integer between
+// minValue and maxValue. A maxValue of math.MaxInt leaves it unbounded.
+func queryInt(c *gin.Context, name string, def, minValue, maxValue int) (int, error) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return def, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	if value < minValue || value > maxValue {
+		if maxValue == math.MaxInt {
+			return 0, fmt.Errorf("%s must be at least %d", name, minValue)
+		}
+		return 0, fmt.Errorf("%s must be between %d and %d", name, minValue, maxValue)
+	}
+
+	return value, nil
+}
+
+// parseCreatedRange reads the optional from and to RFC3339 query parameters.
+// A missing from means the epoch and a missing to means now; ok reports
+// whether either was given.
+func parseCreatedRange(c *gin.Context) (from, to time.Time, ok bool, err error) {
+	fromStr, hasFrom := c.GetQuery("from")
+	toStr, hasTo := c.GetQuery("to")
+	if !hasFrom && !hasTo {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	from = time.Unix(0, 0)
+	if hasFrom {
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			return time.Time{}, time.Time{}, false, errors.New("from must be an RFC3339 timestamp")
+		}
+	}
+
+	to = time.Now()
+	if hasTo {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			return time.Time{}, time.Time{}, false, errors.New("to must be an RFC3339 timestamp")
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, false, errors.New("from must not be after to")
+	}
+
+	return from, to, true, nil
+}
+
+// getMessageStats godoc
+// @Summary Count messages by status
+// @Description Returns how many messages there are in each status; statuses with no messages are reported as 0
+// @Tags messages
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/stats [get]
+func (s *Server) getMessageStats(c *gin.Context) {
+	counts, err := s.messageService.GetMessageCounts(c.Request.Context())
+	if err != nil {
+		s.requestLogger(c).Error("Failed to count messages by status", "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get message stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
+
+// getMessage godoc
+// @Summary Get a specific message
+// @Description Retrieves a specific message by ID
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id} [get]
+func (s *Server) getMessage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		s.requestLogger(c).Error("Invalid message ID", "id", idStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidMessageID, "Invalid message ID")
+		return
+	}
+
+	message, err := s.messageService.GetMessage(c.Request.Context(), id)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get message", "message_id", id, "error", err)
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		} else {
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get message")
+		}
+		return
+	}
+
+	s.requestLogger(c).Info("Message retrieved successfully", "message_id", id)
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// WebhookAttemptResponse is one webhook request made to deliver a message
+type WebhookAttemptResponse struct {
+	ID         int64   `json:"id" example:"1"`
+	StatusCode *int    `json:"status_code,omitempty" example:"500"`
+	DurationMs int64   `json:"duration_ms" example:"120"`
+	Error      *string `json:"error,omitempty" example:"webhook delivery failed with status 500"`
+	CreatedAt  string  `json:"created_at" example:"2023-01-01T00:01:00Z"`
+}
+
+// WebhookAttemptsResponse is a message's webhook attempt history, oldest first
+type WebhookAttemptsResponse struct {
+	MessageID int64                    `json:"message_id" example:"1"`
+	Attempts  []WebhookAttemptResponse `json:"attempts"`
+	Count     int                      `json:"count" example:"3"`
+}
+
+// getMessageAttempts godoc
+// @Summary Get a message's webhook attempts
+// @Description Retrieves every webhook request made to deliver a message, oldest first
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} WebhookAttemptsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id}/attempts [get]
+func (s *Server) getMessageAttempts(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		s.requestLogger(c).Error("Invalid message ID", "id", idStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidMessageID, "Invalid message ID")
+		return
+	}
+
+	attempts, err := s.messageService.GetMessageAttempts(c.Request.Context(), id)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get webhook attempts", "message_id", id, "error", err)
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		} else {
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get webhook attempts")
+		}
+		return
+	}
+
+	response := WebhookAttemptsResponse{
+		MessageID: id,
+		Attempts:  make([]WebhookAttemptResponse, 0, len(attempts)),
+		Count:     len(attempts),
+	}
+	for _, attempt := range attempts {
+		response.Attempts = append(response.Attempts, WebhookAttemptResponse{
+			ID:         attempt.ID,
+			StatusCode: attempt.StatusCode,
+			DurationMs: attempt.DurationMs,
+			Error:      attempt.Error,
+			CreatedAt:  formatTimestamp(attempt.CreatedAt, s.location),
+		})
+	}
+
+	s.requestLogger(c).Info("Webhook attempts retrieved successfully", "message_id", id, "count", len(attempts))
+	c.JSON(http.StatusOK, response)
+}
+
+// getSentMessages godoc
+// @Summary Get sent messages
+// @Description Retrieves a list of sent messages with pagination, most recently sent first unless sort or order is given
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(10)
+// @Param sort query string false "Timestamp to order by" Enums(sent_at, created_at) default(sent_at)
+// @Param order query string false "Sort direction" Enums(asc, desc) default(desc)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/sent [get]
+func (s *Server) getSentMessages(c *gin.Context) {
+	// Parse pagination parameters
+	page, err := queryInt(c, "page", 1, 1, math.MaxInt)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	limit, err := queryInt(c, "limit", 10, 1, maxPageLimit)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	if page-1 > math.MaxInt/limit {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "page is too large")
+		return
+	}
+	order := domain.MessageSort{
+		Field: domain.SortField(c.DefaultQuery("sort", string(domain.DefaultSentMessagesSort.Field))),
+		Order: domain.SortOrder(c.DefaultQuery("order", string(domain.DefaultSentMessagesSort.Order))),
+	}
+	if !order.Field.IsValid() {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "sort must be one of sent_at, created_at")
+		return
+	}
+	if !order.Order.IsValid() {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "order must be one of asc, desc")
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	messages, total, err := s.messageService.GetSentMessages(c.Request.Context(), offset, limit, order)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get sent messages", "error", err, "offset", offset, "limit", limit, "sort", order.Field, "order", order.Order)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get sent messages")
+		return
+	}
+
+	totalPages, hasNext, hasPrev := pageInfo(total, offset, limit)
+	response := PaginatedResponse{
+		Data:       toMessageResponses(messages, s.location),
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+		HasNext:    hasNext,
+		HasPrev:    hasPrev,
+	}
+
+	s.requestLogger(c).Info("Sent messages retrieved successfully", "count", len(messages), "total", total, "page", page)
+	c.JSON(http.StatusOK, response)
+}
+
+// getFailedMessages godoc
+// @Summary Get failed messages
+// @Description Retrieves messages whose delivery failed, most recently failed first, with their last error and retry count
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page, at most 100" default(10)
+// @Success 200 {object} PaginatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/failed [get]
+func (s *Server) getFailedMessages(c *gin.Context) {
+	page, err := queryInt(c, "page", 1, 1, math.MaxInt)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	limit, err := queryInt(c, "limit", 10, 1, maxPageLimit)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+		return
+	}
+	if page-1 > math.MaxInt/limit {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "page is too large")
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	messages, total, err := s.messageService.GetFailedMessages(c.Request.Context(), offset, limit)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get failed messages", "error", err, "offset", offset, "limit", limit)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get failed messages")
+		return
+	}
+
+	totalPages, hasNext, hasPrev := pageInfo(total, offset, limit)
+	response := PaginatedResponse{
+		Data:       toMessageResponses(messages, s.location),
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+		HasNext:    hasNext,
+		HasPrev:    hasPrev,
+	}
+
+	s.requestLogger(c).Info("Failed messages retrieved successfully", "count", len(messages), "total", total, "page", page)
+	c.JSON(http.StatusOK, response)
+}
+
+// getDeadLetterMessages godoc
+// @Summary Get dead-letter messages
+// @Description Retrieves messages that exhausted their retries, including their last error
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/dead-letter [get]
+func (s *Server) getDeadLetterMessages(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	messages, total, err := s.messageService.GetDeadLetterMessages(c.Request.Context(), offset, limit)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get dead-letter messages", "error", err, "offset", offset, "limit", limit)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get dead-letter messages")
+		return
+	}
+
+	s.requestLogger(c).Info("Dead-letter messages retrieved successfully", "count", len(messages), "total", total, "page", page)
+	c.JSON(http.StatusOK, gin.H{
+		"messages": toMessageResponses(messages, s.location),
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// Bounds for the recent messages since query parameter
+const (
+	defaultRecentSince = 5 * time.Minute
+	maxRecentSince     = 24 * time.Hour
+)
+
+// getRecentMessages godoc
+// @Summary Get recent messages
+// @Description Retrieves messages of any status created within the given duration, newest first
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param since query string false "Look-back window as a Go duration, up to 24h" default(5m)
+// @Param limit query int false "Maximum number of messages" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/recent [get]
+func (s *Server) getRecentMessages(c *gin.Context) {
+	sinceStr := c.DefaultQuery("since", defaultRecentSince.String())
+	since, err := time.ParseDuration(sinceStr)
+	if err != nil || since <= 0 || since > maxRecentSince {
+		s.requestLogger(c).Error("Invalid since parameter", "since", sinceStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "since must be a positive duration of at most 24h")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	messages, err := s.messageService.GetRecentMessages(c.Request.Context(), since, limit)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get recent messages", "error", err, "since", since, "limit", limit)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get recent messages")
+		return
+	}
+
+	s.requestLogger(c).Info("Recent messages retrieved successfully", "count", len(messages), "since", since)
+	c.JSON(http.StatusOK, gin.H{
+		"messages": toMessageResponses(messages, s.location),
+		"count":    len(messages),
+		"since":    since.String(),
+	})
+}
+
+// RetryRequest represents the request body for retrying failed messages
+type RetryRequest struct {
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// retryFailedMessages godoc
+// @Summary Retry failed messages
+// @Description Retries all failed messages
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param retry body RetryRequest false "Retry parameters"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/retry [post]
+func (s *Server) retryFailedMessages(c *gin.Context) {
+	var req RetryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = 10 // Default batch size
+	}
+
+	count, err := s.messageService.RetryFailedMessages(c.Request.Context(), batchSize)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to retry failed messages", "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to retry failed messages")
+		return
+	}
+
+	s.requestLogger(c).Info("Failed messages retry completed", "count", count)
+	c.JSON(http.StatusOK, gin.H{"retried_count": count})
+}
+
+// bindingValidationError converts the binding tag failures of a request body
+// bound into req to a ValidationError naming each field by its JSON name. It
+// returns nil when err is not a binding tag failure, e.g. malformed JSON.
+func bindingValidationError(err error, req interface{}) error {
+	var bindingErrs validator.ValidationErrors
+	if !errors.As(err, &bindingErrs) {
+		return nil
+	}
+
+	reqType := reflect.TypeOf(req)
+	fields := make([]domain.FieldError, 0, len(bindingErrs))
+	for _, bindingErr := range bindingErrs {
+		name := bindingErr.Field()
+		if structField, ok := reqType.FieldByName(bindingErr.StructField()); ok {
+			if tag, _, _ := strings.Cut(structField.Tag.Get("json"), ","); tag != "" {
+				name = tag
+			}
+		}
+
+		message := name + " is invalid"
+		if bindingErr.Tag() == "required" {
+			message = name + " is required"
+		}
+		fields = append(fields, domain.FieldError{Field: name, Message: message})
+	}
+
+	return domain.NewFieldValidationError(fields...)
+}
+
+// respondRecipientLimit answers 429 with Retry-After and reports true when err
+// is a recipient daily limit error
+func (s *Server) respondRecipientLimit(c *gin.Context, err error) bool {
+	var limitErr *domain.RecipientLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(time.Until(limitErr.ResetAt).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	respondErrorDetails(c, http.StatusTooManyRequests, ErrCodeRecipientLimitExceeded, limitErr.Error(),
+		gin.H{"reset_at": formatTimestamp(limitErr.ResetAt, s.location)})
+	return true
+}
+
+// resendMessage godoc
+// @Summary Resend a sent message
+// @Description Queues a sent message for redelivery as a new pending message with its own ID and a resent_from reference. The original message is left unchanged.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 201 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id}/resend [post]
+func (s *Server) resendMessage(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	message, err := s.messageService.ResendMessage(c.Request.Context(), id)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to resend message", "message_id", id, "error", err)
+		if s.respondRecipientLimit(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageNotSent):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotSent, "Only sent messages can be resent")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to resend message")
+		}
+		return
+	}
+
+	s.requestLogger(c).Info("Message resent successfully", "message_id", message.ID, "resent_from", id)
+	c.JSON(http.StatusCreated, toMessageResponse(message, s.location))
+}
+
+// sendMessageNow godoc
+// @Summary Send a message now
+// @Description Delivers a pending, failed or dead-lettered message immediately and returns it with the outcome recorded; a failed delivery is reported in the message's status and error_message rather than as an error. The message is locked while it is sent, so a processing run never delivers it at the same time. A sent message is only delivered again with force=true; a failed forced resend leaves it sent and answers 502. Cancelled, expired and claimed messages are rejected with 409.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Param force query bool false "Deliver the message even if it was already sent"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id}/send [post]
+func (s *Server) sendMessageNow(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		s.requestLogger(c).Error("Invalid force parameter", "force", c.Query("force"), "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "force must be a boolean")
+		return
+	}
+
+	message, err := s.messageService.SendNow(c.Request.Context(), id, force)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to send message", "message_id", id, "force", force, "error", err)
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageAlreadySent):
+			respondError(c, http.StatusConflict, ErrCodeMessageAlreadySent, "Message has already been sent; use force=true to send it again")
+		case errors.Is(err, domain.ErrMessageNotSendable):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotSendable, "Cancelled, expired or claimed messages cannot be sent")
+		case errors.Is(err, domain.ErrDeliveryFailed):
+			respondError(c, http.StatusBadGateway, ErrCodeDeliveryFailed, "Message could not be delivered again; it stays sent")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to send message")
+		}
+		return
+	}
+
+	s.requestLogger(c).Info("Message sent on demand", "message_id", id, "status", message.Status, "force", force)
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// requeueMessage godoc
+// @Summary Requeue a message
+// @Description Resets a failed or dead-lettered message back to pending with a fresh retry budget. A message in any other status is rejected with 409.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id}/requeue [post]
+func (s *Server) requeueMessage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		s.requestLogger(c).Error("Invalid message ID", "id", idStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidMessageID, "Invalid message ID")
+		return
+	}
+
+	message, err := s.messageService.RequeueMessage(c.Request.Context(), id)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to requeue message", "message_id", id, "error", err)
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageAlreadySent):
+			respondError(c, http.StatusConflict, ErrCodeMessageAlreadySent, "Message has already been sent")
+		case errors.Is(err, domain.ErrMessageNotRequeueable):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotRequeueable, "Only failed or dead-lettered messages can be requeued")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to requeue message")
+		}
+		return
+	}
+
+	s.requestLogger(c).Info("Message requeued successfully", "message_id", id)
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// cancelMessage godoc
+// @Summary Cancel a message
+// @Description Withdraws a pending, failed or claimed message so it is not delivered. A webhook request in flight for the message on this instance is aborted.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id}/cancel [post]
+func (s *Server) cancelMessage(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	message, err := s.messageService.CancelMessage(c.Request.Context(), id)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to cancel message", "message_id", id, "error", err)
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageNotCancellable):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotCancellable, "Message can no longer be cancelled")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to cancel message")
+		}
 		return
 	}
 
-	s.logger.Info("Message retrieved successfully", "message_id", id)
-	c.JSON(http.StatusOK, message)
+	s.requestLogger(c).Info("Message cancelled successfully", "message_id", id)
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
 }
 
-// getSentMessages godoc
-// @Summary Get sent messages
-// @Description Retrieves a list of sent messages with pagination
+// UpdateMessageRequest represents a partial edit of a pending message; omitted
+// fields are left unchanged
+type UpdateMessageRequest struct {
+	Recipient  *string `json:"recipient,omitempty" example:"user@example.com"`
+	Content    *string `json:"content,omitempty" example:"Hello again!"`
+	WebhookURL *string `json:"webhook_url,omitempty" example:"https://example.com/webhook"`
+}
+
+// updateMessage godoc
+// @Summary Edit a pending message
+// @Description Changes the recipient, content or webhook URL of a message that has not been picked up for delivery. The edited message is validated as on create; a message that is no longer pending cannot be edited.
 // @Tags messages
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
-// @Success 200 {object} PaginatedResponse
-// @Router /api/v1/messages/sent [get]
-func (s *Server) getSentMessages(c *gin.Context) {
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+// @Param id path int true "Message ID"
+// @Param message body UpdateMessageRequest true "Fields to change"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/messages/{id} [patch]
+func (s *Server) updateMessage(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	var req UpdateMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+		return
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+
+	message, err := s.messageService.UpdatePendingMessage(c.Request.Context(), id, &domain.UpdateMessageRequest{
+		Recipient:  req.Recipient,
+		Content:    req.Content,
+		WebhookURL: req.WebhookURL,
+	})
+	if err != nil {
+		s.requestLogger(c).Error("Failed to update message", "message_id", id, "error", err)
+
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			respondValidationError(c, validationErr)
+		case errors.Is(err, domain.ErrMessageNotFound):
+			respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+		case errors.Is(err, domain.ErrMessageNotPending):
+			respondError(c, http.StatusConflict, ErrCodeMessageNotPending, "Only pending messages can be edited")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to update message")
+		}
+		return
 	}
 
-	offset := (page - 1) * limit
+	s.requestLogger(c).Info("Message updated successfully", "message_id", id)
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// Claim batch size bounds for external workers
+const (
+	defaultClaimLimit = 10
+	maxClaimLimit     = 100
+)
+
+// ClaimMessagesRequest represents the request body for claiming messages
+type ClaimMessagesRequest struct {
+	Limit int `json:"limit,omitempty" example:"10"`
+}
+
+// AckMessageRequest represents the request body for acknowledging a delivery
+type AckMessageRequest struct {
+	ProviderMessageID string `json:"provider_message_id,omitempty" example:"abc123"`
+}
+
+// NackMessageRequest represents the request body for reporting a failed delivery
+type NackMessageRequest struct {
+	Error string `json:"error" binding:"required" example:"receiver returned 503"`
+}
+
+// claimMessages godoc
+// @Summary Claim messages for delivery
+// @Description Moves up to limit messages that are due for delivery to sending and returns them to an external worker, which must report each outcome through ack or nack. Messages not reported within the claim lease become claimable again.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param request body ClaimMessagesRequest false "Maximum messages to claim, between 1 and 100 (default 10)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth && AdminKeyAuth
+// @Router /api/v1/messages/claim [post]
+func (s *Server) claimMessages(c *gin.Context) {
+	var req ClaimMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.requestLogger(c).Error("Invalid claim request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+		return
+	}
 
-	messages, total, err := s.messageService.GetSentMessages(c.Request.Context(), offset, limit)
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultClaimLimit
+	}
+	if limit < 1 || limit > maxClaimLimit {
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "limit must be between 1 and 100")
+		return
+	}
+
+	messages, err := s.messageService.ClaimMessages(c.Request.Context(), limit)
 	if err != nil {
-		s.logger.Error("Failed to get sent messages", "error", err, "offset", offset, "limit", limit)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get sent messages"})
+		s.requestLogger(c).Error("Failed to claim messages", "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to claim messages")
 		return
 	}
 
-	var responseMessages []MessageResponse
-	for _, message := range messages {
-		response := MessageResponse{
-			ID:         message.ID,
-			Recipient:  message.Recipient,
-			Content:    message.Content,
-			Status:     string(message.Status),
-			WebhookURL: message.WebhookURL,
-			CreatedAt:  message.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	c.JSON(http.StatusOK, gin.H{
+		"messages": toMessageResponses(messages, s.location),
+		"count":    len(messages),
+	})
+}
+
+// ackMessage godoc
+// @Summary Acknowledge a claimed message
+// @Description Marks a message claimed by an external worker as sent, optionally storing the receiver's message ID
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Param request body AckMessageRequest false "Receiver's reference for the delivered message"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth && AdminKeyAuth
+// @Router /api/v1/messages/{id}/ack [post]
+func (s *Server) ackMessage(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req AckMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.requestLogger(c).Error("Invalid ack request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+		return
+	}
+
+	message, err := s.messageService.AckMessage(c.Request.Context(), id, req.ProviderMessageID)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to acknowledge message", "message_id", id, "error", err)
+		s.respondClaimError(c, err, "Failed to acknowledge message")
+		return
+	}
+
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// nackMessage godoc
+// @Summary Report a failed delivery of a claimed message
+// @Description Records a failed delivery by an external worker. The message is retried with the usual backoff, or dead-lettered once its retries are exhausted.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Param request body NackMessageRequest true "Why the delivery failed"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth && AdminKeyAuth
+// @Router /api/v1/messages/{id}/nack [post]
+func (s *Server) nackMessage(c *gin.Context) {
+	id, ok := s.parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req NackMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid nack request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Error is required")
+		return
+	}
+
+	message, err := s.messageService.NackMessage(c.Request.Context(), id, req.Error)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to reject message", "message_id", id, "error", err)
+		s.respondClaimError(c, err, "Failed to reject message")
+		return
+	}
+
+	c.JSON(http.StatusOK, toMessageResponse(message, s.location))
+}
+
+// parseMessageID reads the :id path parameter, responding 400 when it is not a number
+func (s *Server) parseMessageID(c *gin.Context) (int64, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		s.requestLogger(c).Error("Invalid message ID", "id", idStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidMessageID, "Invalid message ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// respondClaimError maps an ack or nack failure to its HTTP response
+func (s *Server) respondClaimError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrMessageNotFound):
+		respondError(c, http.StatusNotFound, ErrCodeMessageNotFound, "Message not found")
+	case errors.Is(err, domain.ErrMessageNotClaimed):
+		respondError(c, http.StatusConflict, ErrCodeMessageNotClaimed, "Message is not claimed")
+	default:
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, fallback)
+	}
+}
+
+// Bounds for the success-rate window query parameter
+const (
+	minSuccessRateWindow = time.Minute
+	maxSuccessRateWindow = 30 * 24 * time.Hour
+)
+
+// getSuccessRate godoc
+// @Summary Get delivery success rate
+// @Description Returns the ratio of sent to sent plus dead-lettered messages over a time window, optionally per webhook host
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param window query string false "Time window as a Go duration, between 1m and 720h" default(1h)
+// @Param by_host query bool false "Include a per-webhook-host breakdown" default(false)
+// @Success 200 {object} domain.SuccessRate
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/stats/success-rate [get]
+func (s *Server) getSuccessRate(c *gin.Context) {
+	windowStr := c.DefaultQuery("window", "1h")
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window < minSuccessRateWindow || window > maxSuccessRateWindow {
+		s.requestLogger(c).Error("Invalid success rate window", "window", windowStr, "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "window must be a duration between 1m and 720h")
+		return
+	}
+
+	byHost, err := strconv.ParseBool(c.DefaultQuery("by_host", "false"))
+	if err != nil {
+		s.requestLogger(c).Error("Invalid by_host parameter", "by_host", c.Query("by_host"), "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "by_host must be a boolean")
+		return
+	}
+
+	rate, err := s.messageService.GetSuccessRate(c.Request.Context(), window, byHost)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to get success rate", "window", window, "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to get success rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// PauseHostRequest represents the request body for pausing a webhook host
+type PauseHostRequest struct {
+	Host     string `json:"host" binding:"required" example:"api.partner.com"`
+	Duration string `json:"duration,omitempty" example:"30m"`
+}
+
+// ResumeHostRequest represents the request body for resuming a webhook host
+type ResumeHostRequest struct {
+	Host string `json:"host" binding:"required" example:"api.partner.com"`
+}
+
+// pauseHost godoc
+// @Summary Pause deliveries to a webhook host
+// @Description Holds deliveries to a webhook host, for a duration or until resumed. Messages for the host keep their current status.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body PauseHostRequest true "Host to pause and optional duration as a Go duration"
+// @Success 200 {object} domain.PausedHost
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/pause [post]
+func (s *Server) pauseHost(c *gin.Context) {
+	var req PauseHostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid pause request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Host is required")
+		return
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil || parsed <= 0 {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "duration must be a positive duration such as 30m")
+			return
 		}
+		duration = parsed
+	}
+
+	paused, err := s.messageService.PauseHost(c.Request.Context(), req.Host, duration)
+	if err != nil {
+		s.requestLogger(c).Error("Failed to pause webhook host", "host", req.Host, "error", err)
 
-		if message.SentAt != nil {
-			sentAt := message.SentAt.Format("2006-01-02T15:04:05Z")
-			response.SentAt = &sentAt
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, validationErr)
+			return
 		}
 
-		responseMessages = append(responseMessages, response)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to pause webhook host")
+		return
 	}
 
-	response := PaginatedResponse{
-		Data:  responseMessages,
-		Total: total,
-		Page:  page,
-		Limit: limit,
+	c.JSON(http.StatusOK, paused)
+}
+
+// resumeHost godoc
+// @Summary Resume deliveries to a webhook host
+// @Description Lifts a pause so messages for the webhook host are delivered on the next run
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body ResumeHostRequest true "Host to resume"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/resume [post]
+func (s *Server) resumeHost(c *gin.Context) {
+	var req ResumeHostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.requestLogger(c).Error("Invalid resume request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Host is required")
+		return
 	}
 
-	s.logger.Info("Sent messages retrieved successfully", "count", len(messages), "total", total, "page", page)
-	c.JSON(http.StatusOK, response)
-}
+	if err := s.messageService.ResumeHost(c.Request.Context(), req.Host); err != nil {
+		s.requestLogger(c).Error("Failed to resume webhook host", "host", req.Host, "error", err)
 
-// RetryRequest represents the request body for retrying failed messages
-type RetryRequest struct {
-	BatchSize int `json:"batch_size,omitempty"`
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			respondValidationError(c, validationErr)
+		case errors.Is(err, domain.ErrHostNotPaused):
+			respondError(c, http.StatusNotFound, ErrCodeHostNotPaused, "Webhook host is not paused")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to resume webhook host")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook host resumed",
+		"host":    req.Host,
+	})
 }
 
-// retryFailedMessages godoc
-// @Summary Retry failed messages
-// @Description Retries all failed messages
-// @Tags messages
+// getPausedHosts godoc
+// @Summary List paused webhook hosts
+// @Description Returns the webhook hosts whose deliveries are currently on hold
+// @Tags webhooks
 // @Accept json
 // @Produce json
-// @Param retry body RetryRequest false "Retry parameters"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /api/v1/messages/retry [post]
-func (s *Server) retryFailedMessages(c *gin.Context) {
-	var req RetryRequest
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/webhooks/paused [get]
+func (s *Server) getPausedHosts(c *gin.Context) {
+	hosts, err := s.messageService.GetPausedHosts(c.Request.Context())
+	if err != nil {
+		s.requestLogger(c).Error("Failed to list paused webhook hosts", "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list paused webhook hosts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hosts": hosts,
+		"count": len(hosts),
+	})
+}
+
+// CreateSuppressionRequest represents the request body for suppressing a
+// recipient or webhook host
+type CreateSuppressionRequest struct {
+	Key      string `json:"key" binding:"required" example:"user@example.com"`
+	Duration string `json:"duration,omitempty" example:"30m"`
+}
+
+// createSuppression godoc
+// @Summary Suppress a recipient or webhook host
+// @Description Holds deliveries to a recipient (an email address or E.164 phone number) or a webhook host, for a duration or until removed. Messages for the key stay pending, or failed, until then. Suppressing a host pauses it as POST /api/v1/webhooks/pause does.
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param request body CreateSuppressionRequest true "Recipient or host to suppress and optional duration as a Go duration"
+// @Success 200 {object} domain.Suppression
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/suppressions [post]
+func (s *Server) createSuppression(c *gin.Context) {
+	var req CreateSuppressionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		s.logger.Error("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		s.requestLogger(c).Error("Invalid suppression request body", "error", err)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Key is required")
 		return
 	}
 
-	batchSize := req.BatchSize
-	if batchSize <= 0 {
-		batchSize = 10 // Default batch size
+	var duration time.Duration
+	if req.Duration != "" {
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil || parsed <= 0 {
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "duration must be a positive duration such as 30m")
+			return
+		}
+		duration = parsed
 	}
 
-	count, err := s.messageService.RetryFailedMessages(c.Request.Context(), batchSize)
+	suppression, err := s.messageService.Suppress(c.Request.Context(), req.Key, duration)
 	if err != nil {
-		s.logger.Error("Failed to retry failed messages", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry failed messages"})
+		s.requestLogger(c).Error("Failed to create suppression", "key", req.Key, "error", err)
+
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			respondValidationError(c, validationErr)
+			return
+		}
+
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to create suppression")
 		return
 	}
 
-	s.logger.Info("Failed messages retry completed", "count", count)
-	c.JSON(http.StatusOK, gin.H{"retried_count": count})
+	c.JSON(http.StatusOK, suppression)
+}
+
+// deleteSuppression godoc
+// @Summary Remove a suppression
+// @Description Lifts the suppression of a recipient or webhook host so its messages are delivered on the next run
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param key path string true "Suppressed recipient or host"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/suppressions/{key} [delete]
+func (s *Server) deleteSuppression(c *gin.Context) {
+	key := c.Param("key")
+	if err := s.messageService.Unsuppress(c.Request.Context(), key); err != nil {
+		s.requestLogger(c).Error("Failed to remove suppression", "key", key, "error", err)
+
+		var validationErr *domain.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			respondValidationError(c, validationErr)
+		case errors.Is(err, domain.ErrNotSuppressed):
+			respondError(c, http.StatusNotFound, ErrCodeNotSuppressed, "Key is not suppressed")
+		default:
+			respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to remove suppression")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Suppression removed",
+		"key":     key,
+	})
+}
+
+// getSuppressions godoc
+// @Summary List suppressions
+// @Description Returns the recipients and webhook hosts whose deliveries are currently on hold
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/suppressions [get]
+func (s *Server) getSuppressions(c *gin.Context) {
+	suppressions, err := s.messageService.GetSuppressions(c.Request.Context())
+	if err != nil {
+		s.requestLogger(c).Error("Failed to list suppressions", "error", err)
+		respondError(c, http.StatusInternalServerError, ErrCodeInternal, "Failed to list suppressions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suppressions": suppressions,
+		"count":        len(suppressions),
+	})
 }
 
 // ServeHTTP implements the http.Handler interface
@@ -456,7 +2305,11 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 		c.Next()
 
 		// Log request details
-		log.Info("HTTP request",
+		requestLog := log
+		if id := c.GetString(requestIDKey); id != "" {
+			requestLog = log.WithRequestID(id)
+		}
+		requestLog.Info("HTTP request",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", c.Writer.Status(),
@@ -464,3 +2317,119 @@ func LoggerMiddleware(log *logger.Logger) gin.HandlerFunc {
 		)
 	}
 }
+
+// apiKeyHeader is the request header AuthMiddleware reads the API key from
+const apiKeyHeader = "X-API-Key"
+
+// AuthMiddleware creates a Gin middleware that rejects requests whose X-API-Key
+// header does not match expectedKey with 401
+func AuthMiddleware(expectedKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(expectedKey)) != 1 {
+			abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// adminKeyHeader is the request header AdminAuthMiddleware reads the admin key
+// from
+const adminKeyHeader = "X-Admin-Key"
+
+// AdminAuthMiddleware creates a Gin middleware that rejects requests whose
+// X-Admin-Key header does not match expectedKey with 403. An empty expectedKey
+// rejects every request, so admin routes stay closed until a key is configured.
+func AdminAuthMiddleware(expectedKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(adminKeyHeader)
+		if expectedKey == "" || key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(expectedKey)) != 1 {
+			abortWithError(c, http.StatusForbidden, ErrCodeForbidden, "forbidden")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// metricsAuthRealm is the realm BasicAuthMiddleware challenges clients with
+const metricsAuthRealm = `Basic realm="metrics"`
+
+// BasicAuthMiddleware creates a Gin middleware that rejects requests whose
+// basic auth credentials do not match user and pass with 401 and a
+// WWW-Authenticate challenge
+func BasicAuthMiddleware(user, pass string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gotUser, gotPass, ok := c.Request.BasicAuth()
+		// Compare both halves so a wrong user takes as long as a wrong password
+		userMatch := subtle.ConstantTimeCompare([]byte(gotUser), []byte(user))
+		passMatch := subtle.ConstantTimeCompare([]byte(gotPass), []byte(pass))
+		if !ok || userMatch&passMatch != 1 {
+			c.Header("WWW-Authenticate", metricsAuthRealm)
+			abortWithError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// Gin context keys set by RequestIDMiddleware
+const (
+	requestIDKey     = "request_id"
+	requestLoggerKey = "request_logger"
+)
+
+// maxRequestIDLength bounds incoming request IDs so clients cannot flood the logs
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware creates a Gin middleware that takes the request ID from
+// the X-Request-ID header, or generates a UUID when it is missing or invalid,
+// echoes it in the response and stores a logger tagged with it for handlers.
+// The request context carries the ID and logger too, so logging through
+// logger.FromContext(ctx).InfoContext(ctx, ...) further down is tagged as well.
+func RequestIDMiddleware(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Set(requestLoggerKey, log.WithRequestID(id))
+		c.Header(RequestIDHeader, id)
+		ctx := logger.ContextWithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, log))
+
+		c.Next()
+	}
+}
+
+// isValidRequestID reports whether id is non-empty, bounded and printable ASCII
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogger returns the logger tagged with the request's ID, falling back to
+// the server logger outside RequestIDMiddleware
+func (s *Server) requestLogger(c *gin.Context) *logger.Logger {
+	if log, ok := c.Get(requestLoggerKey); ok {
+		if requestLog, ok := log.(*logger.Logger); ok {
+			return requestLog
+		}
+	}
+	return s.logger
+}
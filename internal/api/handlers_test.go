@@ -1,26 +1,36 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/insider/insider-messaging/internal/scheduler"
+	"github.com/insider/insider-messaging/internal/selfcheck"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // createTestServerWithMock creates a test server with a provided mock service
-func createTestServerWithMock(mockService *MockMessageService) *Server {
+func createTestServerWithMock(mockService *MockMessageService, opts ...ServerOption) *Server {
 	testLogger := logger.New()
 	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
-	return NewServer(testLogger, mockService, mockScheduler)
+	return NewServer(testLogger, mockService, mockScheduler, opts...)
 }
 
 // MockMessageService is a mock implementation of MessageService for testing
@@ -36,6 +46,38 @@ func (m *MockMessageService) CreateMessage(ctx context.Context, req *domain.Crea
 	return args.Get(0).(*domain.Message), args.Error(1)
 }
 
+func (m *MockMessageService) ValidateMessage(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) CreateMessageIdempotent(ctx context.Context, req *domain.CreateMessageRequest, key string) (*domain.Message, bool, error) {
+	args := m.Called(ctx, req, key)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*domain.Message), args.Bool(1), args.Error(2)
+}
+
+func (m *MockMessageService) CreateMessages(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]domain.CreateResult, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CreateResult), args.Error(1)
+}
+
+func (m *MockMessageService) ImportMessages(ctx context.Context, reqs []*domain.CreateMessageRequest, progress func(imported, total int)) ([]*domain.Message, error) {
+	args := m.Called(ctx, reqs, progress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
 func (m *MockMessageService) ProcessUnsentMessages(ctx context.Context, batchSize int) (int, error) {
 	args := m.Called(ctx, batchSize)
 	return args.Int(0), args.Error(1)
@@ -49,8 +91,56 @@ func (m *MockMessageService) GetMessage(ctx context.Context, messageID int64) (*
 	return args.Get(0).(*domain.Message), args.Error(1)
 }
 
-func (m *MockMessageService) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
-	args := m.Called(ctx, offset, limit)
+func (m *MockMessageService) GetMessageAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookAttempt), args.Error(1)
+}
+
+func (m *MockMessageService) ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageService) ListMessagesAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, int64, error) {
+	args := m.Called(ctx, status, cursorID, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMessageService) GetMessageCounts(ctx context.Context) (map[domain.MessageStatus]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.MessageStatus]int), args.Error(1)
+}
+
+func (m *MockMessageService) GetMessagesByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, from, to, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageService) GetMessagesByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, recipient, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageService) GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit, order)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
@@ -67,6 +157,162 @@ func (m *MockMessageService) ProcessPendingMessages(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockMessageService) GetFailedMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageService) GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageService) RequeueMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) CancelMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) UpdatePendingMessage(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) ResendMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) SendNow(ctx context.Context, messageID int64, force bool) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, force)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) GetSuccessRate(ctx context.Context, window time.Duration, byHost bool) (*domain.SuccessRate, error) {
+	args := m.Called(ctx, window, byHost)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SuccessRate), args.Error(1)
+}
+
+func (m *MockMessageService) GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error) {
+	args := m.Called(ctx, host, duration)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PausedHost), args.Error(1)
+}
+
+func (m *MockMessageService) ResumeHost(ctx context.Context, host string) error {
+	args := m.Called(ctx, host)
+	return args.Error(0)
+}
+
+func (m *MockMessageService) GetPausedHosts(ctx context.Context) ([]*domain.PausedHost, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PausedHost), args.Error(1)
+}
+
+func (m *MockMessageService) Suppress(ctx context.Context, key string, duration time.Duration) (*domain.Suppression, error) {
+	args := m.Called(ctx, key, duration)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Suppression), args.Error(1)
+}
+
+func (m *MockMessageService) Unsuppress(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockMessageService) GetSuppressions(ctx context.Context) ([]*domain.Suppression, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Suppression), args.Error(1)
+}
+
+func (m *MockMessageService) ClaimMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) AckMessage(ctx context.Context, messageID int64, providerMessageID string) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, providerMessageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) RecordDeliveryStatus(ctx context.Context, messageID int64, status domain.MessageStatus, providerMessageID, errorMsg string) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, status, providerMessageID, errorMsg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) NackMessage(ctx context.Context, messageID int64, errorMsg string) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, errorMsg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageService) ArchiveOldMessages(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageService) PurgeOldMessages(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 func TestHealthHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -102,7 +348,7 @@ func TestHealthHandler(t *testing.T) {
 
 	assert.Equal(t, "ok", response.Status)
 	assert.Equal(t, "insider-messaging", response.Service)
-	assert.Equal(t, "v0.1.0", response.Version)
+	assert.Equal(t, version.Version, response.Version)
 }
 
 func TestNewServer(t *testing.T) {
@@ -158,6 +404,204 @@ func TestLoggerMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+// stubHealthChecker is a HealthChecker returning a fixed result
+type stubHealthChecker struct {
+	err         error
+	hadDeadline bool
+}
+
+func (c *stubHealthChecker) Health(ctx context.Context) error {
+	_, c.hadDeadline = ctx.Deadline()
+	return c.err
+}
+
+func TestHealthHandler_Dependencies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+
+	checkHealth := func(t *testing.T, server *Server) (int, HealthResponse) {
+		req, err := http.NewRequest("GET", "/healthz", nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	t.Run("all healthy", func(t *testing.T) {
+		database := &stubHealthChecker{}
+		redis := &stubHealthChecker{}
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler,
+			WithDependency("database", database), WithDependency("redis", redis))
+
+		code, response := checkHealth(t, server)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", response.Status)
+		assert.Equal(t, "insider-messaging", response.Service)
+		assert.Equal(t, version.Version, response.Version)
+		assert.Equal(t, map[string]string{"database": "ok", "redis": "ok"}, response.Dependencies)
+		assert.True(t, database.hadDeadline, "dependency checks must be bounded by a timeout")
+		assert.True(t, redis.hadDeadline, "dependency checks must be bounded by a timeout")
+	})
+
+	t.Run("one unhealthy", func(t *testing.T) {
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler,
+			WithDependency("database", &stubHealthChecker{}),
+			WithDependency("redis", &stubHealthChecker{err: errors.New("dial tcp: connection refused")}))
+
+		code, response := checkHealth(t, server)
+
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unavailable", response.Status)
+		assert.Equal(t, "insider-messaging", response.Service)
+		assert.Equal(t, map[string]string{"database": "ok", "redis": "unavailable"}, response.Dependencies)
+	})
+
+	t.Run("no dependencies configured", func(t *testing.T) {
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler)
+
+		code, response := checkHealth(t, server)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ok", response.Status)
+		assert.Empty(t, response.Dependencies)
+	})
+}
+
+func TestLivenessHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+	server := NewServer(testLogger, new(MockMessageService), mockScheduler)
+
+	req, err := http.NewRequest("GET", "/livez", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response LivenessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ok", response.Status)
+	assert.Equal(t, "insider-messaging", response.Service)
+	assert.Equal(t, version.Version, response.Version)
+}
+
+func TestVersionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+	server := NewServer(testLogger, new(MockMessageService), mockScheduler)
+
+	req, err := http.NewRequest("GET", "/version", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response VersionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, VersionResponse{Version: "dev", Commit: "unknown", BuildDate: "unknown"}, response)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+
+	get := func(t *testing.T, server *Server, path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", path, nil)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) HealthResponse {
+		var response HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	t.Run("database down fails readiness but not liveness", func(t *testing.T) {
+		mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler,
+			WithDependency("database", &stubHealthChecker{err: errors.New("dial tcp: connection refused")}),
+			WithDependency("redis", &stubHealthChecker{}))
+
+		for _, path := range []string{"/readyz", "/healthz"} {
+			w := get(t, server, path)
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code, path)
+
+			response := decode(t, w)
+			assert.Equal(t, "unavailable", response.Status, path)
+			assert.Equal(t, map[string]string{"database": "unavailable", "redis": "ok"}, response.Dependencies, path)
+		}
+
+		assert.Equal(t, http.StatusOK, get(t, server, "/livez").Code)
+	})
+
+	t.Run("reports scheduler state", func(t *testing.T) {
+		sched := scheduler.NewScheduler(&stubSchedulerService{}, testLogger, scheduler.DefaultConfig())
+		server := NewServer(testLogger, new(MockMessageService), sched)
+
+		response := decode(t, get(t, server, "/readyz"))
+		assert.Equal(t, "stopped", response.Scheduler)
+
+		require.NoError(t, sched.Start(context.Background()))
+		defer sched.Stop()
+
+		w := get(t, server, "/readyz")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "running", decode(t, w).Scheduler)
+	})
+
+	t.Run("includes degraded self-check", func(t *testing.T) {
+		report := selfcheck.Run(context.Background(),
+			selfcheck.Check{Name: "config", Critical: true, Run: passing},
+			selfcheck.Check{Name: "redis", Run: failing},
+		)
+		mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler, WithSelfCheckReport(report))
+
+		w := get(t, server, "/readyz")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		response := decode(t, w)
+		assert.Equal(t, "ok", response.Status)
+		require.NotNil(t, response.SelfCheck)
+		assert.Equal(t, "degraded", response.SelfCheck.Status)
+		require.Len(t, response.SelfCheck.Checks, 2)
+		assert.Equal(t, "redis", response.SelfCheck.Checks[1].Name)
+		assert.Equal(t, "connection refused", response.SelfCheck.Checks[1].Error)
+	})
+
+	t.Run("critical self-check failure is not ready", func(t *testing.T) {
+		report := selfcheck.Run(context.Background(),
+			selfcheck.Check{Name: "migrations", Critical: true, Run: failing},
+		)
+		mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler, WithSelfCheckReport(report))
+
+		w := get(t, server, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "unavailable", decode(t, w).Status)
+		assert.Equal(t, http.StatusOK, get(t, server, "/livez").Code)
+	})
+}
+
 func TestCreateMessage(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -187,14 +631,14 @@ func TestCreateMessage(t *testing.T) {
 				m.On("CreateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).Return(message, nil)
 			},
 			expectedStatus: 201,
-			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"pending","max_retries":3,"retry_count":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"pending","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
 		},
 		{
 			name:           "invalid JSON",
 			requestBody:    `{"invalid": json}`,
 			mockSetup:      func(m *MockMessageService) {},
 			expectedStatus: 400,
-			expectedBody:   `{"error":"Invalid request body"}`,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`,
 		},
 		{
 			name: "missing recipient",
@@ -204,135 +648,2864 @@ func TestCreateMessage(t *testing.T) {
 			}`,
 			mockSetup:      func(m *MockMessageService) {},
 			expectedStatus: 400,
-			expectedBody:   `{"error":"Recipient is required"}`,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient is required","details":[{"field":"recipient","message":"recipient is required"}]}}`,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			mockService := &MockMessageService{}
-			tt.mockSetup(mockService)
-
-			server := createTestServerWithMock(mockService)
-
-			req, _ := http.NewRequest("POST", "/api/v1/messages", strings.NewReader(tt.requestBody))
-			req.Header.Set("Content-Type", "application/json")
+		{
+			name:           "every missing field is reported",
+			requestBody:    `{}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody: `{
+				"error": {
+					"code": "VALIDATION_FAILED",
+					"message": "recipient is required; content is required",
+					"details": [
+						{"field": "recipient", "message": "recipient is required"},
+						{"field": "content", "message": "content is required"}
+					]
+				}
+			}`,
+		},
+		{
+			name: "invalid recipient email",
+			requestBody: `{
+				"recipient": "not-an-email",
+				"content": "Test message",
+				"webhook_url": "https://example.com/webhook"
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("CreateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).
+					Return(nil, domain.NewValidationError("recipient must be a valid email address"))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient must be a valid email address"}}`,
+		},
+		{
+			name: "service field errors",
+			requestBody: `{
+				"recipient": "not-an-email",
+				"content": "Test message",
+				"webhook_url": "ftp://example.com"
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("CreateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).
+					Return(nil, domain.NewFieldValidationError(
+						domain.FieldError{Field: "recipient", Message: "recipient must be a valid email address"},
+						domain.FieldError{Field: "webhook_url", Message: "webhook URL must be a valid http(s) URL"},
+					))
+			},
+			expectedStatus: 400,
+			expectedBody: `{
+				"error": {
+					"code": "VALIDATION_FAILED",
+					"message": "recipient must be a valid email address; webhook URL must be a valid http(s) URL",
+					"details": [
+						{"field": "recipient", "message": "recipient must be a valid email address"},
+						{"field": "webhook_url", "message": "webhook URL must be a valid http(s) URL"}
+					]
+				}
+			}`,
+		},
+		{
+			name: "invalid payload template",
+			requestBody: `{
+				"recipient": "test@example.com",
+				"content": "Test message",
+				"webhook_url": "https://example.com/webhook",
+				"payload_template": "{{json .Content"
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("CreateMessage", mock.Anything, mock.MatchedBy(func(req *domain.CreateMessageRequest) bool {
+					return req.PayloadTemplate == "{{json .Content"
+				})).Return(nil, domain.NewFieldValidationError(
+					domain.FieldError{Field: "payload_template", Message: "invalid payload template: unclosed action"},
+				))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"invalid payload template: unclosed action","details":[{"field":"payload_template","message":"invalid payload template: unclosed action"}]}}`,
+		},
+		{
+			name: "ttl is passed on and the expiry returned",
+			requestBody: `{
+				"recipient": "test@example.com",
+				"content": "Test message",
+				"webhook_url": "https://example.com/webhook",
+				"ttl_seconds": 3600
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				expiresAt := time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)
+				message := &domain.Message{
+					ID:         1,
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+					Status:     domain.MessageStatusPending,
+					MaxRetries: 3,
+					ExpiresAt:  &expiresAt,
+				}
+				m.On("CreateMessage", mock.Anything, mock.MatchedBy(func(req *domain.CreateMessageRequest) bool {
+					return req.TTL == time.Hour && req.ExpiresAt == nil
+				})).Return(message, nil)
+			},
+			expectedStatus: 201,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"pending","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","expires_at":"2023-01-01T01:00:00Z"}`,
+		},
+		{
+			name: "absolute expiry is passed on",
+			requestBody: `{
+				"recipient": "test@example.com",
+				"content": "Test message",
+				"webhook_url": "https://example.com/webhook",
+				"expires_at": "2023-01-01T01:00:00Z"
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("CreateMessage", mock.Anything, mock.MatchedBy(func(req *domain.CreateMessageRequest) bool {
+					return req.TTL == 0 && req.ExpiresAt != nil && req.ExpiresAt.Equal(time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC))
+				})).Return(nil, domain.NewFieldValidationError(
+					domain.FieldError{Field: "expires_at", Message: "expires_at must be in the future"},
+				))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"expires_at must be in the future","details":[{"field":"expires_at","message":"expires_at must be in the future"}]}}`,
+		},
+		{
+			name: "service error",
+			requestBody: `{
+				"recipient": "test@example.com",
+				"content": "Test message",
+				"webhook_url": "https://example.com/webhook"
+			}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("CreateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).Return(nil, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to create message"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateMessage_RecipientLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resetAt := time.Now().Add(90 * time.Second).UTC().Truncate(time.Second)
+	mockService := &MockMessageService{}
+	mockService.On("CreateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).
+		Return(nil, fmt.Errorf("create: %w", &domain.RecipientLimitError{Limit: 5, ResetAt: resetAt}))
+
+	server := createTestServerWithMock(mockService)
+
+	body := `{"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook"}`
+	req, _ := http.NewRequest("POST", "/api/v1/messages", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"error": {
+			"code": "RECIPIENT_LIMIT_EXCEEDED",
+			"message": "recipient has reached the daily limit of 5 messages",
+			"details": {"reset_at": %q}
+		}
+	}`, resetAt.Format(time.RFC3339)), w.Body.String())
+
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, 90, retryAfter, 2)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateMessage_IdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook"}`
+	key := "order-42"
+	message := &domain.Message{
+		ID:             1,
+		Recipient:      "test@example.com",
+		Content:        "Test message",
+		WebhookURL:     "https://example.com/webhook",
+		Status:         domain.MessageStatusPending,
+		MaxRetries:     3,
+		IdempotencyKey: &key,
+	}
+
+	tests := []struct {
+		name           string
+		created        bool
+		expectedStatus int
+	}{
+		{"first request creates", true, http.StatusCreated},
+		{"repeated request returns the original", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			mockService.On("CreateMessageIdempotent", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest"), key).
+				Return(message, tt.created, nil)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", key)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response MessageResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, int64(1), response.ID)
+			require.NotNil(t, response.IdempotencyKey)
+			assert.Equal(t, key, *response.IdempotencyKey)
+
+			mockService.AssertExpectations(t)
+			mockService.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("invalid key", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("CreateMessageIdempotent", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest"), mock.Anything).
+			Return(nil, false, domain.NewValidationError("idempotency key must be at most 255 characters"))
+
+		server := createTestServerWithMock(mockService)
+
+		req, _ := http.NewRequest("POST", "/api/v1/messages", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", strings.Repeat("k", 256))
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":{"code":"VALIDATION_FAILED","message":"idempotency key must be at most 255 characters"}}`, w.Body.String())
+	})
+}
+
+func TestCreateMessage_DryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook"}`
+	message := &domain.Message{
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: "https://example.com/webhook",
+		Status:     domain.MessageStatusPending,
+		MaxRetries: 3,
+		Channel:    domain.ChannelWebhook,
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "query parameter",
+			path: "/api/v1/messages?dry_run=true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ValidateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).Return(message, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":0,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"pending","retry_count":0,"max_retries":3,"priority":0,"channel":"webhook","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","dry_run":true}`,
+		},
+		{
+			name:   "header",
+			path:   "/api/v1/messages",
+			header: "true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ValidateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).Return(message, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":0,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"pending","retry_count":0,"max_retries":3,"priority":0,"channel":"webhook","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","dry_run":true}`,
+		},
+		{
+			name: "validation error",
+			path: "/api/v1/messages?dry_run=true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ValidateMessage", mock.Anything, mock.AnythingOfType("*domain.CreateMessageRequest")).
+					Return(nil, domain.NewFieldValidationError(domain.FieldError{Field: "recipient", Message: "recipient must be a valid email address"}))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient must be a valid email address","details":[{"field":"recipient","message":"recipient must be a valid email address"}]}}`,
+		},
+		{
+			name:           "invalid flag",
+			path:           "/api/v1/messages?dry_run=maybe",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"dry_run must be a boolean"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", "order-42")
+			if tt.header != "" {
+				req.Header.Set("X-Dry-Run", tt.header)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+			mockService.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "CreateMessageIdempotent", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCreateMessagesBulk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	post := func(server *Server, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/messages/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("mixed valid and invalid batch", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("CreateMessages", mock.Anything, mock.MatchedBy(func(reqs []*domain.CreateMessageRequest) bool {
+			return len(reqs) == 3 && reqs[0].Recipient == "a@example.com" && reqs[1].Recipient == "" && reqs[2].Priority == 2
+		})).Return([]domain.CreateResult{
+			{Message: &domain.Message{ID: 1, Recipient: "a@example.com", Content: "First", WebhookURL: "https://example.com/webhook", Status: domain.MessageStatusPending, MaxRetries: 3}},
+			{Err: domain.NewValidationError("recipient is required")},
+			{Message: &domain.Message{ID: 2, Recipient: "b@example.com", Content: "Third", WebhookURL: "https://example.com/webhook", Status: domain.MessageStatusPending, MaxRetries: 3, Priority: 2}},
+		}, nil)
+
+		server := createTestServerWithMock(mockService)
+
+		w := post(server, `{"messages":[
+			{"recipient":"a@example.com","content":"First","webhook_url":"https://example.com/webhook"},
+			{"content":"Second","webhook_url":"https://example.com/webhook"},
+			{"recipient":"b@example.com","content":"Third","webhook_url":"https://example.com/webhook","priority":2}
+		]}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response BulkCreateMessagesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Created)
+		assert.Equal(t, 1, response.Failed)
+		require.Len(t, response.Results, 3)
+
+		assert.Equal(t, 0, response.Results[0].Index)
+		require.NotNil(t, response.Results[0].Message)
+		assert.Equal(t, int64(1), response.Results[0].Message.ID)
+
+		assert.Equal(t, 1, response.Results[1].Index)
+		assert.Nil(t, response.Results[1].Message)
+		assert.Equal(t, "recipient is required", response.Results[1].Error)
+
+		assert.Equal(t, 2, response.Results[2].Index)
+		require.NotNil(t, response.Results[2].Message)
+		assert.Equal(t, int64(2), response.Results[2].Message.ID)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("nothing created", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("CreateMessages", mock.Anything, mock.Anything).Return([]domain.CreateResult{
+			{Err: domain.NewValidationError("recipient must be a valid email address")},
+		}, nil)
+
+		server := createTestServerWithMock(mockService)
+
+		w := post(server, `{"messages":[{"recipient":"nope","content":"Hi","webhook_url":"https://example.com/webhook"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{
+			"created": 0,
+			"failed": 1,
+			"results": [{"index": 0, "error": "recipient must be a valid email address"}]
+		}`, w.Body.String())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("CreateMessages", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+		server := createTestServerWithMock(mockService)
+
+		w := post(server, `{"messages":[{"recipient":"a@example.com","content":"Hi","webhook_url":"https://example.com/webhook"}]}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":{"code":"INTERNAL_ERROR","message":"Failed to create messages"}}`, w.Body.String())
+	})
+
+	tooMany := `{"messages":[` + strings.TrimSuffix(strings.Repeat(`{"recipient":"a@example.com","content":"Hi","webhook_url":"https://example.com/webhook"},`, 501), ",") + `]}`
+
+	badRequests := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{"invalid JSON", `{"messages": [}`, `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`},
+		{"missing messages", `{}`, `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`},
+		{"empty messages", `{"messages":[]}`, `{"error":{"code":"VALIDATION_FAILED","message":"messages must not be empty"}}`},
+		{"too many messages", tooMany, `{"error":{"code":"VALIDATION_FAILED","message":"at most 500 messages may be created per request"}}`},
+	}
+
+	for _, tt := range badRequests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			server := createTestServerWithMock(mockService)
+
+			w := post(server, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			mockService.AssertNotCalled(t, "CreateMessages", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful get messages",
+			queryParams: "?offset=0&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{
+					{
+						ID:        1,
+						Recipient: "test1@example.com",
+						Content:   "Test message 1",
+						Status:    domain.MessageStatusSent,
+					},
+					{
+						ID:        2,
+						Recipient: "test2@example.com",
+						Content:   "Test message 2",
+						Status:    domain.MessageStatusPending,
+					},
+				}
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 0, 10).Return(messages, 2, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":1,"recipient":"test1@example.com","content":"Test message 1","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},{"id":2,"recipient":"test2@example.com","content":"Test message 2","webhook_url":"","status":"pending","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":2,"offset":0,"limit":10,"total_pages":1,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "default pagination",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "all statuses",
+			queryParams: "?status=all",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "pending only",
+			queryParams: "?status=pending&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 3, Recipient: "test3@example.com", Status: domain.MessageStatusPending}}
+				m.On("ListMessages", mock.Anything, domain.MessageStatusPending, 0, 5).Return(messages, 1, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":3,"recipient":"test3@example.com","content":"","webhook_url":"","status":"pending","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":1,"offset":0,"limit":5,"total_pages":1,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "sent only",
+			queryParams: "?status=sent",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatusSent, 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "failed only",
+			queryParams: "?status=failed&offset=10",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatusFailed, 10, 50).Return([]*domain.Message{}, 12, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":12,"offset":10,"limit":50,"total_pages":1,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "offset on a partial last page",
+			queryParams: "?offset=20&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 20, 10).Return([]*domain.Message{}, 25, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":25,"offset":20,"limit":10,"total_pages":3,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "offset on an exact-fit last page",
+			queryParams: "?offset=10&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 10, 10).Return([]*domain.Message{}, 20, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":20,"offset":10,"limit":10,"total_pages":2,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "offset before the last page",
+			queryParams: "?offset=5&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 5, 10).Return([]*domain.Message{}, 20, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":20,"offset":5,"limit":10,"total_pages":2,"has_next":true,"has_prev":true}`,
+		},
+		{
+			name:           "unknown status",
+			queryParams:    "?status=delivered",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"status must be one of all, pending, sent, failed, dead_letter, sending, cancelled, expired"}}`,
+		},
+		{
+			name:        "by recipient",
+			queryParams: "?recipient=test3%40example.com&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 3, Recipient: "test3@example.com", Status: domain.MessageStatusSent}}
+				m.On("GetMessagesByRecipient", mock.Anything, "test3@example.com", 0, 10).Return(messages, 1, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":3,"recipient":"test3@example.com","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":1,"offset":0,"limit":10,"total_pages":1,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "by recipient with status all",
+			queryParams: "?recipient=test3%40example.com&status=all",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessagesByRecipient", mock.Anything, "test3@example.com", 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:           "empty recipient",
+			queryParams:    "?recipient=",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient must not be empty"}}`,
+		},
+		{
+			name:           "recipient with status",
+			queryParams:    "?recipient=test3%40example.com&status=sent",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient cannot be combined with status"}}`,
+		},
+		{
+			name:        "by date range",
+			queryParams: "?from=2026-01-01T00:00:00Z&to=2026-01-31T23:59:59Z&limit=10",
+			mockSetup: func(m *MockMessageService) {
+				from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				to := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+				messages := []*domain.Message{{ID: 4, Recipient: "test4@example.com", Status: domain.MessageStatusSent}}
+				m.On("GetMessagesByDateRange", mock.Anything, from, to, 0, 10).Return(messages, 1, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":4,"recipient":"test4@example.com","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":1,"offset":0,"limit":10,"total_pages":1,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "from only runs to now",
+			queryParams: "?from=2026-01-01T00:00:00Z",
+			mockSetup: func(m *MockMessageService) {
+				from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				untilNow := mock.MatchedBy(func(to time.Time) bool {
+					return time.Since(to) >= 0 && time.Since(to) < time.Minute
+				})
+				m.On("GetMessagesByDateRange", mock.Anything, from, untilNow, 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "to only starts at the epoch",
+			queryParams: "?to=2026-01-01T03:00:00%2B03:00",
+			mockSetup: func(m *MockMessageService) {
+				fromEpoch := mock.MatchedBy(func(from time.Time) bool { return from.Equal(time.Unix(0, 0)) })
+				midnightUTC := mock.MatchedBy(func(to time.Time) bool {
+					return to.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+				})
+				m.On("GetMessagesByDateRange", mock.Anything, fromEpoch, midnightUTC, 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "empty date range",
+			queryParams: "?from=2026-01-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+			mockSetup: func(m *MockMessageService) {
+				instant := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+				m.On("GetMessagesByDateRange", mock.Anything, instant, instant, 0, 50).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:           "date without time",
+			queryParams:    "?from=2026-01-01",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"from must be an RFC3339 timestamp"}}`,
+		},
+		{
+			name:           "empty from",
+			queryParams:    "?from=",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"from must be an RFC3339 timestamp"}}`,
+		},
+		{
+			name:           "invalid to",
+			queryParams:    "?to=yesterday",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"to must be an RFC3339 timestamp"}}`,
+		},
+		{
+			name:           "from after to",
+			queryParams:    "?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"from must not be after to"}}`,
+		},
+		{
+			name:           "date range with status",
+			queryParams:    "?from=2026-01-01T00:00:00Z&status=sent",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"from and to cannot be combined with status or recipient"}}`,
+		},
+		{
+			name:           "date range with recipient",
+			queryParams:    "?to=2026-01-01T00:00:00Z&recipient=test3%40example.com",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"from and to cannot be combined with status or recipient"}}`,
+		},
+		{
+			name:        "first cursor page",
+			queryParams: "?cursor=&status=sent&limit=2",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 9, Status: domain.MessageStatusSent}, {ID: 8, Status: domain.MessageStatusSent}}
+				m.On("ListMessagesAfterCursor", mock.Anything, domain.MessageStatusSent, int64(0), 2).Return(messages, int64(8), nil)
+			},
+			expectedStatus: 200,
+			expectedBody: `{"messages":[
+				{"id":9,"recipient":"","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},
+				{"id":8,"recipient":"","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}
+			],"limit":2,"next_cursor":"OA"}`,
+		},
+		{
+			name:        "last cursor page",
+			queryParams: "?cursor=OA&limit=2",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessagesAfterCursor", mock.Anything, domain.MessageStatus(""), int64(8), 2).Return([]*domain.Message{}, int64(0), nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"limit":2}`,
+		},
+		{
+			name:           "invalid cursor",
+			queryParams:    "?cursor=not-a-cursor",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"cursor is invalid"}}`,
+		},
+		{
+			name:           "cursor with offset",
+			queryParams:    "?cursor=OA&offset=10",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"cursor cannot be combined with offset, recipient, from or to"}}`,
+		},
+		{
+			name:        "cursor service error",
+			queryParams: "?cursor=",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessagesAfterCursor", mock.Anything, domain.MessageStatus(""), int64(0), 50).Return(nil, int64(0), assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get messages"}}`,
+		},
+		{
+			name:           "non-integer limit",
+			queryParams:    "?limit=abc",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be an integer"}}`,
+		},
+		{
+			name:           "negative offset",
+			queryParams:    "?offset=-5",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"offset must be at least 0"}}`,
+		},
+		{
+			name:           "limit above maximum",
+			queryParams:    "?limit=9999",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be between 1 and 100"}}`,
+		},
+		{
+			name:        "service error",
+			queryParams: "?status=sent",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatusSent, 0, 50).Return(nil, 0, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get messages"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMessageStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("reports every status", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("GetMessageCounts", mock.Anything).Return(map[domain.MessageStatus]int{
+			domain.MessageStatusPending:    3,
+			domain.MessageStatusSent:       42,
+			domain.MessageStatusFailed:     0,
+			domain.MessageStatusDeadLetter: 1,
+			domain.MessageStatusSending:    0,
+			domain.MessageStatusCancelled:  0,
+		}, nil)
+
+		server := createTestServerWithMock(mockService)
+
+		req, _ := http.NewRequest("GET", "/api/v1/messages/stats", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"pending":3,"sent":42,"failed":0,"dead_letter":1,"sending":0,"cancelled":0}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService := &MockMessageService{}
+		mockService.On("GetMessageCounts", mock.Anything).Return(nil, assert.AnError)
+
+		server := createTestServerWithMock(mockService)
+
+		req, _ := http.NewRequest("GET", "/api/v1/messages/stats", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get message stats"}}`, w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		messageID      string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "successful get message",
+			messageID: "1",
+			mockSetup: func(m *MockMessageService) {
+				message := &domain.Message{
+					ID:        1,
+					Recipient: "test@example.com",
+					Content:   "Test message",
+					Status:    domain.MessageStatusSent,
+				}
+				m.On("GetMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:           "invalid message ID",
+			messageID:      "invalid",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+		{
+			name:      "message not found",
+			messageID: "999",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessage", mock.Anything, int64(999)).Return(nil, fmt.Errorf("failed to get message: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/"+tt.messageID, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMessageAttempts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	statusCode := func(code int) *int { return &code }
+	errorText := func(text string) *string { return &text }
+	first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		messageID      string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "multiple attempts",
+			messageID: "7",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessageAttempts", mock.Anything, int64(7)).Return([]*domain.WebhookAttempt{
+					{ID: 1, MessageID: 7, DurationMs: 30000, Error: errorText("HTTP request failed: timeout"), CreatedAt: first},
+					{ID: 2, MessageID: 7, StatusCode: statusCode(500), DurationMs: 120, Error: errorText("webhook delivery failed with status 500"), CreatedAt: first.Add(time.Second)},
+					{ID: 3, MessageID: 7, StatusCode: statusCode(202), DurationMs: 85, CreatedAt: first.Add(2 * time.Second)},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody: `{"message_id":7,"count":3,"attempts":[
+				{"id":1,"duration_ms":30000,"error":"HTTP request failed: timeout","created_at":"2024-01-01T12:00:00Z"},
+				{"id":2,"status_code":500,"duration_ms":120,"error":"webhook delivery failed with status 500","created_at":"2024-01-01T12:00:01Z"},
+				{"id":3,"status_code":202,"duration_ms":85,"created_at":"2024-01-01T12:00:02Z"}
+			]}`,
+		},
+		{
+			name:      "no attempts yet",
+			messageID: "7",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessageAttempts", mock.Anything, int64(7)).Return([]*domain.WebhookAttempt{}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"message_id":7,"count":0,"attempts":[]}`,
+		},
+		{
+			name:           "invalid message ID",
+			messageID:      "invalid",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+		{
+			name:      "message not found",
+			messageID: "999",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessageAttempts", mock.Anything, int64(999)).
+					Return(nil, fmt.Errorf("failed to get webhook attempts: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:      "service error",
+			messageID: "7",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessageAttempts", mock.Anything, int64(7)).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get webhook attempts"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/"+tt.messageID+"/attempts", nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRetryFailedMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful retry",
+			requestBody: `{"batch_size": 5}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("RetryFailedMessages", mock.Anything, 5).Return(3, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"retried_count":3}`,
+		},
+		{
+			name:        "default batch size",
+			requestBody: `{}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("RetryFailedMessages", mock.Anything, 10).Return(0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"retried_count":0}`,
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    `{"invalid": json}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages/retry", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSentMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful get sent messages",
+			queryParams: "?page=2&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 6, Recipient: "test@example.com", Content: "Test message", Status: domain.MessageStatusSent, MaxRetries: 3}}
+				m.On("GetSentMessages", mock.Anything, 5, 5, domain.DefaultSentMessagesSort).Return(messages, 6, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[{"id":6,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"sent","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":6,"page":2,"limit":5,"total_pages":2,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "partial last page",
+			queryParams: "?page=3&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 11, Status: domain.MessageStatusSent}, {ID: 12, Status: domain.MessageStatusSent}}
+				m.On("GetSentMessages", mock.Anything, 10, 5, domain.DefaultSentMessagesSort).Return(messages, 12, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[{"id":11,"recipient":"","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},{"id":12,"recipient":"","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":12,"page":3,"limit":5,"total_pages":3,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "exact-fit last page",
+			queryParams: "?page=2&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{{ID: 10, Status: domain.MessageStatusSent}}
+				m.On("GetSentMessages", mock.Anything, 5, 5, domain.DefaultSentMessagesSort).Return(messages, 10, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[{"id":10,"recipient":"","content":"","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":10,"page":2,"limit":5,"total_pages":2,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:        "first of several pages",
+			queryParams: "?page=1&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSentMessages", mock.Anything, 0, 5, domain.DefaultSentMessagesSort).Return([]*domain.Message{}, 10, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[],"total":10,"page":1,"limit":5,"total_pages":2,"has_next":true,"has_prev":false}`,
+		},
+		{
+			name:        "absent parameters use the defaults",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSentMessages", mock.Anything, 0, 10, domain.DefaultSentMessagesSort).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[],"total":0,"page":1,"limit":10,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "sort and order",
+			queryParams: "?sort=created_at&order=asc",
+			mockSetup: func(m *MockMessageService) {
+				order := domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortAsc}
+				m.On("GetSentMessages", mock.Anything, 0, 10, order).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[],"total":0,"page":1,"limit":10,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:        "order alone keeps sorting by sent_at",
+			queryParams: "?order=asc",
+			mockSetup: func(m *MockMessageService) {
+				order := domain.MessageSort{Field: domain.SortBySentAt, Order: domain.SortAsc}
+				m.On("GetSentMessages", mock.Anything, 0, 10, order).Return([]*domain.Message{}, 0, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[],"total":0,"page":1,"limit":10,"total_pages":0,"has_next":false,"has_prev":false}`,
+		},
+		{
+			name:           "unknown sort field",
+			queryParams:    "?sort=recipient",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"sort must be one of sent_at, created_at"}}`,
+		},
+		{
+			name:           "unknown order",
+			queryParams:    "?order=sideways",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"order must be one of asc, desc"}}`,
+		},
+		{
+			name:           "non-integer limit",
+			queryParams:    "?limit=abc",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be an integer"}}`,
+		},
+		{
+			name:           "limit above maximum",
+			queryParams:    "?limit=9999",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be between 1 and 100"}}`,
+		},
+		{
+			name:           "page below one",
+			queryParams:    "?page=0",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"page must be at least 1"}}`,
+		},
+		{
+			name:           "page past the last addressable offset",
+			queryParams:    "?page=9223372036854775807&limit=100",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"page is too large"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/sent"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetFailedMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful get failed messages",
+			queryParams: "?page=2&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				errorMsg := "webhook delivery failed with status 503"
+				failedAt := time.Date(2023, 1, 1, 0, 1, 0, 0, time.UTC)
+				messages := []*domain.Message{
+					{
+						ID:           7,
+						Recipient:    "test@example.com",
+						Content:      "Test message",
+						Status:       domain.MessageStatusFailed,
+						RetryCount:   1,
+						MaxRetries:   3,
+						FailedAt:     &failedAt,
+						ErrorMessage: &errorMsg,
+					},
+				}
+				m.On("GetFailedMessages", mock.Anything, 5, 5).Return(messages, 6, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"data":[{"id":7,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"failed","max_retries":3,"retry_count":1,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","failed_at":"2023-01-01T00:01:00Z","error_message":"webhook delivery failed with status 503"}],"total":6,"page":2,"limit":5,"total_pages":2,"has_next":false,"has_prev":true}`,
+		},
+		{
+			name:           "invalid limit",
+			queryParams:    "?limit=500",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be between 1 and 100"}}`,
+		},
+		{
+			name:        "service error",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetFailedMessages", mock.Anything, 0, 10).Return(nil, 0, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get failed messages"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/failed"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetDeadLetterMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful get dead-letter messages",
+			queryParams: "?page=2&limit=5",
+			mockSetup: func(m *MockMessageService) {
+				errorMsg := "webhook delivery failed with status 500"
+				messages := []*domain.Message{
+					{
+						ID:           1,
+						Recipient:    "test@example.com",
+						Content:      "Test message",
+						Status:       domain.MessageStatusDeadLetter,
+						RetryCount:   3,
+						MaxRetries:   3,
+						ErrorMessage: &errorMsg,
+					},
+				}
+				m.On("GetDeadLetterMessages", mock.Anything, 5, 5).Return(messages, 6, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"dead_letter","max_retries":3,"retry_count":3,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","error_message":"webhook delivery failed with status 500"}],"total":6,"page":2,"limit":5}`,
+		},
+		{
+			name:        "service error",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetDeadLetterMessages", mock.Anything, 0, 10).Return(nil, 0, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get dead-letter messages"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/dead-letter"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequeueMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		messageID      string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "successful requeue",
+			messageID: "1",
+			mockSetup: func(m *MockMessageService) {
+				message := &domain.Message{
+					ID:         1,
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					Status:     domain.MessageStatusPending,
+					MaxRetries: 3,
+				}
+				m.On("RequeueMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"pending","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:      "already sent",
+			messageID: "2",
+			mockSetup: func(m *MockMessageService) {
+				m.On("RequeueMessage", mock.Anything, int64(2)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageAlreadySent))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_ALREADY_SENT","message":"Message has already been sent"}}`,
+		},
+		{
+			name:      "not failed or dead-lettered",
+			messageID: "3",
+			mockSetup: func(m *MockMessageService) {
+				m.On("RequeueMessage", mock.Anything, int64(3)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotRequeueable))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_REQUEUEABLE","message":"Only failed or dead-lettered messages can be requeued"}}`,
+		},
+		{
+			name:      "message not found",
+			messageID: "999",
+			mockSetup: func(m *MockMessageService) {
+				m.On("RequeueMessage", mock.Anything, int64(999)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "invalid message ID",
+			messageID:      "invalid",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages/"+tt.messageID+"/requeue", nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendMessageNow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sentAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sent := &domain.Message{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		Status:     domain.MessageStatusSent,
+		MaxRetries: 3,
+		SentAt:     &sentAt,
+	}
+	sentBody := `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"sent","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","sent_at":"2024-01-01T12:00:00Z"}`
+
+	tests := []struct {
+		name           string
+		path           string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "pending message",
+			path: "/api/v1/messages/1/send",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(1), false).Return(sent, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   sentBody,
+		},
+		{
+			name: "already sent without force",
+			path: "/api/v1/messages/1/send",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(1), false).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageAlreadySent))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_ALREADY_SENT","message":"Message has already been sent; use force=true to send it again"}}`,
+		},
+		{
+			name: "forced resend",
+			path: "/api/v1/messages/1/send?force=true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(1), true).Return(sent, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   sentBody,
+		},
+		{
+			name: "failed forced resend",
+			path: "/api/v1/messages/1/send?force=true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(1), true).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrDeliveryFailed))
+			},
+			expectedStatus: 502,
+			expectedBody:   `{"error":{"code":"DELIVERY_FAILED","message":"Message could not be delivered again; it stays sent"}}`,
+		},
+		{
+			name: "cancelled, expired or claimed message",
+			path: "/api/v1/messages/1/send?force=true",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(1), true).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotSendable))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_SENDABLE","message":"Cancelled, expired or claimed messages cannot be sent"}}`,
+		},
+		{
+			name: "message not found",
+			path: "/api/v1/messages/999/send",
+			mockSetup: func(m *MockMessageService) {
+				m.On("SendNow", mock.Anything, int64(999), false).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "invalid force",
+			path:           "/api/v1/messages/1/send?force=maybe",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"force must be a boolean"}}`,
+		},
+		{
+			name:           "invalid message ID",
+			path:           "/api/v1/messages/invalid/send",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		messageID      string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "successful cancel",
+			messageID: "1",
+			mockSetup: func(m *MockMessageService) {
+				message := &domain.Message{
+					ID:         1,
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					Status:     domain.MessageStatusCancelled,
+					MaxRetries: 3,
+				}
+				m.On("CancelMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"cancelled","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:      "already sent",
+			messageID: "2",
+			mockSetup: func(m *MockMessageService) {
+				m.On("CancelMessage", mock.Anything, int64(2)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotCancellable))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_CANCELLABLE","message":"Message can no longer be cancelled"}}`,
+		},
+		{
+			name:      "message not found",
+			messageID: "999",
+			mockSetup: func(m *MockMessageService) {
+				m.On("CancelMessage", mock.Anything, int64(999)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "invalid message ID",
+			messageID:      "invalid",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages/"+tt.messageID+"/cancel", nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		messageID      string
+		requestBody    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "valid edit",
+			messageID:   "1",
+			requestBody: `{"content":"Corrected message"}`,
+			mockSetup: func(m *MockMessageService) {
+				message := &domain.Message{
+					ID:         1,
+					Recipient:  "test@example.com",
+					Content:    "Corrected message",
+					WebhookURL: "https://example.com/webhook",
+					Status:     domain.MessageStatusPending,
+					MaxRetries: 3,
+				}
+				m.On("UpdatePendingMessage", mock.Anything, int64(1), mock.MatchedBy(func(update *domain.UpdateMessageRequest) bool {
+					return update.Content != nil && *update.Content == "Corrected message" &&
+						update.Recipient == nil && update.WebhookURL == nil
+				})).Return(message, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Corrected message","webhook_url":"https://example.com/webhook","status":"pending","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:        "sent message",
+			messageID:   "2",
+			requestBody: `{"content":"Too late"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("UpdatePendingMessage", mock.Anything, int64(2), mock.Anything).
+					Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotPending))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_PENDING","message":"Only pending messages can be edited"}}`,
+		},
+		{
+			name:        "invalid new email",
+			messageID:   "3",
+			requestBody: `{"recipient":"not-an-email"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("UpdatePendingMessage", mock.Anything, int64(3), mock.Anything).
+					Return(nil, domain.NewFieldValidationError(
+						domain.FieldError{Field: "recipient", Message: "recipient must be a valid email address"},
+					))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"recipient must be a valid email address","details":[{"field":"recipient","message":"recipient must be a valid email address"}]}}`,
+		},
+		{
+			name:        "message not found",
+			messageID:   "999",
+			requestBody: `{"content":"Hello"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("UpdatePendingMessage", mock.Anything, int64(999), mock.Anything).
+					Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "malformed body",
+			messageID:      "1",
+			requestBody:    `{"content":`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("PATCH", "/api/v1/messages/"+tt.messageID, strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResendMessage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	originalID := int64(1)
+	tests := []struct {
+		name           string
+		messageID      string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "successful resend",
+			messageID: "1",
+			mockSetup: func(m *MockMessageService) {
+				message := &domain.Message{
+					ID:         2,
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					Status:     domain.MessageStatusPending,
+					MaxRetries: 3,
+					ResentFrom: &originalID,
+				}
+				m.On("ResendMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+			expectedStatus: 201,
+			expectedBody:   `{"id":2,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"pending","max_retries":3,"retry_count":0,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","resent_from":1}`,
+		},
+		{
+			name:      "not sent",
+			messageID: "3",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ResendMessage", mock.Anything, int64(3)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotSent))
+			},
+			expectedStatus: 409,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_SENT","message":"Only sent messages can be resent"}}`,
+		},
+		{
+			name:      "message not found",
+			messageID: "999",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ResendMessage", mock.Anything, int64(999)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "invalid message ID",
+			messageID:      "invalid",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/messages/"+tt.messageID+"/resend", nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSuccessRate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "default window",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				rate := &domain.SuccessRate{Window: "1h0m0s", Sent: 9, DeadLettered: 1, SuccessRate: 0.9}
+				m.On("GetSuccessRate", mock.Anything, time.Hour, false).Return(rate, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"window":"1h0m0s","sent":9,"dead_lettered":1,"success_rate":0.9}`,
+		},
+		{
+			name:        "per-host breakdown",
+			queryParams: "?window=15m&by_host=true",
+			mockSetup: func(m *MockMessageService) {
+				rate := &domain.SuccessRate{
+					Window:       "15m0s",
+					Sent:         3,
+					DeadLettered: 1,
+					SuccessRate:  0.75,
+					Hosts: []domain.HostSuccessRate{
+						{Host: "a.example.com", Sent: 3, DeadLettered: 0, SuccessRate: 1},
+						{Host: "b.example.com", Sent: 0, DeadLettered: 1, SuccessRate: 0},
+					},
+				}
+				m.On("GetSuccessRate", mock.Anything, 15*time.Minute, true).Return(rate, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"window":"15m0s","sent":3,"dead_lettered":1,"success_rate":0.75,"hosts":[{"host":"a.example.com","sent":3,"dead_lettered":0,"success_rate":1},{"host":"b.example.com","sent":0,"dead_lettered":1,"success_rate":0}]}`,
+		},
+		{
+			name:           "invalid window",
+			queryParams:    "?window=soon",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"window must be a duration between 1m and 720h"}}`,
+		},
+		{
+			name:           "window too large",
+			queryParams:    "?window=1000h",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"window must be a duration between 1m and 720h"}}`,
+		},
+		{
+			name:           "invalid by_host",
+			queryParams:    "?by_host=maybe",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"by_host must be a boolean"}}`,
+		},
+		{
+			name:        "service error",
+			queryParams: "?window=1h",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSuccessRate", mock.Anything, time.Hour, false).Return(nil, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get success rate"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/stats/success-rate"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRecentMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "default window",
+			queryParams: "",
+			mockSetup: func(m *MockMessageService) {
+				messages := []*domain.Message{
+					{ID: 2, Recipient: "test@example.com", Content: "Test message", Status: domain.MessageStatusFailed, MaxRetries: 3, RetryCount: 1},
+				}
+				m.On("GetRecentMessages", mock.Anything, 5*time.Minute, 50).Return(messages, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[{"id":2,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"failed","max_retries":3,"retry_count":1,"priority":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"count":1,"since":"5m0s"}`,
+		},
+		{
+			name:        "custom window and limit",
+			queryParams: "?since=2h&limit=20",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetRecentMessages", mock.Anything, 2*time.Hour, 20).Return(nil, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"messages":[],"count":0,"since":"2h0m0s"}`,
+		},
+		{
+			name:           "invalid since",
+			queryParams:    "?since=yesterday",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"since must be a positive duration of at most 24h"}}`,
+		},
+		{
+			name:           "negative since",
+			queryParams:    "?since=-5m",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"since must be a positive duration of at most 24h"}}`,
+		},
+		{
+			name:           "since too large",
+			queryParams:    "?since=48h",
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"since must be a positive duration of at most 24h"}}`,
+		},
+		{
+			name:        "service error",
+			queryParams: "?since=1m",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetRecentMessages", mock.Anything, time.Minute, 50).Return(nil, assert.AnError)
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to get recent messages"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/messages/recent"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPauseHost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pausedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := pausedAt.Add(30 * time.Minute)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "pause with duration",
+			requestBody: `{"host":"api.partner.com","duration":"30m"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("PauseHost", mock.Anything, "api.partner.com", 30*time.Minute).
+					Return(&domain.PausedHost{Host: "api.partner.com", PausedAt: pausedAt, Until: &until}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"host":"api.partner.com","paused_at":"2024-01-01T12:00:00Z","until":"2024-01-01T12:30:00Z"}`,
+		},
+		{
+			name:        "pause until resumed",
+			requestBody: `{"host":"api.partner.com"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("PauseHost", mock.Anything, "api.partner.com", time.Duration(0)).
+					Return(&domain.PausedHost{Host: "api.partner.com", PausedAt: pausedAt}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"host":"api.partner.com","paused_at":"2024-01-01T12:00:00Z"}`,
+		},
+		{
+			name:           "missing host",
+			requestBody:    `{"duration":"30m"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Host is required"}}`,
+		},
+		{
+			name:           "invalid duration",
+			requestBody:    `{"host":"api.partner.com","duration":"soon"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"duration must be a positive duration such as 30m"}}`,
+		},
+		{
+			name:           "negative duration",
+			requestBody:    `{"host":"api.partner.com","duration":"-5m"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"duration must be a positive duration such as 30m"}}`,
+		},
+		{
+			name:        "host is a URL",
+			requestBody: `{"host":"https://api.partner.com/hook"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("PauseHost", mock.Anything, "https://api.partner.com/hook", time.Duration(0)).
+					Return(nil, domain.NewValidationError("host must be a hostname such as api.example.com, not a URL"))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"host must be a hostname such as api.example.com, not a URL"}}`,
+		},
+		{
+			name:        "store error",
+			requestBody: `{"host":"api.partner.com"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("PauseHost", mock.Anything, "api.partner.com", time.Duration(0)).
+					Return(nil, errors.New("redis unavailable"))
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to pause webhook host"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/webhooks/pause", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResumeHost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful resume",
+			requestBody: `{"host":"api.partner.com"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("ResumeHost", mock.Anything, "api.partner.com").Return(nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"message":"Webhook host resumed","host":"api.partner.com"}`,
+		},
+		{
+			name:        "host not paused",
+			requestBody: `{"host":"api.partner.com"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("ResumeHost", mock.Anything, "api.partner.com").Return(domain.ErrHostNotPaused)
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"HOST_NOT_PAUSED","message":"Webhook host is not paused"}}`,
+		},
+		{
+			name:           "missing host",
+			requestBody:    `{}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Host is required"}}`,
+		},
+		{
+			name:        "store error",
+			requestBody: `{"host":"api.partner.com"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("ResumeHost", mock.Anything, "api.partner.com").Return(errors.New("redis unavailable"))
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to resume webhook host"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("POST", "/api/v1/webhooks/resume", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPausedHosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pausedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "paused hosts",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetPausedHosts", mock.Anything).Return([]*domain.PausedHost{
+					{Host: "api.partner.com", PausedAt: pausedAt},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"hosts":[{"host":"api.partner.com","paused_at":"2024-01-01T12:00:00Z"}],"count":1}`,
+		},
+		{
+			name: "no paused hosts",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetPausedHosts", mock.Anything).Return([]*domain.PausedHost{}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"hosts":[],"count":0}`,
+		},
+		{
+			name: "store error",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetPausedHosts", mock.Anything).Return(nil, errors.New("redis unavailable"))
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to list paused webhook hosts"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest("GET", "/api/v1/webhooks/paused", nil)
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := createdAt.Add(30 * time.Minute)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		requestBody    string
+		mockSetup      func(*MockMessageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "suppress recipient with duration",
+			method:      "POST",
+			path:        "/api/v1/suppressions",
+			requestBody: `{"key":"user@example.com","duration":"30m"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("Suppress", mock.Anything, "user@example.com", 30*time.Minute).
+					Return(&domain.Suppression{Key: "user@example.com", Kind: domain.SuppressionRecipient, CreatedAt: createdAt, Until: &until}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"key":"user@example.com","kind":"recipient","created_at":"2024-01-01T12:00:00Z","until":"2024-01-01T12:30:00Z"}`,
+		},
+		{
+			name:           "missing key",
+			method:         "POST",
+			path:           "/api/v1/suppressions",
+			requestBody:    `{"duration":"30m"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Key is required"}}`,
+		},
+		{
+			name:           "invalid duration",
+			method:         "POST",
+			path:           "/api/v1/suppressions",
+			requestBody:    `{"key":"user@example.com","duration":"soon"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"duration must be a positive duration such as 30m"}}`,
+		},
+		{
+			name:        "invalid key",
+			method:      "POST",
+			path:        "/api/v1/suppressions",
+			requestBody: `{"key":"https://api.partner.com/hook"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("Suppress", mock.Anything, "https://api.partner.com/hook", time.Duration(0)).
+					Return(nil, domain.NewValidationError("host must be a hostname such as api.example.com, not a URL"))
+			},
+			expectedStatus: 400,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"host must be a hostname such as api.example.com, not a URL"}}`,
+		},
+		{
+			name:   "remove suppression",
+			method: "DELETE",
+			path:   "/api/v1/suppressions/user@example.com",
+			mockSetup: func(m *MockMessageService) {
+				m.On("Unsuppress", mock.Anything, "user@example.com").Return(nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"message":"Suppression removed","key":"user@example.com"}`,
+		},
+		{
+			name:   "remove missing suppression",
+			method: "DELETE",
+			path:   "/api/v1/suppressions/api.partner.com",
+			mockSetup: func(m *MockMessageService) {
+				m.On("Unsuppress", mock.Anything, "api.partner.com").Return(domain.ErrNotSuppressed)
+			},
+			expectedStatus: 404,
+			expectedBody:   `{"error":{"code":"NOT_SUPPRESSED","message":"Key is not suppressed"}}`,
+		},
+		{
+			name:   "list suppressions",
+			method: "GET",
+			path:   "/api/v1/suppressions",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSuppressions", mock.Anything).Return([]*domain.Suppression{
+					{Key: "user@example.com", Kind: domain.SuppressionRecipient, CreatedAt: createdAt},
+					{Key: "api.partner.com", Kind: domain.SuppressionHost, CreatedAt: createdAt, Until: &until},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"suppressions":[{"key":"user@example.com","kind":"recipient","created_at":"2024-01-01T12:00:00Z"},{"key":"api.partner.com","kind":"host","created_at":"2024-01-01T12:00:00Z","until":"2024-01-01T12:30:00Z"}],"count":2}`,
+		},
+		{
+			name:   "list error",
+			method: "GET",
+			path:   "/api/v1/suppressions",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSuppressions", mock.Anything).Return(nil, errors.New("redis unavailable"))
+			},
+			expectedStatus: 500,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to list suppressions"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestToMessageResponse(t *testing.T) {
+	istanbul := time.FixedZone("TRT", 3*60*60)
+	createdAt := time.Date(2024, 1, 1, 15, 0, 0, 0, istanbul)
+	failedAt := createdAt.Add(time.Minute)
+	nextRetryAt := createdAt.Add(2 * time.Minute)
+	sentAt := createdAt.Add(3 * time.Minute)
+	errorMsg := "webhook delivery failed with status 500"
+	providerID := "abc123"
+
+	t.Run("all fields", func(t *testing.T) {
+		message := &domain.Message{
+			ID:                1,
+			Recipient:         "test@example.com",
+			Content:           "Test message",
+			WebhookURL:        "https://example.com/webhook",
+			Status:            domain.MessageStatusSent,
+			RetryCount:        1,
+			MaxRetries:        3,
+			Priority:          5,
+			CreatedAt:         createdAt,
+			UpdatedAt:         sentAt,
+			SentAt:            &sentAt,
+			FailedAt:          &failedAt,
+			NextRetryAt:       &nextRetryAt,
+			ErrorMessage:      &errorMsg,
+			ProviderMessageID: &providerID,
+		}
+
+		body, err := json.Marshal(toMessageResponse(message, time.UTC))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id": 1,
+			"recipient": "test@example.com",
+			"content": "Test message",
+			"webhook_url": "https://example.com/webhook",
+			"status": "sent",
+			"retry_count": 1,
+			"max_retries": 3,
+			"priority": 5,
+			"created_at": "2024-01-01T12:00:00Z",
+			"updated_at": "2024-01-01T12:03:00Z",
+			"sent_at": "2024-01-01T12:03:00Z",
+			"failed_at": "2024-01-01T12:01:00Z",
+			"next_retry_at": "2024-01-01T12:02:00Z",
+			"error_message": "webhook delivery failed with status 500",
+			"provider_message_id": "abc123"
+		}`, string(body))
+	})
+
+	t.Run("unset optional fields are omitted", func(t *testing.T) {
+		message := &domain.Message{
+			ID:        2,
+			Status:    domain.MessageStatusPending,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+
+		body, err := json.Marshal(toMessageResponse(message, time.UTC))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id": 2,
+			"recipient": "",
+			"content": "",
+			"webhook_url": "",
+			"status": "pending",
+			"retry_count": 0,
+			"max_retries": 0,
+			"priority": 0,
+			"created_at": "2024-01-01T12:00:00Z",
+			"updated_at": "2024-01-01T12:00:00Z"
+		}`, string(body))
+	})
+
+	t.Run("empty list encodes as array", func(t *testing.T) {
+		body, err := json.Marshal(toMessageResponses(nil, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "[]", string(body))
+	})
+}
+
+func TestFormatTimestamp_DisplayLocation(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		instant  time.Time
+		loc      *time.Location
+		expected string
+	}{
+		{
+			name:     "UTC keeps the Z suffix",
+			instant:  time.Date(2024, 3, 10, 6, 59, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: "2024-03-10T06:59:00Z",
+		},
+		{
+			name:     "nil location falls back to UTC",
+			instant:  time.Date(2024, 3, 10, 6, 59, 0, 0, time.UTC),
+			loc:      nil,
+			expected: "2024-03-10T06:59:00Z",
+		},
+		{
+			name:     "New York just before spring forward",
+			instant:  time.Date(2024, 3, 10, 6, 59, 0, 0, time.UTC),
+			loc:      newYork,
+			expected: "2024-03-10T01:59:00-05:00",
+		},
+		{
+			name:     "New York just after spring forward",
+			instant:  time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC),
+			loc:      newYork,
+			expected: "2024-03-10T03:00:00-04:00",
+		},
+		{
+			name:     "New York first 1:30 on fall back day",
+			instant:  time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC),
+			loc:      newYork,
+			expected: "2024-11-03T01:30:00-04:00",
+		},
+		{
+			name:     "New York repeated 1:30 on fall back day",
+			instant:  time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC),
+			loc:      newYork,
+			expected: "2024-11-03T01:30:00-05:00",
+		},
+		{
+			name:     "Berlin before summer time",
+			instant:  time.Date(2024, 3, 31, 0, 59, 0, 0, time.UTC),
+			loc:      berlin,
+			expected: "2024-03-31T01:59:00+01:00",
+		},
+		{
+			name:     "Berlin after summer time starts",
+			instant:  time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC),
+			loc:      berlin,
+			expected: "2024-03-31T03:00:00+02:00",
+		},
+		{
+			name:     "input zone does not leak into output",
+			instant:  time.Date(2024, 7, 1, 15, 0, 0, 0, time.FixedZone("TRT", 3*60*60)),
+			loc:      berlin,
+			expected: "2024-07-01T14:00:00+02:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatTimestamp(tt.instant, tt.loc))
+
+			parsed, err := time.Parse(time.RFC3339, tt.expected)
+			require.NoError(t, err)
+			assert.True(t, parsed.Equal(tt.instant), "formatted timestamp must denote the same instant")
+		})
+	}
+}
+
+func TestGetMessage_DisplayLocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	createdAt := time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC)
+	sentAt := time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC)
+	message := &domain.Message{
+		ID:        1,
+		Status:    domain.MessageStatusSent,
+		CreatedAt: createdAt,
+		UpdatedAt: sentAt,
+		SentAt:    &sentAt,
+	}
+
+	mockService := &MockMessageService{}
+	mockService.On("GetMessage", mock.Anything, int64(1)).Return(message, nil)
+
+	server := NewServer(logger.New(), mockService, nil, WithDisplayLocation(newYork))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/messages/1", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response MessageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2024-11-03T01:30:00-04:00", response.CreatedAt)
+	assert.Equal(t, "2024-11-03T01:30:00-05:00", response.UpdatedAt)
+	require.NotNil(t, response.SentAt)
+	assert.Equal(t, "2024-11-03T01:30:00-05:00", *response.SentAt)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMessageResponseShapeAcrossEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	failedAt := createdAt.Add(time.Minute)
+	errorMsg := "webhook delivery failed with status 500"
+	message := &domain.Message{
+		ID:           1,
+		Recipient:    "test@example.com",
+		Content:      "Test message",
+		WebhookURL:   "https://example.com/webhook",
+		Status:       domain.MessageStatusFailed,
+		RetryCount:   1,
+		MaxRetries:   3,
+		CreatedAt:    createdAt,
+		UpdatedAt:    failedAt,
+		FailedAt:     &failedAt,
+		ErrorMessage: &errorMsg,
+	}
+	expected := `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"https://example.com/webhook","status":"failed","retry_count":1,"max_retries":3,"priority":0,"created_at":"2024-01-01T12:00:00Z","updated_at":"2024-01-01T12:01:00Z","failed_at":"2024-01-01T12:01:00Z","error_message":"webhook delivery failed with status 500"}`
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		mockSetup func(*MockMessageService)
+		extract   func(t *testing.T, body []byte) json.RawMessage
+	}{
+		{
+			name:   "get message",
+			method: "GET",
+			path:   "/api/v1/messages/1",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+		},
+		{
+			name:   "requeue message",
+			method: "POST",
+			path:   "/api/v1/messages/1/requeue",
+			mockSetup: func(m *MockMessageService) {
+				m.On("RequeueMessage", mock.Anything, int64(1)).Return(message, nil)
+			},
+		},
+		{
+			name:   "list messages",
+			method: "GET",
+			path:   "/api/v1/messages",
+			mockSetup: func(m *MockMessageService) {
+				m.On("ListMessages", mock.Anything, domain.MessageStatus(""), 0, 50).Return([]*domain.Message{message}, 1, nil)
+			},
+			extract: firstOf("messages"),
+		},
+		{
+			name:   "sent messages",
+			method: "GET",
+			path:   "/api/v1/messages/sent",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetSentMessages", mock.Anything, 0, 10, domain.DefaultSentMessagesSort).Return([]*domain.Message{message}, 1, nil)
+			},
+			extract: firstOf("data"),
+		},
+		{
+			name:   "failed messages",
+			method: "GET",
+			path:   "/api/v1/messages/failed",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetFailedMessages", mock.Anything, 0, 10).Return([]*domain.Message{message}, 1, nil)
+			},
+			extract: firstOf("data"),
+		},
+		{
+			name:   "dead-letter messages",
+			method: "GET",
+			path:   "/api/v1/messages/dead-letter",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetDeadLetterMessages", mock.Anything, 0, 10).Return([]*domain.Message{message}, 1, nil)
+			},
+			extract: firstOf("messages"),
+		},
+		{
+			name:   "recent messages",
+			method: "GET",
+			path:   "/api/v1/messages/recent",
+			mockSetup: func(m *MockMessageService) {
+				m.On("GetRecentMessages", mock.Anything, 5*time.Minute, 50).Return([]*domain.Message{message}, nil)
+			},
+			extract: firstOf("messages"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockMessageService{}
+			tt.mockSetup(mockService)
+
+			server := createTestServerWithMock(mockService)
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
 			w := httptest.NewRecorder()
 
 			server.router.ServeHTTP(w, req)
 
-			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			require.Equal(t, http.StatusOK, w.Code)
+			body := json.RawMessage(w.Body.Bytes())
+			if tt.extract != nil {
+				body = tt.extract(t, w.Body.Bytes())
+			}
+			assert.JSONEq(t, expected, string(body))
 
 			mockService.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGetMessages(t *testing.T) {
+// firstOf returns an extractor for the first element of the named list in a response body
+func firstOf(field string) func(t *testing.T, body []byte) json.RawMessage {
+	return func(t *testing.T, body []byte) json.RawMessage {
+		var response map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		var list []json.RawMessage
+		require.NoError(t, json.Unmarshal(response[field], &list))
+		require.NotEmpty(t, list)
+		return list[0]
+	}
+}
+
+// stubSchedulerService is a scheduler.MessageService returning fixed results.
+// When started is set, processing runs signal it and wait for release.
+type stubSchedulerService struct {
+	processed int
+	err       error
+	started   chan struct{}
+	release   chan struct{}
+}
+
+func (s *stubSchedulerService) ProcessPendingMessages(ctx context.Context) (int, error) {
+	if s.started != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return s.processed, s.err
+}
+
+func (s *stubSchedulerService) RetryFailedMessages(ctx context.Context) error { return nil }
+
+func (s *stubSchedulerService) ArchiveOldMessages(ctx context.Context) (int, error) { return 0, nil }
+func (s *stubSchedulerService) PurgeOldMessages(ctx context.Context) (int, error)   { return 0, nil }
+
+func TestGetSchedulerStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	sched := scheduler.NewScheduler(&stubSchedulerService{}, testLogger, &scheduler.Config{
+		ProcessingInterval: 5 * time.Millisecond,
+		RetryInterval:      5 * time.Millisecond,
+	})
+	server := NewServer(testLogger, &MockMessageService{}, sched)
+
+	getStatus := func(t *testing.T) map[string]interface{} {
+		req, _ := http.NewRequest("GET", "/api/v1/scheduler/status", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var status map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		return status
+	}
+
+	t.Run("before any run", func(t *testing.T) {
+		status := getStatus(t)
+
+		assert.Equal(t, false, status["running"])
+		assert.Equal(t, "5ms", status["processing_interval"])
+		assert.Equal(t, "5ms", status["retry_interval"])
+		assert.Contains(t, status, "last_processed_at")
+		assert.Nil(t, status["last_processed_at"])
+		assert.Contains(t, status, "last_retry_at")
+		assert.Nil(t, status["last_retry_at"])
+	})
+
+	t.Run("after runs", func(t *testing.T) {
+		require.NoError(t, sched.Start(context.Background()))
+		defer sched.Stop()
+
+		require.Eventually(t, func() bool {
+			status := sched.GetStatus()
+			return status["last_processed_at"] != nil && status["last_retry_at"] != nil
+		}, time.Second, 5*time.Millisecond)
+
+		status := getStatus(t)
+
+		assert.Equal(t, true, status["running"])
+		assert.Equal(t, "5ms", status["processing_interval"])
+		for _, key := range []string{"last_processed_at", "last_retry_at"} {
+			value, ok := status[key].(string)
+			require.True(t, ok, key)
+			_, err := time.Parse(time.RFC3339, value)
+			assert.NoError(t, err, key)
+		}
+	})
+}
+
+func TestTriggerScheduler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	trigger := func(server *Server) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/scheduler/trigger", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("runs while scheduler is stopped", func(t *testing.T) {
+		sched := scheduler.NewScheduler(&stubSchedulerService{processed: 3}, testLogger, nil)
+		server := NewServer(testLogger, &MockMessageService{}, sched)
+
+		w := trigger(server)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message":"Processing run completed","processed":3}`, w.Body.String())
+		assert.False(t, sched.IsRunning())
+		assert.NotNil(t, sched.GetStatus()["last_processed_at"])
+	})
+
+	t.Run("processing error", func(t *testing.T) {
+		sched := scheduler.NewScheduler(&stubSchedulerService{err: errors.New("database error")}, testLogger, nil)
+		server := NewServer(testLogger, &MockMessageService{}, sched)
+
+		w := trigger(server)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, `{"error":{"code":"INTERNAL_ERROR","message":"Processing run failed","details":"database error"}}`, w.Body.String())
+	})
+
+	t.Run("rejects overlapping runs", func(t *testing.T) {
+		stub := &stubSchedulerService{
+			processed: 1,
+			started:   make(chan struct{}),
+			release:   make(chan struct{}),
+		}
+		sched := scheduler.NewScheduler(stub, testLogger, nil)
+		server := NewServer(testLogger, &MockMessageService{}, sched)
+
+		first := make(chan *httptest.ResponseRecorder)
+		go func() { first <- trigger(server) }()
+		<-stub.started
+
+		w := trigger(server)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":{"code":"PROCESSING_IN_PROGRESS","message":"A processing run is already in progress"}}`, w.Body.String())
+
+		close(stub.release)
+		assert.Equal(t, http.StatusOK, (<-first).Code)
+	})
+}
+
+func TestUpdateSchedulerConfig(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name           string
-		queryParams    string
-		mockSetup      func(*MockMessageService)
-		expectedStatus int
-		expectedBody   string
+		name          string
+		body          string
+		expectedCode  int
+		expectedError string
 	}{
 		{
-			name:        "successful get messages",
-			queryParams: "?offset=0&limit=10",
-			mockSetup: func(m *MockMessageService) {
-				messages := []*domain.Message{
-					{
-						ID:        1,
-						Recipient: "test1@example.com",
-						Content:   "Test message 1",
-						Status:    domain.MessageStatusSent,
-					},
-					{
-						ID:        2,
-						Recipient: "test2@example.com",
-						Content:   "Test message 2",
-						Status:    domain.MessageStatusPending,
-					},
-				}
-				m.On("GetSentMessages", mock.Anything, 0, 10).Return(messages, 2, nil)
-			},
-			expectedStatus: 200,
-			expectedBody:   `{"messages":[{"id":1,"recipient":"test1@example.com","content":"Test message 1","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},{"id":2,"recipient":"test2@example.com","content":"Test message 2","webhook_url":"","status":"pending","max_retries":0,"retry_count":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"total":2,"offset":0,"limit":10}`,
+			name:         "valid intervals",
+			body:         `{"processing_interval":"10s","retry_interval":"1m"}`,
+			expectedCode: http.StatusOK,
 		},
 		{
-			name:        "default pagination",
-			queryParams: "",
-			mockSetup: func(m *MockMessageService) {
-				m.On("GetSentMessages", mock.Anything, 0, 50).Return([]*domain.Message{}, 0, nil)
-			},
-			expectedStatus: 200,
-			expectedBody:   `{"messages":[],"total":0,"offset":0,"limit":50}`,
+			name:          "missing retry interval",
+			body:          `{"processing_interval":"10s"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "processing_interval and retry_interval are required",
+		},
+		{
+			name:          "unparseable processing interval",
+			body:          `{"processing_interval":"soon","retry_interval":"1m"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "processing_interval must be a positive duration such as 10s",
+		},
+		{
+			name:          "zero processing interval",
+			body:          `{"processing_interval":"0s","retry_interval":"1m"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "processing_interval must be a positive duration such as 10s",
+		},
+		{
+			name:          "negative retry interval",
+			body:          `{"processing_interval":"10s","retry_interval":"-1m"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "retry_interval must be a positive duration such as 1m",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockService := &MockMessageService{}
-			tt.mockSetup(mockService)
-
-			server := createTestServerWithMock(mockService)
+			testLogger := logger.New()
+			sched := scheduler.NewScheduler(&stubSchedulerService{}, testLogger, scheduler.DefaultConfig())
+			server := NewServer(testLogger, &MockMessageService{}, sched)
 
-			req, _ := http.NewRequest("GET", "/api/v1/messages"+tt.queryParams, nil)
+			req, _ := http.NewRequest("PUT", "/api/v1/scheduler/config", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
-
 			server.router.ServeHTTP(w, req)
 
-			assert.Equal(t, tt.expectedStatus, w.Code)
-			assert.JSONEq(t, tt.expectedBody, w.Body.String())
-
-			mockService.AssertExpectations(t)
+			require.Equal(t, tt.expectedCode, w.Code)
+
+			status := sched.GetStatus()
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response.Error.Message)
+				assert.Equal(t, "30s", status["processing_interval"])
+				assert.Equal(t, "5m0s", status["retry_interval"])
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "Scheduler config updated", response["message"])
+			assert.Equal(t, "10s", status["processing_interval"])
+			assert.Equal(t, "1m0s", status["retry_interval"])
 		})
 	}
 }
 
-func TestGetMessage(t *testing.T) {
+func TestClaimMessages(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	claimed := []*domain.Message{{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: "https://example.com/webhook",
+		Status:     domain.MessageStatusSending,
+		MaxRetries: 3,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}}
+
 	tests := []struct {
 		name           string
-		messageID      string
+		body           string
 		mockSetup      func(*MockMessageService)
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
-			name:      "successful get message",
-			messageID: "1",
+			name: "default limit without a body",
+			body: "",
 			mockSetup: func(m *MockMessageService) {
-				message := &domain.Message{
-					ID:        1,
-					Recipient: "test@example.com",
-					Content:   "Test message",
-					Status:    domain.MessageStatusSent,
-				}
-				m.On("GetMessage", mock.Anything, int64(1)).Return(message, nil)
+				m.On("ClaimMessages", mock.Anything, 10).Return(claimed, nil)
 			},
-			expectedStatus: 200,
-			expectedBody:   `{"id":1,"recipient":"test@example.com","content":"Test message","webhook_url":"","status":"sent","max_retries":0,"retry_count":0,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"count":1,"messages":[{"id":1,"recipient":"test@example.com","content":"Test message",
+				"webhook_url":"https://example.com/webhook","status":"sending","retry_count":0,"max_retries":3,"priority":0,
+				"created_at":"2024-01-01T12:00:00Z","updated_at":"2024-01-01T12:00:00Z"}]}`,
 		},
 		{
-			name:           "invalid message ID",
-			messageID:      "invalid",
+			name: "explicit limit with nothing to claim",
+			body: `{"limit":50}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("ClaimMessages", mock.Anything, 50).Return([]*domain.Message(nil), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"count":0,"messages":[]}`,
+		},
+		{
+			name:           "limit too large",
+			body:           `{"limit":101}`,
 			mockSetup:      func(m *MockMessageService) {},
-			expectedStatus: 400,
-			expectedBody:   `{"error":"Invalid message ID"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"VALIDATION_FAILED","message":"limit must be between 1 and 100"}}`,
 		},
 		{
-			name:      "message not found",
-			messageID: "999",
+			name:           "invalid body",
+			body:           `{"limit":"ten"}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}}`,
+		},
+		{
+			name: "service error",
+			body: "",
 			mockSetup: func(m *MockMessageService) {
-				m.On("GetMessage", mock.Anything, int64(999)).Return(nil, domain.ErrMessageNotFound)
+				m.On("ClaimMessages", mock.Anything, 10).Return(nil, assert.AnError)
 			},
-			expectedStatus: 404,
-			expectedBody:   `{"error":"Message not found"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to claim messages"}}`,
 		},
 	}
 
@@ -340,12 +3513,12 @@ func TestGetMessage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockMessageService{}
 			tt.mockSetup(mockService)
+			server := createTestServerWithMock(mockService, WithAdminAPIKey("admin-123"))
 
-			server := createTestServerWithMock(mockService)
-
-			req, _ := http.NewRequest("GET", "/api/v1/messages/"+tt.messageID, nil)
+			req, _ := http.NewRequest("POST", "/api/v1/messages/claim", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Admin-Key", "admin-123")
 			w := httptest.NewRecorder()
-
 			server.router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
@@ -356,40 +3529,96 @@ func TestGetMessage(t *testing.T) {
 	}
 }
 
-func TestRetryFailedMessages(t *testing.T) {
+func TestAckNackMessage(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	providerID := "abc123"
+	errorMsg := "receiver returned 503"
+
 	tests := []struct {
 		name           string
-		requestBody    string
+		path           string
+		body           string
 		mockSetup      func(*MockMessageService)
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
-			name:        "successful retry",
-			requestBody: `{"batch_size": 5}`,
+			name: "ack with provider reference",
+			path: "/api/v1/messages/1/ack",
+			body: `{"provider_message_id":"abc123"}`,
 			mockSetup: func(m *MockMessageService) {
-				m.On("RetryFailedMessages", mock.Anything, 5).Return(3, nil)
+				m.On("AckMessage", mock.Anything, int64(1), "abc123").Return(&domain.Message{
+					ID: 1, Status: domain.MessageStatusSent, CreatedAt: createdAt, UpdatedAt: createdAt,
+					SentAt: &createdAt, ProviderMessageID: &providerID,
+				}, nil)
 			},
-			expectedStatus: 200,
-			expectedBody:   `{"retried_count":3}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":1,"recipient":"","content":"","webhook_url":"","status":"sent","retry_count":0,"max_retries":0,
+				"priority":0,"created_at":"2024-01-01T12:00:00Z","updated_at":"2024-01-01T12:00:00Z",
+				"sent_at":"2024-01-01T12:00:00Z","provider_message_id":"abc123"}`,
 		},
 		{
-			name:        "default batch size",
-			requestBody: `{}`,
+			name: "ack without a body",
+			path: "/api/v1/messages/1/ack",
+			body: "",
 			mockSetup: func(m *MockMessageService) {
-				m.On("RetryFailedMessages", mock.Anything, 10).Return(0, nil)
+				m.On("AckMessage", mock.Anything, int64(1), "").Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotClaimed))
 			},
-			expectedStatus: 200,
-			expectedBody:   `{"retried_count":0}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_CLAIMED","message":"Message is not claimed"}}`,
 		},
 		{
-			name:           "invalid JSON",
-			requestBody:    `{"invalid": json}`,
+			name: "ack missing message",
+			path: "/api/v1/messages/999/ack",
+			body: "",
+			mockSetup: func(m *MockMessageService) {
+				m.On("AckMessage", mock.Anything, int64(999), "").Return(nil, domain.ErrMessageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":{"code":"MESSAGE_NOT_FOUND","message":"Message not found"}}`,
+		},
+		{
+			name:           "ack invalid id",
+			path:           "/api/v1/messages/abc/ack",
+			body:           "",
 			mockSetup:      func(m *MockMessageService) {},
-			expectedStatus: 400,
-			expectedBody:   `{"error":"Invalid request body"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_MESSAGE_ID","message":"Invalid message ID"}}`,
+		},
+		{
+			name: "nack",
+			path: "/api/v1/messages/1/nack",
+			body: `{"error":"receiver returned 503"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("NackMessage", mock.Anything, int64(1), "receiver returned 503").Return(&domain.Message{
+					ID: 1, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3,
+					CreatedAt: createdAt, UpdatedAt: createdAt, FailedAt: &createdAt, ErrorMessage: &errorMsg,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"id":1,"recipient":"","content":"","webhook_url":"","status":"failed","retry_count":1,"max_retries":3,
+				"priority":0,"created_at":"2024-01-01T12:00:00Z","updated_at":"2024-01-01T12:00:00Z",
+				"failed_at":"2024-01-01T12:00:00Z","error_message":"receiver returned 503"}`,
+		},
+		{
+			name:           "nack without error",
+			path:           "/api/v1/messages/1/nack",
+			body:           `{}`,
+			mockSetup:      func(m *MockMessageService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":{"code":"INVALID_REQUEST_BODY","message":"Error is required"}}`,
+		},
+		{
+			name: "nack service error",
+			path: "/api/v1/messages/1/nack",
+			body: `{"error":"timeout"}`,
+			mockSetup: func(m *MockMessageService) {
+				m.On("NackMessage", mock.Anything, int64(1), "timeout").Return(nil, assert.AnError)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":{"code":"INTERNAL_ERROR","message":"Failed to reject message"}}`,
 		},
 	}
 
@@ -397,13 +3626,12 @@ func TestRetryFailedMessages(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockService := &MockMessageService{}
 			tt.mockSetup(mockService)
+			server := createTestServerWithMock(mockService, WithAdminAPIKey("admin-123"))
 
-			server := createTestServerWithMock(mockService)
-
-			req, _ := http.NewRequest("POST", "/api/v1/messages/retry", strings.NewReader(tt.requestBody))
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Admin-Key", "admin-123")
 			w := httptest.NewRecorder()
-
 			server.router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
@@ -413,3 +3641,275 @@ func TestRetryFailedMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware("s3cret"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStatus int
+	}{
+		{"valid key", "s3cret", http.StatusOK},
+		{"invalid key", "wrong", http.StatusUnauthorized},
+		{"missing key", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/protected", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error":{"code":"UNAUTHORIZED","message":"unauthorized"}}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		expectedKey string
+		key         string
+		wantStatus  int
+	}{
+		{"valid key", "admin-123", "admin-123", http.StatusOK},
+		{"invalid key", "admin-123", "wrong", http.StatusForbidden},
+		{"missing key", "admin-123", "", http.StatusForbidden},
+		{"no key configured", "", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST("/admin", AdminAuthMiddleware(tt.expectedKey), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"ok": true})
+			})
+
+			req, _ := http.NewRequest("POST", "/admin", nil)
+			if tt.key != "" {
+				req.Header.Set("X-Admin-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"error":{"code":"FORBIDDEN","message":"forbidden"}}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("insider_messaging_up 1\n"))
+	})
+
+	tests := []struct {
+		name       string
+		opts       []ServerOption
+		user, pass string
+		wantStatus int
+	}{
+		{"not served without a handler", nil, "", "", http.StatusNotFound},
+		{"open without credentials", []ServerOption{WithMetricsHandler(metricsHandler)}, "", "", http.StatusOK},
+		{"open with empty auth", []ServerOption{WithMetricsHandler(metricsHandler), WithMetricsAuth("", "")}, "", "", http.StatusOK},
+		{"correct credentials", []ServerOption{WithMetricsHandler(metricsHandler), WithMetricsAuth("prometheus", "s3cret")}, "prometheus", "s3cret", http.StatusOK},
+		{"wrong password", []ServerOption{WithMetricsHandler(metricsHandler), WithMetricsAuth("prometheus", "s3cret")}, "prometheus", "wrong", http.StatusUnauthorized},
+		{"wrong user", []ServerOption{WithMetricsHandler(metricsHandler), WithMetricsAuth("prometheus", "s3cret")}, "grafana", "s3cret", http.StatusUnauthorized},
+		{"missing credentials", []ServerOption{WithMetricsHandler(metricsHandler), WithMetricsAuth("prometheus", "s3cret")}, "", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The API key guards /api/v1 only, never /metrics
+			opts := append([]ServerOption{WithAPIKey("key-123")}, tt.opts...)
+			server := createTestServerWithMock(&MockMessageService{}, opts...)
+
+			req, _ := http.NewRequest("GET", "/metrics", nil)
+			if tt.user != "" || tt.pass != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			switch tt.wantStatus {
+			case http.StatusOK:
+				assert.Equal(t, "insider_messaging_up 1\n", w.Body.String())
+			case http.StatusUnauthorized:
+				assert.Equal(t, `Basic realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+				assert.JSONEq(t, `{"error":{"code":"UNAUTHORIZED","message":"unauthorized"}}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testLogger := logger.New()
+	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+
+	serve := func(server *Server, path, key string) int {
+		req, _ := http.NewRequest("GET", path, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("protects api routes but not probes", func(t *testing.T) {
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler, WithAPIKey("s3cret"))
+
+		assert.Equal(t, http.StatusUnauthorized, serve(server, "/api/v1/scheduler/status", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(server, "/api/v1/scheduler/status", "wrong"))
+		assert.Equal(t, http.StatusOK, serve(server, "/api/v1/scheduler/status", "s3cret"))
+
+		for _, path := range []string{"/livez", "/readyz", "/healthz", "/version"} {
+			assert.Equal(t, http.StatusOK, serve(server, path, ""), path)
+		}
+	})
+
+	t.Run("empty key disables auth", func(t *testing.T) {
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler, WithAPIKey(""))
+
+		assert.Equal(t, http.StatusOK, serve(server, "/api/v1/scheduler/status", ""))
+	})
+
+	t.Run("worker routes need the admin key as well", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		mockService.On("ClaimMessages", mock.Anything, 10).Return([]*domain.Message(nil), nil).Once()
+		server := NewServer(testLogger, mockService, mockScheduler, WithAPIKey("s3cret"), WithAdminAPIKey("admin-123"))
+
+		claim := func(apiKey, adminKey string) int {
+			req, _ := http.NewRequest("POST", "/api/v1/messages/claim", strings.NewReader(""))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", apiKey)
+			if adminKey != "" {
+				req.Header.Set("X-Admin-Key", adminKey)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, claim("", "admin-123"))
+		assert.Equal(t, http.StatusForbidden, claim("s3cret", ""))
+		assert.Equal(t, http.StatusForbidden, claim("s3cret", "s3cret"))
+		assert.Equal(t, http.StatusOK, claim("s3cret", "admin-123"))
+
+		for _, path := range []string{"/api/v1/messages/1/ack", "/api/v1/messages/1/nack"} {
+			req, _ := http.NewRequest("POST", path, nil)
+			req.Header.Set("X-API-Key", "s3cret")
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, path)
+		}
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("worker routes are closed without an admin key", func(t *testing.T) {
+		server := NewServer(testLogger, new(MockMessageService), mockScheduler)
+
+		req, _ := http.NewRequest("POST", "/api/v1/messages/claim", nil)
+		req.Header.Set("X-Admin-Key", "anything")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	testLogger := &logger.Logger{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+	mockScheduler := scheduler.NewScheduler(nil, testLogger, scheduler.DefaultConfig())
+	server := NewServer(testLogger, new(MockMessageService), mockScheduler)
+
+	get := func(path, requestID string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("echoes incoming id and tags handler logs", func(t *testing.T) {
+		buf.Reset()
+
+		w := get("/api/v1/messages/not-a-number", "req-123")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "req-123", w.Header().Get("X-Request-ID"))
+
+		var tagged int
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			if entry["msg"] == "Invalid message ID" || entry["msg"] == "HTTP request" {
+				assert.Equal(t, "req-123", entry["request_id"], entry["msg"])
+				tagged++
+			}
+		}
+		assert.Equal(t, 2, tagged, "handler and access log entries")
+	})
+
+	t.Run("generates id when absent", func(t *testing.T) {
+		first := get("/livez", "").Header().Get("X-Request-ID")
+		second := get("/livez", "").Header().Get("X-Request-ID")
+
+		_, err := uuid.Parse(first)
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("request context carries id and logger", func(t *testing.T) {
+		mockService := new(MockMessageService)
+		server := NewServer(testLogger, mockService, mockScheduler)
+		var ctxLogger *logger.Logger
+		mockService.On("GetMessage", mock.MatchedBy(func(ctx context.Context) bool {
+			ctxLogger = logger.FromContext(ctx)
+			return logger.RequestIDFromContext(ctx) == "req-456"
+		}), int64(1)).Return(&domain.Message{ID: 1}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/v1/messages/1", nil)
+		req.Header.Set("X-Request-ID", "req-456")
+		server.router.ServeHTTP(httptest.NewRecorder(), req)
+
+		mockService.AssertExpectations(t)
+		assert.Same(t, server.logger, ctxLogger)
+	})
+
+	t.Run("replaces invalid id", func(t *testing.T) {
+		for _, id := range []string{"has space", strings.Repeat("a", 129)} {
+			got := get("/livez", id).Header().Get("X-Request-ID")
+
+			assert.NotEqual(t, id, got)
+			_, err := uuid.Parse(got)
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// Idle per-client limiters are dropped after rateLimitIdleTTL; the sweep runs
+// at most once per rateLimitSweepInterval, on the request path
+const (
+	rateLimitIdleTTL       = 10 * time.Minute
+	rateLimitSweepInterval = time.Minute
+)
+
+// ipRateLimiter holds a token bucket per client IP
+type ipRateLimiter struct {
+	rps   rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+// clientLimiter is one client's token bucket and when it was last used
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newIPRateLimiter creates a limiter allowing rps requests per second per IP
+// with bursts of up to burst
+func newIPRateLimiter(rps float64, burst int) *ipRateLimiter {
+	return &ipRateLimiter{
+		rps:     rate.Limit(rps),
+		burst:   max(burst, 1),
+		now:     time.Now,
+		clients: make(map[string]*clientLimiter),
+	}
+}
+
+// reserve takes a token for ip, returning zero when the request may proceed or
+// how long the client has to wait otherwise. A rejected request does not use
+// up a token.
+func (l *ipRateLimiter) reserve(ip string) time.Duration {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+
+	reservation := client.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay
+	}
+	return 0
+}
+
+// sweep drops limiters that have been idle for longer than rateLimitIdleTTL.
+// Callers must hold l.mu.
+func (l *ipRateLimiter) sweep(now time.Time) {
+	for ip, client := range l.clients {
+		if now.Sub(client.lastSeen) > rateLimitIdleTTL {
+			delete(l.clients, ip)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimitMiddleware creates a Gin middleware limiting each client IP to rps
+// requests per second with bursts of up to burst. Requests over the limit get
+// 429 with a Retry-After header.
+func RateLimitMiddleware(rps float64, burst int) gin.HandlerFunc {
+	return rateLimitMiddleware(newIPRateLimiter(rps, burst))
+}
+
+// rateLimitMiddleware serves requests through limiter
+func rateLimitMiddleware(limiter *ipRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if wait := limiter.reserve(c.ClientIP()); wait > 0 {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			abortWithError(c, http.StatusTooManyRequests, ErrCodeRateLimited, "rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
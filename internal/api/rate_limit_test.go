@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedRouter(limiter *ipRateLimiter) *gin.Engine {
+	router := gin.New()
+	router.POST("/messages", rateLimitMiddleware(limiter), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func postFrom(router *gin.Engine, ip string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/messages", nil)
+	req.RemoteAddr = ip + ":12345"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("rejects requests over the limit and recovers", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		limiter := newIPRateLimiter(1, 2)
+		limiter.now = func() time.Time { return now }
+		router := newRateLimitedRouter(limiter)
+
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+
+		w := postFrom(router, "10.0.0.1")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":{"code":"RATE_LIMITED","message":"rate limit exceeded"}}`, w.Body.String())
+
+		// Other clients have their own bucket
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.2").Code)
+
+		now = now.Add(time.Second)
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+		assert.Equal(t, http.StatusTooManyRequests, postFrom(router, "10.0.0.1").Code)
+	})
+
+	t.Run("retry after covers the wait", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		limiter := newIPRateLimiter(0.2, 1)
+		limiter.now = func() time.Time { return now }
+		router := newRateLimitedRouter(limiter)
+
+		require.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+
+		w := postFrom(router, "10.0.0.1")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, retryAfter)
+
+		now = now.Add(time.Duration(retryAfter) * time.Second)
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+	})
+
+	t.Run("recovers in real time", func(t *testing.T) {
+		router := newRateLimitedRouter(newIPRateLimiter(20, 1))
+
+		require.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+		require.Equal(t, http.StatusTooManyRequests, postFrom(router, "10.0.0.1").Code)
+
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, http.StatusCreated, postFrom(router, "10.0.0.1").Code)
+	})
+}
+
+func TestIPRateLimiter_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newIPRateLimiter(1, 1)
+	limiter.now = func() time.Time { return now }
+
+	limiter.reserve("10.0.0.1")
+	now = now.Add(rateLimitIdleTTL / 2)
+	limiter.reserve("10.0.0.2")
+	assert.Len(t, limiter.clients, 2)
+
+	// The first client is now idle past the TTL; the second is not
+	now = now.Add(rateLimitIdleTTL/2 + time.Second)
+	limiter.reserve("10.0.0.3")
+
+	assert.NotContains(t, limiter.clients, "10.0.0.1")
+	assert.Contains(t, limiter.clients, "10.0.0.2")
+	assert.Contains(t, limiter.clients, "10.0.0.3")
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockMessageService)
+	server := createTestServerWithMock(mockService, WithRateLimit(1, 1))
+
+	// The body is invalid so the handler rejects it without calling the service;
+	// only the limiter decides between 400 and 429
+	post := func() int {
+		req, _ := http.NewRequest("POST", "/api/v1/messages", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, post())
+	assert.Equal(t, http.StatusTooManyRequests, post())
+
+	// Other routes are not limited
+	req, _ := http.NewRequest("GET", "/livez", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -1,10 +1,14 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/insider/insider-messaging/pkg/metrics"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/pressly/goose/v3"
 )
@@ -17,13 +21,30 @@ type DB struct {
 	*sql.DB
 }
 
+// Option configures the connection pool of a new database connection
+type Option func(*sql.DB)
+
+// WithPool bounds the connection pool. maxOpen of zero leaves open connections
+// unlimited, and maxLifetime of zero keeps connections open indefinitely.
+func WithPool(maxOpen, maxIdle int, maxLifetime time.Duration) Option {
+	return func(db *sql.DB) {
+		db.SetMaxOpenConns(maxOpen)
+		db.SetMaxIdleConns(maxIdle)
+		db.SetConnMaxLifetime(maxLifetime)
+	}
+}
+
 // New creates a new database connection
-func New(databaseURL string) (*DB, error) {
+func New(databaseURL string, opts ...Option) (*DB, error) {
 	db, err := sql.Open("postgres", databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	for _, opt := range opts {
+		opt(db)
+	}
+
 	// Test the connection
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
@@ -32,14 +53,58 @@ func New(databaseURL string) (*DB, error) {
 	return &DB{DB: db}, nil
 }
 
-// RunMigrations runs all pending database migrations
-func (db *DB) RunMigrations() error {
+// NewWithRetry creates a new database connection like New, but keeps pinging a
+// database that is not reachable yet, up to attempts times in total. The wait
+// starts at backoff and doubles after every failed attempt.
+func NewWithRetry(databaseURL string, attempts int, backoff time.Duration, logger *slog.Logger, opts ...Option) (*DB, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	for _, opt := range opts {
+		opt(db)
+	}
+
+	delay := backoff
+	for attempt := 1; ; attempt++ {
+		err := db.Ping()
+		if err == nil {
+			return &DB{DB: db}, nil
+		}
+		if attempt >= attempts {
+			db.Close()
+			return nil, fmt.Errorf("failed to ping database after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warn("Database not reachable, retrying",
+			"attempt", attempt,
+			"attempts", attempts,
+			"retry_in", delay,
+			"error", err,
+		)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// setupGoose points goose at the embedded migrations and the Postgres dialect
+func setupGoose() error {
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return fmt.Errorf("failed to set goose dialect: %w", err)
 	}
 
+	return nil
+}
+
+// RunMigrations runs all pending database migrations
+func (db *DB) RunMigrations() error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+
 	if err := goose.Up(db.DB, "migrations"); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -47,12 +112,56 @@ func (db *DB) RunMigrations() error {
 	return nil
 }
 
+// RollbackMigration rolls back the most recently applied migration
+func (db *DB) RollbackMigration() error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+
+	if err := goose.Down(db.DB, "migrations"); err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+
+	return nil
+}
+
+// MigrationVersion returns the version of the most recently applied migration
+func (db *DB) MigrationVersion() (int64, error) {
+	if err := setupGoose(); err != nil {
+		return 0, err
+	}
+
+	version, err := goose.GetDBVersion(db.DB)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
-// Health checks if the database connection is healthy
-func (db *DB) Health() error {
-	return db.Ping()
+// ReportConnections sets the database connections gauge to the number of open
+// connections every interval until ctx is cancelled
+func (db *DB) ReportConnections(ctx context.Context, m *metrics.Metrics, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.SetDatabaseConnections(float64(db.Stats().OpenConnections))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health checks if the database connection is healthy within ctx's deadline
+func (db *DB) Health(ctx context.Context) error {
+	return db.PingContext(ctx)
 }
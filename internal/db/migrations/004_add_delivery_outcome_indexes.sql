@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_messages_status_sent_at ON messages (status, sent_at);
+CREATE INDEX IF NOT EXISTS idx_messages_status_updated_at ON messages (status, updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_status_updated_at;
+DROP INDEX IF EXISTS idx_messages_status_sent_at;
+-- +goose StatementEnd
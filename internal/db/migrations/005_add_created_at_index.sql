@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+-- 001 declares this index inline, which PostgreSQL does not support, so make
+-- sure it exists for recent-message lookups
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- The index belongs to the messages table definition, so it is left in place
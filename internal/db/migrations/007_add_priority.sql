@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_messages_retry_priority ON messages (priority DESC, next_retry_at ASC) WHERE status = 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_retry_priority;
+ALTER TABLE messages DROP COLUMN IF EXISTS priority;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS messages_archive (
+    id BIGINT PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    webhook_url VARCHAR(500) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    retry_count INTEGER NOT NULL,
+    max_retries INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE,
+    error_message TEXT,
+    next_retry_at TIMESTAMP WITH TIME ZONE,
+    provider_message_id VARCHAR(255),
+    priority INTEGER NOT NULL DEFAULT 0,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_messages_archive_sent_at ON messages_archive (sent_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS messages_archive;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS resent_from BIGINT;
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS resent_from BIGINT;
+CREATE INDEX IF NOT EXISTS idx_messages_resent_from ON messages (resent_from) WHERE resent_from IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_resent_from;
+ALTER TABLE messages_archive DROP COLUMN IF EXISTS resent_from;
+ALTER TABLE messages DROP COLUMN IF EXISTS resent_from;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_idempotency_key ON messages (idempotency_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_idempotency_key;
+ALTER TABLE messages_archive DROP COLUMN IF EXISTS idempotency_key;
+ALTER TABLE messages DROP COLUMN IF EXISTS idempotency_key;
+-- +goose StatementEnd
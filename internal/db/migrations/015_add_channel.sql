@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel VARCHAR(20) NOT NULL DEFAULT 'webhook';
+ALTER TABLE messages ADD CONSTRAINT messages_channel_check CHECK (channel IN ('webhook', 'email', 'sms'));
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS channel VARCHAR(20) NOT NULL DEFAULT 'webhook';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages_archive DROP COLUMN IF EXISTS channel;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_channel_check;
+ALTER TABLE messages DROP COLUMN IF EXISTS channel;
+-- +goose StatementEnd
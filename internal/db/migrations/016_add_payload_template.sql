@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS payload_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS payload_template TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE messages_archive DROP COLUMN IF EXISTS payload_template;
+ALTER TABLE messages DROP COLUMN IF EXISTS payload_template;
+-- +goose StatementEnd
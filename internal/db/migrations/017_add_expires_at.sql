@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter', 'sending', 'cancelled', 'expired'));
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages (expires_at)
+    WHERE expires_at IS NOT NULL AND status IN ('pending', 'failed');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_messages_expires_at;
+UPDATE messages SET status = 'cancelled' WHERE status = 'expired';
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter', 'sending', 'cancelled'));
+ALTER TABLE messages_archive DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isUnsafeContentRune reports whether r is a control character that commonly
+// breaks receiver-side parsers. Newlines and tabs are kept.
+func isUnsafeContentRune(r rune) bool {
+	switch r {
+	case '\n', '\r', '\t':
+		return false
+	}
+	return unicode.IsControl(r)
+}
+
+// IsSafeContent reports whether content is valid UTF-8 without control characters
+func IsSafeContent(content string) bool {
+	if !utf8.ValidString(content) {
+		return false
+	}
+	return strings.IndexFunc(content, isUnsafeContentRune) == -1
+}
+
+// SanitizeContent replaces invalid UTF-8 sequences with U+FFFD and strips
+// control characters other than newlines and tabs
+func SanitizeContent(content string) string {
+	if IsSafeContent(content) {
+		return content
+	}
+
+	valid := strings.ToValidUTF8(content, string(utf8.RuneError))
+	return strings.Map(func(r rune) rune {
+		if isUnsafeContentRune(r) {
+			return -1
+		}
+		return r
+	}, valid)
+}
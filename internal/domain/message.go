@@ -2,23 +2,182 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // Common errors
 var (
-	ErrMessageNotFound = errors.New("message not found")
+	ErrMessageNotFound    = errors.New("message not found")
+	ErrMessageAlreadySent = errors.New("message already sent")
+	ErrHostNotPaused      = errors.New("webhook host is not paused")
+	ErrNotSuppressed      = errors.New("key is not suppressed")
+	ErrMessageNotClaimed  = errors.New("message is not claimed")
+	ErrMessageNotSent     = errors.New("message has not been sent")
+
+	ErrMessageNotCancellable = errors.New("message can no longer be cancelled")
+	ErrMessageNotPending     = errors.New("message is no longer pending")
+	ErrMessageNotRequeueable = errors.New("only failed or dead-lettered messages can be requeued")
+	ErrMessageNotSendable    = errors.New("cancelled, expired or claimed messages cannot be sent")
+
+	ErrDeliveryFailed = errors.New("delivery failed")
+
+	ErrDuplicateIdempotencyKey = errors.New("idempotency key already used")
+
+	ErrUnknownChannel = errors.New("no deliverer for message channel")
 )
 
+// FieldError describes why a single request field was rejected. Field is the
+// field's JSON name.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError reports a message request that was rejected before being stored.
+// Its message is safe to return to API clients. Fields lists every rejected
+// field when the request was checked field by field.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// NewValidationError creates a ValidationError with the given message
+func NewValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+// NewFieldValidationError creates a ValidationError reporting every given field
+// error, with a message joining theirs
+func NewFieldValidationError(fields ...FieldError) error {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return &ValidationError{Message: strings.Join(messages, "; "), Fields: fields}
+}
+
+// RecipientLimitError reports a message rejected because its recipient already
+// received the daily maximum. Its message is safe to return to API clients.
+type RecipientLimitError struct {
+	Limit   int
+	ResetAt time.Time // When the recipient's count starts over
+}
+
+// Error implements the error interface
+func (e *RecipientLimitError) Error() string {
+	return fmt.Sprintf("recipient has reached the daily limit of %d messages", e.Limit)
+}
+
 // MessageStatus represents the status of a message
 type MessageStatus string
 
 const (
-	MessageStatusPending MessageStatus = "pending"
-	MessageStatusSent    MessageStatus = "sent"
-	MessageStatusFailed  MessageStatus = "failed"
+	MessageStatusPending    MessageStatus = "pending"
+	MessageStatusSent       MessageStatus = "sent"
+	MessageStatusFailed     MessageStatus = "failed"
+	MessageStatusDeadLetter MessageStatus = "dead_letter"
+
+	// MessageStatusSending marks a message claimed by an external worker that
+	// has not yet reported the delivery outcome
+	MessageStatusSending MessageStatus = "sending"
+
+	// MessageStatusCancelled marks a message withdrawn before it was delivered
+	MessageStatusCancelled MessageStatus = "cancelled"
+
+	// MessageStatusExpired marks a message dropped undelivered because its
+	// expiry passed first
+	MessageStatusExpired MessageStatus = "expired"
+)
+
+// MessageStatuses lists every message status
+var MessageStatuses = []MessageStatus{
+	MessageStatusPending,
+	MessageStatusSent,
+	MessageStatusFailed,
+	MessageStatusDeadLetter,
+	MessageStatusSending,
+	MessageStatusCancelled,
+	MessageStatusExpired,
+}
+
+// Channel is how a message reaches its recipient
+type Channel string
+
+const (
+	ChannelWebhook Channel = "webhook" // HTTP POST to the message's webhook URL
+	ChannelEmail   Channel = "email"
+	ChannelSMS     Channel = "sms"
+)
+
+// Channels lists every message channel
+var Channels = []Channel{ChannelWebhook, ChannelEmail, ChannelSMS}
+
+// IsValid checks if the channel is one of the known channels
+func (c Channel) IsValid() bool {
+	switch c {
+	case ChannelWebhook, ChannelEmail, ChannelSMS:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortField is a message timestamp a list of messages may be ordered by
+type SortField string
+
+const (
+	SortBySentAt    SortField = "sent_at"
+	SortByCreatedAt SortField = "created_at"
+)
+
+// IsValid checks if the sort field is one of the allowed fields
+func (f SortField) IsValid() bool {
+	switch f {
+	case SortBySentAt, SortByCreatedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortOrder is the direction of a sort
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
 )
 
+// IsValid checks if the sort order is asc or desc
+func (o SortOrder) IsValid() bool {
+	return o == SortAsc || o == SortDesc
+}
+
+// MessageSort orders a list of messages
+type MessageSort struct {
+	Field SortField
+	Order SortOrder
+}
+
+// DefaultSentMessagesSort lists sent messages most recently sent first
+var DefaultSentMessagesSort = MessageSort{Field: SortBySentAt, Order: SortDesc}
+
+// IsValid checks if both the field and the order are allowed
+func (s MessageSort) IsValid() bool {
+	return s.Field.IsValid() && s.Order.IsValid()
+}
+
+// MaxMessagePriority is the highest priority a message may be created with.
+// Retries of higher-priority messages are attempted first; 0 is the default.
+const MaxMessagePriority = 10
+
 // Message represents a message in the system
 type Message struct {
 	ID           int64         `json:"id" db:"id"`
@@ -33,12 +192,32 @@ type Message struct {
 	SentAt       *time.Time    `json:"sent_at,omitempty" db:"sent_at"`
 	FailedAt     *time.Time    `json:"failed_at,omitempty" db:"failed_at"`
 	ErrorMessage *string       `json:"error_message,omitempty" db:"error_message"`
+	NextRetryAt  *time.Time    `json:"next_retry_at,omitempty" db:"next_retry_at"`
+	Priority     int           `json:"priority" db:"priority"`
+	Channel      Channel       `json:"channel" db:"channel"`
+
+	// PayloadTemplate renders this message's webhook body in place of the
+	// default payload; empty sends the default
+	PayloadTemplate string `json:"payload_template,omitempty" db:"payload_template"`
+
+	// ExpiresAt is when an undelivered message stops being worth sending and
+	// is marked expired instead; nil never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+
+	// ProviderMessageID is the receiver's own reference for a delivered message, used for reconciliation
+	ProviderMessageID *string `json:"provider_message_id,omitempty" db:"provider_message_id"`
+
+	// ResentFrom is the ID of the sent message this one was cloned from for redelivery
+	ResentFrom *int64 `json:"resent_from,omitempty" db:"resent_from"`
+
+	// IdempotencyKey is the client-supplied key the message was created with
+	IdempotencyKey *string `json:"idempotency_key,omitempty" db:"idempotency_key"`
 }
 
 // IsValid checks if the message status is valid
 func (s MessageStatus) IsValid() bool {
 	switch s {
-	case MessageStatusPending, MessageStatusSent, MessageStatusFailed:
+	case MessageStatusPending, MessageStatusSent, MessageStatusFailed, MessageStatusDeadLetter, MessageStatusSending, MessageStatusCancelled, MessageStatusExpired:
 		return true
 	default:
 		return false
@@ -50,6 +229,16 @@ func (m *Message) CanRetry() bool {
 	return m.Status == MessageStatusFailed && m.RetryCount < m.MaxRetries
 }
 
+// IsExpired checks if the message has an expiry that passed by now
+func (m *Message) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
+// IsDeadLetter checks if the message has exhausted its retries and was dead-lettered
+func (m *Message) IsDeadLetter() bool {
+	return m.Status == MessageStatusDeadLetter
+}
+
 // MarkAsSent marks the message as sent
 func (m *Message) MarkAsSent() {
 	m.Status = MessageStatusSent
@@ -68,10 +257,61 @@ func (m *Message) MarkAsFailed(errorMsg string) {
 	m.RetryCount++
 }
 
+// MarkAsDeadLetter marks the message as dead-lettered after exhausting its retries
+func (m *Message) MarkAsDeadLetter() {
+	m.Status = MessageStatusDeadLetter
+	m.UpdatedAt = time.Now()
+}
+
 // CreateMessageRequest represents the request to create a new message
 type CreateMessageRequest struct {
 	Recipient  string `json:"recipient" validate:"required,email"`
 	Content    string `json:"content" validate:"required"`
-	WebhookURL string `json:"webhook_url" validate:"required,url"`
+	WebhookURL string `json:"webhook_url" validate:"omitempty,url"` // Required for the webhook channel
 	MaxRetries int    `json:"max_retries,omitempty"`
+	Priority   int    `json:"priority,omitempty"`
+
+	// Channel is how the message is delivered; empty means ChannelWebhook
+	Channel Channel `json:"channel,omitempty"`
+
+	// PayloadTemplate is a text/template for the webhook body; empty sends
+	// the default payload
+	PayloadTemplate string `json:"payload_template,omitempty"`
+
+	// TTL and ExpiresAt give an optional expiry, relative to creation or
+	// absolute; at most one may be set. The service resolves TTL into
+	// ExpiresAt before the message is stored.
+	TTL       time.Duration `json:"-"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+
+	// ResentFrom links a message created by a resend to its original; it is
+	// set by the service, never by clients
+	ResentFrom *int64 `json:"-"`
+
+	// IdempotencyKey deduplicates retried creates; it comes from the
+	// Idempotency-Key header rather than the body
+	IdempotencyKey *string `json:"-"`
+}
+
+// UpdateMessageRequest is a partial edit of a pending message; nil fields are
+// left unchanged
+type UpdateMessageRequest struct {
+	Recipient  *string `json:"recipient,omitempty"`
+	Content    *string `json:"content,omitempty"`
+	WebhookURL *string `json:"webhook_url,omitempty"`
 }
+
+// IsEmpty reports whether the request changes nothing
+func (r *UpdateMessageRequest) IsEmpty() bool {
+	return r.Recipient == nil && r.Content == nil && r.WebhookURL == nil
+}
+
+// CreateResult is the outcome of one request of a bulk create: the created
+// message, or the *ValidationError or *RecipientLimitError that rejected it
+type CreateResult struct {
+	Message *Message
+	Err     error
+}
+
+// MaxIdempotencyKeyLength is the longest Idempotency-Key accepted
+const MaxIdempotencyKeyLength = 255
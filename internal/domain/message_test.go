@@ -15,6 +15,7 @@ func TestMessageStatus_IsValid(t *testing.T) {
 		{MessageStatusPending, true},
 		{MessageStatusSent, true},
 		{MessageStatusFailed, true},
+		{MessageStatusDeadLetter, true},
 		{MessageStatus("invalid"), false},
 		{MessageStatus(""), false},
 	}
@@ -115,3 +116,81 @@ func TestMessage_MarkAsFailed(t *testing.T) {
 	assert.True(t, message.UpdatedAt.After(before) || message.UpdatedAt.Equal(before))
 	assert.True(t, message.UpdatedAt.Before(after) || message.UpdatedAt.Equal(after))
 }
+
+func TestMessage_IsDeadLetter(t *testing.T) {
+	assert.True(t, (&Message{Status: MessageStatusDeadLetter}).IsDeadLetter())
+	assert.False(t, (&Message{Status: MessageStatusFailed}).IsDeadLetter())
+	assert.False(t, (&Message{Status: MessageStatusSent}).IsDeadLetter())
+}
+
+func TestMessage_MarkAsDeadLetter(t *testing.T) {
+	message := &Message{
+		Status:     MessageStatusFailed,
+		RetryCount: 3,
+		MaxRetries: 3,
+	}
+
+	before := time.Now()
+	message.MarkAsDeadLetter()
+
+	assert.Equal(t, MessageStatusDeadLetter, message.Status)
+	assert.True(t, message.IsDeadLetter())
+	assert.False(t, message.CanRetry())
+	assert.Equal(t, 3, message.RetryCount)
+	assert.True(t, message.UpdatedAt.After(before) || message.UpdatedAt.Equal(before))
+}
+
+func TestCalculateSuccessRate(t *testing.T) {
+	assert.Equal(t, 0.0, CalculateSuccessRate(0, 0))
+	assert.Equal(t, 1.0, CalculateSuccessRate(5, 0))
+	assert.Equal(t, 0.0, CalculateSuccessRate(0, 5))
+	assert.Equal(t, 0.75, CalculateSuccessRate(3, 1))
+}
+
+func TestValidationError(t *testing.T) {
+	err := NewValidationError("recipient is required")
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "recipient is required", validationErr.Message)
+	assert.Equal(t, "recipient is required", err.Error())
+}
+
+func TestFieldValidationError(t *testing.T) {
+	err := NewFieldValidationError(
+		FieldError{Field: "recipient", Message: "recipient is required"},
+		FieldError{Field: "content", Message: "content is required"},
+	)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "recipient is required; content is required", err.Error())
+	assert.Equal(t, []FieldError{
+		{Field: "recipient", Message: "recipient is required"},
+		{Field: "content", Message: "content is required"},
+	}, validationErr.Fields)
+}
+
+func TestSanitizeContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		safe     bool
+		expected string
+	}{
+		{name: "plain text", content: "Hello, World!", safe: true, expected: "Hello, World!"},
+		{name: "newlines and tabs are kept", content: "line 1\n\tline 2\r\n", safe: true, expected: "line 1\n\tline 2\r\n"},
+		{name: "unicode text", content: "Merhaba dünya 👋", safe: true, expected: "Merhaba dünya 👋"},
+		{name: "control characters are stripped", content: "bell\x07 null\x00 esc\x1b[0m del\x7f", safe: false, expected: "bell null esc[0m del"},
+		{name: "C1 control characters are stripped", content: "next\u0085line", safe: false, expected: "nextline"},
+		{name: "invalid UTF-8 is replaced", content: "bad \xff\xfe bytes", safe: false, expected: "bad � bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.safe, IsSafeContent(tt.content))
+			assert.Equal(t, tt.expected, SanitizeContent(tt.content))
+			assert.True(t, IsSafeContent(SanitizeContent(tt.content)))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+// DeliveryOutcome counts the terminal delivery outcomes recorded for one webhook URL
+type DeliveryOutcome struct {
+	WebhookURL   string
+	Sent         int
+	DeadLettered int
+}
+
+// SuccessRate reports the ratio of sent to sent plus dead-lettered messages over a window
+type SuccessRate struct {
+	Window       string            `json:"window" example:"1h0m0s"`
+	Sent         int               `json:"sent" example:"98"`
+	DeadLettered int               `json:"dead_lettered" example:"2"`
+	SuccessRate  float64           `json:"success_rate" example:"0.98"`
+	Hosts        []HostSuccessRate `json:"hosts,omitempty"`
+}
+
+// HostSuccessRate is the success rate for messages delivered to a single webhook host
+type HostSuccessRate struct {
+	Host         string  `json:"host" example:"webhook.site"`
+	Sent         int     `json:"sent" example:"49"`
+	DeadLettered int     `json:"dead_lettered" example:"1"`
+	SuccessRate  float64 `json:"success_rate" example:"0.98"`
+}
+
+// CalculateSuccessRate returns sent/(sent+deadLettered), or 0 when nothing reached a terminal state
+func CalculateSuccessRate(sent, deadLettered int) float64 {
+	total := sent + deadLettered
+	if total == 0 {
+		return 0
+	}
+	return float64(sent) / float64(total)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Kinds of key a suppression can hold
+const (
+	SuppressionRecipient = "recipient"
+	SuppressionHost      = "host"
+)
+
+// Suppression holds deliveries to a recipient or a webhook host. Messages for
+// a suppressed key stay in their current state until the suppression is
+// removed or lapses.
+type Suppression struct {
+	Key       string    `json:"key" example:"user@example.com"`
+	Kind      string    `json:"kind" example:"recipient" enums:"recipient,host"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Until is when the suppression lapses on its own; nil means until removed
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// IsActive reports whether the suppression is still in effect at now
+func (s *Suppression) IsActive(now time.Time) bool {
+	return s.Until == nil || now.Before(*s.Until)
+}
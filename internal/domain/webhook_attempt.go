@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// WebhookAttempt records one HTTP request made to deliver a message, whatever
+// its outcome
+type WebhookAttempt struct {
+	ID        int64
+	MessageID int64
+
+	// StatusCode is the receiver's HTTP status, nil when no response arrived
+	StatusCode *int
+
+	DurationMs int64
+
+	// Error describes why the attempt failed, nil when it succeeded
+	Error *string
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// PausedHost is a webhook host whose deliveries are on hold. Messages for a
+// paused host stay in their current state until the host is resumed.
+type PausedHost struct {
+	Host     string    `json:"host" example:"api.partner.com"`
+	PausedAt time.Time `json:"paused_at"`
+
+	// Until is when the pause lapses on its own; nil means until resumed
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// IsActive reports whether the pause is still in effect at now
+func (p *PausedHost) IsActive(now time.Time) bool {
+	return p.Until == nil || now.Before(*p.Until)
+}
@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/pkg/metrics"
+)
+
+// EventType identifies a message lifecycle transition
+type EventType string
+
+const (
+	EventMessageCreated      EventType = "message.created"
+	EventMessageSent         EventType = "message.sent"
+	EventMessageFailed       EventType = "message.failed"
+	EventMessageDeadLettered EventType = "message.dead_lettered"
+	EventMessageRequeued     EventType = "message.requeued"
+	EventMessageCancelled    EventType = "message.cancelled"
+	EventMessageExpired      EventType = "message.expired"
+)
+
+// eventStatuses maps each event type to the status the message holds after it
+var eventStatuses = map[EventType]domain.MessageStatus{
+	EventMessageCreated:      domain.MessageStatusPending,
+	EventMessageSent:         domain.MessageStatusSent,
+	EventMessageFailed:       domain.MessageStatusFailed,
+	EventMessageDeadLettered: domain.MessageStatusDeadLetter,
+	EventMessageRequeued:     domain.MessageStatusPending,
+	EventMessageCancelled:    domain.MessageStatusCancelled,
+	EventMessageExpired:      domain.MessageStatusExpired,
+}
+
+// Event describes a message status change delivered to every registered sink
+type Event struct {
+	Type       EventType            `json:"type"`
+	MessageID  int64                `json:"message_id"`
+	Status     domain.MessageStatus `json:"status"`
+	WebhookURL string               `json:"webhook_url"`
+	RetryCount int                  `json:"retry_count"`
+	Error      string               `json:"error,omitempty"`
+	OccurredAt time.Time            `json:"occurred_at"`
+}
+
+// NewEvent builds an event of the given type for message
+func NewEvent(eventType EventType, message *domain.Message) Event {
+	return Event{
+		Type:       eventType,
+		MessageID:  message.ID,
+		Status:     eventStatuses[eventType],
+		WebhookURL: message.WebhookURL,
+		RetryCount: message.RetryCount,
+		OccurredAt: time.Now(),
+	}
+}
+
+// Sink receives lifecycle events published on the bus
+type Sink interface {
+	// Name identifies the sink in logs and metrics
+	Name() string
+
+	// Handle processes a single event
+	Handle(ctx context.Context, event Event) error
+}
+
+// defaultSinkBufferSize is how many events may queue for a sink before new ones are dropped
+const defaultSinkBufferSize = 256
+
+// sinkWorker owns the queue feeding a single sink
+type sinkWorker struct {
+	sink   Sink
+	events chan Event
+}
+
+// Bus fans lifecycle events out to sinks. Each sink has its own queue and
+// goroutine, so a slow or failing sink never blocks delivery or other sinks.
+type Bus struct {
+	logger  *slog.Logger
+	metrics *metrics.Metrics // Optional metrics
+
+	mu      sync.RWMutex
+	closed  bool
+	workers []*sinkWorker
+	wg      sync.WaitGroup
+}
+
+// NewBus creates a bus and starts a worker for each sink
+func NewBus(logger *slog.Logger, m *metrics.Metrics, sinks ...Sink) *Bus {
+	b := &Bus{
+		logger:  logger,
+		metrics: m,
+	}
+
+	for _, sink := range sinks {
+		worker := &sinkWorker{
+			sink:   sink,
+			events: make(chan Event, defaultSinkBufferSize),
+		}
+		b.workers = append(b.workers, worker)
+
+		b.wg.Add(1)
+		go b.run(worker)
+	}
+
+	return b
+}
+
+// Publish queues event for every sink without blocking. Events for a sink whose
+// queue is full are dropped and counted.
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, worker := range b.workers {
+		select {
+		case worker.events <- event:
+		default:
+			b.logger.Warn("Event sink queue full, dropping event",
+				"sink", worker.sink.Name(),
+				"event_type", event.Type,
+				"message_id", event.MessageID,
+			)
+			b.record(worker.sink.Name(), "dropped")
+		}
+	}
+}
+
+// Close stops accepting events, waits for sinks to drain their queues and then
+// closes every sink that implements io.Closer
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, worker := range b.workers {
+		close(worker.events)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+
+	for _, worker := range b.workers {
+		closer, ok := worker.sink.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			b.logger.Error("Failed to close event sink",
+				"sink", worker.sink.Name(),
+				"error", err,
+			)
+		}
+	}
+}
+
+// run delivers queued events to a single sink until its queue is closed
+func (b *Bus) run(worker *sinkWorker) {
+	defer b.wg.Done()
+
+	for event := range worker.events {
+		if err := b.deliver(worker.sink, event); err != nil {
+			b.logger.Error("Event sink failed to handle event",
+				"sink", worker.sink.Name(),
+				"event_type", event.Type,
+				"message_id", event.MessageID,
+				"error", err,
+			)
+			b.record(worker.sink.Name(), "error")
+			continue
+		}
+		b.record(worker.sink.Name(), "success")
+	}
+}
+
+// deliver calls the sink, converting a panic into an error so one faulty sink
+// cannot take down the bus
+func (b *Bus) deliver(sink Sink, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("sink panicked: %v", r)
+		}
+	}()
+
+	return sink.Handle(context.Background(), event)
+}
+
+// record counts a sink delivery outcome when metrics are configured
+func (b *Bus) record(sink, result string) {
+	if b.metrics != nil {
+		b.metrics.RecordEventSinkDelivery(sink, result)
+	}
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/pkg/metrics"
+)
+
+// recordingSink stores every event it receives and optionally fails or panics
+type recordingSink struct {
+	name    string
+	err     error
+	panics  bool
+	mu      sync.Mutex
+	handled []Event
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Handle(ctx context.Context, event Event) error {
+	if s.panics {
+		panic("boom")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handled = append(s.handled, event)
+	return s.err
+}
+
+func (s *recordingSink) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.handled...)
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestNewEvent(t *testing.T) {
+	message := &domain.Message{
+		ID:         7,
+		WebhookURL: "https://example.com/hook",
+		RetryCount: 2,
+	}
+
+	event := NewEvent(EventMessageDeadLettered, message)
+
+	assert.Equal(t, EventMessageDeadLettered, event.Type)
+	assert.Equal(t, int64(7), event.MessageID)
+	assert.Equal(t, domain.MessageStatusDeadLetter, event.Status)
+	assert.Equal(t, "https://example.com/hook", event.WebhookURL)
+	assert.Equal(t, 2, event.RetryCount)
+	assert.False(t, event.OccurredAt.IsZero())
+}
+
+func TestBus_PublishFansOutToAllSinks(t *testing.T) {
+	first := &recordingSink{name: "first"}
+	second := &recordingSink{name: "second"}
+	bus := NewBus(testLogger(), nil, first, second)
+
+	bus.Publish(NewEvent(EventMessageCreated, &domain.Message{ID: 1}))
+	bus.Publish(NewEvent(EventMessageSent, &domain.Message{ID: 1}))
+	bus.Close()
+
+	for _, sink := range []*recordingSink{first, second} {
+		received := sink.events()
+		require.Len(t, received, 2, sink.name)
+		assert.Equal(t, EventMessageCreated, received[0].Type)
+		assert.Equal(t, EventMessageSent, received[1].Type)
+	}
+}
+
+func TestBus_FailingSinkDoesNotAffectOthers(t *testing.T) {
+	m := metrics.NewWithRegistry(prometheus.NewRegistry())
+	failing := &recordingSink{name: "failing", err: errors.New("unavailable")}
+	panicking := &recordingSink{name: "panicking", panics: true}
+	healthy := &recordingSink{name: "healthy"}
+	bus := NewBus(testLogger(), m, failing, panicking, healthy)
+
+	bus.Publish(NewEvent(EventMessageFailed, &domain.Message{ID: 3}))
+	bus.Close()
+
+	assert.Len(t, healthy.events(), 1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.EventSinkDeliveries.WithLabelValues("healthy", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.EventSinkDeliveries.WithLabelValues("failing", "error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.EventSinkDeliveries.WithLabelValues("panicking", "error")))
+}
+
+func TestBus_PublishAfterCloseIsIgnored(t *testing.T) {
+	sink := &recordingSink{name: "sink"}
+	bus := NewBus(testLogger(), nil, sink)
+
+	bus.Close()
+	bus.Close()
+	bus.Publish(NewEvent(EventMessageCreated, &domain.Message{ID: 1}))
+
+	assert.Empty(t, sink.events())
+}
+
+func TestBus_NoSinks(t *testing.T) {
+	bus := NewBus(testLogger(), nil)
+
+	assert.NotPanics(t, func() {
+		bus.Publish(NewEvent(EventMessageCreated, &domain.Message{ID: 1}))
+		bus.Close()
+	})
+}
@@ -0,0 +1,216 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/insider/insider-messaging/pkg/config"
+	"github.com/insider/insider-messaging/pkg/metrics"
+)
+
+// Sink names accepted in EVENT_SINKS
+const (
+	SinkAudit    = "audit"
+	SinkMetrics  = "metrics"
+	SinkCallback = "callback"
+	SinkKafka    = "kafka"
+)
+
+// SinksFromConfig builds the sinks named in cfg.EventSinks
+func SinksFromConfig(cfg *config.Config, logger *slog.Logger, m *metrics.Metrics) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.EventSinks {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case SinkAudit:
+			sinks = append(sinks, NewAuditSink(logger))
+		case SinkMetrics:
+			if m == nil {
+				return nil, fmt.Errorf("event sink %q requires metrics", SinkMetrics)
+			}
+			sinks = append(sinks, NewMetricsSink(m))
+		case SinkCallback:
+			if cfg.StatusCallbackURL == "" {
+				return nil, fmt.Errorf("event sink %q requires STATUS_CALLBACK_URL", SinkCallback)
+			}
+			sinks = append(sinks, NewCallbackSink(cfg.StatusCallbackURL))
+		case SinkKafka:
+			if len(cfg.KafkaBrokers) == 0 {
+				return nil, fmt.Errorf("event sink %q requires KAFKA_BROKERS", SinkKafka)
+			}
+			sinks = append(sinks, NewKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic))
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown event sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// AuditSink writes every event to the structured log
+type AuditSink struct {
+	logger *slog.Logger
+}
+
+// NewAuditSink creates an audit sink
+func NewAuditSink(logger *slog.Logger) *AuditSink {
+	return &AuditSink{logger: logger}
+}
+
+// Name returns the sink name
+func (s *AuditSink) Name() string {
+	return SinkAudit
+}
+
+// Handle logs the event
+func (s *AuditSink) Handle(ctx context.Context, event Event) error {
+	s.logger.Info("Message lifecycle event",
+		"event_type", event.Type,
+		"message_id", event.MessageID,
+		"status", event.Status,
+		"retry_count", event.RetryCount,
+		"error", event.Error,
+		"occurred_at", event.OccurredAt,
+	)
+	return nil
+}
+
+// MetricsSink counts status transitions on the messages_total metric. The
+// message service counts creates, sends and failures itself, so those events
+// are skipped rather than counted twice.
+type MetricsSink struct {
+	metrics *metrics.Metrics
+}
+
+// NewMetricsSink creates a metrics sink
+func NewMetricsSink(m *metrics.Metrics) *MetricsSink {
+	return &MetricsSink{metrics: m}
+}
+
+// Name returns the sink name
+func (s *MetricsSink) Name() string {
+	return SinkMetrics
+}
+
+// Handle records the status the message moved to
+func (s *MetricsSink) Handle(ctx context.Context, event Event) error {
+	switch event.Type {
+	case EventMessageCreated, EventMessageSent, EventMessageFailed:
+		return nil
+	}
+	s.metrics.RecordMessageStatus(string(event.Status))
+	return nil
+}
+
+// CallbackSink POSTs each event as JSON to a status-callback URL
+type CallbackSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewCallbackSink creates a callback sink posting to url
+func NewCallbackSink(url string) *CallbackSink {
+	return &CallbackSink{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the sink name
+func (s *CallbackSink) Name() string {
+	return SinkCallback
+}
+
+// Handle posts the event and treats any non-2xx response as a failure
+func (s *CallbackSink) Handle(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", config.DefaultWebhookUserAgent())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// kafkaWriter is the part of kafka.Writer the kafka sink uses
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaSink produces each event as JSON to a Kafka topic, keyed by message ID
+// so a message's events stay in order on one partition
+type KafkaSink struct {
+	writer kafkaWriter
+}
+
+// NewKafkaSink creates a kafka sink producing to topic on brokers
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireOne,
+			WriteTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Name returns the sink name
+func (s *KafkaSink) Name() string {
+	return SinkKafka
+}
+
+// Handle writes the event to the topic, with its type in the event_type header
+func (s *KafkaSink) Handle(ctx context.Context, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	err = s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.FormatInt(event.MessageID, 10)),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write event to kafka: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes pending writes and closes the producer
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
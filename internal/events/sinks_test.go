@@ -0,0 +1,199 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/pkg/config"
+	"github.com/insider/insider-messaging/pkg/metrics"
+)
+
+func TestSinksFromConfig(t *testing.T) {
+	m := metrics.NewWithRegistry(prometheus.NewRegistry())
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		metrics   *metrics.Metrics
+		wantSinks []string
+		wantErr   string
+	}{
+		{
+			name:      "no sinks",
+			cfg:       &config.Config{},
+			metrics:   m,
+			wantSinks: nil,
+		},
+		{
+			name: "all sinks",
+			cfg: &config.Config{
+				EventSinks:        []string{"audit", "Metrics", "callback", "kafka"},
+				StatusCallbackURL: "https://example.com/status",
+				KafkaBrokers:      []string{"localhost:9092"},
+				KafkaTopic:        "message-events",
+			},
+			metrics:   m,
+			wantSinks: []string{SinkAudit, SinkMetrics, SinkCallback, SinkKafka},
+		},
+		{
+			name:    "unknown sink",
+			cfg:     &config.Config{EventSinks: []string{"sqs"}},
+			metrics: m,
+			wantErr: `unknown event sink "sqs"`,
+		},
+		{
+			name:    "metrics sink without metrics",
+			cfg:     &config.Config{EventSinks: []string{"metrics"}},
+			wantErr: `event sink "metrics" requires metrics`,
+		},
+		{
+			name:    "callback sink without url",
+			cfg:     &config.Config{EventSinks: []string{"callback"}},
+			metrics: m,
+			wantErr: `event sink "callback" requires STATUS_CALLBACK_URL`,
+		},
+		{
+			name:    "kafka sink without brokers",
+			cfg:     &config.Config{EventSinks: []string{"kafka"}},
+			metrics: m,
+			wantErr: `event sink "kafka" requires KAFKA_BROKERS`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sinks, err := SinksFromConfig(tt.cfg, testLogger(), tt.metrics)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			var names []string
+			for _, sink := range sinks {
+				names = append(names, sink.Name())
+			}
+			assert.Equal(t, tt.wantSinks, names)
+		})
+	}
+}
+
+func TestMetricsSink_Handle(t *testing.T) {
+	m := metrics.NewWithRegistry(prometheus.NewRegistry())
+	sink := NewMetricsSink(m)
+
+	err := sink.Handle(context.Background(), NewEvent(EventMessageDeadLettered, &domain.Message{ID: 1}))
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesTotal.WithLabelValues("dead_letter")))
+
+	// The message service counts sends itself
+	err = sink.Handle(context.Background(), NewEvent(EventMessageSent, &domain.Message{ID: 1}))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.MessagesTotal.WithLabelValues("sent")))
+}
+
+func TestCallbackSink_Handle(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewCallbackSink(server.URL)
+	event := NewEvent(EventMessageFailed, &domain.Message{ID: 9, WebhookURL: "https://example.com/hook"})
+	event.Error = "timeout"
+
+	err := sink.Handle(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, EventMessageFailed, received.Type)
+	assert.Equal(t, int64(9), received.MessageID)
+	assert.Equal(t, domain.MessageStatusFailed, received.Status)
+	assert.Equal(t, "timeout", received.Error)
+}
+
+func TestCallbackSink_HandleErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewCallbackSink(server.URL)
+
+	err := sink.Handle(context.Background(), NewEvent(EventMessageSent, &domain.Message{ID: 1}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "callback failed with status 500")
+}
+
+// fakeKafkaWriter records the messages written to it
+type fakeKafkaWriter struct {
+	err      error
+	messages []kafka.Message
+	closed   bool
+}
+
+func (w *fakeKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return w.err
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestKafkaSink_Handle(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	sink := &KafkaSink{writer: writer}
+
+	err := sink.Handle(context.Background(), NewEvent(EventMessageSent, &domain.Message{ID: 42}))
+
+	require.NoError(t, err)
+	require.Len(t, writer.messages, 1)
+	message := writer.messages[0]
+	assert.Equal(t, "42", string(message.Key))
+	assert.Equal(t, []kafka.Header{{Key: "event_type", Value: []byte("message.sent")}}, message.Headers)
+
+	var event Event
+	require.NoError(t, json.Unmarshal(message.Value, &event))
+	assert.Equal(t, EventMessageSent, event.Type)
+	assert.Equal(t, int64(42), event.MessageID)
+	assert.Equal(t, domain.MessageStatusSent, event.Status)
+}
+
+func TestKafkaSink_HandleWriteError(t *testing.T) {
+	sink := &KafkaSink{writer: &fakeKafkaWriter{err: errors.New("leader not available")}}
+
+	err := sink.Handle(context.Background(), NewEvent(EventMessageCreated, &domain.Message{ID: 1}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write event to kafka: leader not available")
+}
+
+func TestBus_CloseClosesKafkaSink(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	bus := NewBus(testLogger(), nil, &KafkaSink{writer: writer})
+
+	bus.Publish(NewEvent(EventMessageCreated, &domain.Message{ID: 1}))
+	bus.Close()
+
+	assert.Len(t, writer.messages, 1)
+	assert.True(t, writer.closed)
+}
@@ -0,0 +1,40 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/grpcapi/messagingv1"
+)
+
+// toProtoMessage maps a domain message to its gRPC representation
+func toProtoMessage(message *domain.Message) *messagingv1.Message {
+	return &messagingv1.Message{
+		Id:                message.ID,
+		Recipient:         message.Recipient,
+		Content:           message.Content,
+		WebhookUrl:        message.WebhookURL,
+		Status:            string(message.Status),
+		RetryCount:        int32(message.RetryCount),
+		MaxRetries:        int32(message.MaxRetries),
+		Priority:          int32(message.Priority),
+		Channel:           string(message.Channel),
+		CreatedAt:         timestamppb.New(message.CreatedAt),
+		UpdatedAt:         timestamppb.New(message.UpdatedAt),
+		SentAt:            toProtoTimestamp(message.SentAt),
+		FailedAt:          toProtoTimestamp(message.FailedAt),
+		NextRetryAt:       toProtoTimestamp(message.NextRetryAt),
+		ErrorMessage:      message.ErrorMessage,
+		ProviderMessageId: message.ProviderMessageID,
+	}
+}
+
+// toProtoTimestamp maps an optional time, leaving it unset when t is nil
+func toProtoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v5.27.3
+// source: messaging/v1/messaging.proto
+
+package messagingv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MessagingService_CreateMessage_FullMethodName    = "/messaging.v1.MessagingService/CreateMessage"
+	MessagingService_GetMessage_FullMethodName       = "/messaging.v1.MessagingService/GetMessage"
+	MessagingService_ListSentMessages_FullMethodName = "/messaging.v1.MessagingService/ListSentMessages"
+)
+
+// MessagingServiceClient is the client API for MessagingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MessagingService exposes message creation and lookup to internal gRPC clients.
+// It is backed by the same service as the REST API.
+type MessagingServiceClient interface {
+	// CreateMessage queues a message for delivery
+	CreateMessage(ctx context.Context, in *CreateMessageRequest, opts ...grpc.CallOption) (*Message, error)
+	// GetMessage returns a message by ID
+	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error)
+	// ListSentMessages returns a page of sent messages, newest first
+	ListSentMessages(ctx context.Context, in *ListSentMessagesRequest, opts ...grpc.CallOption) (*ListSentMessagesResponse, error)
+}
+
+type messagingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMessagingServiceClient(cc grpc.ClientConnInterface) MessagingServiceClient {
+	return &messagingServiceClient{cc}
+}
+
+func (c *messagingServiceClient) CreateMessage(ctx context.Context, in *CreateMessageRequest, opts ...grpc.CallOption) (*Message, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Message)
+	err := c.cc.Invoke(ctx, MessagingService_CreateMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingServiceClient) GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*Message, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Message)
+	err := c.cc.Invoke(ctx, MessagingService_GetMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingServiceClient) ListSentMessages(ctx context.Context, in *ListSentMessagesRequest, opts ...grpc.CallOption) (*ListSentMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSentMessagesResponse)
+	err := c.cc.Invoke(ctx, MessagingService_ListSentMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MessagingServiceServer is the server API for MessagingService service.
+// All implementations must embed UnimplementedMessagingServiceServer
+// for forward compatibility.
+//
+// MessagingService exposes message creation and lookup to internal gRPC clients.
+// It is backed by the same service as the REST API.
+type MessagingServiceServer interface {
+	// CreateMessage queues a message for delivery
+	CreateMessage(context.Context, *CreateMessageRequest) (*Message, error)
+	// GetMessage returns a message by ID
+	GetMessage(context.Context, *GetMessageRequest) (*Message, error)
+	// ListSentMessages returns a page of sent messages, newest first
+	ListSentMessages(context.Context, *ListSentMessagesRequest) (*ListSentMessagesResponse, error)
+	mustEmbedUnimplementedMessagingServiceServer()
+}
+
+// UnimplementedMessagingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMessagingServiceServer struct{}
+
+func (UnimplementedMessagingServiceServer) CreateMessage(context.Context, *CreateMessageRequest) (*Message, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateMessage not implemented")
+}
+func (UnimplementedMessagingServiceServer) GetMessage(context.Context, *GetMessageRequest) (*Message, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMessage not implemented")
+}
+func (UnimplementedMessagingServiceServer) ListSentMessages(context.Context, *ListSentMessagesRequest) (*ListSentMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSentMessages not implemented")
+}
+func (UnimplementedMessagingServiceServer) mustEmbedUnimplementedMessagingServiceServer() {}
+func (UnimplementedMessagingServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeMessagingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MessagingServiceServer will
+// result in compilation errors.
+type UnsafeMessagingServiceServer interface {
+	mustEmbedUnimplementedMessagingServiceServer()
+}
+
+func RegisterMessagingServiceServer(s grpc.ServiceRegistrar, srv MessagingServiceServer) {
+	// If the following call panics, it indicates UnimplementedMessagingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MessagingService_ServiceDesc, srv)
+}
+
+func _MessagingService_CreateMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServiceServer).CreateMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagingService_CreateMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServiceServer).CreateMessage(ctx, req.(*CreateMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagingService_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServiceServer).GetMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagingService_GetMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServiceServer).GetMessage(ctx, req.(*GetMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessagingService_ListSentMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSentMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServiceServer).ListSentMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessagingService_ListSentMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServiceServer).ListSentMessages(ctx, req.(*ListSentMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MessagingService_ServiceDesc is the grpc.ServiceDesc for MessagingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MessagingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "messaging.v1.MessagingService",
+	HandlerType: (*MessagingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateMessage",
+			Handler:    _MessagingService_CreateMessage_Handler,
+		},
+		{
+			MethodName: "GetMessage",
+			Handler:    _MessagingService_GetMessage_Handler,
+		},
+		{
+			MethodName: "ListSentMessages",
+			Handler:    _MessagingService_ListSentMessages_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "messaging/v1/messaging.proto",
+}
@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/grpcapi/messagingv1"
+	"github.com/insider/insider-messaging/internal/service"
+	"github.com/insider/insider-messaging/pkg/logger"
+)
+
+// Metadata keys read from incoming calls, matching the REST API's headers
+const (
+	apiKeyMetadata    = "x-api-key"
+	requestIDMetadata = "x-request-id"
+)
+
+// Pagination defaults and bounds for ListSentMessages, matching the REST API
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// defaultMaxRetries is the retry budget of messages created over gRPC
+const defaultMaxRetries = 3
+
+// Server implements messagingv1.MessagingServiceServer on top of the message
+// service shared with the REST API
+type Server struct {
+	messagingv1.UnimplementedMessagingServiceServer
+
+	logger         *logger.Logger
+	messageService service.MessageService
+	apiKey         string
+}
+
+// ServerOption configures optional server behavior
+type ServerOption func(*Server)
+
+// WithAPIKey requires every call to carry key in the x-api-key metadata. An
+// empty key leaves the API unauthenticated.
+func WithAPIKey(key string) ServerOption {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// NewServer creates the gRPC messaging service
+func NewServer(log *logger.Logger, messageService service.MessageService, opts ...ServerOption) *Server {
+	s := &Server{
+		logger:         log.WithComponent("grpc"),
+		messageService: messageService,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// NewGRPCServer returns a grpc.Server with s registered and its logging and
+// authentication interceptors installed
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(s.requestIDInterceptor, s.authInterceptor))
+	grpcServer := grpc.NewServer(opts...)
+	messagingv1.RegisterMessagingServiceServer(grpcServer, s)
+	return grpcServer
+}
+
+// CreateMessage queues a message for delivery
+func (s *Server) CreateMessage(ctx context.Context, req *messagingv1.CreateMessageRequest) (*messagingv1.Message, error) {
+	message, err := s.messageService.CreateMessage(ctx, &domain.CreateMessageRequest{
+		Recipient:  req.GetRecipient(),
+		Content:    req.GetContent(),
+		WebhookURL: req.GetWebhookUrl(),
+		MaxRetries: defaultMaxRetries,
+		Priority:   int(req.GetPriority()),
+		Channel:    domain.Channel(req.GetChannel()),
+	})
+	if err != nil {
+		s.requestLogger(ctx).Error("Failed to create message", "error", err, "recipient", req.GetRecipient())
+
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, validationStatus(validationErr)
+		}
+		var limitErr *domain.RecipientLimitError
+		if errors.As(err, &limitErr) {
+			return nil, status.Error(codes.ResourceExhausted, limitErr.Error())
+		}
+		return nil, status.Error(codes.Internal, "failed to create message")
+	}
+
+	s.requestLogger(ctx).Info("Message created successfully", "message_id", message.ID, "recipient", req.GetRecipient())
+	return toProtoMessage(message), nil
+}
+
+// GetMessage returns a message by ID
+func (s *Server) GetMessage(ctx context.Context, req *messagingv1.GetMessageRequest) (*messagingv1.Message, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be a positive integer")
+	}
+
+	message, err := s.messageService.GetMessage(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			return nil, status.Error(codes.NotFound, "message not found")
+		}
+		s.requestLogger(ctx).Error("Failed to get message", "error", err, "message_id", req.GetId())
+		return nil, status.Error(codes.Internal, "failed to get message")
+	}
+
+	return toProtoMessage(message), nil
+}
+
+// ListSentMessages returns a page of sent messages
+func (s *Server) ListSentMessages(ctx context.Context, req *messagingv1.ListSentMessagesRequest) (*messagingv1.ListSentMessagesResponse, error) {
+	page := int(req.GetPage())
+	if page == 0 {
+		page = 1
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if page < 1 {
+		return nil, status.Error(codes.InvalidArgument, "page must be at least 1")
+	}
+	if limit < 1 || limit > maxPageLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxPageLimit)
+	}
+
+	offset := (page - 1) * limit
+	messages, total, err := s.messageService.GetSentMessages(ctx, offset, limit, domain.DefaultSentMessagesSort)
+	if err != nil {
+		s.requestLogger(ctx).Error("Failed to get sent messages", "error", err, "offset", offset, "limit", limit)
+		return nil, status.Error(codes.Internal, "failed to get sent messages")
+	}
+
+	response := &messagingv1.ListSentMessagesResponse{
+		Messages: make([]*messagingv1.Message, len(messages)),
+		Total:    int32(min(total, math.MaxInt32)),
+		Page:     int32(page),
+		Limit:    int32(limit),
+	}
+	for i, message := range messages {
+		response.Messages[i] = toProtoMessage(message)
+	}
+
+	return response, nil
+}
+
+// validationStatus converts a validation error to InvalidArgument with a
+// BadRequest detail naming each rejected field
+func validationStatus(err *domain.ValidationError) error {
+	st := status.New(codes.InvalidArgument, err.Message)
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(err.Fields))
+	for i, field := range err.Fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+	}
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// requestIDInterceptor tags the call's context with the x-request-id metadata,
+// or a new ID when it is missing or invalid, and logs each completed call
+func (s *Server) requestIDInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := firstMetadata(ctx, requestIDMetadata)
+	if !isValidRequestID(id) {
+		id = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	ctx = logger.WithContext(logger.ContextWithRequestID(ctx, id), s.logger)
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logger.InfoContext(ctx, "gRPC request",
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	)
+	return resp, err
+}
+
+// authInterceptor rejects calls whose x-api-key metadata does not match the
+// configured API key with Unauthenticated
+func (s *Server) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if s.apiKey != "" {
+		key := firstMetadata(ctx, apiKeyMetadata)
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		}
+	}
+	return handler(ctx, req)
+}
+
+// requestLogger returns the logger carried by ctx, tagged with its request ID
+func (s *Server) requestLogger(ctx context.Context) *logger.Logger {
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		return s.logger.WithRequestID(id)
+	}
+	return s.logger
+}
+
+// firstMetadata returns the first value of key in the incoming metadata
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// maxRequestIDLength bounds the request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// isValidRequestID reports whether id is non-empty, bounded and printable ASCII
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
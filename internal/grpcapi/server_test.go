@@ -0,0 +1,152 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/grpcapi/messagingv1"
+	"github.com/insider/insider-messaging/internal/repo"
+	"github.com/insider/insider-messaging/internal/service"
+	"github.com/insider/insider-messaging/pkg/logger"
+)
+
+// newTestClient serves a Server backed by messageRepo over an in-memory
+// connection and returns a client for it
+func newTestClient(t *testing.T, messageRepo repo.MessageRepository, opts ...ServerOption) messagingv1.MessagingServiceClient {
+	t.Helper()
+
+	log := &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	messageService := service.NewMessageService(messageRepo, log.Logger)
+	grpcServer := NewServer(log, messageService, opts...).NewGRPCServer()
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return messagingv1.NewMessagingServiceClient(conn)
+}
+
+func TestServer_CreateAndGetMessage(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, repo.NewInMemoryMessageRepository())
+
+	created, err := client.CreateMessage(ctx, &messagingv1.CreateMessageRequest{
+		Recipient:  "user@example.com",
+		Content:    "Hello",
+		WebhookUrl: "https://example.com/webhook",
+		Priority:   5,
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, created.GetId())
+	assert.Equal(t, string(domain.MessageStatusPending), created.GetStatus())
+	assert.Equal(t, string(domain.ChannelWebhook), created.GetChannel())
+	assert.Equal(t, int32(5), created.GetPriority())
+	assert.Equal(t, int32(3), created.GetMaxRetries())
+	assert.NotNil(t, created.GetCreatedAt())
+	assert.Nil(t, created.GetSentAt())
+
+	fetched, err := client.GetMessage(ctx, &messagingv1.GetMessageRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, created.GetId(), fetched.GetId())
+	assert.Equal(t, "user@example.com", fetched.GetRecipient())
+	assert.Equal(t, "Hello", fetched.GetContent())
+	assert.Equal(t, "https://example.com/webhook", fetched.GetWebhookUrl())
+}
+
+func TestServer_CreateMessage_InvalidArgument(t *testing.T) {
+	client := newTestClient(t, repo.NewInMemoryMessageRepository())
+
+	_, err := client.CreateMessage(context.Background(), &messagingv1.CreateMessageRequest{
+		Recipient:  "not-an-email",
+		Content:    "Hello",
+		WebhookUrl: "https://example.com/webhook",
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.GetFieldViolations(), 1)
+	assert.Equal(t, "recipient", badRequest.GetFieldViolations()[0].GetField())
+}
+
+func TestServer_GetMessage_NotFound(t *testing.T) {
+	client := newTestClient(t, repo.NewInMemoryMessageRepository())
+
+	_, err := client.GetMessage(context.Background(), &messagingv1.GetMessageRequest{Id: 999})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetMessage(context.Background(), &messagingv1.GetMessageRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_ListSentMessages(t *testing.T) {
+	ctx := context.Background()
+	messageRepo := repo.NewInMemoryMessageRepository()
+	client := newTestClient(t, messageRepo)
+
+	for _, recipient := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  recipient,
+			Content:    "Hello",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+		require.NoError(t, messageRepo.MarkSent(ctx, message.ID))
+	}
+
+	response, err := client.ListSentMessages(ctx, &messagingv1.ListSentMessagesRequest{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), response.GetTotal())
+	assert.Equal(t, int32(1), response.GetPage())
+	assert.Equal(t, int32(2), response.GetLimit())
+	require.Len(t, response.GetMessages(), 2)
+	assert.NotNil(t, response.GetMessages()[0].GetSentAt())
+
+	_, err = client.ListSentMessages(ctx, &messagingv1.ListSentMessagesRequest{Limit: 101})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_APIKey(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, repo.NewInMemoryMessageRepository(), WithAPIKey("secret"))
+
+	_, err := client.GetMessage(ctx, &messagingv1.GetMessageRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetMessage(metadata.AppendToOutgoingContext(ctx, "x-api-key", "wrong"), &messagingv1.GetMessageRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	var header metadata.MD
+	_, err = client.GetMessage(metadata.AppendToOutgoingContext(ctx, "x-api-key", "secret", "x-request-id", "req-1"),
+		&messagingv1.GetMessageRequest{Id: 1}, grpc.Header(&header))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+// HostPauseStore records webhook hosts whose deliveries are on hold
+type HostPauseStore interface {
+	// PauseHost puts deliveries to host on hold for duration, or until resumed
+	// when duration is zero. Pausing an already paused host replaces its pause.
+	PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error)
+
+	// ResumeHost lifts the pause on host, returning domain.ErrHostNotPaused if
+	// it is not paused
+	ResumeHost(ctx context.Context, host string) error
+
+	// ListPausedHosts returns the hosts currently paused, ordered by host
+	ListPausedHosts(ctx context.Context) ([]*domain.PausedHost, error)
+}
+
+// newPausedHost builds the pause record for host starting now
+func newPausedHost(host string, duration time.Duration) *domain.PausedHost {
+	now := time.Now()
+	paused := &domain.PausedHost{
+		Host:     host,
+		PausedAt: now,
+	}
+	if duration > 0 {
+		until := now.Add(duration)
+		paused.Until = &until
+	}
+	return paused
+}
+
+// sortPausedHosts orders paused hosts by host name
+func sortPausedHosts(hosts []*domain.PausedHost) {
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Host < hosts[j].Host
+	})
+}
+
+// inMemoryHostPauseStore implements HostPauseStore for a single instance
+type inMemoryHostPauseStore struct {
+	mu    sync.Mutex
+	hosts map[string]*domain.PausedHost
+}
+
+// NewInMemoryHostPauseStore creates a host pause store that is local to this process
+func NewInMemoryHostPauseStore() HostPauseStore {
+	return &inMemoryHostPauseStore{
+		hosts: make(map[string]*domain.PausedHost),
+	}
+}
+
+// PauseHost puts deliveries to host on hold
+func (s *inMemoryHostPauseStore) PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paused := newPausedHost(host, duration)
+	s.hosts[host] = paused
+
+	return paused, nil
+}
+
+// ResumeHost lifts the pause on host
+func (s *inMemoryHostPauseStore) ResumeHost(ctx context.Context, host string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paused, exists := s.hosts[host]
+	if !exists {
+		return domain.ErrHostNotPaused
+	}
+	delete(s.hosts, host)
+
+	if !paused.IsActive(time.Now()) {
+		return domain.ErrHostNotPaused
+	}
+
+	return nil
+}
+
+// ListPausedHosts returns the hosts currently paused, dropping lapsed pauses
+func (s *inMemoryHostPauseStore) ListPausedHosts(ctx context.Context) ([]*domain.PausedHost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	hosts := make([]*domain.PausedHost, 0, len(s.hosts))
+	for host, paused := range s.hosts {
+		if !paused.IsActive(now) {
+			delete(s.hosts, host)
+			continue
+		}
+		hosts = append(hosts, paused)
+	}
+
+	sortPausedHosts(hosts)
+	return hosts, nil
+}
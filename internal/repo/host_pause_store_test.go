@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryHostPauseStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pause, list and resume", func(t *testing.T) {
+		store := NewInMemoryHostPauseStore()
+
+		paused, err := store.PauseHost(ctx, "b.example.com", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "b.example.com", paused.Host)
+		require.NotNil(t, paused.Until)
+		assert.WithinDuration(t, paused.PausedAt.Add(time.Hour), *paused.Until, time.Millisecond)
+
+		_, err = store.PauseHost(ctx, "a.example.com", 0)
+		require.NoError(t, err)
+
+		hosts, err := store.ListPausedHosts(ctx)
+		require.NoError(t, err)
+		require.Len(t, hosts, 2)
+		assert.Equal(t, "a.example.com", hosts[0].Host)
+		assert.Nil(t, hosts[0].Until)
+		assert.Equal(t, "b.example.com", hosts[1].Host)
+
+		require.NoError(t, store.ResumeHost(ctx, "a.example.com"))
+		assert.ErrorIs(t, store.ResumeHost(ctx, "a.example.com"), domain.ErrHostNotPaused)
+
+		hosts, err = store.ListPausedHosts(ctx)
+		require.NoError(t, err)
+		require.Len(t, hosts, 1)
+		assert.Equal(t, "b.example.com", hosts[0].Host)
+	})
+
+	t.Run("lapsed pauses are dropped", func(t *testing.T) {
+		store := NewInMemoryHostPauseStore()
+
+		_, err := store.PauseHost(ctx, "a.example.com", time.Nanosecond)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		hosts, err := store.ListPausedHosts(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, hosts)
+		assert.ErrorIs(t, store.ResumeHost(ctx, "a.example.com"), domain.ErrHostNotPaused)
+	})
+}
@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// idempotencyLockKeyPrefix prefixes the locks that serialize creates sharing
+// an idempotency key
+const idempotencyLockKeyPrefix = "idempotency:lock:"
+
+// IdempotencyLocker serializes creates sharing an idempotency key across
+// instances
+type IdempotencyLocker interface {
+	// AcquireIdempotencyLock takes the lock for key for owner, expiring after
+	// ttl, and reports whether it was free
+	AcquireIdempotencyLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// ReleaseIdempotencyLock gives up the lock for key if owner holds it
+	ReleaseIdempotencyLock(ctx context.Context, key, owner string) error
+}
+
+// AcquireIdempotencyLock takes the lock for key with SET NX PX
+func (r *RedisCacheRepository) AcquireIdempotencyLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := r.client.SetNX(ctx, idempotencyLockKeyPrefix+key, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire idempotency lock: %w", err)
+	}
+	return acquired, nil
+}
+
+// ReleaseIdempotencyLock deletes the lock for key only while owner still holds
+// it, so a lock that expired and was taken by another create is left alone
+func (r *RedisCacheRepository) ReleaseIdempotencyLock(ctx context.Context, key, owner string) error {
+	if err := releaseOwnedLockScript.Run(ctx, r.client, []string{idempotencyLockKeyPrefix + key}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCacheRepository_IdempotencyLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	acquired, err := cache.AcquireIdempotencyLock(ctx, "order-1", "create-a", 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, 5*time.Second, server.TTL(idempotencyLockKeyPrefix+"order-1"))
+
+	acquired, err = cache.AcquireIdempotencyLock(ctx, "order-1", "create-b", 5*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired, "a second create must not take a held lock")
+
+	// Other keys are independent
+	acquired, err = cache.AcquireIdempotencyLock(ctx, "order-2", "create-b", 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Releasing by a non-holder leaves the lock alone
+	require.NoError(t, cache.ReleaseIdempotencyLock(ctx, "order-1", "create-b"))
+	assert.True(t, server.Exists(idempotencyLockKeyPrefix+"order-1"))
+
+	require.NoError(t, cache.ReleaseIdempotencyLock(ctx, "order-1", "create-a"))
+	acquired, err = cache.AcquireIdempotencyLock(ctx, "order-1", "create-b", 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// An expired lock can be taken by another create
+	server.FastForward(6 * time.Second)
+	acquired, err = cache.AcquireIdempotencyLock(ctx, "order-1", "create-a", 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
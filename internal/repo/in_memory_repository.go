@@ -2,6 +2,8 @@ package repo
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 type inMemoryMessageRepository struct {
 	mu       sync.RWMutex
 	messages map[int64]*domain.Message
+	archived []*domain.Message
+	attempts []*domain.WebhookAttempt
 	nextID   int64
 }
 
@@ -28,6 +32,14 @@ func (r *inMemoryMessageRepository) Create(ctx context.Context, req *domain.Crea
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if req.IdempotencyKey != nil {
+		for _, existing := range r.messages {
+			if existing.IdempotencyKey != nil && *existing.IdempotencyKey == *req.IdempotencyKey {
+				return nil, domain.ErrDuplicateIdempotencyKey
+			}
+		}
+	}
+
 	maxRetries := req.MaxRetries
 	if maxRetries == 0 {
 		maxRetries = 3 // Default max retries
@@ -41,8 +53,15 @@ func (r *inMemoryMessageRepository) Create(ctx context.Context, req *domain.Crea
 		Status:     domain.MessageStatusPending,
 		MaxRetries: maxRetries,
 		RetryCount: 0,
+		Priority:   req.Priority,
+		Channel:    channelOrDefault(req.Channel),
+		ResentFrom: req.ResentFrom,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
+
+		IdempotencyKey:  req.IdempotencyKey,
+		PayloadTemplate: req.PayloadTemplate,
+		ExpiresAt:       req.ExpiresAt,
 	}
 
 	r.messages[r.nextID] = message
@@ -51,16 +70,30 @@ func (r *inMemoryMessageRepository) Create(ctx context.Context, req *domain.Crea
 	return message, nil
 }
 
-// SelectUnsentForUpdate selects unsent messages for processing
+// CreateBatch creates all messages in memory
+func (r *inMemoryMessageRepository) CreateBatch(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]*domain.Message, error) {
+	messages := make([]*domain.Message, 0, len(reqs))
+	for _, req := range reqs {
+		// Imported requests carry no idempotency key, so Create cannot fail
+		// and the batch is all-or-nothing as well
+		message, _ := r.Create(ctx, req)
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// SelectUnsentForUpdate selects unsent messages for processing, skipping
+// expired ones
 func (r *inMemoryMessageRepository) SelectUnsentForUpdate(ctx context.Context, limit int) ([]*domain.Message, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	now := time.Now()
 	var messages []*domain.Message
 	count := 0
 
 	for _, message := range r.messages {
-		if message.Status == domain.MessageStatusPending && count < limit {
+		if message.Status == domain.MessageStatusPending && !message.IsExpired(now) && count < limit {
 			messages = append(messages, message)
 			count++
 		}
@@ -69,6 +102,86 @@ func (r *inMemoryMessageRepository) SelectUnsentForUpdate(ctx context.Context, l
 	return messages, nil
 }
 
+// LockUnsent returns the message when it is still pending, or failed with a
+// retry due, and not expired, matching the PostgreSQL repository; there are no
+// row locks to take in memory
+func (r *inMemoryMessageRepository) LockUnsent(ctx context.Context, messageID int64) (*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	message, exists := r.messages[messageID]
+	if !exists || message.IsExpired(now) {
+		return nil, domain.ErrMessageNotFound
+	}
+	retryDue := message.CanRetry() && isRetryDue(message, now)
+	if message.Status != domain.MessageStatusPending && !retryDue {
+		return nil, domain.ErrMessageNotFound
+	}
+
+	copied := *message
+	return &copied, nil
+}
+
+// LockForSend returns a copy of the message whatever its status; there are no
+// row locks to take in memory
+func (r *inMemoryMessageRepository) LockForSend(ctx context.Context, messageID int64) (*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return nil, domain.ErrMessageNotFound
+	}
+
+	copied := *message
+	return &copied, nil
+}
+
+// ClaimPending moves up to limit due messages to sending, oldest first, and
+// returns copies of them
+func (r *inMemoryMessageRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var claimable []*domain.Message
+	for _, message := range r.messages {
+		switch {
+		case message.IsExpired(now):
+			continue
+		case message.Status == domain.MessageStatusPending:
+		case message.CanRetry() && (message.NextRetryAt == nil || !message.NextRetryAt.After(now)):
+		case message.Status == domain.MessageStatusSending && !message.UpdatedAt.Add(lease).After(now):
+		default:
+			continue
+		}
+		claimable = append(claimable, message)
+	}
+
+	sort.Slice(claimable, func(i, j int) bool {
+		if !claimable[i].CreatedAt.Equal(claimable[j].CreatedAt) {
+			return claimable[i].CreatedAt.Before(claimable[j].CreatedAt)
+		}
+		return claimable[i].ID < claimable[j].ID
+	})
+
+	if len(claimable) > limit {
+		claimable = claimable[:limit]
+	}
+
+	claimed := make([]*domain.Message, 0, len(claimable))
+	for _, message := range claimable {
+		message.Status = domain.MessageStatusSending
+		message.UpdatedAt = now
+
+		copied := *message
+		claimed = append(claimed, &copied)
+	}
+
+	return claimed, nil
+}
+
 // MarkSent marks a message as sent
 func (r *inMemoryMessageRepository) MarkSent(ctx context.Context, messageID int64) error {
 	r.mu.Lock()
@@ -78,17 +191,43 @@ func (r *inMemoryMessageRepository) MarkSent(ctx context.Context, messageID int6
 	if !exists {
 		return domain.ErrMessageNotFound
 	}
+	if message.Status == domain.MessageStatusSent {
+		return nil
+	}
+
+	now := time.Now()
+	message.Status = domain.MessageStatusSent
+	message.SentAt = &now
+	message.UpdatedAt = now
+
+	return nil
+}
+
+// MarkSentWithReference marks a message as sent and stores the provider's message ID
+func (r *inMemoryMessageRepository) MarkSentWithReference(ctx context.Context, messageID int64, providerMessageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+	if message.Status == domain.MessageStatusSent {
+		return nil
+	}
 
 	now := time.Now()
 	message.Status = domain.MessageStatusSent
 	message.SentAt = &now
+	message.ProviderMessageID = &providerMessageID
 	message.UpdatedAt = now
 
 	return nil
 }
 
-// MarkFailed marks a message as failed with error details
-func (r *inMemoryMessageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string) error {
+// MarkResent moves a sent message's sent_at to now, keeping its provider
+// message ID unless a new one is given
+func (r *inMemoryMessageRepository) MarkResent(ctx context.Context, messageID int64, providerMessageID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -96,16 +235,45 @@ func (r *inMemoryMessageRepository) MarkFailed(ctx context.Context, messageID in
 	if !exists {
 		return domain.ErrMessageNotFound
 	}
+	if message.Status != domain.MessageStatusSent {
+		return domain.ErrMessageNotSent
+	}
+
+	now := time.Now()
+	message.SentAt = &now
+	if providerMessageID != "" {
+		message.ProviderMessageID = &providerMessageID
+	}
+	message.UpdatedAt = now
 
+	return nil
+}
+
+// MarkFailed marks a message as failed with error details and schedules its
+// next retry retryDelay after the failure
+func (r *inMemoryMessageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string, retryDelay time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+
+	now := time.Now()
+	nextRetryAt := now.Add(retryDelay)
 	message.Status = domain.MessageStatusFailed
 	message.ErrorMessage = &errorMsg
 	message.RetryCount++
-	message.UpdatedAt = time.Now()
+	message.FailedAt = &now
+	message.NextRetryAt = &nextRetryAt
+	message.UpdatedAt = now
 
 	return nil
 }
 
-// GetByID retrieves a message by its ID
+// GetByID retrieves a copy of a message by its ID, so later updates to the
+// stored message don't change what the caller read
 func (r *inMemoryMessageRepository) GetByID(ctx context.Context, messageID int64) (*domain.Message, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -115,11 +283,47 @@ func (r *inMemoryMessageRepository) GetByID(ctx context.Context, messageID int64
 		return nil, domain.ErrMessageNotFound
 	}
 
-	return message, nil
+	copied := *message
+	return &copied, nil
+}
+
+// GetByIDs retrieves copies of the messages with the given IDs
+func (r *inMemoryMessageRepository) GetByIDs(ctx context.Context, messageIDs []int64) ([]*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var messages []*domain.Message
+	for _, id := range messageIDs {
+		if message, exists := r.messages[id]; exists {
+			copied := *message
+			messages = append(messages, &copied)
+		}
+	}
+
+	return messages, nil
+}
+
+// GetByIdempotencyKey retrieves the message created with the given idempotency key
+func (r *inMemoryMessageRepository) GetByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, message := range r.messages {
+		if message.IdempotencyKey != nil && *message.IdempotencyKey == key {
+			copied := *message
+			return &copied, nil
+		}
+	}
+
+	return nil, domain.ErrMessageNotFound
 }
 
-// GetSentMessages retrieves sent messages with pagination
-func (r *inMemoryMessageRepository) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+// GetSentMessages retrieves sent messages in the given order with pagination
+func (r *inMemoryMessageRepository) GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error) {
+	if !order.IsValid() {
+		return nil, 0, fmt.Errorf("unsupported sent message order %q %q", order.Field, order.Order)
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -130,6 +334,18 @@ func (r *inMemoryMessageRepository) GetSentMessages(ctx context.Context, offset,
 		}
 	}
 
+	sort.Slice(sentMessages, func(i, j int) bool {
+		a, b := sentMessages[i], sentMessages[j]
+		if order.Order == domain.SortAsc {
+			a, b = b, a
+		}
+		ta, tb := sortTime(a, order.Field), sortTime(b, order.Field)
+		if ta.Equal(tb) {
+			return a.ID > b.ID
+		}
+		return ta.After(tb)
+	})
+
 	total := len(sentMessages)
 
 	// Apply pagination
@@ -150,20 +366,529 @@ func (r *inMemoryMessageRepository) GetSentMessages(ctx context.Context, offset,
 	return sentMessages[start:end], total, nil
 }
 
-// GetFailedMessages retrieves failed messages that can be retried
+// sortTime returns the timestamp of message that field orders by
+func sortTime(message *domain.Message, field domain.SortField) time.Time {
+	if field != domain.SortBySentAt {
+		return message.CreatedAt
+	}
+	if message.SentAt == nil {
+		return time.Time{}
+	}
+	return *message.SentAt
+}
+
+// ListMessages retrieves messages filtered by status, newest first with pagination
+func (r *inMemoryMessageRepository) ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error) {
+	return r.listMatching(func(message *domain.Message) bool {
+		return status == "" || message.Status == status
+	}, offset, limit)
+}
+
+// ListAfterCursor retrieves up to limit messages with the given status whose ID
+// is below cursorID, highest ID first
+func (r *inMemoryMessageRepository) ListAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []*domain.Message{}
+	for _, message := range r.messages {
+		if (status == "" || message.Status == status) && (cursorID == 0 || message.ID < cursorID) {
+			copied := *message
+			matched = append(matched, &copied)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ID > matched[j].ID
+	})
+
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	return matched, nil
+}
+
+// GetByRecipient retrieves messages for a recipient, newest first with pagination
+func (r *inMemoryMessageRepository) GetByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error) {
+	return r.listMatching(func(message *domain.Message) bool {
+		return message.Recipient == recipient
+	}, offset, limit)
+}
+
+// ListByDateRange retrieves messages created between from and to inclusive,
+// newest first with pagination
+func (r *inMemoryMessageRepository) ListByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error) {
+	return r.listMatching(func(message *domain.Message) bool {
+		return !message.CreatedAt.Before(from) && !message.CreatedAt.After(to)
+	}, offset, limit)
+}
+
+// listMatching returns copies of the messages accepted by match, newest first,
+// paginated, along with how many matched in total
+func (r *inMemoryMessageRepository) listMatching(match func(*domain.Message) bool, offset, limit int) ([]*domain.Message, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Message
+	for _, message := range r.messages {
+		if match(message) {
+			copied := *message
+			matched = append(matched, &copied)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	total := len(matched)
+	if offset >= total {
+		return []*domain.Message{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	return matched[offset:end], total, nil
+}
+
+// GetFailedMessages retrieves failed messages that can be retried, highest
+// priority first and then by when their retry became due
 func (r *inMemoryMessageRepository) GetFailedMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var failedMessages []*domain.Message
-	count := 0
+	now := time.Now()
 
 	for _, message := range r.messages {
-		if message.Status == domain.MessageStatusFailed && message.RetryCount < message.MaxRetries && count < limit {
+		if message.Status == domain.MessageStatusFailed && message.RetryCount < message.MaxRetries && isRetryDue(message, now) && !message.IsExpired(now) {
 			failedMessages = append(failedMessages, message)
-			count++
 		}
 	}
 
+	// Match the PostgreSQL ordering: priority DESC, next_retry_at ASC NULLS FIRST, id ASC
+	sort.Slice(failedMessages, func(i, j int) bool {
+		a, b := failedMessages[i], failedMessages[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if (a.NextRetryAt == nil) != (b.NextRetryAt == nil) {
+			return a.NextRetryAt == nil
+		}
+		if a.NextRetryAt != nil && !a.NextRetryAt.Equal(*b.NextRetryAt) {
+			return a.NextRetryAt.Before(*b.NextRetryAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if len(failedMessages) > limit {
+		failedMessages = failedMessages[:limit]
+	}
+
 	return failedMessages, nil
 }
+
+// GetFailedMessagesPaginated retrieves every failed message, most recently
+// failed first with pagination, whether or not it is due for a retry
+func (r *inMemoryMessageRepository) GetFailedMessagesPaginated(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var failedMessages []*domain.Message
+	for _, message := range r.messages {
+		if message.Status == domain.MessageStatusFailed {
+			failedMessages = append(failedMessages, message)
+		}
+	}
+
+	sortByFailedAtDesc(failedMessages)
+
+	total := len(failedMessages)
+
+	start := offset
+	if start >= total {
+		return []*domain.Message{}, total, nil
+	}
+
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return failedMessages[start:end], total, nil
+}
+
+// MarkDeadLetter moves a message that exhausted its retries to the dead-letter state
+func (r *inMemoryMessageRepository) MarkDeadLetter(ctx context.Context, messageID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+
+	message.MarkAsDeadLetter()
+
+	return nil
+}
+
+// GetDeadLetterMessages retrieves dead-lettered messages, most recently failed
+// first, with pagination
+func (r *inMemoryMessageRepository) GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deadLetterMessages []*domain.Message
+	for _, message := range r.messages {
+		if message.IsDeadLetter() {
+			deadLetterMessages = append(deadLetterMessages, message)
+		}
+	}
+
+	sortByFailedAtDesc(deadLetterMessages)
+
+	total := len(deadLetterMessages)
+
+	start := offset
+	if start >= total {
+		return []*domain.Message{}, total, nil
+	}
+
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return deadLetterMessages[start:end], total, nil
+}
+
+// Requeue resets an unsent message back to pending with a fresh retry budget
+func (r *inMemoryMessageRepository) Requeue(ctx context.Context, messageID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+
+	switch message.Status {
+	case domain.MessageStatusFailed, domain.MessageStatusDeadLetter:
+	case domain.MessageStatusSent:
+		return domain.ErrMessageAlreadySent
+	default:
+		return domain.ErrMessageNotRequeueable
+	}
+
+	message.Status = domain.MessageStatusPending
+	message.RetryCount = 0
+	message.FailedAt = nil
+	message.ErrorMessage = nil
+	message.NextRetryAt = nil
+	message.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Cancel marks a message that has not been delivered yet as cancelled
+func (r *inMemoryMessageRepository) Cancel(ctx context.Context, messageID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return domain.ErrMessageNotFound
+	}
+
+	switch message.Status {
+	case domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusSending:
+	default:
+		return domain.ErrMessageNotCancellable
+	}
+
+	message.Status = domain.MessageStatusCancelled
+	message.NextRetryAt = nil
+	message.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// ExpireOverdue marks pending and failed messages whose expiry has passed as
+// expired and returns copies of them
+func (r *inMemoryMessageRepository) ExpireOverdue(ctx context.Context) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var expired []*domain.Message
+	for _, message := range r.messages {
+		if message.Status != domain.MessageStatusPending && message.Status != domain.MessageStatusFailed {
+			continue
+		}
+		if !message.IsExpired(now) {
+			continue
+		}
+
+		message.Status = domain.MessageStatusExpired
+		message.NextRetryAt = nil
+		message.UpdatedAt = now
+
+		copied := *message
+		expired = append(expired, &copied)
+	}
+
+	return expired, nil
+}
+
+// UpdatePending applies update to a message that is still pending
+func (r *inMemoryMessageRepository) UpdatePending(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.messages[messageID]
+	if !exists {
+		return nil, domain.ErrMessageNotFound
+	}
+	if message.Status != domain.MessageStatusPending {
+		return nil, domain.ErrMessageNotPending
+	}
+
+	if update.Recipient != nil {
+		message.Recipient = *update.Recipient
+	}
+	if update.Content != nil {
+		message.Content = *update.Content
+	}
+	if update.WebhookURL != nil {
+		message.WebhookURL = *update.WebhookURL
+	}
+	message.UpdatedAt = time.Now()
+
+	copied := *message
+	return &copied, nil
+}
+
+// CountByStatus counts messages per status, including every known status with
+// no messages as zero
+func (r *inMemoryMessageRepository) CountByStatus(ctx context.Context) (map[domain.MessageStatus]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[domain.MessageStatus]int, len(domain.MessageStatuses))
+	for _, status := range domain.MessageStatuses {
+		counts[status] = 0
+	}
+	for _, message := range r.messages {
+		counts[message.Status]++
+	}
+
+	return counts, nil
+}
+
+// CountWithStatus counts the messages with status
+func (r *inMemoryMessageRepository) CountWithStatus(ctx context.Context, status domain.MessageStatus) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, message := range r.messages {
+		if message.Status == status {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// CountDeliveryOutcomes counts sent and dead-lettered messages per webhook URL
+// that reached their terminal state at or after since
+func (r *inMemoryMessageRepository) CountDeliveryOutcomes(ctx context.Context, since time.Time) ([]*domain.DeliveryOutcome, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byURL := make(map[string]*domain.DeliveryOutcome)
+	for _, message := range r.messages {
+		isSent := message.Status == domain.MessageStatusSent && message.SentAt != nil && !message.SentAt.Before(since)
+		isDeadLettered := message.IsDeadLetter() && !message.UpdatedAt.Before(since)
+		if !isSent && !isDeadLettered {
+			continue
+		}
+
+		outcome, exists := byURL[message.WebhookURL]
+		if !exists {
+			outcome = &domain.DeliveryOutcome{WebhookURL: message.WebhookURL}
+			byURL[message.WebhookURL] = outcome
+		}
+
+		if isSent {
+			outcome.Sent++
+		} else {
+			outcome.DeadLettered++
+		}
+	}
+
+	outcomes := make([]*domain.DeliveryOutcome, 0, len(byURL))
+	for _, outcome := range byURL {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Slice(outcomes, func(i, j int) bool {
+		return outcomes[i].WebhookURL < outcomes[j].WebhookURL
+	})
+
+	return outcomes, nil
+}
+
+// GetRecentMessages retrieves messages of any status created within the last
+// since duration, newest first
+func (r *inMemoryMessageRepository) GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cutoff := time.Now().Add(-since)
+	var recentMessages []*domain.Message
+	for _, message := range r.messages {
+		if !message.CreatedAt.Before(cutoff) {
+			recentMessages = append(recentMessages, message)
+		}
+	}
+
+	sort.Slice(recentMessages, func(i, j int) bool {
+		return recentMessages[i].CreatedAt.After(recentMessages[j].CreatedAt)
+	})
+
+	if len(recentMessages) > limit {
+		recentMessages = recentMessages[:limit]
+	}
+
+	return recentMessages, nil
+}
+
+// CountByRecipientSince counts messages created for recipient at or after since
+func (r *inMemoryMessageRepository) CountByRecipientSince(ctx context.Context, recipient string, since time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, message := range r.messages {
+		if message.Recipient == recipient && !message.CreatedAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// RecordAttempt appends a webhook attempt to the in-memory log
+func (r *inMemoryMessageRepository) RecordAttempt(ctx context.Context, messageID int64, statusCode int, durationMs int64, attemptErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt := &domain.WebhookAttempt{
+		ID:         int64(len(r.attempts) + 1),
+		MessageID:  messageID,
+		DurationMs: durationMs,
+		CreatedAt:  time.Now(),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if attemptErr != nil {
+		errorText := attemptErr.Error()
+		attempt.Error = &errorText
+	}
+	r.attempts = append(r.attempts, attempt)
+
+	return nil
+}
+
+// ListAttempts retrieves the webhook attempts recorded for a message, oldest first
+func (r *inMemoryMessageRepository) ListAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var attempts []*domain.WebhookAttempt
+	for _, attempt := range r.attempts {
+		if attempt.MessageID == messageID {
+			copied := *attempt
+			attempts = append(attempts, &copied)
+		}
+	}
+
+	return attempts, nil
+}
+
+// ArchiveOlderThan moves messages sent before cutoff out of the live set
+func (r *inMemoryMessageRepository) ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	archived := 0
+	for id, message := range r.messages {
+		if message.Status == domain.MessageStatusSent && message.SentAt != nil && message.SentAt.Before(cutoff) {
+			r.archived = append(r.archived, message)
+			delete(r.messages, id)
+			archived++
+		}
+	}
+
+	return archived, nil
+}
+
+// DeleteOlderThan deletes messages with status last updated before cutoff,
+// with their recorded attempts
+func (r *inMemoryMessageRepository) DeleteOlderThan(ctx context.Context, status domain.MessageStatus, cutoff time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := make(map[int64]bool)
+	for id, message := range r.messages {
+		if message.Status == status && message.UpdatedAt.Before(cutoff) {
+			delete(r.messages, id)
+			deleted[id] = true
+		}
+	}
+
+	kept := r.attempts[:0]
+	for _, attempt := range r.attempts {
+		if !deleted[attempt.MessageID] {
+			kept = append(kept, attempt)
+		}
+	}
+	r.attempts = kept
+
+	return len(deleted), nil
+}
+
+// WithTx runs fn against the repository itself; every operation is already
+// atomic under the repository lock, so there is nothing to roll back
+func (r *inMemoryMessageRepository) WithTx(ctx context.Context, fn func(txRepo MessageRepository) error) error {
+	return fn(r)
+}
+
+// sortByFailedAtDesc orders messages like the PostgreSQL repository's
+// failed_at DESC NULLS LAST, id DESC
+func sortByFailedAtDesc(messages []*domain.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		a, b := messages[i], messages[j]
+		if (a.FailedAt == nil) != (b.FailedAt == nil) {
+			return a.FailedAt != nil
+		}
+		if a.FailedAt != nil && !a.FailedAt.Equal(*b.FailedAt) {
+			return a.FailedAt.After(*b.FailedAt)
+		}
+		return a.ID > b.ID
+	})
+}
+
+// isRetryDue reports whether a failed message's retry grace period has elapsed
+func isRetryDue(message *domain.Message, now time.Time) bool {
+	return message.NextRetryAt == nil || !message.NextRetryAt.After(now)
+}
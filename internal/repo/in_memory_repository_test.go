@@ -0,0 +1,736 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryMessageRepository_CountDeliveryOutcomes(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	since := now.Add(-time.Hour)
+
+	inside := now.Add(-30 * time.Minute)
+	outside := now.Add(-2 * time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			// Inside the window
+			1: {ID: 1, WebhookURL: "https://a.example.com/hook", Status: domain.MessageStatusSent, SentAt: &inside, UpdatedAt: inside},
+			2: {ID: 2, WebhookURL: "https://a.example.com/hook", Status: domain.MessageStatusSent, SentAt: &since, UpdatedAt: since},
+			3: {ID: 3, WebhookURL: "https://a.example.com/hook", Status: domain.MessageStatusDeadLetter, UpdatedAt: inside},
+			4: {ID: 4, WebhookURL: "https://b.example.com/hook", Status: domain.MessageStatusDeadLetter, UpdatedAt: inside},
+			// Outside the window
+			5: {ID: 5, WebhookURL: "https://a.example.com/hook", Status: domain.MessageStatusSent, SentAt: &outside, UpdatedAt: outside},
+			6: {ID: 6, WebhookURL: "https://b.example.com/hook", Status: domain.MessageStatusDeadLetter, UpdatedAt: outside},
+			// Not terminal
+			7: {ID: 7, WebhookURL: "https://c.example.com/hook", Status: domain.MessageStatusFailed, UpdatedAt: inside},
+			8: {ID: 8, WebhookURL: "https://c.example.com/hook", Status: domain.MessageStatusPending, UpdatedAt: inside},
+		},
+		nextID: 9,
+	}
+
+	outcomes, err := repo.CountDeliveryOutcomes(ctx, since)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2)
+	assert.Equal(t, &domain.DeliveryOutcome{WebhookURL: "https://a.example.com/hook", Sent: 2, DeadLettered: 1}, outcomes[0])
+	assert.Equal(t, &domain.DeliveryOutcome{WebhookURL: "https://b.example.com/hook", Sent: 0, DeadLettered: 1}, outcomes[1])
+}
+
+func TestInMemoryMessageRepository_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryMessageRepository()
+	key := "order-42"
+
+	req := &domain.CreateMessageRequest{
+		Recipient:      "test@example.com",
+		Content:        "Test message",
+		WebhookURL:     "https://example.com/webhook",
+		IdempotencyKey: &key,
+	}
+
+	created, err := repo.Create(ctx, req)
+	require.NoError(t, err)
+
+	_, err = repo.Create(ctx, req)
+	assert.ErrorIs(t, err, domain.ErrDuplicateIdempotencyKey)
+
+	found, err := repo.GetByIdempotencyKey(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	_, err = repo.GetByIdempotencyKey(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+}
+
+func TestInMemoryMessageRepository_ListMessages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, CreatedAt: now.Add(-3 * time.Minute)},
+			2: {ID: 2, Status: domain.MessageStatusPending, CreatedAt: now.Add(-2 * time.Minute)},
+			3: {ID: 3, Status: domain.MessageStatusFailed, CreatedAt: now.Add(-time.Minute)},
+			4: {ID: 4, Status: domain.MessageStatusPending, CreatedAt: now},
+		},
+		nextID: 5,
+	}
+
+	ids := func(messages []*domain.Message) []int64 {
+		var result []int64
+		for _, message := range messages {
+			result = append(result, message.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		status    domain.MessageStatus
+		offset    int
+		limit     int
+		wantIDs   []int64
+		wantTotal int
+	}{
+		{"all newest first", "", 0, 10, []int64{4, 3, 2, 1}, 4},
+		{"all paginated", "", 1, 2, []int64{3, 2}, 4},
+		{"pending", domain.MessageStatusPending, 0, 10, []int64{4, 2}, 2},
+		{"sent", domain.MessageStatusSent, 0, 10, []int64{1}, 1},
+		{"failed", domain.MessageStatusFailed, 0, 10, []int64{3}, 1},
+		{"offset past the end", domain.MessageStatusPending, 5, 10, nil, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, total, err := repo.ListMessages(ctx, tt.status, tt.offset, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.Equal(t, tt.wantIDs, ids(messages))
+		})
+	}
+}
+
+func TestInMemoryMessageRepository_ListAfterCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryMessageRepository()
+
+	create := func() *domain.Message {
+		message, err := repo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 3,
+		})
+		require.NoError(t, err)
+		return message
+	}
+	for i := 0; i < 7; i++ {
+		create()
+	}
+
+	t.Run("walks every page without gaps or repeats as messages arrive", func(t *testing.T) {
+		var seen []int64
+		cursorID := int64(0)
+		for {
+			messages, err := repo.ListAfterCursor(ctx, "", cursorID, 3)
+			require.NoError(t, err)
+			for _, message := range messages {
+				seen = append(seen, message.ID)
+			}
+			if len(messages) < 3 {
+				break
+			}
+			cursorID = messages[len(messages)-1].ID
+
+			// A message created mid-walk would shift every offset page
+			create()
+		}
+
+		assert.Equal(t, []int64{7, 6, 5, 4, 3, 2, 1}, seen)
+	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		require.NoError(t, repo.MarkSent(ctx, 2))
+		require.NoError(t, repo.MarkSent(ctx, 5))
+
+		messages, err := repo.ListAfterCursor(ctx, domain.MessageStatusSent, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, int64(5), messages[0].ID)
+		assert.Equal(t, int64(2), messages[1].ID)
+
+		messages, err = repo.ListAfterCursor(ctx, domain.MessageStatusSent, 5, 10)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, int64(2), messages[0].ID)
+	})
+}
+
+func TestInMemoryMessageRepository_GetByRecipient(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Recipient: "user@example.com", Status: domain.MessageStatusSent, CreatedAt: now.Add(-2 * time.Minute)},
+			2: {ID: 2, Recipient: "other@example.com", Status: domain.MessageStatusSent, CreatedAt: now.Add(-time.Minute)},
+			3: {ID: 3, Recipient: "user@example.com", Status: domain.MessageStatusPending, CreatedAt: now},
+			4: {ID: 4, Recipient: "USER@example.com", Status: domain.MessageStatusPending, CreatedAt: now},
+		},
+		nextID: 5,
+	}
+
+	messages, total, err := repo.GetByRecipient(ctx, "user@example.com", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, messages, 2)
+	assert.Equal(t, int64(3), messages[0].ID)
+	assert.Equal(t, int64(1), messages[1].ID)
+
+	messages, total, err = repo.GetByRecipient(ctx, "user@example.com", 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, int64(1), messages[0].ID)
+
+	messages, total, err = repo.GetByRecipient(ctx, "nobody@example.com", 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, messages)
+}
+
+func TestInMemoryMessageRepository_ListByDateRange(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, CreatedAt: start.Add(-time.Second)},
+			2: {ID: 2, Status: domain.MessageStatusSent, CreatedAt: start},
+			3: {ID: 3, Status: domain.MessageStatusPending, CreatedAt: start.Add(time.Hour)},
+			4: {ID: 4, Status: domain.MessageStatusPending, CreatedAt: start.Add(2 * time.Hour)},
+		},
+		nextID: 5,
+	}
+
+	// Both bounds are inclusive
+	messages, total, err := repo.ListByDateRange(ctx, start, start.Add(time.Hour), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, messages, 2)
+	assert.Equal(t, int64(3), messages[0].ID)
+	assert.Equal(t, int64(2), messages[1].ID)
+
+	messages, total, err = repo.ListByDateRange(ctx, start.Add(time.Minute), start.Add(30*time.Minute), 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, messages)
+}
+
+func TestInMemoryMessageRepository_CountByStatus(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryMessageRepository()
+	counts, err := repo.CountByStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, counts, len(domain.MessageStatuses))
+	for _, status := range domain.MessageStatuses {
+		assert.Zero(t, counts[status], status)
+	}
+
+	repo = &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent},
+			2: {ID: 2, Status: domain.MessageStatusSent},
+			3: {ID: 3, Status: domain.MessageStatusDeadLetter},
+		},
+		nextID: 4,
+	}
+	counts, err = repo.CountByStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, counts[domain.MessageStatusSent])
+	assert.Equal(t, 1, counts[domain.MessageStatusDeadLetter])
+	assert.Zero(t, counts[domain.MessageStatusPending])
+	assert.Len(t, counts, len(domain.MessageStatuses))
+
+	sent, err := repo.CountWithStatus(ctx, domain.MessageStatusSent)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+}
+
+func TestInMemoryMessageRepository_GetRecentMessages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, CreatedAt: now.Add(-2 * time.Minute)},
+			2: {ID: 2, Status: domain.MessageStatusFailed, CreatedAt: now.Add(-time.Minute)},
+			3: {ID: 3, Status: domain.MessageStatusPending, CreatedAt: now.Add(-10 * time.Minute)},
+			4: {ID: 4, Status: domain.MessageStatusDeadLetter, CreatedAt: now.Add(-30 * time.Second)},
+		},
+		nextID: 5,
+	}
+
+	messages, err := repo.GetRecentMessages(ctx, 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, int64(4), messages[0].ID)
+	assert.Equal(t, int64(2), messages[1].ID)
+	assert.Equal(t, int64(1), messages[2].ID)
+
+	limited, err := repo.GetRecentMessages(ctx, 5*time.Minute, 2)
+	require.NoError(t, err)
+	assert.Len(t, limited, 2)
+}
+
+func TestInMemoryMessageRepository_GetFailedMessages_PriorityOrder(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	earlier := now.Add(-10 * time.Minute)
+	later := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusFailed, MaxRetries: 3, Priority: 0, NextRetryAt: &earlier},
+			2: {ID: 2, Status: domain.MessageStatusFailed, MaxRetries: 3, Priority: 5, NextRetryAt: &later},
+			3: {ID: 3, Status: domain.MessageStatusFailed, MaxRetries: 3, Priority: 5, NextRetryAt: &earlier},
+			4: {ID: 4, Status: domain.MessageStatusFailed, MaxRetries: 3, Priority: 0},
+			// Not yet due or not retryable
+			5: {ID: 5, Status: domain.MessageStatusFailed, MaxRetries: 3, Priority: 10, NextRetryAt: &future},
+			6: {ID: 6, Status: domain.MessageStatusFailed, MaxRetries: 3, RetryCount: 3, Priority: 10},
+			7: {ID: 7, Status: domain.MessageStatusPending, MaxRetries: 3, Priority: 10},
+		},
+		nextID: 8,
+	}
+
+	messages, err := repo.GetFailedMessages(ctx, 10)
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(t, []int64{3, 2, 4, 1}, ids)
+
+	limited, err := repo.GetFailedMessages(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, limited, 2)
+	assert.Equal(t, int64(3), limited[0].ID)
+	assert.Equal(t, int64(2), limited[1].ID)
+}
+
+func TestInMemoryMessageRepository_GetSentMessages_Order(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	sentFirst := now.Add(-time.Hour)
+	sentLast := now.Add(-time.Minute)
+
+	// Message 1 was created first but sent last
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, CreatedAt: now.Add(-3 * time.Hour), SentAt: &sentLast},
+			2: {ID: 2, Status: domain.MessageStatusSent, CreatedAt: now.Add(-2 * time.Hour), SentAt: &sentFirst},
+			3: {ID: 3, Status: domain.MessageStatusPending, CreatedAt: now},
+		},
+		nextID: 4,
+	}
+
+	tests := []struct {
+		order domain.MessageSort
+		ids   []int64
+	}{
+		{domain.MessageSort{Field: domain.SortBySentAt, Order: domain.SortDesc}, []int64{1, 2}},
+		{domain.MessageSort{Field: domain.SortBySentAt, Order: domain.SortAsc}, []int64{2, 1}},
+		{domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortDesc}, []int64{2, 1}},
+		{domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortAsc}, []int64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.order.Field)+" "+string(tt.order.Order), func(t *testing.T) {
+			messages, total, err := repo.GetSentMessages(ctx, 0, 10, tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, 2, total)
+
+			var ids []int64
+			for _, message := range messages {
+				ids = append(ids, message.ID)
+			}
+			assert.Equal(t, tt.ids, ids)
+		})
+	}
+
+	_, _, err := repo.GetSentMessages(ctx, 0, 10, domain.MessageSort{Field: "recipient", Order: domain.SortAsc})
+	assert.Error(t, err)
+}
+
+func TestInMemoryMessageRepository_GetFailedMessages_NextRetryAt(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryMessageRepository()
+
+	backingOff, err := repo.Create(ctx, &domain.CreateMessageRequest{Recipient: "a@example.com", Content: "a", MaxRetries: 3})
+	require.NoError(t, err)
+	due, err := repo.Create(ctx, &domain.CreateMessageRequest{Recipient: "b@example.com", Content: "b", MaxRetries: 3})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.MarkFailed(ctx, backingOff.ID, "connection refused", time.Hour))
+	require.NoError(t, repo.MarkFailed(ctx, due.ID, "connection refused", 0))
+
+	stored, err := repo.GetByID(ctx, backingOff.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.NextRetryAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *stored.NextRetryAt, time.Minute)
+
+	// Only the message whose backoff has elapsed is returned for retry
+	messages, err := repo.GetFailedMessages(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, due.ID, messages[0].ID)
+}
+
+func TestInMemoryMessageRepository_GetFailedMessagesPaginated(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusFailed, FailedAt: &earlier},
+			2: {ID: 2, Status: domain.MessageStatusFailed, FailedAt: &now},
+			3: {ID: 3, Status: domain.MessageStatusFailed},
+			4: {ID: 4, Status: domain.MessageStatusDeadLetter, FailedAt: &now},
+			5: {ID: 5, Status: domain.MessageStatusPending},
+		},
+		nextID: 6,
+	}
+
+	// Most recently failed first, regardless of whether a retry is due
+	messages, total, err := repo.GetFailedMessagesPaginated(ctx, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, messages, 2)
+	assert.Equal(t, int64(2), messages[0].ID)
+	assert.Equal(t, int64(1), messages[1].ID)
+
+	messages, total, err = repo.GetFailedMessagesPaginated(ctx, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, int64(3), messages[0].ID)
+
+	messages, _, err = repo.GetFailedMessagesPaginated(ctx, 10, 2)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestInMemoryMessageRepository_GetDeadLetterMessages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusDeadLetter, FailedAt: &earlier},
+			2: {ID: 2, Status: domain.MessageStatusDeadLetter, FailedAt: &now},
+			3: {ID: 3, Status: domain.MessageStatusDeadLetter},
+			4: {ID: 4, Status: domain.MessageStatusDeadLetter, FailedAt: &now},
+			5: {ID: 5, Status: domain.MessageStatusFailed, FailedAt: &now},
+		},
+		nextID: 6,
+	}
+
+	// Most recently failed first, ties by newest ID, never-failed last
+	messages, total, err := repo.GetDeadLetterMessages(ctx, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, messages, 3)
+	assert.Equal(t, int64(4), messages[0].ID)
+	assert.Equal(t, int64(2), messages[1].ID)
+	assert.Equal(t, int64(1), messages[2].ID)
+
+	messages, total, err = repo.GetDeadLetterMessages(ctx, 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, int64(3), messages[0].ID)
+}
+
+func TestInMemoryMessageRepository_ArchiveOlderThan(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cutoff := now.Add(-24 * time.Hour)
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, SentAt: &old},
+			2: {ID: 2, Status: domain.MessageStatusSent, SentAt: &recent},
+			3: {ID: 3, Status: domain.MessageStatusDeadLetter, UpdatedAt: old},
+			4: {ID: 4, Status: domain.MessageStatusSent, SentAt: &old},
+		},
+		nextID: 5,
+	}
+
+	archived, err := repo.ArchiveOlderThan(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 2, archived)
+	assert.Len(t, repo.archived, 2)
+
+	_, err = repo.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	for _, id := range []int64{2, 3} {
+		_, err := repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	archived, err = repo.ArchiveOlderThan(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived)
+}
+
+func TestInMemoryMessageRepository_DeleteOlderThan(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cutoff := now.Add(-24 * time.Hour)
+	old := now.Add(-48 * time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, UpdatedAt: old},
+			2: {ID: 2, Status: domain.MessageStatusSent, UpdatedAt: now},
+			3: {ID: 3, Status: domain.MessageStatusDeadLetter, UpdatedAt: old},
+			4: {ID: 4, Status: domain.MessageStatusPending, UpdatedAt: old},
+		},
+		nextID: 5,
+	}
+	require.NoError(t, repo.RecordAttempt(ctx, 1, 200, 10, nil))
+	require.NoError(t, repo.RecordAttempt(ctx, 2, 200, 10, nil))
+
+	deleted, err := repo.DeleteOlderThan(ctx, domain.MessageStatusSent, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = repo.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	for _, id := range []int64{2, 3, 4} {
+		_, err := repo.GetByID(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	attempts, err := repo.ListAttempts(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, attempts)
+	attempts, err = repo.ListAttempts(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, attempts, 1)
+}
+
+func TestInMemoryMessageRepository_ClaimPending(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusPending, CreatedAt: now.Add(-5 * time.Minute)},
+			2: {ID: 2, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3, NextRetryAt: &past, CreatedAt: now.Add(-4 * time.Minute)},
+			3: {ID: 3, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3, NextRetryAt: &future, CreatedAt: now.Add(-3 * time.Minute)},
+			4: {ID: 4, Status: domain.MessageStatusSending, UpdatedAt: now.Add(-10 * time.Minute), CreatedAt: now.Add(-2 * time.Minute)},
+			5: {ID: 5, Status: domain.MessageStatusSending, UpdatedAt: now, CreatedAt: now.Add(-time.Minute)},
+			6: {ID: 6, Status: domain.MessageStatusSent, CreatedAt: now.Add(-6 * time.Minute)},
+			7: {ID: 7, Status: domain.MessageStatusPending, CreatedAt: now},
+		},
+		nextID: 8,
+	}
+
+	claimed, err := repo.ClaimPending(ctx, 3, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	for i, id := range []int64{1, 2, 4} {
+		assert.Equal(t, id, claimed[i].ID)
+		assert.Equal(t, domain.MessageStatusSending, claimed[i].Status)
+	}
+
+	// Only the newest pending message is left; fresh claims and future retries are not claimable
+	claimed, err = repo.ClaimPending(ctx, 10, 5*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, int64(7), claimed[0].ID)
+}
+
+func TestInMemoryMessageRepository_LockUnsent(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Minute)
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusPending},
+			2: {ID: 2, Status: domain.MessageStatusSent},
+			3: {ID: 3, Status: domain.MessageStatusPending, ExpiresAt: &past},
+			4: {ID: 4, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3, NextRetryAt: &past},
+			5: {ID: 5, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3, NextRetryAt: &future},
+			6: {ID: 6, Status: domain.MessageStatusFailed, RetryCount: 3, MaxRetries: 3},
+		},
+		nextID: 7,
+	}
+
+	for _, id := range []int64{1, 4} {
+		locked, err := repo.LockUnsent(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, locked.ID)
+	}
+
+	for _, id := range []int64{2, 3, 5, 6, 7} {
+		_, err := repo.LockUnsent(ctx, id)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound, "message %d", id)
+	}
+}
+
+func TestInMemoryMessageRepository_MarkSentWithReference(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusPending},
+		},
+		nextID: 2,
+	}
+
+	require.NoError(t, repo.MarkSentWithReference(ctx, 1, "abc123"))
+	first, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, first.SentAt)
+
+	// A second mark leaves the first delivery's sent_at and reference in place
+	require.NoError(t, repo.MarkSentWithReference(ctx, 1, "def456"))
+	second, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, *first.SentAt, *second.SentAt)
+	require.NotNil(t, second.ProviderMessageID)
+	assert.Equal(t, "abc123", *second.ProviderMessageID)
+
+	assert.ErrorIs(t, repo.MarkSentWithReference(ctx, 2, "abc123"), domain.ErrMessageNotFound)
+}
+
+func TestInMemoryMessageRepository_MarkResent(t *testing.T) {
+	ctx := context.Background()
+	sentAt := time.Now().Add(-time.Hour)
+	reference := "abc123"
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusSent, SentAt: &sentAt, ProviderMessageID: &reference},
+			2: {ID: 2, Status: domain.MessageStatusFailed},
+		},
+		nextID: 3,
+	}
+
+	// Without a new reference the earlier one is kept
+	require.NoError(t, repo.MarkResent(ctx, 1, ""))
+	message, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusSent, message.Status)
+	assert.True(t, message.SentAt.After(sentAt))
+	assert.Equal(t, "abc123", *message.ProviderMessageID)
+
+	require.NoError(t, repo.MarkResent(ctx, 1, "def456"))
+	message, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "def456", *message.ProviderMessageID)
+
+	assert.ErrorIs(t, repo.MarkResent(ctx, 2, ""), domain.ErrMessageNotSent)
+	assert.ErrorIs(t, repo.MarkResent(ctx, 3, ""), domain.ErrMessageNotFound)
+}
+
+func TestInMemoryMessageRepository_Cancel(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &inMemoryMessageRepository{
+		messages: map[int64]*domain.Message{
+			1: {ID: 1, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3},
+			2: {ID: 2, Status: domain.MessageStatusSent},
+		},
+		nextID: 3,
+	}
+
+	require.NoError(t, repo.Cancel(ctx, 1))
+	assert.Equal(t, domain.MessageStatusCancelled, repo.messages[1].Status)
+	assert.Nil(t, repo.messages[1].NextRetryAt)
+
+	assert.ErrorIs(t, repo.Cancel(ctx, 1), domain.ErrMessageNotCancellable)
+	assert.ErrorIs(t, repo.Cancel(ctx, 2), domain.ErrMessageNotCancellable)
+	assert.ErrorIs(t, repo.Cancel(ctx, 999), domain.ErrMessageNotFound)
+}
+
+func TestInMemoryMessageRepository_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	newRepo := func() *inMemoryMessageRepository {
+		return &inMemoryMessageRepository{
+			messages: map[int64]*domain.Message{
+				1: {ID: 1, Status: domain.MessageStatusPending, ExpiresAt: &past, CreatedAt: now},
+				2: {ID: 2, Status: domain.MessageStatusPending, ExpiresAt: &future, CreatedAt: now},
+				3: {ID: 3, Status: domain.MessageStatusPending, CreatedAt: now},
+				4: {ID: 4, Status: domain.MessageStatusFailed, MaxRetries: 3, ExpiresAt: &past, NextRetryAt: &past, CreatedAt: now},
+				5: {ID: 5, Status: domain.MessageStatusSent, ExpiresAt: &past, CreatedAt: now},
+				6: {ID: 6, Status: domain.MessageStatusSending, ExpiresAt: &past, CreatedAt: now},
+			},
+			nextID: 7,
+		}
+	}
+	ids := func(messages []*domain.Message) []int64 {
+		var ids []int64
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+		return ids
+	}
+
+	t.Run("selection skips expired messages", func(t *testing.T) {
+		repo := newRepo()
+
+		unsent, err := repo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{2, 3}, ids(unsent))
+
+		failed, err := repo.GetFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, failed)
+
+		claimed, err := repo.ClaimPending(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{2, 3}, ids(claimed))
+	})
+
+	t.Run("overdue pending and failed messages expire", func(t *testing.T) {
+		repo := newRepo()
+
+		expired, err := repo.ExpireOverdue(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{1, 4}, ids(expired))
+
+		assert.Equal(t, domain.MessageStatusExpired, repo.messages[1].Status)
+		assert.Equal(t, domain.MessageStatusExpired, repo.messages[4].Status)
+		assert.Nil(t, repo.messages[4].NextRetryAt)
+		assert.Equal(t, domain.MessageStatusPending, repo.messages[2].Status)
+		assert.Equal(t, domain.MessageStatusPending, repo.messages[3].Status)
+		assert.Equal(t, domain.MessageStatusSent, repo.messages[5].Status)
+		assert.Equal(t, domain.MessageStatusSending, repo.messages[6].Status)
+
+		expired, err = repo.ExpireOverdue(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// leaderLockKey holds the ID of the scheduler replica currently leading
+const leaderLockKey = "scheduler:leader"
+
+// renewLeaderLockScript extends the lock's TTL only while owner still holds it
+var renewLeaderLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseOwnedLockScript deletes a lock only while owner still holds it
+var releaseOwnedLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLeaderLock takes the scheduler leader lock for owner with SET NX PX,
+// or renews it for ttl when owner already holds it. It reports whether owner
+// holds the lock afterwards.
+func (r *RedisCacheRepository) AcquireLeaderLock(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := r.client.SetNX(ctx, leaderLockKey, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leader lock: %w", err)
+	}
+	if acquired {
+		return true, nil
+	}
+
+	renewed, err := renewLeaderLockScript.Run(ctx, r.client, []string{leaderLockKey}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew leader lock: %w", err)
+	}
+
+	return renewed == 1, nil
+}
+
+// ReleaseLeaderLock gives up the scheduler leader lock if owner holds it, so
+// another replica can take over without waiting for it to expire
+func (r *RedisCacheRepository) ReleaseLeaderLock(ctx context.Context, owner string) error {
+	if err := releaseOwnedLockScript.Run(ctx, r.client, []string{leaderLockKey}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release leader lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCacheRepository_LeaderLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	acquired, err := cache.AcquireLeaderLock(ctx, "replica-a", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = cache.AcquireLeaderLock(ctx, "replica-b", 10*time.Second)
+	require.NoError(t, err)
+	assert.False(t, acquired, "a second replica must not take a held lock")
+
+	// Renewing extends the holder's TTL
+	server.FastForward(8 * time.Second)
+	acquired, err = cache.AcquireLeaderLock(ctx, "replica-a", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, 10*time.Second, server.TTL(leaderLockKey))
+
+	// Releasing by a non-holder leaves the lock alone
+	require.NoError(t, cache.ReleaseLeaderLock(ctx, "replica-b"))
+	owner, err := server.Get(leaderLockKey)
+	require.NoError(t, err)
+	assert.Equal(t, "replica-a", owner)
+
+	require.NoError(t, cache.ReleaseLeaderLock(ctx, "replica-a"))
+	acquired, err = cache.AcquireLeaderLock(ctx, "replica-b", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// An expired lock can be taken by another replica
+	server.FastForward(11 * time.Second)
+	acquired, err = cache.AcquireLeaderLock(ctx, "replica-a", 10*time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
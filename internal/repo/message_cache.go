@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+// MessageCache keeps copies of messages whose state no longer changes so
+// reads can skip the database
+type MessageCache interface {
+	// GetCachedMessage returns the cached copy of a message, or nil on a miss
+	GetCachedMessage(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// CacheMessage stores a copy of message until the cache TTL expires
+	CacheMessage(ctx context.Context, message *domain.Message) error
+
+	// DeleteCachedMessage drops the cached copy of a message, if any
+	DeleteCachedMessage(ctx context.Context, messageID int64) error
+}
+
+// RecentlySentCache keeps the IDs of the most recently sent messages, newest
+// first, so the first pages of sent messages can skip the database
+type RecentlySentCache interface {
+	// AddRecentlySentMessage puts messageID at the head of the list, removing
+	// any earlier entry for it and keeping at most window IDs
+	AddRecentlySentMessage(ctx context.Context, messageID int64, window int) error
+
+	// GetRecentlySentMessages returns up to limit IDs, newest first. The list
+	// is empty while the cache is cold.
+	GetRecentlySentMessages(ctx context.Context, limit int) ([]int, error)
+
+	// CacheRecentlySentMessages replaces the list with messageIDs
+	CacheRecentlySentMessages(ctx context.Context, messageIDs []int) error
+}
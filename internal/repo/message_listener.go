@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// NewMessageChannel is the PostgreSQL notification channel signalled when
+// messages are created by a repository built WithNewMessageNotify
+const NewMessageChannel = "new_message"
+
+// Reconnect backoff of the listener connection, and how often it is pinged so
+// a silently dropped connection is noticed
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	listenerPingInterval = 90 * time.Second
+)
+
+// NewMessageListener turns new_message notifications into wakeups. Bursts
+// collapse into a single pending wakeup, so a slow consumer is never flooded.
+type NewMessageListener struct {
+	listener *pq.Listener
+	logger   *slog.Logger
+	wakeups  chan struct{}
+	done     chan struct{}
+}
+
+// ListenForNewMessages opens a dedicated connection to databaseURL and listens
+// on NewMessageChannel until Close. The connection is re-established after it
+// drops; a wakeup is sent on reconnect since notifications may have been missed.
+func ListenForNewMessages(databaseURL string, logger *slog.Logger) (*NewMessageListener, error) {
+	listener := pq.NewListener(databaseURL, listenerMinReconnect, listenerMaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("New message listener connection problem", "event", event, "error", err)
+		}
+	})
+	if err := listener.Listen(NewMessageChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", NewMessageChannel, err)
+	}
+
+	l := &NewMessageListener{
+		listener: listener,
+		logger:   logger,
+		wakeups:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l, nil
+}
+
+// Wakeups receives after messages were created
+func (l *NewMessageListener) Wakeups() <-chan struct{} {
+	return l.wakeups
+}
+
+// Close stops listening and closes the connection
+func (l *NewMessageListener) Close() error {
+	err := l.listener.Close()
+	<-l.done
+	return err
+}
+
+// run forwards notifications as wakeups until the listener is closed
+func (l *NewMessageListener) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case _, ok := <-l.listener.Notify:
+			if !ok {
+				return
+			}
+			select {
+			case l.wakeups <- struct{}{}:
+			default:
+			}
+		case <-ticker.C:
+			if err := l.listener.Ping(); err != nil {
+				l.logger.Debug("New message listener ping failed", "error", err)
+			}
+		}
+	}
+}
@@ -3,9 +3,14 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/lib/pq"
 )
 
 // MessageRepository defines the interface for message data operations
@@ -13,33 +18,269 @@ type MessageRepository interface {
 	// Create creates a new message in the database
 	Create(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error)
 
-	// SelectUnsentForUpdate selects unsent messages for processing with row-level locking
+	// CreateBatch creates all messages in a single statement, so either every
+	// message is stored or none is, and returns them in request order
+	CreateBatch(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]*domain.Message, error)
+
+	// SelectUnsentForUpdate selects unsent messages for processing with
+	// row-level locking, skipping messages whose expiry has passed
 	SelectUnsentForUpdate(ctx context.Context, limit int) ([]*domain.Message, error)
 
-	// MarkSent marks a message as sent
+	// LockUnsent locks a message for delivery until the surrounding
+	// transaction ends and returns its current state. A message that is no
+	// longer due, or that another transaction holds, returns
+	// domain.ErrMessageNotFound.
+	LockUnsent(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// LockForSend locks a message of any status until the surrounding
+	// transaction ends and returns its current state, waiting for a
+	// transaction that holds it to finish first
+	LockForSend(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// MarkSent marks a message as sent; a message that is already sent is
+	// left unchanged and is not an error
 	MarkSent(ctx context.Context, messageID int64) error
 
-	// MarkFailed marks a message as failed with error details
-	MarkFailed(ctx context.Context, messageID int64, errorMsg string) error
+	// MarkSentWithReference marks a message as sent and stores the provider's
+	// message ID; like MarkSent it leaves a message that is already sent
+	// unchanged
+	MarkSentWithReference(ctx context.Context, messageID int64, providerMessageID string) error
+
+	// MarkResent records that a sent message was delivered again: it moves
+	// sent_at to now and stores the provider's message ID when there is one.
+	// A message that is not sent returns domain.ErrMessageNotSent.
+	MarkResent(ctx context.Context, messageID int64, providerMessageID string) error
+
+	// MarkFailed marks a message as failed with error details and schedules its
+	// next retry retryDelay after the failure
+	MarkFailed(ctx context.Context, messageID int64, errorMsg string, retryDelay time.Duration) error
 
 	// GetByID retrieves a message by its ID
 	GetByID(ctx context.Context, messageID int64) (*domain.Message, error)
 
-	// GetSentMessages retrieves sent messages with pagination
-	GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+	// GetByIDs retrieves the messages with the given IDs in no particular
+	// order, leaving out IDs that do not exist
+	GetByIDs(ctx context.Context, messageIDs []int64) ([]*domain.Message, error)
+
+	// GetByIdempotencyKey retrieves the message created with the given
+	// idempotency key, or domain.ErrMessageNotFound
+	GetByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error)
+
+	// GetSentMessages retrieves sent messages in the given order with pagination
+	GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error)
+
+	// ListMessages retrieves messages with the given status, or of every status
+	// when status is empty, newest first with pagination
+	ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error)
 
-	// GetFailedMessages retrieves failed messages that can be retried
+	// ListAfterCursor retrieves up to limit messages with the given status, or
+	// of every status when status is empty, whose ID is below cursorID, highest
+	// ID first. A cursorID of 0 starts from the newest message; passing the last
+	// returned ID fetches the next page without gaps or repeats as rows change.
+	ListAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, error)
+
+	// GetByRecipient retrieves messages sent to exactly the given recipient,
+	// newest first with pagination
+	GetByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error)
+
+	// ListByDateRange retrieves messages created between from and to inclusive,
+	// newest first with pagination
+	ListByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error)
+
+	// GetFailedMessages retrieves failed messages that can be retried, highest
+	// priority first and then by when their retry became due
 	GetFailedMessages(ctx context.Context, limit int) ([]*domain.Message, error)
+
+	// GetFailedMessagesPaginated retrieves every failed message, most recently
+	// failed first with pagination, whether or not it is due for a retry
+	GetFailedMessagesPaginated(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+
+	// MarkDeadLetter moves a message that exhausted its retries to the dead-letter state
+	MarkDeadLetter(ctx context.Context, messageID int64) error
+
+	// GetDeadLetterMessages retrieves dead-lettered messages, most recently
+	// failed first, with pagination
+	GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+
+	// Requeue resets a failed or dead-lettered message back to pending with a
+	// fresh retry budget. A sent message returns domain.ErrMessageAlreadySent
+	// and one in any other status domain.ErrMessageNotRequeueable.
+	Requeue(ctx context.Context, messageID int64) error
+
+	// Cancel marks an undelivered message as cancelled
+	Cancel(ctx context.Context, messageID int64) error
+
+	// ExpireOverdue marks pending and failed messages whose expiry has passed
+	// as expired and returns them
+	ExpireOverdue(ctx context.Context) ([]*domain.Message, error)
+
+	// UpdatePending applies update to a message that is still pending and
+	// returns the updated message, or domain.ErrMessageNotPending once it
+	// has moved on
+	UpdatePending(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error)
+
+	// CountByStatus counts messages per status, including every known status
+	// with no messages as zero
+	CountByStatus(ctx context.Context) (map[domain.MessageStatus]int, error)
+
+	// CountWithStatus counts the messages with status
+	CountWithStatus(ctx context.Context, status domain.MessageStatus) (int, error)
+
+	// CountDeliveryOutcomes counts sent and dead-lettered messages per webhook URL
+	// that reached their terminal state at or after since
+	CountDeliveryOutcomes(ctx context.Context, since time.Time) ([]*domain.DeliveryOutcome, error)
+
+	// GetRecentMessages retrieves messages of any status created within the last
+	// since duration, newest first
+	GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error)
+
+	// ClaimPending atomically moves up to limit messages that are due for
+	// delivery to the sending status for an external worker and returns them.
+	// Messages claimed longer than lease ago without an outcome are claimable
+	// again.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.Message, error)
+
+	// CountByRecipientSince counts messages of any status created for recipient
+	// at or after since
+	CountByRecipientSince(ctx context.Context, recipient string, since time.Time) (int, error)
+
+	// RecordAttempt stores one webhook request made for a message: the HTTP
+	// status (0 when no response arrived), how long it took and the error it
+	// failed with, nil on success
+	RecordAttempt(ctx context.Context, messageID int64, statusCode int, durationMs int64, attemptErr error) error
+
+	// ListAttempts retrieves the webhook requests recorded for a message, oldest
+	// first
+	ListAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error)
+
+	// ArchiveOlderThan moves messages sent before cutoff to cold storage and
+	// returns how many were moved
+	ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error)
+
+	// DeleteOlderThan permanently deletes messages with the given status last
+	// updated before cutoff, with their recorded webhook attempts, and
+	// returns how many messages were deleted
+	DeleteOlderThan(ctx context.Context, status domain.MessageStatus, cutoff time.Time) (int, error)
+
+	// WithTx runs fn with a repository whose operations share one transaction,
+	// so a row lock taken by LockUnsent is held until the status update
+	// commits. The transaction commits when fn returns nil and rolls
+	// back otherwise. WithTx on a repository already in a transaction runs fn
+	// in a savepoint of it, so a failure in fn rolls back only fn's work and
+	// the transaction stays usable.
+	WithTx(ctx context.Context, fn func(txRepo MessageRepository) error) error
+}
+
+// dbtx is the part of *sql.DB and *sql.Tx the repository queries through
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
+// messageColumns lists the columns read by scanMessage, in scan order
+const messageColumns = `id, recipient, content, webhook_url, status, retry_count, max_retries,
+		       created_at, updated_at, sent_at, failed_at, error_message, next_retry_at,
+		       provider_message_id, priority, resent_from, idempotency_key, channel, payload_template,
+		       expires_at`
+
+// notExpired matches messages without an expiry or whose expiry is still ahead
+const notExpired = `(expires_at IS NULL OR expires_at > NOW())`
+
+// dueForDelivery matches pending messages and failed ones whose retry is due,
+// with $1 bound to the pending status and $2 to the failed status
+const dueForDelivery = `(status = $1 OR (status = $2 AND retry_count < max_retries AND (next_retry_at IS NULL OR next_retry_at <= NOW())))
+		  AND ` + notExpired
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// idempotencyKeyIndex is the unique index that deduplicates idempotency keys
+const idempotencyKeyIndex = "idx_messages_idempotency_key"
+
+// archiveBatchSize bounds how many messages ArchiveOlderThan moves per transaction
+const archiveBatchSize = 500
+
+// deleteBatchSize bounds how many messages DeleteOlderThan removes per statement
+const deleteBatchSize = 1000
+
 // messageRepository implements MessageRepository using PostgreSQL
 type messageRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	q      dbtx // db, or the transaction of a repository scoped by WithTx
+	inTx   bool
+	notify bool // NOTIFY NewMessageChannel after creating messages
+}
+
+// MessageRepositoryOption configures optional repository behavior
+type MessageRepositoryOption func(*messageRepository)
+
+// WithNewMessageNotify makes Create and CreateBatch signal NewMessageChannel,
+// so a NewMessageListener can wake the scheduler without waiting for its next tick
+func WithNewMessageNotify() MessageRepositoryOption {
+	return func(r *messageRepository) {
+		r.notify = true
+	}
 }
 
 // NewMessageRepository creates a new message repository
-func NewMessageRepository(db *sql.DB) MessageRepository {
-	return &messageRepository{db: db}
+func NewMessageRepository(db *sql.DB, opts ...MessageRepositoryOption) MessageRepository {
+	r := &messageRepository{db: db, q: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithTx runs fn in a transaction. ArchiveOlderThan keeps committing its own
+// batches even on a repository scoped to a transaction.
+func (r *messageRepository) WithTx(ctx context.Context, fn func(txRepo MessageRepository) error) error {
+	if r.inTx {
+		return r.withSavepoint(ctx, fn)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&messageRepository{db: r.db, q: tx, inTx: true, notify: r.notify}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the repository's transaction.
+// PostgreSQL aborts a whole transaction on its first failed statement; rolling
+// back to the savepoint undoes that, so the caller can retry or carry on.
+func (r *messageRepository) withSavepoint(ctx context.Context, fn func(txRepo MessageRepository) error) error {
+	if _, err := r.q.ExecContext(ctx, "SAVEPOINT nested_tx"); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(r); err != nil {
+		if _, rollbackErr := r.q.ExecContext(ctx, "ROLLBACK TO SAVEPOINT nested_tx"); rollbackErr != nil {
+			return fmt.Errorf("%w (rolling back to savepoint failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if _, err := r.q.ExecContext(ctx, "RELEASE SAVEPOINT nested_tx"); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+
+	return nil
 }
 
 // Create creates a new message in the database
@@ -50,68 +291,125 @@ func (r *messageRepository) Create(ctx context.Context, req *domain.CreateMessag
 	}
 
 	query := `
-		INSERT INTO messages (recipient, content, webhook_url, max_retries, status, retry_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-		RETURNING id, recipient, content, webhook_url, status, retry_count, max_retries, created_at, updated_at, sent_at, failed_at, error_message
+		INSERT INTO messages (recipient, content, webhook_url, max_retries, status, retry_count, priority, resent_from, idempotency_key, channel, payload_template, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		RETURNING ` + messageColumns + `
 	`
 
-	var msg domain.Message
-	var sentAt, failedAt sql.NullTime
-	var errorMessage sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query,
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query,
 		req.Recipient,
 		req.Content,
 		req.WebhookURL,
 		maxRetries,
 		domain.MessageStatusPending,
 		0,
-	).Scan(
-		&msg.ID,
-		&msg.Recipient,
-		&msg.Content,
-		&msg.WebhookURL,
-		&msg.Status,
-		&msg.RetryCount,
-		&msg.MaxRetries,
-		&msg.CreatedAt,
-		&msg.UpdatedAt,
-		&sentAt,
-		&failedAt,
-		&errorMessage,
-	)
-
+		req.Priority,
+		req.ResentFrom,
+		req.IdempotencyKey,
+		channelOrDefault(req.Channel),
+		req.PayloadTemplate,
+		req.ExpiresAt,
+	))
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == idempotencyKeyIndex {
+			return nil, fmt.Errorf("failed to create message: %w", domain.ErrDuplicateIdempotencyKey)
+		}
 		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
 
-	// Handle nullable fields
-	if sentAt.Valid {
-		msg.SentAt = &sentAt.Time
+	r.notifyNewMessages(ctx)
+	return msg, nil
+}
+
+// notifyNewMessages signals NewMessageChannel when notifications are enabled.
+// Inside a transaction PostgreSQL delivers the notification on commit. A
+// failed notification is ignored: the message is already stored and the
+// scheduler's next tick picks it up anyway.
+func (r *messageRepository) notifyNewMessages(ctx context.Context) {
+	if !r.notify {
+		return
 	}
-	if failedAt.Valid {
-		msg.FailedAt = &failedAt.Time
+	_, _ = r.q.ExecContext(ctx, `SELECT pg_notify($1, '')`, NewMessageChannel)
+}
+
+// CreateBatch creates all messages in a single multi-row INSERT
+func (r *messageRepository) CreateBatch(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]*domain.Message, error) {
+	if len(reqs) == 0 {
+		return nil, nil
 	}
-	if errorMessage.Valid {
-		msg.ErrorMessage = &errorMessage.String
+
+	const columnsPerRow = 11
+	values := make([]string, 0, len(reqs))
+	args := make([]interface{}, 0, len(reqs)*columnsPerRow)
+	for i, req := range reqs {
+		maxRetries := req.MaxRetries
+		if maxRetries == 0 {
+			maxRetries = 3 // Default max retries
+		}
+
+		n := i * columnsPerRow
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, NOW(), NOW())",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11))
+		args = append(args,
+			req.Recipient,
+			req.Content,
+			req.WebhookURL,
+			maxRetries,
+			domain.MessageStatusPending,
+			0,
+			req.Priority,
+			req.ResentFrom,
+			channelOrDefault(req.Channel),
+			req.PayloadTemplate,
+			req.ExpiresAt,
+		)
 	}
 
-	return &msg, nil
+	query := `
+		INSERT INTO messages (recipient, content, webhook_url, max_retries, status, retry_count, priority, resent_from, channel, payload_template, expires_at, created_at, updated_at)
+		VALUES ` + strings.Join(values, ", ") + `
+		RETURNING ` + messageColumns + `
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*domain.Message, 0, len(reqs))
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan created message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over created messages: %w", err)
+	}
+
+	// IDs come from a sequence in VALUES order; RETURNING order is not guaranteed
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+
+	r.notifyNewMessages(ctx)
+	return messages, nil
 }
 
-// SelectUnsentForUpdate selects unsent messages for processing with row-level locking
+// SelectUnsentForUpdate selects unsent messages for processing with row-level
+// locking. Expired messages are left for ExpireOverdue.
 func (r *messageRepository) SelectUnsentForUpdate(ctx context.Context, limit int) ([]*domain.Message, error) {
 	query := `
-		SELECT id, recipient, content, webhook_url, status, retry_count, max_retries, 
-		       created_at, updated_at, sent_at, failed_at, error_message
+		SELECT ` + messageColumns + `
 		FROM messages 
-		WHERE status = $1 OR (status = $2 AND retry_count < max_retries)
+		WHERE ` + dueForDelivery + `
 		ORDER BY created_at ASC
 		LIMIT $3
 		FOR UPDATE SKIP LOCKED
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, domain.MessageStatusPending, domain.MessageStatusFailed, limit)
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusPending, domain.MessageStatusFailed, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select unsent messages: %w", err)
 	}
@@ -119,58 +417,134 @@ func (r *messageRepository) SelectUnsentForUpdate(ctx context.Context, limit int
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var msg domain.Message
-		var sentAt, failedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		err := rows.Scan(
-			&msg.ID,
-			&msg.Recipient,
-			&msg.Content,
-			&msg.WebhookURL,
-			&msg.Status,
-			&msg.RetryCount,
-			&msg.MaxRetries,
-			&msg.CreatedAt,
-			&msg.UpdatedAt,
-			&sentAt,
-			&failedAt,
-			&errorMessage,
-		)
+		msg, err := scanMessage(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
 
-		// Handle nullable fields
-		if sentAt.Valid {
-			msg.SentAt = &sentAt.Time
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// LockForSend locks one message whatever its status. Unlike LockUnsent it
+// waits for another instance's delivery to commit, so the caller sees the
+// outcome of that delivery rather than sending alongside it.
+func (r *messageRepository) LockForSend(ctx context.Context, messageID int64) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query, messageID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
 		}
-		if failedAt.Valid {
-			msg.FailedAt = &failedAt.Time
+		return nil, fmt.Errorf("failed to lock message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// LockUnsent locks one message that is still due for delivery. SKIP LOCKED
+// makes a message another instance is delivering look like one that is no
+// longer due, so the caller skips it instead of waiting.
+func (r *messageRepository) LockUnsent(ctx context.Context, messageID int64) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = $3
+		  AND ` + dueForDelivery + `
+		FOR UPDATE SKIP LOCKED
+	`
+
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query, domain.MessageStatusPending, domain.MessageStatusFailed, messageID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message with ID %d is not due for delivery: %w", messageID, domain.ErrMessageNotFound)
 		}
-		if errorMessage.Valid {
-			msg.ErrorMessage = &errorMessage.String
+		return nil, fmt.Errorf("failed to lock message: %w", err)
+	}
+
+	return msg, nil
+}
+
+// ClaimPending moves due messages to sending in a single statement, so
+// concurrent workers never claim the same message. updated_at records when a
+// message was claimed and so when its lease runs out.
+func (r *messageRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.Message, error) {
+	query := `
+		UPDATE messages
+		SET status = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM messages
+			WHERE (status = $2
+			   OR (status = $3 AND retry_count < max_retries AND (next_retry_at IS NULL OR next_retry_at <= NOW()))
+			   OR (status = $1 AND updated_at <= NOW() - ($4 * INTERVAL '1 millisecond')))
+			  AND ` + notExpired + `
+			ORDER BY created_at ASC, id ASC
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + messageColumns + `
+	`
+
+	rows, err := r.q.QueryContext(ctx, query,
+		domain.MessageStatusSending,
+		domain.MessageStatusPending,
+		domain.MessageStatusFailed,
+		lease.Milliseconds(),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan claimed message: %w", err)
 		}
 
-		messages = append(messages, &msg)
+		messages = append(messages, msg)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over messages: %w", err)
+		return nil, fmt.Errorf("error iterating over claimed messages: %w", err)
 	}
 
+	// RETURNING does not preserve the subquery order
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
 	return messages, nil
 }
 
-// MarkSent marks a message as sent
+// MarkSent marks a message as sent. Marking a message that is already sent,
+// e.g. by a concurrent worker, is a no-op that keeps the original sent_at.
 func (r *messageRepository) MarkSent(ctx context.Context, messageID int64) error {
 	query := `
 		UPDATE messages 
 		SET status = $1, sent_at = NOW(), updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND status != $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, domain.MessageStatusSent, messageID)
+	result, err := r.q.ExecContext(ctx, query, domain.MessageStatusSent, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to mark message as sent: %w", err)
 	}
@@ -181,23 +555,30 @@ func (r *messageRepository) MarkSent(ctx context.Context, messageID int64) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("message with ID %d not found", messageID)
+		var exists bool
+		err := r.q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM messages WHERE id = $1)`, messageID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check message existence: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
 	}
 
 	return nil
 }
 
-// MarkFailed marks a message as failed with error details
-func (r *messageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string) error {
+// MarkSentWithReference marks a message as sent and stores the provider's message ID
+func (r *messageRepository) MarkSentWithReference(ctx context.Context, messageID int64, providerMessageID string) error {
 	query := `
 		UPDATE messages 
-		SET status = $1, error_message = $2, failed_at = NOW(), updated_at = NOW(), retry_count = retry_count + 1
-		WHERE id = $3
+		SET status = $1, sent_at = NOW(), provider_message_id = $2, updated_at = NOW()
+		WHERE id = $3 AND status != $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query, domain.MessageStatusFailed, errorMsg, messageID)
+	result, err := r.q.ExecContext(ctx, query, domain.MessageStatusSent, providerMessageID, messageID)
 	if err != nil {
-		return fmt.Errorf("failed to mark message as failed: %w", err)
+		return fmt.Errorf("failed to mark message as sent: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -206,123 +587,195 @@ func (r *messageRepository) MarkFailed(ctx context.Context, messageID int64, err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("message with ID %d not found", messageID)
+		var exists bool
+		err := r.q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM messages WHERE id = $1)`, messageID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check message existence: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
 	}
 
 	return nil
 }
 
-// GetByID retrieves a message by its ID
-func (r *messageRepository) GetByID(ctx context.Context, messageID int64) (*domain.Message, error) {
+// MarkResent moves a sent message's sent_at to now, keeping its provider
+// message ID unless a new one is given
+func (r *messageRepository) MarkResent(ctx context.Context, messageID int64, providerMessageID string) error {
 	query := `
-		SELECT id, recipient, content, webhook_url, status, retry_count, max_retries, 
-		       created_at, updated_at, sent_at, failed_at, error_message
-		FROM messages 
-		WHERE id = $1
+		UPDATE messages 
+		SET sent_at = NOW(), provider_message_id = COALESCE(NULLIF($1, ''), provider_message_id), updated_at = NOW()
+		WHERE id = $2 AND status = $3
 	`
 
-	var msg domain.Message
-	var sentAt, failedAt sql.NullTime
-	var errorMessage sql.NullString
-
-	err := r.db.QueryRowContext(ctx, query, messageID).Scan(
-		&msg.ID,
-		&msg.Recipient,
-		&msg.Content,
-		&msg.WebhookURL,
-		&msg.Status,
-		&msg.RetryCount,
-		&msg.MaxRetries,
-		&msg.CreatedAt,
-		&msg.UpdatedAt,
-		&sentAt,
-		&failedAt,
-		&errorMessage,
-	)
+	result, err := r.q.ExecContext(ctx, query, providerMessageID, messageID, domain.MessageStatusSent)
+	if err != nil {
+		return fmt.Errorf("failed to mark message as resent: %w", err)
+	}
 
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("message with ID %d not found", messageID)
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		var exists bool
+		err := r.q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM messages WHERE id = $1)`, messageID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check message existence: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get message: %w", err)
+		if !exists {
+			return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
+		return fmt.Errorf("message with ID %d: %w", messageID, domain.ErrMessageNotSent)
 	}
 
-	// Handle nullable fields
-	if sentAt.Valid {
-		msg.SentAt = &sentAt.Time
+	return nil
+}
+
+// MarkFailed marks a message as failed with error details and schedules its
+// next retry retryDelay after the failure
+func (r *messageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string, retryDelay time.Duration) error {
+	query := `
+		UPDATE messages 
+		SET status = $1, error_message = $2, failed_at = NOW(), updated_at = NOW(), retry_count = retry_count + 1,
+		    next_retry_at = NOW() + ($3 * INTERVAL '1 millisecond')
+		WHERE id = $4
+	`
+
+	result, err := r.q.ExecContext(ctx, query, domain.MessageStatusFailed, errorMsg, retryDelay.Milliseconds(), messageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark message as failed: %w", err)
 	}
-	if failedAt.Valid {
-		msg.FailedAt = &failedAt.Time
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
-	if errorMessage.Valid {
-		msg.ErrorMessage = &errorMessage.String
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
 	}
 
-	return &msg, nil
+	return nil
 }
 
-// GetSentMessages retrieves sent messages with pagination
-func (r *messageRepository) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
-	// First, get the total count
-	countQuery := `SELECT COUNT(*) FROM messages WHERE status = $1`
-	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, domain.MessageStatusSent).Scan(&total)
+// GetByID retrieves a message by its ID
+func (r *messageRepository) GetByID(ctx context.Context, messageID int64) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE id = $1
+	`
+
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query, messageID))
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count sent messages: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
 
-	// Then get the paginated results
+	return msg, nil
+}
+
+// GetByIDs retrieves the messages with the given IDs in one query
+func (r *messageRepository) GetByIDs(ctx context.Context, messageIDs []int64) ([]*domain.Message, error) {
 	query := `
-		SELECT id, recipient, content, webhook_url, status, retry_count, max_retries, 
-		       created_at, updated_at, sent_at, failed_at, error_message
-		FROM messages 
-		WHERE status = $1
-		ORDER BY sent_at DESC
-		LIMIT $2 OFFSET $3
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = ANY($1)
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, domain.MessageStatusSent, limit, offset)
+	rows, err := r.q.QueryContext(ctx, query, pq.Array(messageIDs))
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get sent messages: %w", err)
+		return nil, fmt.Errorf("failed to get messages: %w", err)
 	}
 	defer rows.Close()
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var msg domain.Message
-		var sentAt, failedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		err := rows.Scan(
-			&msg.ID,
-			&msg.Recipient,
-			&msg.Content,
-			&msg.WebhookURL,
-			&msg.Status,
-			&msg.RetryCount,
-			&msg.MaxRetries,
-			&msg.CreatedAt,
-			&msg.UpdatedAt,
-			&sentAt,
-			&failedAt,
-			&errorMessage,
-		)
+		msg, err := scanMessage(rows)
 		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan sent message: %w", err)
+			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
+		messages = append(messages, msg)
+	}
 
-		// Handle nullable fields
-		if sentAt.Valid {
-			msg.SentAt = &sentAt.Time
-		}
-		if failedAt.Valid {
-			msg.FailedAt = &failedAt.Time
-		}
-		if errorMessage.Valid {
-			msg.ErrorMessage = &errorMessage.String
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetByIdempotencyKey retrieves the message created with the given idempotency key
+func (r *messageRepository) GetByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE idempotency_key = $1
+	`
+
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("message with idempotency key %q not found: %w", key, domain.ErrMessageNotFound)
+		}
+		return nil, fmt.Errorf("failed to get message by idempotency key: %w", err)
+	}
+
+	return msg, nil
+}
+
+// sentMessagesOrderBy holds the ORDER BY clause of each allowed sent message
+// sort. Only these fixed strings ever reach the query text.
+var sentMessagesOrderBy = map[domain.MessageSort]string{
+	{Field: domain.SortBySentAt, Order: domain.SortDesc}:    "sent_at DESC",
+	{Field: domain.SortBySentAt, Order: domain.SortAsc}:     "sent_at ASC",
+	{Field: domain.SortByCreatedAt, Order: domain.SortDesc}: "created_at DESC",
+	{Field: domain.SortByCreatedAt, Order: domain.SortAsc}:  "created_at ASC",
+}
+
+// GetSentMessages retrieves sent messages in the given order with pagination
+func (r *messageRepository) GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error) {
+	orderBy, ok := sentMessagesOrderBy[order]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sent message order %q %q", order.Field, order.Order)
+	}
+
+	// First, get the total count
+	countQuery := `SELECT COUNT(*) FROM messages WHERE status = $1`
+	var total int
+	err := r.q.QueryRowContext(ctx, countQuery, domain.MessageStatusSent).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count sent messages: %w", err)
+	}
+
+	// Then get the paginated results
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE status = $1
+		ORDER BY ` + orderBy + `
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusSent, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get sent messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan sent message: %w", err)
+		}
 
-		messages = append(messages, &msg)
+		messages = append(messages, msg)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -332,18 +785,175 @@ func (r *messageRepository) GetSentMessages(ctx context.Context, offset, limit i
 	return messages, total, nil
 }
 
-// GetFailedMessages retrieves failed messages that can be retried
+// ListMessages retrieves messages filtered by status, newest first with pagination
+func (r *messageRepository) ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error) {
+	// An empty status matches every row
+	filter := `($1 = '' OR status = $1)`
+
+	var total int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE `+filter, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE ` + filter + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// ListAfterCursor retrieves a page of messages by keyset on the primary key, so
+// deep pages cost as little as the first one
+func (r *messageRepository) ListAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, status, cursorID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages after cursor: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*domain.Message{}
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetByRecipient retrieves messages for a recipient, newest first with pagination
+func (r *messageRepository) GetByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error) {
+	var total int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE recipient = $1`, recipient).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages for recipient: %w", err)
+	}
+
+	// Served by idx_messages_recipient_created_at
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE recipient = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, recipient, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages for recipient: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over messages for recipient: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// ListByDateRange retrieves messages created between from and to inclusive,
+// newest first with pagination
+func (r *messageRepository) ListByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error) {
+	var total int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE created_at BETWEEN $1 AND $2`, from, to).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count messages in date range: %w", err)
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE created_at BETWEEN $1 AND $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, from, to, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages in date range: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over messages in date range: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetFailedMessages retrieves failed messages that can be retried, highest
+// priority first and then by when their retry became due
 func (r *messageRepository) GetFailedMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
 	query := `
-		SELECT id, recipient, content, webhook_url, status, retry_count, max_retries, 
-		       created_at, updated_at, sent_at, failed_at, error_message
+		SELECT ` + messageColumns + `
 		FROM messages 
-		WHERE status = $1 AND retry_count < max_retries
-		ORDER BY failed_at ASC
+		WHERE status = $1 AND retry_count < max_retries AND (next_retry_at IS NULL OR next_retry_at <= NOW())
+		  AND ` + notExpired + `
+		ORDER BY priority DESC, next_retry_at ASC NULLS FIRST, id ASC
 		LIMIT $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, domain.MessageStatusFailed, limit)
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusFailed, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get failed messages: %w", err)
 	}
@@ -351,45 +961,669 @@ func (r *messageRepository) GetFailedMessages(ctx context.Context, limit int) ([
 
 	var messages []*domain.Message
 	for rows.Next() {
-		var msg domain.Message
-		var sentAt, failedAt sql.NullTime
-		var errorMessage sql.NullString
-
-		err := rows.Scan(
-			&msg.ID,
-			&msg.Recipient,
-			&msg.Content,
-			&msg.WebhookURL,
-			&msg.Status,
-			&msg.RetryCount,
-			&msg.MaxRetries,
-			&msg.CreatedAt,
-			&msg.UpdatedAt,
-			&sentAt,
-			&failedAt,
-			&errorMessage,
-		)
+		msg, err := scanMessage(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan failed message: %w", err)
 		}
 
-		// Handle nullable fields
-		if sentAt.Valid {
-			msg.SentAt = &sentAt.Time
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over failed messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetFailedMessagesPaginated retrieves every failed message, most recently
+// failed first with pagination, whether or not it is due for a retry
+func (r *messageRepository) GetFailedMessagesPaginated(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	countQuery := `SELECT COUNT(*) FROM messages WHERE status = $1`
+	var total int
+	err := r.q.QueryRowContext(ctx, countQuery, domain.MessageStatusFailed).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count failed messages: %w", err)
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE status = $1
+		ORDER BY failed_at DESC NULLS LAST, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusFailed, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get failed messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan failed message: %w", err)
 		}
-		if failedAt.Valid {
-			msg.FailedAt = &failedAt.Time
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over failed messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// MarkDeadLetter moves a message that exhausted its retries to the dead-letter state
+func (r *messageRepository) MarkDeadLetter(ctx context.Context, messageID int64) error {
+	query := `
+		UPDATE messages 
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	result, err := r.q.ExecContext(ctx, query, domain.MessageStatusDeadLetter, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark message as dead-letter: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+	}
+
+	return nil
+}
+
+// GetDeadLetterMessages retrieves dead-lettered messages, most recently failed
+// first with ties broken by ID so pages stay stable, with pagination
+func (r *messageRepository) GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	countQuery := `SELECT COUNT(*) FROM messages WHERE status = $1`
+	var total int
+	err := r.q.QueryRowContext(ctx, countQuery, domain.MessageStatusDeadLetter).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count dead-letter messages: %w", err)
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE status = $1
+		ORDER BY failed_at DESC NULLS LAST, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusDeadLetter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get dead-letter messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan dead-letter message: %w", err)
 		}
-		if errorMessage.Valid {
-			msg.ErrorMessage = &errorMessage.String
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over dead-letter messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// Requeue resets an unsent message back to pending with a fresh retry budget
+func (r *messageRepository) Requeue(ctx context.Context, messageID int64) error {
+	query := `
+		UPDATE messages 
+		SET status = $1, retry_count = 0, failed_at = NULL, error_message = NULL, next_retry_at = NULL, updated_at = NOW()
+		WHERE id = $2 AND status IN ($3, $4)
+	`
+
+	result, err := r.q.ExecContext(ctx, query,
+		domain.MessageStatusPending,
+		messageID,
+		domain.MessageStatusFailed,
+		domain.MessageStatusDeadLetter,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to requeue message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish a missing message from one in a status that cannot be requeued
+		var status domain.MessageStatus
+		err := r.q.QueryRowContext(ctx, `SELECT status FROM messages WHERE id = $1`, messageID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get message status: %w", err)
+		}
+		if status == domain.MessageStatusSent {
+			return fmt.Errorf("message with ID %d cannot be requeued: %w", messageID, domain.ErrMessageAlreadySent)
+		}
+		return fmt.Errorf("message with ID %d is %s: %w", messageID, status, domain.ErrMessageNotRequeueable)
+	}
+
+	return nil
+}
+
+// Cancel marks a message that has not been delivered yet as cancelled, so the
+// scheduler and external workers no longer pick it up
+func (r *messageRepository) Cancel(ctx context.Context, messageID int64) error {
+	query := `
+		UPDATE messages
+		SET status = $1, next_retry_at = NULL, updated_at = NOW()
+		WHERE id = $2 AND status IN ($3, $4, $5)
+	`
+
+	result, err := r.q.ExecContext(ctx, query,
+		domain.MessageStatusCancelled,
+		messageID,
+		domain.MessageStatusPending,
+		domain.MessageStatusFailed,
+		domain.MessageStatusSending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel message: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Distinguish a missing message from one that is already final
+		var status domain.MessageStatus
+		err := r.q.QueryRowContext(ctx, `SELECT status FROM messages WHERE id = $1`, messageID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get message status: %w", err)
+		}
+		return fmt.Errorf("message with ID %d is %s: %w", messageID, status, domain.ErrMessageNotCancellable)
+	}
+
+	return nil
+}
+
+// ExpireOverdue marks pending and failed messages whose expiry has passed as
+// expired in a single statement. Messages claimed by an external worker are
+// left alone, since their delivery may already be under way.
+func (r *messageRepository) ExpireOverdue(ctx context.Context) ([]*domain.Message, error) {
+	query := `
+		UPDATE messages
+		SET status = $1, next_retry_at = NULL, updated_at = NOW()
+		WHERE status IN ($2, $3) AND expires_at <= NOW()
+		RETURNING ` + messageColumns + `
+	`
+
+	rows, err := r.q.QueryContext(ctx, query,
+		domain.MessageStatusExpired,
+		domain.MessageStatusPending,
+		domain.MessageStatusFailed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired message: %w", err)
 		}
 
-		messages = append(messages, &msg)
+		messages = append(messages, msg)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over failed messages: %w", err)
+		return nil, fmt.Errorf("error iterating over expired messages: %w", err)
 	}
 
 	return messages, nil
 }
+
+// UpdatePending applies update to a message that is still pending. A message
+// being delivered stays row-locked until its batch commits, so an edit racing
+// a delivery waits for it and then finds the message no longer pending.
+func (r *messageRepository) UpdatePending(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error) {
+	query := `
+		UPDATE messages
+		SET recipient = COALESCE($1, recipient),
+		    content = COALESCE($2, content),
+		    webhook_url = COALESCE($3, webhook_url),
+		    updated_at = NOW()
+		WHERE id = $4 AND status = $5
+		RETURNING ` + messageColumns + `
+	`
+
+	msg, err := scanMessage(r.q.QueryRowContext(ctx, query,
+		update.Recipient,
+		update.Content,
+		update.WebhookURL,
+		messageID,
+		domain.MessageStatusPending,
+	))
+	if err == nil {
+		return msg, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+
+	// Distinguish a missing message from one that is no longer pending
+	var status domain.MessageStatus
+	err = r.q.QueryRowContext(ctx, `SELECT status FROM messages WHERE id = $1`, messageID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("message with ID %d not found: %w", messageID, domain.ErrMessageNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message status: %w", err)
+	}
+	return nil, fmt.Errorf("message with ID %d is %s: %w", messageID, status, domain.ErrMessageNotPending)
+}
+
+// CountByStatus counts messages per status, including every known status with
+// no messages as zero
+func (r *messageRepository) CountByStatus(ctx context.Context) (map[domain.MessageStatus]int, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT status, COUNT(*) FROM messages GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count messages by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.MessageStatus]int, len(domain.MessageStatuses))
+	for _, status := range domain.MessageStatuses {
+		counts[status] = 0
+	}
+	for rows.Next() {
+		var status domain.MessageStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over status counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountWithStatus counts the messages with status, which unlike CountByStatus
+// only reads that status's rows
+func (r *messageRepository) CountWithStatus(ctx context.Context, status domain.MessageStatus) (int, error) {
+	var count int
+	err := r.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE status = $1`, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s messages: %w", status, err)
+	}
+	return count, nil
+}
+
+// CountDeliveryOutcomes counts sent and dead-lettered messages per webhook URL
+// that reached their terminal state at or after since. Dead-lettered messages are
+// windowed on updated_at, which is set when they are moved to the dead-letter state.
+func (r *messageRepository) CountDeliveryOutcomes(ctx context.Context, since time.Time) ([]*domain.DeliveryOutcome, error) {
+	query := `
+		SELECT webhook_url,
+		       COUNT(*) FILTER (WHERE status = $1),
+		       COUNT(*) FILTER (WHERE status = $2)
+		FROM messages
+		WHERE (status = $1 AND sent_at >= $3)
+		   OR (status = $2 AND updated_at >= $3)
+		GROUP BY webhook_url
+		ORDER BY webhook_url
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, domain.MessageStatusSent, domain.MessageStatusDeadLetter, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count delivery outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []*domain.DeliveryOutcome
+	for rows.Next() {
+		var outcome domain.DeliveryOutcome
+		if err := rows.Scan(&outcome.WebhookURL, &outcome.Sent, &outcome.DeadLettered); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery outcome: %w", err)
+		}
+		outcomes = append(outcomes, &outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over delivery outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
+
+// GetRecentMessages retrieves messages of any status created within the last
+// since duration, newest first
+func (r *messageRepository) GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages 
+		WHERE created_at >= NOW() - ($1 * INTERVAL '1 millisecond')
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, since.Milliseconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recent message: %w", err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over recent messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// CountByRecipientSince counts messages created for recipient at or after since
+func (r *messageRepository) CountByRecipientSince(ctx context.Context, recipient string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM messages
+		WHERE recipient = $1 AND created_at >= $2
+	`
+
+	var count int
+	if err := r.q.QueryRowContext(ctx, query, recipient, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count messages for recipient: %w", err)
+	}
+
+	return count, nil
+}
+
+// RecordAttempt inserts a row into webhook_attempts
+func (r *messageRepository) RecordAttempt(ctx context.Context, messageID int64, statusCode int, durationMs int64, attemptErr error) error {
+	query := `
+		INSERT INTO webhook_attempts (message_id, status_code, duration_ms, error)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	var code sql.NullInt64
+	if statusCode != 0 {
+		code = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	var errorText sql.NullString
+	if attemptErr != nil {
+		errorText = sql.NullString{String: attemptErr.Error(), Valid: true}
+	}
+
+	if _, err := r.q.ExecContext(ctx, query, messageID, code, durationMs, errorText); err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListAttempts retrieves the webhook attempts recorded for a message, oldest first
+func (r *messageRepository) ListAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error) {
+	query := `
+		SELECT id, message_id, status_code, duration_ms, error, created_at
+		FROM webhook_attempts
+		WHERE message_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.q.QueryContext(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*domain.WebhookAttempt
+	for rows.Next() {
+		var (
+			attempt   domain.WebhookAttempt
+			code      sql.NullInt64
+			errorText sql.NullString
+		)
+		if err := rows.Scan(&attempt.ID, &attempt.MessageID, &code, &attempt.DurationMs, &errorText, &attempt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		if code.Valid {
+			statusCode := int(code.Int64)
+			attempt.StatusCode = &statusCode
+		}
+		if errorText.Valid {
+			attempt.Error = &errorText.String
+		}
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ArchiveOlderThan moves messages sent before cutoff to messages_archive. Rows are
+// moved in batches, each copied and deleted in its own transaction, so a long
+// backlog never holds locks on the hot table for the whole run.
+func (r *messageRepository) ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	archived := 0
+	for {
+		moved, err := r.archiveBatch(ctx, cutoff)
+		if err != nil {
+			return archived, err
+		}
+
+		archived += moved
+		if moved < archiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// archiveBatch moves up to archiveBatchSize messages sent before cutoff in a
+// single transaction and returns how many were moved
+func (r *messageRepository) archiveBatch(ctx context.Context, cutoff time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin archive transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id
+		FROM messages
+		WHERE status = $1 AND sent_at < $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, domain.MessageStatusSent, cutoff, archiveBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select messages to archive: %w", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan message ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over messages to archive: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	insertQuery := `
+		INSERT INTO messages_archive (` + messageColumns + `)
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = ANY($1)
+	`
+	if _, err := tx.ExecContext(ctx, insertQuery, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to copy messages to archive: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to delete archived messages: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archive transaction: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// DeleteOlderThan deletes messages with status last updated before cutoff.
+// updated_at is when a message reached its final status, so it dates sent and
+// dead-lettered messages alike. Rows are deleted in batches, each in its own
+// statement, so a long backlog never holds locks on the hot table for the
+// whole run.
+func (r *messageRepository) DeleteOlderThan(ctx context.Context, status domain.MessageStatus, cutoff time.Time) (int, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM messages
+			WHERE id IN (
+				SELECT id
+				FROM messages
+				WHERE status = $1 AND updated_at < $2
+				ORDER BY id
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id
+		), deleted_attempts AS (
+			DELETE FROM webhook_attempts
+			WHERE message_id IN (SELECT id FROM deleted)
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	deleted := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		var batch int
+		if err := r.q.QueryRowContext(ctx, query, status, cutoff, deleteBatchSize).Scan(&batch); err != nil {
+			return deleted, fmt.Errorf("failed to delete %s messages: %w", status, err)
+		}
+
+		deleted += batch
+		if batch < deleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// channelOrDefault returns channel, or ChannelWebhook for requests that did
+// not choose one
+func channelOrDefault(channel domain.Channel) domain.Channel {
+	if channel == "" {
+		return domain.ChannelWebhook
+	}
+	return channel
+}
+
+// scanMessage scans a single row selected with messageColumns into a domain.Message
+func scanMessage(row rowScanner) (*domain.Message, error) {
+	var msg domain.Message
+	var sentAt, failedAt, nextRetryAt, expiresAt sql.NullTime
+	var errorMessage, providerMessageID, idempotencyKey sql.NullString
+	var resentFrom sql.NullInt64
+
+	err := row.Scan(
+		&msg.ID,
+		&msg.Recipient,
+		&msg.Content,
+		&msg.WebhookURL,
+		&msg.Status,
+		&msg.RetryCount,
+		&msg.MaxRetries,
+		&msg.CreatedAt,
+		&msg.UpdatedAt,
+		&sentAt,
+		&failedAt,
+		&errorMessage,
+		&nextRetryAt,
+		&providerMessageID,
+		&msg.Priority,
+		&resentFrom,
+		&idempotencyKey,
+		&msg.Channel,
+		&msg.PayloadTemplate,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle nullable fields
+	if sentAt.Valid {
+		msg.SentAt = &sentAt.Time
+	}
+	if failedAt.Valid {
+		msg.FailedAt = &failedAt.Time
+	}
+	if errorMessage.Valid {
+		msg.ErrorMessage = &errorMessage.String
+	}
+	if nextRetryAt.Valid {
+		msg.NextRetryAt = &nextRetryAt.Time
+	}
+	if providerMessageID.Valid {
+		msg.ProviderMessageID = &providerMessageID.String
+	}
+	if resentFrom.Valid {
+		msg.ResentFrom = &resentFrom.Int64
+	}
+	if idempotencyKey.Valid {
+		msg.IdempotencyKey = &idempotencyKey.String
+	}
+	if expiresAt.Valid {
+		msg.ExpiresAt = &expiresAt.Time
+	}
+
+	return &msg, nil
+}
@@ -3,15 +3,45 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// messageTestColumns mirrors messageColumns for sqlmock result sets
+var messageTestColumns = []string{
+	"id", "recipient", "content", "webhook_url", "status", "retry_count",
+	"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
+	"next_retry_at", "provider_message_id", "priority", "resent_from", "idempotency_key",
+	"channel", "payload_template", "expires_at",
+}
+
+// Indexes of the NOT NULL columns in messageTestColumns that messageRow defaults
+const (
+	priorityColumn        = 14
+	channelColumn         = 17
+	payloadTemplateColumn = 18
+)
+
+// messageRow pads a message row with NULLs for any trailing nullable columns the
+// test does not set; priority, channel and payload_template are NOT NULL and
+// default to 0, webhook and empty
+func messageRow(values ...driver.Value) []driver.Value {
+	row := make([]driver.Value, len(messageTestColumns))
+	row[priorityColumn] = 0
+	row[channelColumn] = string(domain.ChannelWebhook)
+	row[payloadTemplateColumn] = ""
+	copy(row, values)
+	return row
+}
+
 func TestMessageRepository_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -29,16 +59,13 @@ func TestMessageRepository_Create(t *testing.T) {
 		}
 
 		now := time.Now()
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
 			1, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
 			0, req.MaxRetries, now, now, nil, nil, nil,
-		)
+		)...)
 
 		mock.ExpectQuery(`INSERT INTO messages`).
-			WithArgs(req.Recipient, req.Content, req.WebhookURL, req.MaxRetries, domain.MessageStatusPending, 0).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, req.MaxRetries, domain.MessageStatusPending, 0, 0, nil, nil, domain.ChannelWebhook, "", nil).
 			WillReturnRows(rows)
 
 		msg, err := repo.Create(ctx, req)
@@ -66,16 +93,13 @@ func TestMessageRepository_Create(t *testing.T) {
 		}
 
 		now := time.Now()
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
 			1, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
 			0, 3, now, now, nil, nil, nil,
-		)
+		)...)
 
 		mock.ExpectQuery(`INSERT INTO messages`).
-			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0, 0, nil, nil, domain.ChannelWebhook, "", nil).
 			WillReturnRows(rows)
 
 		msg, err := repo.Create(ctx, req)
@@ -84,137 +108,171 @@ func TestMessageRepository_Create(t *testing.T) {
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
-
-func TestMessageRepository_SelectUnsentForUpdate(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	require.NoError(t, err)
-	defer db.Close()
 
-	repo := NewMessageRepository(db)
-	ctx := context.Background()
+	t.Run("resend links the original", func(t *testing.T) {
+		original := int64(7)
+		req := &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 3,
+			Priority:   2,
+			ResentFrom: &original,
+		}
 
-	t.Run("successful selection", func(t *testing.T) {
 		now := time.Now()
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
-			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
-			domain.MessageStatusPending, 0, 3, now, now, nil, nil, nil,
-		).AddRow(
-			2, "test2@example.com", "Message 2", "https://example.com/webhook2",
-			domain.MessageStatusFailed, 1, 3, now, now, nil, now, "Previous error",
-		)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			8, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
+			0, 3, now, now, nil, nil, nil, nil, nil, 2, original, nil,
+		)...)
 
-		mock.ExpectQuery(`SELECT .+ FROM messages .+ FOR UPDATE SKIP LOCKED`).
-			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
+		mock.ExpectQuery(`INSERT INTO messages \(.*resent_from.*\)`).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0, 2, original, nil, domain.ChannelWebhook, "", nil).
 			WillReturnRows(rows)
 
-		messages, err := repo.SelectUnsentForUpdate(ctx, 10)
+		msg, err := repo.Create(ctx, req)
 		require.NoError(t, err)
-		assert.Len(t, messages, 2)
-
-		// Check first message
-		assert.Equal(t, int64(1), messages[0].ID)
-		assert.Equal(t, domain.MessageStatusPending, messages[0].Status)
-		assert.Equal(t, 0, messages[0].RetryCount)
-
-		// Check second message
-		assert.Equal(t, int64(2), messages[1].ID)
-		assert.Equal(t, domain.MessageStatusFailed, messages[1].Status)
-		assert.Equal(t, 1, messages[1].RetryCount)
-		assert.NotNil(t, messages[1].ErrorMessage)
-		assert.Equal(t, "Previous error", *messages[1].ErrorMessage)
+		require.NotNil(t, msg.ResentFrom)
+		assert.Equal(t, original, *msg.ResentFrom)
+		assert.Equal(t, 2, msg.Priority)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("no messages found", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		})
+	t.Run("stores the idempotency key", func(t *testing.T) {
+		key := "order-42"
+		req := &domain.CreateMessageRequest{
+			Recipient:      "test@example.com",
+			Content:        "Test message",
+			WebhookURL:     "https://example.com/webhook",
+			MaxRetries:     3,
+			IdempotencyKey: &key,
+		}
 
-		mock.ExpectQuery(`SELECT .+ FROM messages .+ FOR UPDATE SKIP LOCKED`).
-			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			9, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
+			0, 3, now, now, nil, nil, nil, nil, nil, 0, nil, key,
+		)...)
+
+		mock.ExpectQuery(`INSERT INTO messages \(.*idempotency_key.*\)`).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0, 0, nil, key, domain.ChannelWebhook, "", nil).
 			WillReturnRows(rows)
 
-		messages, err := repo.SelectUnsentForUpdate(ctx, 10)
+		msg, err := repo.Create(ctx, req)
 		require.NoError(t, err)
-		assert.Len(t, messages, 0)
+		require.NotNil(t, msg.IdempotencyKey)
+		assert.Equal(t, key, *msg.IdempotencyKey)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
 
-func TestMessageRepository_MarkSent(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	require.NoError(t, err)
-	defer db.Close()
+	t.Run("stores the channel", func(t *testing.T) {
+		req := &domain.CreateMessageRequest{
+			Recipient:  "+905551234567",
+			Content:    "Test message",
+			MaxRetries: 3,
+			Channel:    domain.ChannelSMS,
+		}
 
-	repo := NewMessageRepository(db)
-	ctx := context.Background()
+		now := time.Now()
+		row := messageRow(
+			10, req.Recipient, req.Content, "", domain.MessageStatusPending,
+			0, 3, now, now,
+		)
+		row[channelColumn] = string(domain.ChannelSMS)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(row...)
 
-	t.Run("successful mark as sent", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`).
-			WithArgs(domain.MessageStatusSent, int64(1)).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO messages \(.*channel.*\)`).
+			WithArgs(req.Recipient, req.Content, "", 3, domain.MessageStatusPending, 0, 0, nil, nil, domain.ChannelSMS, "", nil).
+			WillReturnRows(rows)
 
-		err := repo.MarkSent(ctx, 1)
+		msg, err := repo.Create(ctx, req)
 		require.NoError(t, err)
+		assert.Equal(t, domain.ChannelSMS, msg.Channel)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("message not found", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`).
-			WithArgs(domain.MessageStatusSent, int64(999)).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+	t.Run("stores the payload template", func(t *testing.T) {
+		req := &domain.CreateMessageRequest{
+			Recipient:       "test@example.com",
+			Content:         "Test message",
+			WebhookURL:      "https://example.com/webhook",
+			MaxRetries:      3,
+			PayloadTemplate: `{"text":{{json .Content}}}`,
+		}
 
-		err := repo.MarkSent(ctx, 999)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "message with ID 999 not found")
+		now := time.Now()
+		row := messageRow(
+			11, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
+			0, 3, now, now,
+		)
+		row[payloadTemplateColumn] = req.PayloadTemplate
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(row...)
+
+		mock.ExpectQuery(`INSERT INTO messages \(.*payload_template.*\)`).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0, 0, nil, nil, domain.ChannelWebhook, req.PayloadTemplate, nil).
+			WillReturnRows(rows)
+
+		msg, err := repo.Create(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.PayloadTemplate, msg.PayloadTemplate)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
 
-func TestMessageRepository_MarkFailed(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	require.NoError(t, err)
-	defer db.Close()
+	t.Run("stores the expiry", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		req := &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 3,
+			ExpiresAt:  &expiresAt,
+		}
 
-	repo := NewMessageRepository(db)
-	ctx := context.Background()
+		now := time.Now()
+		row := messageRow(
+			12, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
+			0, 3, now, now,
+		)
+		row[len(row)-1] = expiresAt
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(row...)
 
-	t.Run("successful mark as failed", func(t *testing.T) {
-		errorMsg := "Connection timeout"
-		mock.ExpectExec(`UPDATE messages SET status = .+, error_message = .+, failed_at = NOW\(\), updated_at = NOW\(\), retry_count = retry_count \+ 1`).
-			WithArgs(domain.MessageStatusFailed, errorMsg, int64(1)).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO messages \(.*expires_at.*\)`).
+			WithArgs(req.Recipient, req.Content, req.WebhookURL, 3, domain.MessageStatusPending, 0, 0, nil, nil, domain.ChannelWebhook, "", req.ExpiresAt).
+			WillReturnRows(rows)
 
-		err := repo.MarkFailed(ctx, 1, errorMsg)
+		msg, err := repo.Create(ctx, req)
 		require.NoError(t, err)
+		require.NotNil(t, msg.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*msg.ExpiresAt))
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("message not found", func(t *testing.T) {
-		errorMsg := "Connection timeout"
-		mock.ExpectExec(`UPDATE messages SET status = .+, error_message = .+, failed_at = NOW\(\), updated_at = NOW\(\), retry_count = retry_count \+ 1`).
-			WithArgs(domain.MessageStatusFailed, errorMsg, int64(999)).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+	t.Run("duplicate idempotency key", func(t *testing.T) {
+		key := "order-42"
+		req := &domain.CreateMessageRequest{
+			Recipient:      "test@example.com",
+			Content:        "Test message",
+			WebhookURL:     "https://example.com/webhook",
+			IdempotencyKey: &key,
+		}
 
-		err := repo.MarkFailed(ctx, 999, errorMsg)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "message with ID 999 not found")
+		mock.ExpectQuery(`INSERT INTO messages`).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_messages_idempotency_key"})
+
+		msg, err := repo.Create(ctx, req)
+		assert.ErrorIs(t, err, domain.ErrDuplicateIdempotencyKey)
+		assert.Nil(t, msg)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
-func TestMessageRepository_GetByID(t *testing.T) {
+func TestMessageRepository_GetByIdempotencyKey(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -222,47 +280,40 @@ func TestMessageRepository_GetByID(t *testing.T) {
 	repo := NewMessageRepository(db)
 	ctx := context.Background()
 
-	t.Run("successful get by ID", func(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
 		now := time.Now()
-		sentAt := now.Add(time.Hour)
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
-			1, "test@example.com", "Test message", "https://example.com/webhook",
-			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil,
-		)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			5, "test@example.com", "Test message", "https://example.com/webhook", domain.MessageStatusPending,
+			0, 3, now, now, nil, nil, nil, nil, nil, 0, nil, "order-42",
+		)...)
 
-		mock.ExpectQuery(`SELECT .+ FROM messages WHERE id = \$1`).
-			WithArgs(int64(1)).
+		mock.ExpectQuery(`SELECT .* FROM messages\s+WHERE idempotency_key = \$1`).
+			WithArgs("order-42").
 			WillReturnRows(rows)
 
-		msg, err := repo.GetByID(ctx, 1)
+		msg, err := repo.GetByIdempotencyKey(ctx, "order-42")
 		require.NoError(t, err)
-		assert.Equal(t, int64(1), msg.ID)
-		assert.Equal(t, "test@example.com", msg.Recipient)
-		assert.Equal(t, domain.MessageStatusSent, msg.Status)
-		assert.NotNil(t, msg.SentAt)
-		assert.Equal(t, sentAt, *msg.SentAt)
+		assert.Equal(t, int64(5), msg.ID)
+		require.NotNil(t, msg.IdempotencyKey)
+		assert.Equal(t, "order-42", *msg.IdempotencyKey)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("message not found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT .+ FROM messages WHERE id = \$1`).
-			WithArgs(int64(999)).
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .* FROM messages`).
+			WithArgs("missing").
 			WillReturnError(sql.ErrNoRows)
 
-		msg, err := repo.GetByID(ctx, 999)
-		require.Error(t, err)
+		msg, err := repo.GetByIdempotencyKey(ctx, "missing")
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
 		assert.Nil(t, msg)
-		assert.Contains(t, err.Error(), "message with ID 999 not found")
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
-func TestMessageRepository_GetSentMessages(t *testing.T) {
+func TestMessageRepository_CreateBatch(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -270,43 +321,161 @@ func TestMessageRepository_GetSentMessages(t *testing.T) {
 	repo := NewMessageRepository(db)
 	ctx := context.Background()
 
-	t.Run("successful get sent messages", func(t *testing.T) {
-		// Mock count query
-		countRows := sqlmock.NewRows([]string{"count"}).AddRow(25)
-		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
-			WithArgs(domain.MessageStatusSent).
-			WillReturnRows(countRows)
+	t.Run("inserts all rows in one statement", func(t *testing.T) {
+		reqs := []*domain.CreateMessageRequest{
+			{Recipient: "a@example.com", Content: "First", WebhookURL: "https://example.com/webhook"},
+			{Recipient: "b@example.com", Content: "Second", WebhookURL: "https://example.com/webhook", MaxRetries: 5, Priority: 1},
+		}
 
-		// Mock data query
 		now := time.Now()
-		sentAt := now.Add(time.Hour)
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
-			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
-			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil,
-		).AddRow(
-			2, "test2@example.com", "Message 2", "https://example.com/webhook2",
-			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil,
-		)
+		// RETURNING order is not guaranteed, so the rows come back reversed
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			11, "b@example.com", "Second", "https://example.com/webhook", domain.MessageStatusPending,
+			0, 5, now, now, nil, nil, nil,
+		)...).AddRow(messageRow(
+			10, "a@example.com", "First", "https://example.com/webhook", domain.MessageStatusPending,
+			0, 3, now, now, nil, nil, nil,
+		)...)
 
-		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 ORDER BY sent_at DESC LIMIT \$2 OFFSET \$3`).
-			WithArgs(domain.MessageStatusSent, 10, 0).
+		mock.ExpectQuery(`INSERT INTO messages .* VALUES \(\$1, .*\), \(\$12, .*\)`).
+			WithArgs(
+				"a@example.com", "First", "https://example.com/webhook", 3, domain.MessageStatusPending, 0, 0, nil, domain.ChannelWebhook, "", nil,
+				"b@example.com", "Second", "https://example.com/webhook", 5, domain.MessageStatusPending, 0, 1, nil, domain.ChannelWebhook, "", nil,
+			).
 			WillReturnRows(rows)
 
-		messages, total, err := repo.GetSentMessages(ctx, 0, 10)
+		messages, err := repo.CreateBatch(ctx, reqs)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, int64(10), messages[0].ID)
+		assert.Equal(t, "a@example.com", messages[0].Recipient)
+		assert.Equal(t, int64(11), messages[1].ID)
+		assert.Equal(t, "b@example.com", messages[1].Recipient)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		messages, err := repo.CreateBatch(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		reqs := []*domain.CreateMessageRequest{
+			{Recipient: "a@example.com", Content: "First", WebhookURL: "https://example.com/webhook"},
+		}
+
+		mock.ExpectQuery(`INSERT INTO messages`).WillReturnError(errors.New("connection reset"))
+
+		messages, err := repo.CreateBatch(ctx, reqs)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Contains(t, err.Error(), "failed to create messages")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_NewMessageNotify(t *testing.T) {
+	ctx := context.Background()
+	req := &domain.CreateMessageRequest{
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: "https://example.com/webhook",
+	}
+	createdRows := func(ids ...int64) *sqlmock.Rows {
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns)
+		for _, id := range ids {
+			rows.AddRow(messageRow(
+				id, req.Recipient, req.Content, req.WebhookURL, domain.MessageStatusPending,
+				0, 3, now, now, nil, nil, nil,
+			)...)
+		}
+		return rows
+	}
+	const notifyQuery = `SELECT pg_notify\(\$1, ''\)`
+
+	t.Run("create notifies", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db, WithNewMessageNotify())
+
+		mock.ExpectQuery(`INSERT INTO messages`).WillReturnRows(createdRows(1))
+		mock.ExpectExec(notifyQuery).
+			WithArgs(NewMessageChannel).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err = repo.Create(ctx, req)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("batch create notifies once", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db, WithNewMessageNotify())
+
+		mock.ExpectQuery(`INSERT INTO messages`).WillReturnRows(createdRows(1, 2))
+		mock.ExpectExec(notifyQuery).
+			WithArgs(NewMessageChannel).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		messages, err := repo.CreateBatch(ctx, []*domain.CreateMessageRequest{req, req})
 		require.NoError(t, err)
 		assert.Len(t, messages, 2)
-		assert.Equal(t, 25, total)
-		assert.Equal(t, domain.MessageStatusSent, messages[0].Status)
-		assert.Equal(t, domain.MessageStatusSent, messages[1].Status)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("notify failure does not fail the create", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db, WithNewMessageNotify())
+
+		mock.ExpectQuery(`INSERT INTO messages`).WillReturnRows(createdRows(1))
+		mock.ExpectExec(notifyQuery).
+			WithArgs(NewMessageChannel).
+			WillReturnError(errors.New("connection reset"))
+
+		msg, err := repo.Create(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), msg.ID)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("transaction notifies from within it", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db, WithNewMessageNotify())
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO messages`).WillReturnRows(createdRows(1))
+		mock.ExpectExec(notifyQuery).
+			WithArgs(NewMessageChannel).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			_, err := txRepo.Create(ctx, req)
+			return err
+		})
+		require.NoError(t, err)
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
-func TestMessageRepository_GetFailedMessages(t *testing.T) {
+func TestMessageRepository_SelectUnsentForUpdate(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
@@ -314,34 +483,1791 @@ func TestMessageRepository_GetFailedMessages(t *testing.T) {
 	repo := NewMessageRepository(db)
 	ctx := context.Background()
 
-	t.Run("successful get failed messages", func(t *testing.T) {
+	t.Run("successful selection", func(t *testing.T) {
 		now := time.Now()
-		failedAt := now.Add(time.Hour)
-		errorMsg := "Connection timeout"
-		rows := sqlmock.NewRows([]string{
-			"id", "recipient", "content", "webhook_url", "status", "retry_count",
-			"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
-		}).AddRow(
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
 			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
-			domain.MessageStatusFailed, 1, 3, now, now, nil, failedAt, errorMsg,
-		).AddRow(
+			domain.MessageStatusPending, 0, 3, now, now, nil, nil, nil,
+		)...).AddRow(messageRow(
 			2, "test2@example.com", "Message 2", "https://example.com/webhook2",
-			domain.MessageStatusFailed, 2, 3, now, now, nil, failedAt, errorMsg,
-		)
+			domain.MessageStatusFailed, 1, 3, now, now, nil, now, "Previous error",
+		)...)
 
-		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 AND retry_count < max_retries ORDER BY failed_at ASC LIMIT \$2`).
-			WithArgs(domain.MessageStatusFailed, 10).
+		mock.ExpectQuery(`SELECT .+ FROM messages .+ FOR UPDATE SKIP LOCKED`).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
 			WillReturnRows(rows)
 
-		messages, err := repo.GetFailedMessages(ctx, 10)
+		messages, err := repo.SelectUnsentForUpdate(ctx, 10)
 		require.NoError(t, err)
 		assert.Len(t, messages, 2)
-		assert.Equal(t, domain.MessageStatusFailed, messages[0].Status)
-		assert.Equal(t, 1, messages[0].RetryCount)
+
+		// Check first message
+		assert.Equal(t, int64(1), messages[0].ID)
+		assert.Equal(t, domain.MessageStatusPending, messages[0].Status)
+		assert.Equal(t, 0, messages[0].RetryCount)
+
+		// Check second message
+		assert.Equal(t, int64(2), messages[1].ID)
 		assert.Equal(t, domain.MessageStatusFailed, messages[1].Status)
-		assert.Equal(t, 2, messages[1].RetryCount)
-		assert.NotNil(t, messages[0].ErrorMessage)
-		assert.Equal(t, errorMsg, *messages[0].ErrorMessage)
+		assert.Equal(t, 1, messages[1].RetryCount)
+		assert.NotNil(t, messages[1].ErrorMessage)
+		assert.Equal(t, "Previous error", *messages[1].ErrorMessage)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips expired messages", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE \(status = \$1 OR .+\) AND \(expires_at IS NULL OR expires_at > NOW\(\)\) ORDER BY .+ FOR UPDATE SKIP LOCKED`).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+		_, err := repo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no messages found", func(t *testing.T) {
+		rows := sqlmock.NewRows(messageTestColumns)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages .+ FOR UPDATE SKIP LOCKED`).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
+			WillReturnRows(rows)
+
+		messages, err := repo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, messages, 0)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_LockUnsent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	const lockQuery = `SELECT .+ FROM messages WHERE id = \$3 AND \(status = \$1 OR .+\) AND \(expires_at IS NULL OR expires_at > NOW\(\)\) FOR UPDATE SKIP LOCKED`
+
+	t.Run("locks a due message", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, int64(1)).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+				1, "test@example.com", "Hello", "https://example.com/webhook",
+				domain.MessageStatusFailed, 1, 3, now, now,
+			)...))
+
+		message, err := repo.LockUnsent(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), message.ID)
+		assert.Equal(t, 1, message.RetryCount)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message no longer due or held elsewhere", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, int64(2)).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+		_, err := repo.LockUnsent(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, int64(3)).
+			WillReturnError(errors.New("connection reset"))
+
+		_, err := repo.LockUnsent(ctx, 3)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, domain.ErrMessageNotFound)
+		assert.Contains(t, err.Error(), "failed to lock message")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_WithTx(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	const lockQuery = `SELECT .+ FROM messages WHERE id = \$3 .+ FOR UPDATE SKIP LOCKED`
+	const markSentQuery = `UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`
+
+	t.Run("lock and mark commit together", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, int64(1)).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+				1, "test@example.com", "Hello", "https://example.com/webhook",
+				domain.MessageStatusPending, 0, 3, now, now,
+			)...))
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			message, err := txRepo.LockUnsent(ctx, 1)
+			if err != nil {
+				return err
+			}
+			return txRepo.MarkSent(ctx, message.ID)
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("an error rolls back", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			return txRepo.MarkSent(ctx, 1)
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to mark message as sent")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed commit is reported", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(sql.ErrConnDone)
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			return txRepo.MarkSent(ctx, 1)
+		})
+		require.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Contains(t, err.Error(), "failed to commit transaction")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nested calls run in a savepoint of the outer transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			return txRepo.WithTx(ctx, func(inner MessageRepository) error {
+				return inner.MarkSent(ctx, 1)
+			})
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("a failed nested call rolls back only its savepoint", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnError(errors.New("deadlock detected"))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(markSentQuery).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RELEASE SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err = repo.WithTx(ctx, func(txRepo MessageRepository) error {
+			markSent := func(inner MessageRepository) error {
+				return inner.MarkSent(ctx, 1)
+			}
+			require.Error(t, txRepo.WithTx(ctx, markSent))
+			return txRepo.WithTx(ctx, markSent)
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkSent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful mark as sent", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkSent(ctx, 1)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`).
+			WithArgs(domain.MessageStatusSent, int64(999)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`).
+			WithArgs(int64(999)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		err := repo.MarkSent(ctx, 999)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+		assert.Contains(t, err.Error(), "message with ID 999 not found")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("concurrent double mark is a no-op", func(t *testing.T) {
+		// The first worker marks the message sent; the second finds no row
+		// still unsent and must succeed without touching sent_at again
+		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\) WHERE id = \$2 AND status != \$1`).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\) WHERE id = \$2 AND status != \$1`).
+			WithArgs(domain.MessageStatusSent, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		require.NoError(t, repo.MarkSent(ctx, 1))
+		require.NoError(t, repo.MarkSent(ctx, 1))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("existence check failure", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE messages SET status = .+, sent_at = NOW\(\), updated_at = NOW\(\)`).
+			WithArgs(domain.MessageStatusSent, int64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`).
+			WithArgs(int64(2)).
+			WillReturnError(errors.New("connection reset"))
+
+		err := repo.MarkSent(ctx, 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to check message existence")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_LockForSend(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	// A plain FOR UPDATE waits for a delivery in progress instead of skipping it
+	const lockQuery = `SELECT .+ FROM messages WHERE id = \$1 FOR UPDATE$`
+
+	t.Run("locks a message of any status", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+				1, "test@example.com", "Hello", "https://example.com/webhook",
+				domain.MessageStatusSent, 0, 3, now, now,
+			)...))
+
+		message, err := repo.LockForSend(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, message.Status)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectQuery(lockQuery).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+		_, err := repo.LockForSend(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkResent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	markQuery := `UPDATE messages SET sent_at = NOW\(\), provider_message_id = COALESCE\(NULLIF\(\$1, ''\), provider_message_id\), updated_at = NOW\(\) WHERE id = \$2 AND status = \$3`
+	existsQuery := `SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`
+
+	t.Run("sent message", func(t *testing.T) {
+		mock.ExpectExec(markQuery).
+			WithArgs("abc123", int64(1), domain.MessageStatusSent).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.NoError(t, repo.MarkResent(ctx, 1, "abc123"))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not sent", func(t *testing.T) {
+		mock.ExpectExec(markQuery).
+			WithArgs("", int64(2), domain.MessageStatusSent).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(existsQuery).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		assert.ErrorIs(t, repo.MarkResent(ctx, 2, ""), domain.ErrMessageNotSent)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(markQuery).
+			WithArgs("", int64(999), domain.MessageStatusSent).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(existsQuery).
+			WithArgs(int64(999)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		assert.ErrorIs(t, repo.MarkResent(ctx, 999, ""), domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkSentWithReference(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	markQuery := `UPDATE messages SET status = \$1, sent_at = NOW\(\), provider_message_id = \$2, updated_at = NOW\(\) WHERE id = \$3 AND status != \$1`
+
+	t.Run("successful mark as sent with reference", func(t *testing.T) {
+		mock.ExpectExec(markQuery).
+			WithArgs(domain.MessageStatusSent, "abc123", int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkSentWithReference(ctx, 1, "abc123")
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(markQuery).
+			WithArgs(domain.MessageStatusSent, "abc123", int64(999)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`).
+			WithArgs(int64(999)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		err := repo.MarkSentWithReference(ctx, 999, "abc123")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("marking a sent message again is a no-op", func(t *testing.T) {
+		// The guard leaves the first delivery's sent_at and reference in place
+		mock.ExpectExec(markQuery).
+			WithArgs(domain.MessageStatusSent, "def456", int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM messages WHERE id = \$1\)`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		require.NoError(t, repo.MarkSentWithReference(ctx, 1, "def456"))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkFailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful mark as failed", func(t *testing.T) {
+		errorMsg := "Connection timeout"
+		mock.ExpectExec(`UPDATE messages SET status = .+, error_message = .+, failed_at = NOW\(\), updated_at = NOW\(\), retry_count = retry_count \+ 1, next_retry_at = NOW\(\) \+ \(\$3 \* INTERVAL '1 millisecond'\)`).
+			WithArgs(domain.MessageStatusFailed, errorMsg, int64(30000), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkFailed(ctx, 1, errorMsg, 30*time.Second)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		errorMsg := "Connection timeout"
+		mock.ExpectExec(`UPDATE messages SET status = .+, error_message = .+, failed_at = NOW\(\), updated_at = NOW\(\), retry_count = retry_count \+ 1`).
+			WithArgs(domain.MessageStatusFailed, errorMsg, int64(30000), int64(999)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.MarkFailed(ctx, 999, errorMsg, 30*time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message with ID 999 not found")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful get by ID", func(t *testing.T) {
+		now := time.Now()
+		sentAt := now.Add(time.Hour)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "test@example.com", "Test message", "https://example.com/webhook",
+			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil, nil, "abc123",
+		)...)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(rows)
+
+		msg, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), msg.ID)
+		assert.Equal(t, "test@example.com", msg.Recipient)
+		assert.Equal(t, domain.MessageStatusSent, msg.Status)
+		assert.NotNil(t, msg.SentAt)
+		assert.Equal(t, sentAt, *msg.SentAt)
+		require.NotNil(t, msg.ProviderMessageID)
+		assert.Equal(t, "abc123", *msg.ProviderMessageID)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE id = \$1`).
+			WithArgs(int64(999)).
+			WillReturnError(sql.ErrNoRows)
+
+		msg, err := repo.GetByID(ctx, 999)
+		require.Error(t, err)
+		assert.Nil(t, msg)
+		assert.Contains(t, err.Error(), "message with ID 999 not found")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetSentMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful get sent messages", func(t *testing.T) {
+		// Mock count query
+		countRows := sqlmock.NewRows([]string{"count"}).AddRow(25)
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+			WithArgs(domain.MessageStatusSent).
+			WillReturnRows(countRows)
+
+		// Mock data query
+		now := time.Now()
+		sentAt := now.Add(time.Hour)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
+			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil,
+		)...).AddRow(messageRow(
+			2, "test2@example.com", "Message 2", "https://example.com/webhook2",
+			domain.MessageStatusSent, 0, 3, now, now, sentAt, nil, nil,
+		)...)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 ORDER BY sent_at DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs(domain.MessageStatusSent, 10, 0).
+			WillReturnRows(rows)
+
+		messages, total, err := repo.GetSentMessages(ctx, 0, 10, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, 25, total)
+		assert.Equal(t, domain.MessageStatusSent, messages[0].Status)
+		assert.Equal(t, domain.MessageStatusSent, messages[1].Status)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	orders := []struct {
+		order   domain.MessageSort
+		orderBy string
+	}{
+		{domain.MessageSort{Field: domain.SortBySentAt, Order: domain.SortDesc}, `sent_at DESC`},
+		{domain.MessageSort{Field: domain.SortBySentAt, Order: domain.SortAsc}, `sent_at ASC`},
+		{domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortDesc}, `created_at DESC`},
+		{domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortAsc}, `created_at ASC`},
+	}
+	for _, tt := range orders {
+		t.Run(string(tt.order.Field)+" "+string(tt.order.Order), func(t *testing.T) {
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+				WithArgs(domain.MessageStatusSent).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 ORDER BY `+tt.orderBy+` LIMIT \$2 OFFSET \$3`).
+				WithArgs(domain.MessageStatusSent, 10, 20).
+				WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+			_, total, err := repo.GetSentMessages(ctx, 20, 10, tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, 0, total)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("unsupported order is rejected before querying", func(t *testing.T) {
+		order := domain.MessageSort{Field: "recipient; DROP TABLE messages", Order: domain.SortAsc}
+
+		_, _, err := repo.GetSentMessages(ctx, 0, 10, order)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported sent message order")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ListMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		status domain.MessageStatus
+	}{
+		{"all", ""},
+		{"pending", domain.MessageStatusPending},
+		{"sent", domain.MessageStatusSent},
+		{"failed", domain.MessageStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rowStatus := tt.status
+			if rowStatus == "" {
+				rowStatus = domain.MessageStatusPending
+			}
+
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE \(\$1 = '' OR status = \$1\)`).
+				WithArgs(tt.status).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+			now := time.Now()
+			rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+				1, "test@example.com", "Test message", "https://example.com/webhook", rowStatus,
+				0, 3, now, now, nil, nil, nil,
+			)...)
+			mock.ExpectQuery(`SELECT .* FROM messages\s+WHERE \(\$1 = '' OR status = \$1\)\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$2 OFFSET \$3`).
+				WithArgs(tt.status, 10, 20).
+				WillReturnRows(rows)
+
+			messages, total, err := repo.ListMessages(ctx, tt.status, 20, 10)
+			require.NoError(t, err)
+			assert.Equal(t, 3, total)
+			require.Len(t, messages, 1)
+			assert.Equal(t, rowStatus, messages[0].Status)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("count error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT`).WillReturnError(errors.New("connection reset"))
+
+		messages, total, err := repo.ListMessages(ctx, domain.MessageStatusSent, 0, 10)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Zero(t, total)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ListAfterCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	const cursorQuery = `SELECT .* FROM messages\s+WHERE \(\$1 = '' OR status = \$1\) AND \(\$2 = 0 OR id < \$2\)\s+ORDER BY id DESC\s+LIMIT \$3`
+	pageRows := func(ids ...int64) *sqlmock.Rows {
+		rows := sqlmock.NewRows(messageTestColumns)
+		for _, id := range ids {
+			rows.AddRow(messageRow(
+				id, "test@example.com", "Test message", "https://example.com/webhook", domain.MessageStatusSent,
+				0, 3, now, now, now, nil, nil,
+			)...)
+		}
+		return rows
+	}
+
+	t.Run("walks every page without gaps", func(t *testing.T) {
+		// Seven sent messages read three at a time; each page starts below the
+		// last ID of the one before
+		mock.ExpectQuery(cursorQuery).WithArgs(domain.MessageStatusSent, int64(0), 3).WillReturnRows(pageRows(7, 6, 5))
+		mock.ExpectQuery(cursorQuery).WithArgs(domain.MessageStatusSent, int64(5), 3).WillReturnRows(pageRows(4, 3, 2))
+		mock.ExpectQuery(cursorQuery).WithArgs(domain.MessageStatusSent, int64(2), 3).WillReturnRows(pageRows(1))
+
+		var seen []int64
+		cursorID := int64(0)
+		for {
+			messages, err := repo.ListAfterCursor(ctx, domain.MessageStatusSent, cursorID, 3)
+			require.NoError(t, err)
+			for _, message := range messages {
+				seen = append(seen, message.ID)
+			}
+			if len(messages) < 3 {
+				break
+			}
+			cursorID = messages[len(messages)-1].ID
+		}
+
+		assert.Equal(t, []int64{7, 6, 5, 4, 3, 2, 1}, seen)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(cursorQuery).WillReturnError(errors.New("connection reset"))
+
+		messages, err := repo.ListAfterCursor(ctx, "", 0, 3)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetByRecipient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("returns matching messages", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE recipient = \$1`).
+			WithArgs("user@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			2, "user@example.com", "Second", "https://example.com/webhook", domain.MessageStatusPending,
+			0, 3, now, now, nil, nil, nil,
+		)...).AddRow(messageRow(
+			1, "user@example.com", "First", "https://example.com/webhook", domain.MessageStatusSent,
+			0, 3, now.Add(-time.Minute), now, now, nil, nil,
+		)...)
+		mock.ExpectQuery(`SELECT .* FROM messages\s+WHERE recipient = \$1\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$2 OFFSET \$3`).
+			WithArgs("user@example.com", 10, 0).
+			WillReturnRows(rows)
+
+		messages, total, err := repo.GetByRecipient(ctx, "user@example.com", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, messages, 2)
+		for _, msg := range messages {
+			assert.Equal(t, "user@example.com", msg.Recipient)
+		}
+		assert.Equal(t, int64(2), messages[0].ID)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT`).
+			WithArgs("user@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT .* FROM messages`).WillReturnError(errors.New("connection reset"))
+
+		messages, _, err := repo.GetByRecipient(ctx, "user@example.com", 0, 10)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ListByDateRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("returns messages in range", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE created_at BETWEEN \$1 AND \$2`).
+			WithArgs(from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		createdAt := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "user@example.com", "Hello", "https://example.com/webhook", domain.MessageStatusSent,
+			0, 3, createdAt, createdAt, createdAt, nil, nil,
+		)...)
+		mock.ExpectQuery(`SELECT .* FROM messages\s+WHERE created_at BETWEEN \$1 AND \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3 OFFSET \$4`).
+			WithArgs(from, to, 10, 0).
+			WillReturnRows(rows)
+
+		messages, total, err := repo.ListByDateRange(ctx, from, to, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, messages, 1)
+		assert.Equal(t, createdAt, messages[0].CreatedAt)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT`).
+			WithArgs(from, from).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT .* FROM messages`).
+			WithArgs(from, from, 10, 0).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+		messages, total, err := repo.ListByDateRange(ctx, from, from, 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("count error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT`).
+			WithArgs(from, to).
+			WillReturnError(errors.New("connection reset"))
+
+		messages, _, err := repo.ListByDateRange(ctx, from, to, 0, 10)
+		assert.Error(t, err)
+		assert.Nil(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetFailedMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful get failed messages", func(t *testing.T) {
+		now := time.Now()
+		failedAt := now.Add(time.Hour)
+		errorMsg := "Connection timeout"
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
+			domain.MessageStatusFailed, 1, 3, now, now, nil, failedAt, errorMsg,
+		)...).AddRow(messageRow(
+			2, "test2@example.com", "Message 2", "https://example.com/webhook2",
+			domain.MessageStatusFailed, 2, 3, now, now, nil, failedAt, errorMsg,
+		)...)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 AND retry_count < max_retries AND \(next_retry_at IS NULL OR next_retry_at <= NOW\(\)\) AND \(expires_at IS NULL OR expires_at > NOW\(\)\) ORDER BY priority DESC, next_retry_at ASC NULLS FIRST, id ASC LIMIT \$2`).
+			WithArgs(domain.MessageStatusFailed, 10).
+			WillReturnRows(rows)
+
+		messages, err := repo.GetFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, messages, 2)
+		assert.Equal(t, domain.MessageStatusFailed, messages[0].Status)
+		assert.Equal(t, 1, messages[0].RetryCount)
+		assert.Equal(t, domain.MessageStatusFailed, messages[1].Status)
+		assert.Equal(t, 2, messages[1].RetryCount)
+		assert.NotNil(t, messages[0].ErrorMessage)
+		assert.Equal(t, errorMsg, *messages[0].ErrorMessage)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_MarkDeadLetter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful mark as dead-letter", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE messages SET status = \$1, updated_at = NOW\(\) WHERE id = \$2`).
+			WithArgs(domain.MessageStatusDeadLetter, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MarkDeadLetter(ctx, 1)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE messages SET status = \$1, updated_at = NOW\(\) WHERE id = \$2`).
+			WithArgs(domain.MessageStatusDeadLetter, int64(999)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.MarkDeadLetter(ctx, 999)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message with ID 999 not found")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetDeadLetterMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful get dead-letter messages", func(t *testing.T) {
+		countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+			WithArgs(domain.MessageStatusDeadLetter).
+			WillReturnRows(countRows)
+
+		now := time.Now()
+		errorMsg := "webhook delivery failed with status 500"
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "test1@example.com", "Message 1", "https://example.com/webhook1",
+			domain.MessageStatusDeadLetter, 3, 3, now, now, nil, now, errorMsg,
+		)...)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 ORDER BY failed_at DESC NULLS LAST, id DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs(domain.MessageStatusDeadLetter, 10, 0).
+			WillReturnRows(rows)
+
+		messages, total, err := repo.GetDeadLetterMessages(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, 1, total)
+		assert.True(t, messages[0].IsDeadLetter())
+		assert.Equal(t, 3, messages[0].RetryCount)
+		require.NotNil(t, messages[0].ErrorMessage)
+		assert.Equal(t, errorMsg, *messages[0].ErrorMessage)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetFailedMessagesPaginated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful get failed messages", func(t *testing.T) {
+		countRows := sqlmock.NewRows([]string{"count"}).AddRow(12)
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+			WithArgs(domain.MessageStatusFailed).
+			WillReturnRows(countRows)
+
+		now := time.Now()
+		errorMsg := "webhook delivery failed with status 503"
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			4, "test4@example.com", "Message 4", "https://example.com/webhook4",
+			domain.MessageStatusFailed, 2, 3, now, now, nil, now, errorMsg,
+		)...)
+
+		mock.ExpectQuery(`SELECT .+ FROM messages WHERE status = \$1 ORDER BY failed_at DESC NULLS LAST, id DESC LIMIT \$2 OFFSET \$3`).
+			WithArgs(domain.MessageStatusFailed, 10, 10).
+			WillReturnRows(rows)
+
+		messages, total, err := repo.GetFailedMessagesPaginated(ctx, 10, 10)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, 12, total)
+		assert.Equal(t, domain.MessageStatusFailed, messages[0].Status)
+		assert.Equal(t, 2, messages[0].RetryCount)
+		require.NotNil(t, messages[0].FailedAt)
+		require.NotNil(t, messages[0].ErrorMessage)
+		assert.Equal(t, errorMsg, *messages[0].ErrorMessage)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("count error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+			WithArgs(domain.MessageStatusFailed).
+			WillReturnError(errors.New("connection reset"))
+
+		messages, total, err := repo.GetFailedMessagesPaginated(ctx, 0, 10)
+		require.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Equal(t, 0, total)
+		assert.Contains(t, err.Error(), "failed to count failed messages")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_Requeue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	requeueQuery := `UPDATE messages SET status = \$1, retry_count = 0, failed_at = NULL, error_message = NULL, next_retry_at = NULL, updated_at = NOW\(\) WHERE id = \$2 AND status IN \(\$3, \$4\)`
+
+	t.Run("successful requeue", func(t *testing.T) {
+		mock.ExpectExec(requeueQuery).
+			WithArgs(domain.MessageStatusPending, int64(1), domain.MessageStatusFailed, domain.MessageStatusDeadLetter).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Requeue(ctx, 1)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		mock.ExpectExec(requeueQuery).
+			WithArgs(domain.MessageStatusPending, int64(2), domain.MessageStatusFailed, domain.MessageStatusDeadLetter).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.MessageStatusSent))
+
+		err := repo.Requeue(ctx, 2)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageAlreadySent)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	for _, status := range []domain.MessageStatus{
+		domain.MessageStatusPending,
+		domain.MessageStatusSending,
+		domain.MessageStatusCancelled,
+		domain.MessageStatusExpired,
+	} {
+		t.Run(string(status)+" message cannot be requeued", func(t *testing.T) {
+			mock.ExpectExec(requeueQuery).
+				WithArgs(domain.MessageStatusPending, int64(3), domain.MessageStatusFailed, domain.MessageStatusDeadLetter).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+				WithArgs(int64(3)).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(status))
+
+			err := repo.Requeue(ctx, 3)
+			assert.ErrorIs(t, err, domain.ErrMessageNotRequeueable)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(requeueQuery).
+			WithArgs(domain.MessageStatusPending, int64(999), domain.MessageStatusFailed, domain.MessageStatusDeadLetter).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(999)).
+			WillReturnError(sql.ErrNoRows)
+
+		err := repo.Requeue(ctx, 999)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_Cancel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	cancelQuery := `UPDATE messages SET status = \$1, next_retry_at = NULL, updated_at = NOW\(\) WHERE id = \$2 AND status IN \(\$3, \$4, \$5\)`
+
+	t.Run("successful cancel", func(t *testing.T) {
+		mock.ExpectExec(cancelQuery).
+			WithArgs(domain.MessageStatusCancelled, int64(1), domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusSending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Cancel(ctx, 1)
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		mock.ExpectExec(cancelQuery).
+			WithArgs(domain.MessageStatusCancelled, int64(2), domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusSending).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.MessageStatusSent))
+
+		err := repo.Cancel(ctx, 2)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageNotCancellable)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectExec(cancelQuery).
+			WithArgs(domain.MessageStatusCancelled, int64(999), domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusSending).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(999)).
+			WillReturnError(sql.ErrNoRows)
+
+		err := repo.Cancel(ctx, 999)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ExpireOverdue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	expireQuery := `UPDATE messages SET status = \$1, next_retry_at = NULL, updated_at = NOW\(\) WHERE status IN \(\$2, \$3\) AND expires_at <= NOW\(\) RETURNING .+`
+
+	t.Run("marks overdue messages expired", func(t *testing.T) {
+		now := time.Now()
+		expiresAt := now.Add(-time.Minute)
+		row := messageRow(
+			1, "test@example.com", "Stale", "https://example.com/webhook", domain.MessageStatusExpired,
+			0, 3, now, now,
+		)
+		row[len(row)-1] = expiresAt
+		mock.ExpectQuery(expireQuery).
+			WithArgs(domain.MessageStatusExpired, domain.MessageStatusPending, domain.MessageStatusFailed).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns).AddRow(row...))
+
+		messages, err := repo.ExpireOverdue(ctx)
+		require.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, domain.MessageStatusExpired, messages[0].Status)
+		require.NotNil(t, messages[0].ExpiresAt)
+		assert.True(t, expiresAt.Equal(*messages[0].ExpiresAt))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing overdue", func(t *testing.T) {
+		mock.ExpectQuery(expireQuery).
+			WithArgs(domain.MessageStatusExpired, domain.MessageStatusPending, domain.MessageStatusFailed).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns))
+
+		messages, err := repo.ExpireOverdue(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(expireQuery).WillReturnError(errors.New("connection reset"))
+
+		messages, err := repo.ExpireOverdue(ctx)
+		assert.ErrorContains(t, err, "failed to expire messages")
+		assert.Nil(t, messages)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_UpdatePending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	updateQuery := `UPDATE messages SET recipient = COALESCE\(\$1, recipient\), content = COALESCE\(\$2, content\), webhook_url = COALESCE\(\$3, webhook_url\), updated_at = NOW\(\) WHERE id = \$4 AND status = \$5 RETURNING`
+	content := "Corrected message"
+	update := &domain.UpdateMessageRequest{Content: &content}
+
+	t.Run("successful update", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			1, "test@example.com", content, "https://example.com/webhook", domain.MessageStatusPending,
+			0, 3, now, now,
+		)...)
+		mock.ExpectQuery(updateQuery).
+			WithArgs(nil, content, nil, int64(1), domain.MessageStatusPending).
+			WillReturnRows(rows)
+
+		msg, err := repo.UpdatePending(ctx, 1, update)
+		require.NoError(t, err)
+		assert.Equal(t, content, msg.Content)
+		assert.Equal(t, "test@example.com", msg.Recipient)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no longer pending", func(t *testing.T) {
+		mock.ExpectQuery(updateQuery).
+			WithArgs(nil, content, nil, int64(2), domain.MessageStatusPending).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(domain.MessageStatusSent))
+
+		_, err := repo.UpdatePending(ctx, 2, update)
+		assert.ErrorIs(t, err, domain.ErrMessageNotPending)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mock.ExpectQuery(updateQuery).
+			WithArgs(nil, content, nil, int64(999), domain.MessageStatusPending).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT status FROM messages WHERE id = \$1`).
+			WithArgs(int64(999)).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdatePending(ctx, 999, update)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_CountByStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("missing statuses count as zero", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"status", "count"}).
+			AddRow(domain.MessageStatusPending, 4).
+			AddRow(domain.MessageStatusSent, 10)
+		mock.ExpectQuery(`SELECT status, COUNT\(\*\) FROM messages GROUP BY status`).WillReturnRows(rows)
+
+		counts, err := repo.CountByStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[domain.MessageStatus]int{
+			domain.MessageStatusPending:    4,
+			domain.MessageStatusSent:       10,
+			domain.MessageStatusFailed:     0,
+			domain.MessageStatusDeadLetter: 0,
+			domain.MessageStatusSending:    0,
+			domain.MessageStatusCancelled:  0,
+			domain.MessageStatusExpired:    0,
+		}, counts)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT status, COUNT`).WillReturnError(errors.New("connection reset"))
+
+		counts, err := repo.CountByStatus(ctx)
+		assert.Error(t, err)
+		assert.Nil(t, counts)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_CountWithStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE status = \$1`).
+		WithArgs(domain.MessageStatusSent).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+	count, err := repo.CountWithStatus(ctx, domain.MessageStatusSent)
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_GetByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT .+ FROM messages WHERE id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]int64{1, 2, 3})).
+		WillReturnRows(sqlmock.NewRows(messageTestColumns).
+			AddRow(messageRow(1, "a@example.com", "Hello", "https://example.com/webhook", domain.MessageStatusSent, 0, 3, now, now)...).
+			AddRow(messageRow(3, "c@example.com", "Hello", "https://example.com/webhook", domain.MessageStatusSent, 0, 3, now, now)...))
+
+	messages, err := repo.GetByIDs(ctx, []int64{1, 2, 3})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, int64(1), messages[0].ID)
+	assert.Equal(t, int64(3), messages[1].ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_CountDeliveryOutcomes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	since := time.Now().Add(-time.Hour)
+
+	t.Run("successful count", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"webhook_url", "sent", "dead_lettered"}).
+			AddRow("https://a.example.com/hook", 5, 1).
+			AddRow("https://b.example.com/hook", 0, 2)
+
+		mock.ExpectQuery(`SELECT webhook_url, COUNT\(\*\) FILTER \(WHERE status = \$1\), COUNT\(\*\) FILTER \(WHERE status = \$2\) FROM messages WHERE \(status = \$1 AND sent_at >= \$3\) OR \(status = \$2 AND updated_at >= \$3\) GROUP BY webhook_url`).
+			WithArgs(domain.MessageStatusSent, domain.MessageStatusDeadLetter, since).
+			WillReturnRows(rows)
+
+		outcomes, err := repo.CountDeliveryOutcomes(ctx, since)
+		require.NoError(t, err)
+		require.Len(t, outcomes, 2)
+		assert.Equal(t, &domain.DeliveryOutcome{WebhookURL: "https://a.example.com/hook", Sent: 5, DeadLettered: 1}, outcomes[0])
+		assert.Equal(t, &domain.DeliveryOutcome{WebhookURL: "https://b.example.com/hook", Sent: 0, DeadLettered: 2}, outcomes[1])
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT webhook_url`).
+			WithArgs(domain.MessageStatusSent, domain.MessageStatusDeadLetter, since).
+			WillReturnError(sql.ErrConnDone)
+
+		outcomes, err := repo.CountDeliveryOutcomes(ctx, since)
+		require.Error(t, err)
+		assert.Nil(t, outcomes)
+		assert.Contains(t, err.Error(), "failed to count delivery outcomes")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetRecentMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	recentQuery := `SELECT .+ FROM messages WHERE created_at >= NOW\(\) - \(\$1 \* INTERVAL '1 millisecond'\) ORDER BY created_at DESC LIMIT \$2`
+
+	t.Run("successful get recent messages", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			2, "test2@example.com", "Message 2", "https://example.com/webhook",
+			domain.MessageStatusPending, 0, 3, now, now,
+		)...).AddRow(messageRow(
+			1, "test1@example.com", "Message 1", "https://example.com/webhook",
+			domain.MessageStatusSent, 0, 3, now.Add(-time.Minute), now, now,
+		)...)
+
+		mock.ExpectQuery(recentQuery).
+			WithArgs(int64(300000), 50).
+			WillReturnRows(rows)
+
+		messages, err := repo.GetRecentMessages(ctx, 5*time.Minute, 50)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, int64(2), messages[0].ID)
+		assert.Equal(t, domain.MessageStatusPending, messages[0].Status)
+		assert.Equal(t, domain.MessageStatusSent, messages[1].Status)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(recentQuery).
+			WithArgs(int64(60000), 10).
+			WillReturnError(sql.ErrConnDone)
+
+		messages, err := repo.GetRecentMessages(ctx, time.Minute, 10)
+		require.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Contains(t, err.Error(), "failed to get recent messages")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ArchiveOlderThan(t *testing.T) {
+	ctx := context.Background()
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	const selectQuery = `SELECT id FROM messages WHERE status = \$1 AND sent_at < \$2 ORDER BY id LIMIT \$3 FOR UPDATE SKIP LOCKED`
+
+	idRows := func(from, to int64) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id"})
+		for id := from; id <= to; id++ {
+			rows.AddRow(id)
+		}
+		return rows
+	}
+	idRange := func(from, to int64) []int64 {
+		var ids []int64
+		for id := from; id <= to; id++ {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	t.Run("moves messages in batches until a short batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		// A full batch is followed by another attempt
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, archiveBatchSize).
+			WillReturnRows(idRows(1, archiveBatchSize))
+		mock.ExpectExec(`INSERT INTO messages_archive \(.+\) SELECT .+ FROM messages WHERE id = ANY\(\$1\)`).
+			WithArgs(pq.Array(idRange(1, archiveBatchSize))).
+			WillReturnResult(sqlmock.NewResult(0, archiveBatchSize))
+		mock.ExpectExec(`DELETE FROM messages WHERE id = ANY\(\$1\)`).
+			WithArgs(pq.Array(idRange(1, archiveBatchSize))).
+			WillReturnResult(sqlmock.NewResult(0, archiveBatchSize))
+		mock.ExpectCommit()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, archiveBatchSize).
+			WillReturnRows(idRows(archiveBatchSize+1, archiveBatchSize+2))
+		mock.ExpectExec(`INSERT INTO messages_archive`).
+			WithArgs(pq.Array(idRange(archiveBatchSize+1, archiveBatchSize+2))).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`DELETE FROM messages`).
+			WithArgs(pq.Array(idRange(archiveBatchSize+1, archiveBatchSize+2))).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		archived, err := repo.ArchiveOlderThan(ctx, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, archiveBatchSize+2, archived)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to archive", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, archiveBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		archived, err := repo.ArchiveOlderThan(ctx, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, 0, archived)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed copy rolls back the batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, archiveBatchSize).
+			WillReturnRows(idRows(1, 2))
+		mock.ExpectExec(`INSERT INTO messages_archive`).
+			WithArgs(pq.Array([]int64{1, 2})).
+			WillReturnError(errors.New("relation \"messages_archive\" does not exist"))
+		mock.ExpectRollback()
+
+		archived, err := repo.ArchiveOlderThan(ctx, cutoff)
+		require.Error(t, err)
+		assert.Equal(t, 0, archived)
+		assert.Contains(t, err.Error(), "failed to copy messages to archive")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_DeleteOlderThan(t *testing.T) {
+	ctx := context.Background()
+	cutoff := time.Now().Add(-90 * 24 * time.Hour)
+
+	const deleteQuery = `WITH deleted AS \( DELETE FROM messages WHERE id IN \( SELECT id FROM messages WHERE status = \$1 AND updated_at < \$2 ORDER BY id LIMIT \$3 FOR UPDATE SKIP LOCKED \) RETURNING id \), deleted_attempts AS \( DELETE FROM webhook_attempts WHERE message_id IN \(SELECT id FROM deleted\) \) SELECT COUNT\(\*\) FROM deleted`
+
+	countRow := func(count int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"count"}).AddRow(count)
+	}
+
+	t.Run("deletes in batches until a short batch", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		// A full batch is followed by another attempt
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, deleteBatchSize).
+			WillReturnRows(countRow(deleteBatchSize))
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, deleteBatchSize).
+			WillReturnRows(countRow(3))
+
+		deleted, err := repo.DeleteOlderThan(ctx, domain.MessageStatusSent, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, deleteBatchSize+3, deleted)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("uses the given status", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(domain.MessageStatusDeadLetter, cutoff, deleteBatchSize).
+			WillReturnRows(countRow(0))
+
+		deleted, err := repo.DeleteOlderThan(ctx, domain.MessageStatusDeadLetter, cutoff)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error reports what was deleted so far", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, deleteBatchSize).
+			WillReturnRows(countRow(deleteBatchSize))
+		mock.ExpectQuery(deleteQuery).
+			WithArgs(domain.MessageStatusSent, cutoff, deleteBatchSize).
+			WillReturnError(errors.New("connection reset"))
+
+		deleted, err := repo.DeleteOlderThan(ctx, domain.MessageStatusSent, cutoff)
+		require.Error(t, err)
+		assert.Equal(t, deleteBatchSize, deleted)
+		assert.Contains(t, err.Error(), "failed to delete sent messages")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cancelled context stops between batches", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		deleted, err := repo.DeleteOlderThan(cancelCtx, domain.MessageStatusSent, cutoff)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, deleted)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_CountByRecipientSince(t *testing.T) {
+	ctx := context.Background()
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages WHERE recipient = \$1 AND created_at >= \$2`).
+			WithArgs("test@example.com", since).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		count, err := repo.CountByRecipientSince(ctx, "test@example.com", since)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM messages`).
+			WillReturnError(errors.New("connection refused"))
+
+		_, err = repo.CountByRecipientSince(ctx, "test@example.com", since)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to count messages for recipient")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_RecordAttempt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failed attempt", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectExec(`INSERT INTO webhook_attempts \(message_id, status_code, duration_ms, error\)`).
+			WithArgs(int64(1), int64(500), int64(120), "webhook delivery failed with status 500").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err = repo.RecordAttempt(ctx, 1, 500, 120, errors.New("webhook delivery failed with status 500"))
+		require.NoError(t, err)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no response or error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectExec(`INSERT INTO webhook_attempts`).
+			WithArgs(int64(1), nil, int64(40), nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		require.NoError(t, repo.RecordAttempt(ctx, 1, 0, 40, nil))
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("exec error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectExec(`INSERT INTO webhook_attempts`).
+			WillReturnError(errors.New("connection refused"))
+
+		err = repo.RecordAttempt(ctx, 1, 202, 40, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record webhook attempt")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ListAttempts(t *testing.T) {
+	ctx := context.Background()
+	first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Second)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		rows := sqlmock.NewRows([]string{"id", "message_id", "status_code", "duration_ms", "error", "created_at"}).
+			AddRow(1, 7, nil, 30000, "HTTP request failed: timeout", first).
+			AddRow(2, 7, 202, 85, nil, second)
+		mock.ExpectQuery(`SELECT id, message_id, status_code, duration_ms, error, created_at FROM webhook_attempts WHERE message_id = \$1 ORDER BY created_at, id`).
+			WithArgs(int64(7)).
+			WillReturnRows(rows)
+
+		attempts, err := repo.ListAttempts(ctx, 7)
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+
+		assert.Equal(t, int64(1), attempts[0].ID)
+		assert.Equal(t, int64(7), attempts[0].MessageID)
+		assert.Nil(t, attempts[0].StatusCode)
+		assert.Equal(t, int64(30000), attempts[0].DurationMs)
+		require.NotNil(t, attempts[0].Error)
+		assert.Equal(t, "HTTP request failed: timeout", *attempts[0].Error)
+		assert.Equal(t, first, attempts[0].CreatedAt)
+
+		require.NotNil(t, attempts[1].StatusCode)
+		assert.Equal(t, 202, *attempts[1].StatusCode)
+		assert.Nil(t, attempts[1].Error)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		repo := NewMessageRepository(db)
+
+		mock.ExpectQuery(`SELECT (.+) FROM webhook_attempts`).
+			WillReturnError(errors.New("connection refused"))
+
+		_, err = repo.ListAttempts(ctx, 7)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list webhook attempts")
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_ClaimPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	claimQuery := `UPDATE messages SET status = \$1, updated_at = NOW\(\) WHERE id IN \( SELECT id FROM messages WHERE \(status = \$2 .+ FOR UPDATE SKIP LOCKED \) RETURNING .+`
+
+	t.Run("claims oldest first", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(messageTestColumns).AddRow(messageRow(
+			2, "test2@example.com", "Message 2", "https://example.com/webhook",
+			domain.MessageStatusSending, 0, 3, now, now,
+		)...).AddRow(messageRow(
+			1, "test1@example.com", "Message 1", "https://example.com/webhook",
+			domain.MessageStatusSending, 1, 3, now.Add(-time.Minute), now,
+		)...)
+
+		mock.ExpectQuery(claimQuery).
+			WithArgs(domain.MessageStatusSending, domain.MessageStatusPending, domain.MessageStatusFailed, int64(300000), 10).
+			WillReturnRows(rows)
+
+		messages, err := repo.ClaimPending(ctx, 10, 5*time.Minute)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, int64(1), messages[0].ID)
+		assert.Equal(t, int64(2), messages[1].ID)
+		assert.Equal(t, domain.MessageStatusSending, messages[0].Status)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(claimQuery).
+			WillReturnError(sql.ErrConnDone)
+
+		messages, err := repo.ClaimPending(ctx, 10, time.Minute)
+		require.Error(t, err)
+		assert.Nil(t, messages)
+		assert.Contains(t, err.Error(), "failed to claim messages")
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// RecipientCounter keeps a fast running count of messages created per
+// recipient per UTC day, used to enforce the recipient daily limit without
+// counting rows on every create
+type RecipientCounter interface {
+	// IncrementRecipientDailyCount adds delta to recipient's count for the UTC
+	// day starting at day and returns the new count. A counter that did not
+	// exist starts from zero, so a result equal to delta means it was just
+	// created.
+	IncrementRecipientDailyCount(ctx context.Context, recipient string, day time.Time, delta int64) (int64, error)
+}
@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+// RecipientSuppressionStore records recipients whose deliveries are on hold
+type RecipientSuppressionStore interface {
+	// SuppressRecipient holds deliveries to recipient for duration, or until
+	// removed when duration is zero. Suppressing an already suppressed
+	// recipient replaces its suppression.
+	SuppressRecipient(ctx context.Context, recipient string, duration time.Duration) (*domain.Suppression, error)
+
+	// UnsuppressRecipient removes the suppression of recipient, returning
+	// domain.ErrNotSuppressed if it is not suppressed
+	UnsuppressRecipient(ctx context.Context, recipient string) error
+
+	// ListSuppressedRecipients returns the recipients currently suppressed,
+	// ordered by recipient
+	ListSuppressedRecipients(ctx context.Context) ([]*domain.Suppression, error)
+}
+
+// newRecipientSuppression builds the suppression record for recipient starting now
+func newRecipientSuppression(recipient string, duration time.Duration) *domain.Suppression {
+	now := time.Now()
+	suppression := &domain.Suppression{
+		Key:       recipient,
+		Kind:      domain.SuppressionRecipient,
+		CreatedAt: now,
+	}
+	if duration > 0 {
+		until := now.Add(duration)
+		suppression.Until = &until
+	}
+	return suppression
+}
+
+// sortSuppressions orders suppressions by key
+func sortSuppressions(suppressions []*domain.Suppression) {
+	sort.Slice(suppressions, func(i, j int) bool {
+		return suppressions[i].Key < suppressions[j].Key
+	})
+}
+
+// inMemoryRecipientSuppressionStore implements RecipientSuppressionStore for a single instance
+type inMemoryRecipientSuppressionStore struct {
+	mu         sync.Mutex
+	recipients map[string]*domain.Suppression
+}
+
+// NewInMemoryRecipientSuppressionStore creates a recipient suppression store
+// that is local to this process
+func NewInMemoryRecipientSuppressionStore() RecipientSuppressionStore {
+	return &inMemoryRecipientSuppressionStore{
+		recipients: make(map[string]*domain.Suppression),
+	}
+}
+
+// SuppressRecipient holds deliveries to recipient
+func (s *inMemoryRecipientSuppressionStore) SuppressRecipient(ctx context.Context, recipient string, duration time.Duration) (*domain.Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	suppression := newRecipientSuppression(recipient, duration)
+	s.recipients[recipient] = suppression
+
+	return suppression, nil
+}
+
+// UnsuppressRecipient removes the suppression of recipient
+func (s *inMemoryRecipientSuppressionStore) UnsuppressRecipient(ctx context.Context, recipient string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	suppression, exists := s.recipients[recipient]
+	if !exists {
+		return domain.ErrNotSuppressed
+	}
+	delete(s.recipients, recipient)
+
+	if !suppression.IsActive(time.Now()) {
+		return domain.ErrNotSuppressed
+	}
+
+	return nil
+}
+
+// ListSuppressedRecipients returns the recipients currently suppressed,
+// dropping lapsed suppressions
+func (s *inMemoryRecipientSuppressionStore) ListSuppressedRecipients(ctx context.Context) ([]*domain.Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	suppressions := make([]*domain.Suppression, 0, len(s.recipients))
+	for recipient, suppression := range s.recipients {
+		if !suppression.IsActive(now) {
+			delete(s.recipients, recipient)
+			continue
+		}
+		suppressions = append(suppressions, suppression)
+	}
+
+	sortSuppressions(suppressions)
+	return suppressions, nil
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryRecipientSuppressionStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("suppress, list and unsuppress", func(t *testing.T) {
+		store := NewInMemoryRecipientSuppressionStore()
+
+		suppression, err := store.SuppressRecipient(ctx, "b@example.com", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "b@example.com", suppression.Key)
+		assert.Equal(t, domain.SuppressionRecipient, suppression.Kind)
+		require.NotNil(t, suppression.Until)
+		assert.WithinDuration(t, suppression.CreatedAt.Add(time.Hour), *suppression.Until, time.Millisecond)
+
+		_, err = store.SuppressRecipient(ctx, "a@example.com", 0)
+		require.NoError(t, err)
+
+		suppressions, err := store.ListSuppressedRecipients(ctx)
+		require.NoError(t, err)
+		require.Len(t, suppressions, 2)
+		assert.Equal(t, "a@example.com", suppressions[0].Key)
+		assert.Nil(t, suppressions[0].Until)
+		assert.Equal(t, "b@example.com", suppressions[1].Key)
+
+		require.NoError(t, store.UnsuppressRecipient(ctx, "a@example.com"))
+		assert.ErrorIs(t, store.UnsuppressRecipient(ctx, "a@example.com"), domain.ErrNotSuppressed)
+
+		suppressions, err = store.ListSuppressedRecipients(ctx)
+		require.NoError(t, err)
+		require.Len(t, suppressions, 1)
+		assert.Equal(t, "b@example.com", suppressions[0].Key)
+	})
+
+	t.Run("lapsed suppressions are dropped", func(t *testing.T) {
+		store := NewInMemoryRecipientSuppressionStore()
+
+		_, err := store.SuppressRecipient(ctx, "a@example.com", time.Nanosecond)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		suppressions, err := store.ListSuppressedRecipients(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, suppressions)
+		assert.ErrorIs(t, store.UnsuppressRecipient(ctx, "a@example.com"), domain.ErrNotSuppressed)
+	})
+}
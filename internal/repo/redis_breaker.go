@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheUnavailable is returned by cache operations skipped because Redis
+// failed recently
+var ErrCacheUnavailable = errors.New("redis cache unavailable")
+
+// Defaults used when a RedisCacheRepository is built without options
+const (
+	defaultCacheOperationTimeout = 500 * time.Millisecond
+	defaultCacheFailureCooldown  = 5 * time.Second
+)
+
+// RedisCacheOption configures optional Redis cache behavior
+type RedisCacheOption func(*RedisCacheRepository)
+
+// WithOperationTimeout bounds each Redis command, so a hung Redis fails fast
+// instead of stalling the caller. Zero or negative keeps the default.
+func WithOperationTimeout(timeout time.Duration) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		if timeout > 0 {
+			r.breaker.timeout = timeout
+		}
+	}
+}
+
+// WithFailureCooldown sets how long Redis is skipped after a failed command;
+// zero disables skipping so every command reaches Redis
+func WithFailureCooldown(cooldown time.Duration) RedisCacheOption {
+	return func(r *RedisCacheRepository) {
+		r.breaker.cooldown = max(cooldown, 0)
+	}
+}
+
+// cacheBreaker is a go-redis hook that applies a per-command timeout and,
+// after a command fails, fails every command with ErrCacheUnavailable for the
+// cooldown instead of waiting on a Redis that is likely still down
+type cacheBreaker struct {
+	timeout  time.Duration
+	cooldown time.Duration
+
+	// openUntil is the Unix nanosecond time Redis is skipped until
+	openUntil atomic.Int64
+}
+
+// newCacheBreaker returns a breaker with the default timeout and cooldown
+func newCacheBreaker() *cacheBreaker {
+	return &cacheBreaker{
+		timeout:  defaultCacheOperationTimeout,
+		cooldown: defaultCacheFailureCooldown,
+	}
+}
+
+// isOpen reports whether Redis is being skipped after a recent failure
+func (b *cacheBreaker) isOpen() bool {
+	return time.Now().UnixNano() < b.openUntil.Load()
+}
+
+// record opens the breaker when err means Redis is unreachable or too slow.
+// Misses, error replies and callers giving up on their own context are not
+// outages.
+func (b *cacheBreaker) record(parent context.Context, err error) {
+	if err == nil || b.cooldown == 0 || parent.Err() != nil {
+		return
+	}
+	if errors.Is(err, redis.Nil) {
+		return
+	}
+	var reply redis.Error
+	if errors.As(err, &reply) {
+		return
+	}
+	b.openUntil.Store(time.Now().Add(b.cooldown).UnixNano())
+}
+
+// DialHook implements redis.Hook
+func (b *cacheBreaker) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook implements redis.Hook
+func (b *cacheBreaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if b.isOpen() {
+			cmd.SetErr(ErrCacheUnavailable)
+			return ErrCacheUnavailable
+		}
+
+		opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+
+		err := next(opCtx, cmd)
+		b.record(ctx, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook
+func (b *cacheBreaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if b.isOpen() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCacheUnavailable)
+			}
+			return ErrCacheUnavailable
+		}
+
+		opCtx, cancel := context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+
+		err := next(opCtx, cmds)
+		b.record(ctx, err)
+		return err
+	}
+}
@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+func TestRedisCacheRepository_FailureCooldown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("skips redis after a failure until the cooldown passes", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour,
+			WithFailureCooldown(100*time.Millisecond))
+		require.NoError(t, err)
+		defer cache.Close()
+
+		server.Close()
+		_, err = cache.GetCachedMessage(ctx, 1)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCacheUnavailable, "the first failure must reach redis")
+
+		// Redis is back, but the breaker keeps skipping it for the cooldown
+		require.NoError(t, server.Restart())
+		_, err = cache.GetCachedMessage(ctx, 1)
+		assert.ErrorIs(t, err, ErrCacheUnavailable)
+		assert.ErrorIs(t, cache.Health(ctx), ErrCacheUnavailable)
+
+		time.Sleep(150 * time.Millisecond)
+		message, err := cache.GetCachedMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, message)
+	})
+
+	t.Run("misses do not open the breaker", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+		require.NoError(t, err)
+		defer cache.Close()
+
+		message, err := cache.GetCachedMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, message)
+
+		require.NoError(t, cache.CacheMessage(ctx, &domain.Message{ID: 1, Status: domain.MessageStatusSent}))
+		message, err = cache.GetCachedMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), message.ID)
+	})
+
+	t.Run("zero cooldown retries redis on every call", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour,
+			WithFailureCooldown(0))
+		require.NoError(t, err)
+		defer cache.Close()
+
+		server.Close()
+		_, err = cache.GetCachedMessage(ctx, 1)
+		require.Error(t, err)
+
+		require.NoError(t, server.Restart())
+		assert.NoError(t, cache.Health(ctx))
+	})
+
+	t.Run("slow commands time out", func(t *testing.T) {
+		// A server that accepts connections but never replies
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+
+		client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), ContextTimeoutEnabled: true})
+		cache := &RedisCacheRepository{client: client, ttl: time.Hour, breaker: newCacheBreaker()}
+		WithOperationTimeout(20 * time.Millisecond)(cache)
+		client.AddHook(cache.breaker)
+		defer cache.Close()
+
+		start := time.Now()
+		_, err = cache.GetCachedMessage(ctx, 1)
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+
+		_, err = cache.GetCachedMessage(ctx, 1)
+		assert.ErrorIs(t, err, ErrCacheUnavailable, "a timeout counts as a failure")
+	})
+}
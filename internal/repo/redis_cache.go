@@ -6,9 +6,29 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/redis/go-redis/v9"
 )
 
+// pausedHostKeyPrefix namespaces the keys holding paused webhook hosts
+const pausedHostKeyPrefix = "webhook:paused:"
+
+// suppressedRecipientKeyPrefix namespaces the keys holding suppressed recipients
+const suppressedRecipientKeyPrefix = "recipient:suppressed:"
+
+// recentlySentKey holds the IDs of the most recently sent messages, newest first
+const recentlySentKey = "messages:recently_sent"
+
+// messageKeyPrefix namespaces the keys holding full cached messages
+const messageKeyPrefix = "message:cached:"
+
+// recipientDailyCountKeyPrefix namespaces the per-recipient daily message counters
+const recipientDailyCountKeyPrefix = "recipient:daily:"
+
+// recipientDailyCountGrace keeps a day's counter around briefly after midnight
+// so instances with slightly skewed clocks still find it
+const recipientDailyCountGrace = time.Hour
+
 // MessageMetadata represents cached metadata for sent messages
 type MessageMetadata struct {
 	ID         int       `json:"id"`
@@ -22,18 +42,23 @@ type MessageMetadata struct {
 
 // RedisCacheRepository provides Redis-based caching for message metadata
 type RedisCacheRepository struct {
-	client *redis.Client
-	ttl    time.Duration
+	client  *redis.Client
+	ttl     time.Duration
+	breaker *cacheBreaker
 }
 
-// NewRedisCacheRepository creates a new Redis cache repository
-func NewRedisCacheRepository(redisURL string, ttl time.Duration) (*RedisCacheRepository, error) {
-	opts, err := redis.ParseURL(redisURL)
+// NewRedisCacheRepository creates a new Redis cache repository. Each command
+// after the initial connection check is bounded by the operation timeout, and
+// Redis is skipped for the failure cooldown after a command fails, with
+// ErrCacheUnavailable returned meanwhile.
+func NewRedisCacheRepository(redisURL string, ttl time.Duration, opts ...RedisCacheOption) (*RedisCacheRepository, error) {
+	clientOpts, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
 	}
+	clientOpts.ContextTimeoutEnabled = true
 
-	client := redis.NewClient(opts)
+	client := redis.NewClient(clientOpts)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -43,10 +68,17 @@ func NewRedisCacheRepository(redisURL string, ttl time.Duration) (*RedisCacheRep
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	return &RedisCacheRepository{
-		client: client,
-		ttl:    ttl,
-	}, nil
+	r := &RedisCacheRepository{
+		client:  client,
+		ttl:     ttl,
+		breaker: newCacheBreaker(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	client.AddHook(r.breaker)
+
+	return r, nil
 }
 
 // CacheMessageMetadata stores message metadata in Redis
@@ -96,9 +128,56 @@ func (r *RedisCacheRepository) DeleteMessageMetadata(ctx context.Context, messag
 	return nil
 }
 
+// CacheMessage stores a full copy of a message in Redis
+func (r *RedisCacheRepository) CacheMessage(ctx context.Context, message *domain.Message) error {
+	key := fmt.Sprintf("%s%d", messageKeyPrefix, message.ID)
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	if err := r.client.Set(ctx, key, data, r.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache message: %w", err)
+	}
+
+	return nil
+}
+
+// GetCachedMessage retrieves a full copy of a message from Redis, or nil on a miss
+func (r *RedisCacheRepository) GetCachedMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	key := fmt.Sprintf("%s%d", messageKeyPrefix, messageID)
+
+	data, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil // Cache miss
+		}
+		return nil, fmt.Errorf("failed to get message from cache: %w", err)
+	}
+
+	var message domain.Message
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// DeleteCachedMessage removes a full copy of a message from Redis
+func (r *RedisCacheRepository) DeleteCachedMessage(ctx context.Context, messageID int64) error {
+	key := fmt.Sprintf("%s%d", messageKeyPrefix, messageID)
+
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete message from cache: %w", err)
+	}
+
+	return nil
+}
+
 // CacheRecentlySentMessages stores a list of recently sent message IDs
 func (r *RedisCacheRepository) CacheRecentlySentMessages(ctx context.Context, messageIDs []int) error {
-	key := "messages:recently_sent"
+	key := recentlySentKey
 
 	// Convert IDs to strings for Redis list
 	values := make([]interface{}, len(messageIDs))
@@ -124,7 +203,7 @@ func (r *RedisCacheRepository) CacheRecentlySentMessages(ctx context.Context, me
 
 // GetRecentlySentMessages retrieves recently sent message IDs from Redis
 func (r *RedisCacheRepository) GetRecentlySentMessages(ctx context.Context, limit int) ([]int, error) {
-	key := "messages:recently_sent"
+	key := recentlySentKey
 
 	results, err := r.client.LRange(ctx, key, 0, int64(limit-1)).Result()
 	if err != nil {
@@ -145,6 +224,179 @@ func (r *RedisCacheRepository) GetRecentlySentMessages(ctx context.Context, limi
 	return messageIDs, nil
 }
 
+// AddRecentlySentMessage puts a message ID at the head of the recently sent
+// list, dropping an earlier entry for it and trimming the list to window IDs
+func (r *RedisCacheRepository) AddRecentlySentMessage(ctx context.Context, messageID int64, window int) error {
+	pipe := r.client.TxPipeline()
+	// A message marked sent again moves to the head rather than being listed twice
+	pipe.LRem(ctx, recentlySentKey, 0, messageID)
+	pipe.LPush(ctx, recentlySentKey, messageID)
+	pipe.LTrim(ctx, recentlySentKey, 0, int64(window-1))
+	pipe.Expire(ctx, recentlySentKey, r.ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to add recently sent message: %w", err)
+	}
+
+	return nil
+}
+
+// PauseHost stores the pause for host so every instance sees it. A timed pause
+// is stored with a matching TTL so Redis lifts it on its own.
+func (r *RedisCacheRepository) PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error) {
+	paused := newPausedHost(host, duration)
+
+	data, err := json.Marshal(paused)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal paused host: %w", err)
+	}
+
+	if err := r.client.Set(ctx, pausedHostKeyPrefix+host, data, duration).Err(); err != nil {
+		return nil, fmt.Errorf("failed to pause host: %w", err)
+	}
+
+	return paused, nil
+}
+
+// ResumeHost removes the pause for host
+func (r *RedisCacheRepository) ResumeHost(ctx context.Context, host string) error {
+	deleted, err := r.client.Del(ctx, pausedHostKeyPrefix+host).Result()
+	if err != nil {
+		return fmt.Errorf("failed to resume host: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrHostNotPaused
+	}
+
+	return nil
+}
+
+// ListPausedHosts returns the hosts currently paused across all instances
+func (r *RedisCacheRepository) ListPausedHosts(ctx context.Context) ([]*domain.PausedHost, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, pausedHostKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan paused hosts: %w", err)
+	}
+
+	hosts := make([]*domain.PausedHost, 0, len(keys))
+	if len(keys) == 0 {
+		return hosts, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paused hosts: %w", err)
+	}
+
+	for _, value := range values {
+		// A key that expired between SCAN and MGET comes back as nil
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var paused domain.PausedHost
+		if err := json.Unmarshal([]byte(data), &paused); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal paused host: %w", err)
+		}
+		hosts = append(hosts, &paused)
+	}
+
+	sortPausedHosts(hosts)
+	return hosts, nil
+}
+
+// SuppressRecipient stores the suppression of recipient so every instance sees
+// it. A timed suppression is stored with a matching TTL so Redis lifts it on
+// its own.
+func (r *RedisCacheRepository) SuppressRecipient(ctx context.Context, recipient string, duration time.Duration) (*domain.Suppression, error) {
+	suppression := newRecipientSuppression(recipient, duration)
+
+	data, err := json.Marshal(suppression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal suppression: %w", err)
+	}
+
+	if err := r.client.Set(ctx, suppressedRecipientKeyPrefix+recipient, data, duration).Err(); err != nil {
+		return nil, fmt.Errorf("failed to suppress recipient: %w", err)
+	}
+
+	return suppression, nil
+}
+
+// UnsuppressRecipient removes the suppression of recipient
+func (r *RedisCacheRepository) UnsuppressRecipient(ctx context.Context, recipient string) error {
+	deleted, err := r.client.Del(ctx, suppressedRecipientKeyPrefix+recipient).Result()
+	if err != nil {
+		return fmt.Errorf("failed to unsuppress recipient: %w", err)
+	}
+	if deleted == 0 {
+		return domain.ErrNotSuppressed
+	}
+
+	return nil
+}
+
+// ListSuppressedRecipients returns the recipients currently suppressed across
+// all instances
+func (r *RedisCacheRepository) ListSuppressedRecipients(ctx context.Context) ([]*domain.Suppression, error) {
+	var keys []string
+	iter := r.client.Scan(ctx, 0, suppressedRecipientKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan suppressed recipients: %w", err)
+	}
+
+	suppressions := make([]*domain.Suppression, 0, len(keys))
+	if len(keys) == 0 {
+		return suppressions, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suppressed recipients: %w", err)
+	}
+
+	for _, value := range values {
+		// A key that expired between SCAN and MGET comes back as nil
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var suppression domain.Suppression
+		if err := json.Unmarshal([]byte(data), &suppression); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal suppression: %w", err)
+		}
+		suppressions = append(suppressions, &suppression)
+	}
+
+	sortSuppressions(suppressions)
+	return suppressions, nil
+}
+
+// IncrementRecipientDailyCount adds delta to recipient's message count for the
+// UTC day starting at day. The counter expires shortly after that day ends.
+func (r *RedisCacheRepository) IncrementRecipientDailyCount(ctx context.Context, recipient string, day time.Time, delta int64) (int64, error) {
+	key := recipientDailyCountKeyPrefix + day.UTC().Format("2006-01-02") + ":" + recipient
+
+	pipe := r.client.TxPipeline()
+	count := pipe.IncrBy(ctx, key, delta)
+	pipe.ExpireAt(ctx, key, day.Add(24*time.Hour+recipientDailyCountGrace))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment recipient daily count: %w", err)
+	}
+
+	return count.Val(), nil
+}
+
 // Close closes the Redis connection
 func (r *RedisCacheRepository) Close() error {
 	return r.client.Close()
@@ -2,9 +2,12 @@ package repo
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +60,38 @@ func TestRedisCacheRepository_Integration(t *testing.T) {
 		assert.Nil(t, retrieved) // Should return nil for cache miss
 	})
 
+	t.Run("CacheAndGetMessage", func(t *testing.T) {
+		sentAt := time.Now().UTC().Truncate(time.Second)
+		providerID := "provider-789"
+		message := &domain.Message{
+			ID:                789,
+			Recipient:         "cached@example.com",
+			Content:           "Cached content",
+			WebhookURL:        "https://example.com/webhook",
+			Status:            domain.MessageStatusSent,
+			MaxRetries:        3,
+			CreatedAt:         sentAt.Add(-time.Minute),
+			UpdatedAt:         sentAt,
+			SentAt:            &sentAt,
+			ProviderMessageID: &providerID,
+		}
+
+		require.NoError(t, cache.CacheMessage(ctx, message))
+
+		retrieved, err := cache.GetCachedMessage(ctx, 789)
+		require.NoError(t, err)
+		assert.Equal(t, message, retrieved)
+
+		missing, err := cache.GetCachedMessage(ctx, 790)
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+
+		require.NoError(t, cache.DeleteCachedMessage(ctx, 789))
+		deleted, err := cache.GetCachedMessage(ctx, 789)
+		require.NoError(t, err)
+		assert.Nil(t, deleted)
+	})
+
 	t.Run("DeleteMessageMetadata", func(t *testing.T) {
 		metadata := &MessageMetadata{
 			ID:         456,
@@ -116,12 +151,45 @@ func TestRedisCacheRepository_Integration(t *testing.T) {
 		assert.Empty(t, retrieved)
 	})
 
+	t.Run("AddRecentlySentMessage", func(t *testing.T) {
+		require.NoError(t, cache.CacheRecentlySentMessages(ctx, []int{}))
+
+		for id := int64(1); id <= 5; id++ {
+			require.NoError(t, cache.AddRecentlySentMessage(ctx, id, 3))
+		}
+
+		// Newest first, trimmed to the window
+		retrieved, err := cache.GetRecentlySentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{5, 4, 3}, retrieved)
+	})
+
 	t.Run("HealthCheck", func(t *testing.T) {
 		err := cache.Health(ctx)
 		assert.NoError(t, err)
 	})
 }
 
+func TestRedisCacheRepository_AddRecentlySentMessage(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache, err := NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	ctx := context.Background()
+
+	for _, id := range []int64{1, 2, 3} {
+		require.NoError(t, cache.AddRecentlySentMessage(ctx, id, 3))
+	}
+
+	// A message marked sent again moves to the head instead of being listed
+	// twice and pushing another message out of the window
+	require.NoError(t, cache.AddRecentlySentMessage(ctx, 2, 3))
+	retrieved, err := cache.GetRecentlySentMessages(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 1}, retrieved)
+}
+
 func TestRedisCacheRepository_InvalidURL(t *testing.T) {
 	_, err := NewRedisCacheRepository("invalid-url", time.Hour)
 	assert.Error(t, err)
@@ -134,3 +202,97 @@ func TestRedisCacheRepository_ConnectionFailure(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to connect to Redis")
 }
+
+func TestRedisCacheRepository_HostPauses(t *testing.T) {
+	// Skip if Redis is not available
+	cache, err := NewRedisCacheRepository("redis://localhost:6379", time.Hour)
+	if err != nil {
+		t.Skipf("Redis not available, skipping integration tests: %v", err)
+		return
+	}
+	defer cache.Close()
+
+	ctx := context.Background()
+	host := "pause-test.example.com"
+	defer cache.ResumeHost(ctx, host)
+
+	paused, err := cache.PauseHost(ctx, host, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, paused.Until)
+
+	hosts, err := cache.ListPausedHosts(ctx)
+	require.NoError(t, err)
+	var found bool
+	for _, h := range hosts {
+		if h.Host == host {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	require.NoError(t, cache.ResumeHost(ctx, host))
+	assert.ErrorIs(t, cache.ResumeHost(ctx, host), domain.ErrHostNotPaused)
+}
+
+func TestRedisCacheRepository_RecipientSuppressions(t *testing.T) {
+	// Skip if Redis is not available
+	cache, err := NewRedisCacheRepository("redis://localhost:6379", time.Hour)
+	if err != nil {
+		t.Skipf("Redis not available, skipping integration tests: %v", err)
+		return
+	}
+	defer cache.Close()
+
+	ctx := context.Background()
+	recipient := "suppression-test@example.com"
+	defer cache.UnsuppressRecipient(ctx, recipient)
+
+	suppression, err := cache.SuppressRecipient(ctx, recipient, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, suppression.Until)
+
+	suppressions, err := cache.ListSuppressedRecipients(ctx)
+	require.NoError(t, err)
+	var found bool
+	for _, s := range suppressions {
+		if s.Key == recipient {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	require.NoError(t, cache.UnsuppressRecipient(ctx, recipient))
+	assert.ErrorIs(t, cache.UnsuppressRecipient(ctx, recipient), domain.ErrNotSuppressed)
+}
+
+func TestRedisCacheRepository_RecipientDailyCount(t *testing.T) {
+	// Skip if Redis is not available
+	cache, err := NewRedisCacheRepository("redis://localhost:6379", time.Hour)
+	if err != nil {
+		t.Skipf("Redis not available, skipping integration tests: %v", err)
+		return
+	}
+	defer cache.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	recipient := fmt.Sprintf("limit-test-%d@example.com", now.UnixNano())
+
+	count, err := cache.IncrementRecipientDailyCount(ctx, recipient, day, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = cache.IncrementRecipientDailyCount(ctx, recipient, day, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	count, err = cache.IncrementRecipientDailyCount(ctx, recipient, day, -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	// Another day has its own counter
+	count, err = cache.IncrementRecipientDailyCount(ctx, recipient, day.AddDate(0, 0, 1), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
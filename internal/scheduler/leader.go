@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaderLock is a lock shared by scheduler replicas so only one of them runs
+// the background loops at a time
+type LeaderLock interface {
+	// AcquireLeaderLock takes the lock for owner, or renews it for ttl when
+	// owner already holds it, and reports whether owner holds it afterwards
+	AcquireLeaderLock(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+
+	// ReleaseLeaderLock gives up the lock if owner holds it
+	ReleaseLeaderLock(ctx context.Context, owner string) error
+}
+
+// defaultLeaderLockTTL is used when a leader lock is configured without a TTL
+const defaultLeaderLockTTL = 30 * time.Second
+
+// leaderLockReleaseTimeout bounds releasing the lock on stop
+const leaderLockReleaseTimeout = 5 * time.Second
+
+// newLeaderID returns an ID identifying this replica as a lock owner
+func newLeaderID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "scheduler"
+	}
+	return host + "-" + uuid.NewString()
+}
+
+// holdLeadership acquires the leader lock and keeps renewing it every third of
+// its TTL. A replica that does not hold it keeps trying on the same schedule.
+func (s *Scheduler) holdLeadership() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.leaderLockTTL / 3)
+	defer ticker.Stop()
+
+	s.refreshLeadership()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshLeadership()
+		}
+	}
+}
+
+// refreshLeadership tries to acquire or renew the leader lock once. Any error
+// counts as losing it, so a replica cut off from the lock stops processing
+// before its lease could have passed to another.
+func (s *Scheduler) refreshLeadership() {
+	ctx, cancel := context.WithTimeout(s.ctx, s.leaderLockTTL/3)
+	defer cancel()
+
+	held, err := s.leaderLock.AcquireLeaderLock(ctx, s.leaderID, s.leaderLockTTL)
+	if s.ctx.Err() != nil {
+		// Stopping; the lock is released once the loops have finished
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to acquire leader lock", "error", err)
+		held = false
+	}
+
+	wasLeader := s.leader.Swap(held)
+	switch {
+	case held && !wasLeader:
+		s.logger.Info("Acquired leader lock, processing on this replica", "leader_id", s.leaderID)
+	case !held && wasLeader:
+		s.logger.Warn("Lost leader lock, processing paused on this replica", "leader_id", s.leaderID)
+	}
+}
+
+// releaseLeadership gives up the leader lock if this replica holds it. The
+// release is attempted even when a failed renewal made this replica step down,
+// since the lock may still be in its name.
+func (s *Scheduler) releaseLeadership() {
+	wasLeader := s.leader.Swap(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaderLockReleaseTimeout)
+	defer cancel()
+
+	if err := s.leaderLock.ReleaseLeaderLock(ctx, s.leaderID); err != nil {
+		s.logger.Error("Failed to release leader lock", "error", err)
+		return
+	}
+	if wasLeader {
+		s.logger.Info("Released leader lock", "leader_id", s.leaderID)
+	}
+}
+
+// isLeader reports whether this replica may run the background loops, which is
+// always the case without a leader lock
+func (s *Scheduler) isLeader() bool {
+	return s.leaderLock == nil || s.leader.Load()
+}
@@ -2,49 +2,137 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
 )
 
+// ErrProcessingInProgress is returned by TriggerProcessing while the maximum
+// number of processing runs is already in flight
+var ErrProcessingInProgress = errors.New("processing run already in progress")
+
+// ErrInvalidInterval is returned by UpdateConfig for a zero or negative interval
+var ErrInvalidInterval = errors.New("scheduler intervals must be positive")
+
 // MessageService defines the interface for message processing
 type MessageService interface {
-	ProcessPendingMessages(ctx context.Context) error
+	// ProcessPendingMessages delivers a batch and returns how many were processed
+	ProcessPendingMessages(ctx context.Context) (int, error)
 	RetryFailedMessages(ctx context.Context) error
+
+	// ArchiveOldMessages moves old sent messages to the archive and returns how many were moved
+	ArchiveOldMessages(ctx context.Context) (int, error)
+
+	// PurgeOldMessages deletes messages past their retention and returns how many were deleted
+	PurgeOldMessages(ctx context.Context) (int, error)
 }
 
+// archiveRunTimeout bounds a single archive run, which may move several batches
+const archiveRunTimeout = 5 * time.Minute
+
+// retentionRunTimeout bounds a single retention run, which may delete several batches
+const retentionRunTimeout = 5 * time.Minute
+
 // Scheduler manages background message processing
 type Scheduler struct {
 	messageService MessageService
 	logger         *logger.Logger
+	metrics        *metrics.Metrics // Optional metrics
 
 	// Configuration
 	processingInterval time.Duration
 	retryInterval      time.Duration
+	archiveInterval    time.Duration   // Zero disables the archive loop
+	retentionInterval  time.Duration   // Zero disables the retention loop
+	jitterFraction     float64         // Zero ticks exactly on the interval
+	wakeup             <-chan struct{} // Nil when runs only start on ticks
+
+	// Leadership across replicas; leaderLock is nil when every replica runs
+	// the loops
+	leaderLock    LeaderLock
+	leaderLockTTL time.Duration
+	leaderID      string
+	leader        atomic.Bool
+
+	// processingReset and retryReset tell a running loop to pick up a changed
+	// interval; each holds at most one pending signal
+	processingReset chan struct{}
+	retryReset      chan struct{}
 
 	// Control
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
+	// processingSlots holds a token for each processing run in flight, so
+	// scheduled and manually triggered runs together never exceed its capacity
+	processingSlots chan struct{}
+
 	// Status
-	running bool
-	mu      sync.RWMutex
+	running         bool
+	lastProcessedAt time.Time // When the last processing run finished
+	lastRetryAt     time.Time // When the last retry run finished
+	lastArchiveAt   time.Time // When the last archive run finished
+	lastRetentionAt time.Time // When the last retention run finished
+	mu              sync.RWMutex
 }
 
 // Config holds scheduler configuration
 type Config struct {
 	ProcessingInterval time.Duration
 	RetryInterval      time.Duration
+
+	// ArchiveInterval is how often old sent messages are archived; zero disables it
+	ArchiveInterval time.Duration
+
+	// RetentionInterval is how often messages past their retention are
+	// deleted; zero disables it
+	RetentionInterval time.Duration
+
+	// JitterFraction spreads processing and retry ticks by up to this fraction
+	// of the interval either way, so replicas started together drift apart.
+	// It must be in [0, 1); zero disables jitter.
+	JitterFraction float64
+
+	// Wakeup, when set, starts a processing run each time it receives, between
+	// the regular ticks, e.g. when the database reports a new message. The
+	// processing interval still applies as a safety net for missed wakeups.
+	Wakeup <-chan struct{}
+
+	// Metrics records run durations, outcomes and processed counts; optional
+	Metrics *metrics.Metrics
+
+	// LeaderLock, when set, restricts the processing, retry, archive and
+	// retention loops to the replica holding it; the others idle and keep
+	// trying to acquire it. Manually triggered runs are not restricted.
+	LeaderLock LeaderLock
+
+	// LeaderLockTTL is how long the leader lock outlives its last renewal; it
+	// is renewed every third of it. Zero uses 30s.
+	LeaderLockTTL time.Duration
+
+	// MaxInFlightRuns caps how many processing runs may be in flight at once.
+	// A tick that finds every slot taken is dropped rather than queued, so
+	// slow webhooks cannot pile up runs. Zero uses 1, which never overlaps
+	// runs.
+	MaxInFlightRuns int
 }
 
+// defaultJitterFraction is the tick jitter used by DefaultConfig
+const defaultJitterFraction = 0.1
+
 // DefaultConfig returns default scheduler configuration
 func DefaultConfig() *Config {
 	return &Config{
 		ProcessingInterval: 30 * time.Second,
 		RetryInterval:      5 * time.Minute,
+		JitterFraction:     defaultJitterFraction,
 	}
 }
 
@@ -54,14 +142,104 @@ func NewScheduler(messageService MessageService, logger *logger.Logger, config *
 		config = DefaultConfig()
 	}
 
+	log := logger.WithComponent("scheduler")
+
+	jitterFraction := config.JitterFraction
+	if jitterFraction < 0 || jitterFraction >= 1 {
+		log.Warn("Ignoring out of range jitter fraction", "jitter_fraction", jitterFraction)
+		jitterFraction = 0
+	}
+
+	leaderLockTTL := config.LeaderLockTTL
+	if leaderLockTTL <= 0 {
+		leaderLockTTL = defaultLeaderLockTTL
+	}
+
+	maxInFlightRuns := config.MaxInFlightRuns
+	if maxInFlightRuns <= 0 {
+		maxInFlightRuns = 1
+	}
+
 	return &Scheduler{
 		messageService:     messageService,
-		logger:             logger.WithComponent("scheduler"),
+		logger:             log,
+		metrics:            config.Metrics,
 		processingInterval: config.ProcessingInterval,
 		retryInterval:      config.RetryInterval,
+		archiveInterval:    config.ArchiveInterval,
+		retentionInterval:  config.RetentionInterval,
+		jitterFraction:     jitterFraction,
+		wakeup:             config.Wakeup,
+		leaderLock:         config.LeaderLock,
+		leaderLockTTL:      leaderLockTTL,
+		leaderID:           newLeaderID(),
+		processingReset:    make(chan struct{}, 1),
+		retryReset:         make(chan struct{}, 1),
+		processingSlots:    make(chan struct{}, maxInFlightRuns),
+	}
+}
+
+// UpdateConfig changes the processing and retry intervals. A running scheduler
+// reschedules its next ticks in place, so they come one new interval from
+// now; a stopped scheduler uses the new intervals on its next start. The
+// archive interval, jitter and metrics are fixed at construction and are not
+// changed.
+func (s *Scheduler) UpdateConfig(config *Config) error {
+	if config == nil || config.ProcessingInterval <= 0 || config.RetryInterval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.processingInterval = config.ProcessingInterval
+	s.retryInterval = config.RetryInterval
+
+	s.logger.Info("Scheduler intervals updated",
+		"processing_interval", s.processingInterval,
+		"retry_interval", s.retryInterval,
+		"running", s.running,
+	)
+
+	if s.running {
+		signalReset(s.processingReset)
+		signalReset(s.retryReset)
+	}
+
+	return nil
+}
+
+// signalReset queues a reset signal unless one is already pending
+func signalReset(reset chan struct{}) {
+	select {
+	case reset <- struct{}{}:
+	default:
+	}
+}
+
+// acquireProcessingSlot takes a processing run slot without waiting and
+// reports whether one was free
+func (s *Scheduler) acquireProcessingSlot() bool {
+	select {
+	case s.processingSlots <- struct{}{}:
+		return true
+	default:
+		return false
 	}
 }
 
+// releaseProcessingSlot returns a slot taken by acquireProcessingSlot
+func (s *Scheduler) releaseProcessingSlot() {
+	<-s.processingSlots
+}
+
+// currentIntervals returns the processing and retry intervals under the lock
+func (s *Scheduler) currentIntervals() (processing, retry time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.processingInterval, s.retryInterval
+}
+
 // Start begins the scheduler background processing
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -74,18 +252,47 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	s.ctx, s.cancel = context.WithCancel(ctx)
 	s.running = true
 
+	// Signals left over from updates while stopped are stale; the loops below
+	// start from the current intervals
+	drainReset(s.processingReset)
+	drainReset(s.retryReset)
+
 	s.logger.Info("Starting scheduler",
 		"processing_interval", s.processingInterval,
 		"retry_interval", s.retryInterval,
+		"archive_interval", s.archiveInterval,
+		"retention_interval", s.retentionInterval,
+		"jitter_fraction", s.jitterFraction,
+		"leader_lock", s.leaderLock != nil,
+		"max_in_flight_runs", cap(s.processingSlots),
+		"wakeup", s.wakeup != nil,
 	)
 
+	// Start leadership goroutine when replicas share a leader lock
+	if s.leaderLock != nil {
+		s.wg.Add(1)
+		go s.holdLeadership()
+	}
+
 	// Start processing goroutine
 	s.wg.Add(1)
-	go s.processMessages()
+	go s.processMessages(s.processingInterval)
 
 	// Start retry goroutine
 	s.wg.Add(1)
-	go s.retryFailedMessages()
+	go s.retryFailedMessages(s.retryInterval)
+
+	// Start archive goroutine when archiving is enabled
+	if s.archiveInterval > 0 {
+		s.wg.Add(1)
+		go s.archiveMessages()
+	}
+
+	// Start retention goroutine when retention is enabled
+	if s.retentionInterval > 0 {
+		s.wg.Add(1)
+		go s.purgeMessages()
+	}
 
 	return nil
 }
@@ -93,9 +300,8 @@ func (s *Scheduler) Start(ctx context.Context) error {
 // Stop gracefully stops the scheduler
 func (s *Scheduler) Stop() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if !s.running {
+		s.mu.Unlock()
 		return fmt.Errorf("scheduler is not running")
 	}
 
@@ -103,11 +309,20 @@ func (s *Scheduler) Stop() error {
 
 	// Cancel context to signal goroutines to stop
 	s.cancel()
+	s.mu.Unlock()
 
-	// Wait for all goroutines to finish
+	// Wait for all goroutines to finish. This happens outside the lock because
+	// an in-flight run takes it to record when it finished.
 	s.wg.Wait()
 
+	if s.leaderLock != nil {
+		s.releaseLeadership()
+	}
+
+	s.mu.Lock()
 	s.running = false
+	s.mu.Unlock()
+
 	s.logger.Info("Scheduler stopped")
 
 	return nil
@@ -120,12 +335,32 @@ func (s *Scheduler) IsRunning() bool {
 	return s.running
 }
 
-// processMessages runs the main message processing loop
-func (s *Scheduler) processMessages() {
+// drainReset discards a pending reset signal, if any
+func drainReset(reset chan struct{}) {
+	select {
+	case <-reset:
+	default:
+	}
+}
+
+// jitter returns interval offset by a random amount of up to jitterFraction
+// of it either way
+func (s *Scheduler) jitter(interval time.Duration) time.Duration {
+	if s.jitterFraction == 0 {
+		return interval
+	}
+	offset := (2*rand.Float64() - 1) * s.jitterFraction * float64(interval)
+	return interval + time.Duration(offset)
+}
+
+// processMessages runs the main message processing loop. Each tick is
+// scheduled individually so it can carry its own jitter, and starts its run in
+// the background so a slow run does not delay the next tick.
+func (s *Scheduler) processMessages(interval time.Duration) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.processingInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(s.jitter(interval))
+	defer timer.Stop()
 
 	s.logger.Info("Message processing loop started")
 
@@ -134,18 +369,52 @@ func (s *Scheduler) processMessages() {
 		case <-s.ctx.Done():
 			s.logger.Info("Message processing loop stopped")
 			return
-		case <-ticker.C:
-			s.processMessagesOnce()
+		case <-s.processingReset:
+			interval, _ = s.currentIntervals()
+			timer.Reset(s.jitter(interval))
+		case <-s.wakeup:
+			// A run already in flight may miss the new message; the next
+			// tick picks it up
+			if s.isLeader() && s.startProcessingRun() {
+				s.logger.Debug("Processing run started by wakeup")
+			}
+		case <-timer.C:
+			if !s.isLeader() {
+				s.logger.Debug("Skipping processing tick, not the leader")
+			} else if !s.startProcessingRun() {
+				s.logger.Warn("Skipping processing tick, too many runs in flight",
+					"max_in_flight_runs", cap(s.processingSlots),
+				)
+				if s.metrics != nil {
+					s.metrics.RecordSchedulerDroppedRun()
+				}
+			}
+			timer.Reset(s.jitter(interval))
 		}
 	}
 }
 
+// startProcessingRun starts a processing run in the background and reports
+// whether a processing slot was free for it
+func (s *Scheduler) startProcessingRun() bool {
+	if !s.acquireProcessingSlot() {
+		return false
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.releaseProcessingSlot()
+		s.processMessagesOnce(s.ctx)
+	}()
+	return true
+}
+
 // retryFailedMessages runs the retry processing loop
-func (s *Scheduler) retryFailedMessages() {
+func (s *Scheduler) retryFailedMessages(interval time.Duration) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.retryInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(s.jitter(interval))
+	defer timer.Stop()
 
 	s.logger.Info("Retry processing loop started")
 
@@ -154,25 +423,102 @@ func (s *Scheduler) retryFailedMessages() {
 		case <-s.ctx.Done():
 			s.logger.Info("Retry processing loop stopped")
 			return
+		case <-s.retryReset:
+			_, interval = s.currentIntervals()
+			timer.Reset(s.jitter(interval))
+		case <-timer.C:
+			if s.isLeader() {
+				s.retryFailedMessagesOnce()
+			} else {
+				s.logger.Debug("Skipping retry tick, not the leader")
+			}
+			timer.Reset(s.jitter(interval))
+		}
+	}
+}
+
+// archiveMessages runs the archive loop
+func (s *Scheduler) archiveMessages() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.archiveInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Archive loop started")
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.logger.Info("Archive loop stopped")
+			return
 		case <-ticker.C:
-			s.retryFailedMessagesOnce()
+			if s.isLeader() {
+				s.archiveMessagesOnce()
+			} else {
+				s.logger.Debug("Skipping archive tick, not the leader")
+			}
 		}
 	}
 }
 
-// processMessagesOnce processes pending messages once
-func (s *Scheduler) processMessagesOnce() {
-	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+// purgeMessages runs the retention loop
+func (s *Scheduler) purgeMessages() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.retentionInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Retention loop started")
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.logger.Info("Retention loop stopped")
+			return
+		case <-ticker.C:
+			if s.isLeader() {
+				s.purgeMessagesOnce()
+			} else {
+				s.logger.Debug("Skipping retention tick, not the leader")
+			}
+		}
+	}
+}
+
+// TriggerProcessing runs a single processing cycle immediately and returns how
+// many messages it processed. It works whether or not the scheduler is running,
+// and returns ErrProcessingInProgress when no processing slot is free.
+func (s *Scheduler) TriggerProcessing(ctx context.Context) (int, error) {
+	if !s.acquireProcessingSlot() {
+		return 0, ErrProcessingInProgress
+	}
+	defer s.releaseProcessingSlot()
+
+	s.logger.Info("Processing run triggered manually")
+	return s.processMessagesOnce(ctx)
+}
+
+// processMessagesOnce processes pending messages once. Callers must hold a processing slot.
+func (s *Scheduler) processMessagesOnce(parent context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
 	defer cancel()
 
 	s.logger.Debug("Processing pending messages")
+	defer s.recordRun(&s.lastProcessedAt)
 
-	if err := s.messageService.ProcessPendingMessages(ctx); err != nil {
+	start := time.Now()
+	processed, err := s.messageService.ProcessPendingMessages(ctx)
+	s.recordRunMetrics(runLoopProcess, start, err)
+	if s.metrics != nil {
+		s.metrics.RecordSchedulerMessagesProcessed(processed)
+	}
+	if err != nil {
 		s.logger.Error("Failed to process pending messages", "error", err)
-		return
+		return processed, err
 	}
 
-	s.logger.Debug("Pending messages processed successfully")
+	s.logger.Debug("Pending messages processed successfully", "processed", processed)
+	return processed, nil
 }
 
 // retryFailedMessagesOnce retries failed messages once
@@ -181,8 +527,12 @@ func (s *Scheduler) retryFailedMessagesOnce() {
 	defer cancel()
 
 	s.logger.Debug("Retrying failed messages")
+	defer s.recordRun(&s.lastRetryAt)
 
-	if err := s.messageService.RetryFailedMessages(ctx); err != nil {
+	start := time.Now()
+	err := s.messageService.RetryFailedMessages(ctx)
+	s.recordRunMetrics(runLoopRetry, start, err)
+	if err != nil {
 		s.logger.Error("Failed to retry failed messages", "error", err)
 		return
 	}
@@ -190,14 +540,100 @@ func (s *Scheduler) retryFailedMessagesOnce() {
 	s.logger.Debug("Failed messages retry completed")
 }
 
-// GetStatus returns the current scheduler status
+// archiveMessagesOnce archives old sent messages once
+func (s *Scheduler) archiveMessagesOnce() {
+	ctx, cancel := context.WithTimeout(s.ctx, archiveRunTimeout)
+	defer cancel()
+
+	s.logger.Debug("Archiving old messages")
+	defer s.recordRun(&s.lastArchiveAt)
+
+	archived, err := s.messageService.ArchiveOldMessages(ctx)
+	if err != nil {
+		s.logger.Error("Failed to archive old messages", "archived", archived, "error", err)
+		return
+	}
+
+	s.logger.Debug("Old messages archived", "archived", archived)
+}
+
+// purgeMessagesOnce deletes messages past their retention once. Stopping the
+// scheduler cancels a run in progress between batches.
+func (s *Scheduler) purgeMessagesOnce() {
+	ctx, cancel := context.WithTimeout(s.ctx, retentionRunTimeout)
+	defer cancel()
+
+	s.logger.Debug("Deleting messages past their retention")
+	defer s.recordRun(&s.lastRetentionAt)
+
+	deleted, err := s.messageService.PurgeOldMessages(ctx)
+	if s.metrics != nil {
+		s.metrics.RecordRetentionDeleted(deleted)
+	}
+	if err != nil {
+		s.logger.Error("Failed to delete messages past their retention", "deleted", deleted, "error", err)
+		return
+	}
+
+	s.logger.Debug("Messages past their retention deleted", "deleted", deleted)
+}
+
+// Loop labels of the scheduler run metrics
+const (
+	runLoopProcess = "process"
+	runLoopRetry   = "retry"
+)
+
+// recordRunMetrics records the duration and outcome of a run of loop that
+// started at start, when metrics are configured
+func (s *Scheduler) recordRunMetrics(loop string, start time.Time, err error) {
+	if s.metrics == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	s.metrics.RecordSchedulerRun(loop, result, time.Since(start))
+}
+
+// recordRun stores the completion time of a run, whether or not it succeeded
+func (s *Scheduler) recordRun(lastRun *time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*lastRun = time.Now()
+}
+
+// GetStatus returns the current scheduler status. The last run timestamps are
+// RFC 3339 strings, or nil if that loop has not completed a run yet. With a
+// leader lock it also reports whether this replica is the leader.
 func (s *Scheduler) GetStatus() map[string]interface{} {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return map[string]interface{}{
+	status := map[string]interface{}{
 		"running":             s.running,
 		"processing_interval": s.processingInterval.String(),
 		"retry_interval":      s.retryInterval.String(),
+		"archive_interval":    s.archiveInterval.String(),
+		"retention_interval":  s.retentionInterval.String(),
+		"jitter_fraction":     s.jitterFraction,
+		"last_processed_at":   formatRunTime(s.lastProcessedAt),
+		"last_retry_at":       formatRunTime(s.lastRetryAt),
+		"last_archive_at":     formatRunTime(s.lastArchiveAt),
+		"last_retention_at":   formatRunTime(s.lastRetentionAt),
+	}
+	if s.leaderLock != nil {
+		status["leader"] = s.leader.Load()
+	}
+	return status
+}
+
+// formatRunTime formats a run timestamp for status output, returning nil for
+// a run that has not happened
+func formatRunTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
 	}
+	return t.UTC().Format(time.RFC3339)
 }
@@ -3,39 +3,63 @@ package scheduler
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/insider/insider-messaging/internal/repo"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
 )
 
 // mockMessageService implements MessageService for testing
 type mockMessageService struct {
 	mu                   sync.Mutex
 	processPendingCalled int
+	processPendingCount  int
 	retryFailedCalled    int
+	archiveCalled        int
+	purgeCalled          int
+	purgeDeleted         int
+	purgeBlock           bool          // Makes PurgeOldMessages wait for its context
+	purgeCancelled       chan struct{} // Closed when a blocked purge sees its context end, if set
+	purgeCancelOnce      sync.Once
 	processPendingError  error
 	retryFailedError     error
 	processPendingDelay  time.Duration
+	processPendingStart  chan struct{} // Signalled when a processing run begins, if set
+	processPendingTimes  []time.Time
 	retryFailedDelay     time.Duration
 }
 
-func (m *mockMessageService) ProcessPendingMessages(ctx context.Context) error {
+func (m *mockMessageService) ProcessPendingMessages(ctx context.Context) (int, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.processPendingCalled++
+	m.processPendingTimes = append(m.processPendingTimes, time.Now())
+	if m.processPendingStart != nil {
+		m.processPendingStart <- struct{}{}
+	}
 
 	if m.processPendingDelay > 0 {
 		select {
 		case <-time.After(m.processPendingDelay):
 		case <-ctx.Done():
-			return ctx.Err()
+			return 0, ctx.Err()
 		}
 	}
 
-	return m.processPendingError
+	if m.processPendingError != nil {
+		return 0, m.processPendingError
+	}
+	return m.processPendingCount, nil
 }
 
 func (m *mockMessageService) RetryFailedMessages(ctx context.Context) error {
@@ -55,6 +79,49 @@ func (m *mockMessageService) RetryFailedMessages(ctx context.Context) error {
 	return m.retryFailedError
 }
 
+func (m *mockMessageService) ArchiveOldMessages(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.archiveCalled++
+	return 0, nil
+}
+
+func (m *mockMessageService) PurgeOldMessages(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	m.purgeCalled++
+	block, cancelled := m.purgeBlock, m.purgeCancelled
+	m.mu.Unlock()
+
+	if block {
+		<-ctx.Done()
+		if cancelled != nil {
+			// A later tick can start another purge before Stop lands
+			m.purgeCancelOnce.Do(func() { close(cancelled) })
+		}
+		return 0, ctx.Err()
+	}
+	return m.purgeDeleted, nil
+}
+
+func (m *mockMessageService) getPurgeCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.purgeCalled
+}
+
+func (m *mockMessageService) getArchiveCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.archiveCalled
+}
+
+func (m *mockMessageService) getProcessTimes() []time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Time(nil), m.processPendingTimes...)
+}
+
 func (m *mockMessageService) getCallCounts() (int, int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -274,3 +341,664 @@ func TestScheduler_GetStatus(t *testing.T) {
 		t.Error("Expected running to be true after start")
 	}
 }
+
+func TestScheduler_LastRunTimestamps(t *testing.T) {
+	mockService := &mockMessageService{retryFailedError: errors.New("retry error")}
+	logger := logger.New().WithComponent("scheduler-test")
+	config := &Config{
+		ProcessingInterval: 10 * time.Millisecond,
+		RetryInterval:      10 * time.Millisecond,
+	}
+	scheduler := NewScheduler(mockService, logger, config)
+
+	status := scheduler.GetStatus()
+	if status["last_processed_at"] != nil {
+		t.Errorf("Expected last_processed_at to be nil before any run, got %v", status["last_processed_at"])
+	}
+	if status["last_retry_at"] != nil {
+		t.Errorf("Expected last_retry_at to be nil before any run, got %v", status["last_retry_at"])
+	}
+
+	before := time.Now().UTC().Truncate(time.Second)
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start scheduler: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := scheduler.Stop(); err != nil {
+		t.Fatalf("Failed to stop scheduler: %v", err)
+	}
+
+	status = scheduler.GetStatus()
+	for _, key := range []string{"last_processed_at", "last_retry_at"} {
+		value, ok := status[key].(string)
+		if !ok {
+			t.Fatalf("Expected %s to be a timestamp string, got %v", key, status[key])
+		}
+		ranAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Expected %s to be RFC 3339, got %q: %v", key, value, err)
+		}
+		if ranAt.Before(before) {
+			t.Errorf("Expected %s at or after %v, got %v", key, before, ranAt)
+		}
+	}
+}
+
+func TestScheduler_TriggerProcessing(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("runs once without starting the scheduler", func(t *testing.T) {
+		mockService := &mockMessageService{processPendingCount: 4}
+		scheduler := NewScheduler(mockService, logger, nil)
+
+		processed, err := scheduler.TriggerProcessing(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if processed != 4 {
+			t.Errorf("Expected 4 messages processed, got %d", processed)
+		}
+		if scheduler.IsRunning() {
+			t.Error("Expected scheduler to remain stopped after a trigger")
+		}
+		if processPending, _ := mockService.getCallCounts(); processPending != 1 {
+			t.Errorf("Expected 1 ProcessPendingMessages call, got %d", processPending)
+		}
+	})
+
+	t.Run("returns processing errors", func(t *testing.T) {
+		mockService := &mockMessageService{processPendingError: errors.New("process error")}
+		scheduler := NewScheduler(mockService, logger, nil)
+
+		if _, err := scheduler.TriggerProcessing(context.Background()); err == nil {
+			t.Error("Expected an error from a failing run")
+		}
+	})
+
+	t.Run("rejects a trigger while a run is in progress", func(t *testing.T) {
+		mockService := &mockMessageService{
+			processPendingDelay: 100 * time.Millisecond,
+			processPendingStart: make(chan struct{}, 1),
+		}
+		scheduler := NewScheduler(mockService, logger, nil)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := scheduler.TriggerProcessing(context.Background())
+			done <- err
+		}()
+
+		// Wait for the first run to hold the processing lock
+		select {
+		case <-mockService.processPendingStart:
+		case <-time.After(time.Second):
+			t.Fatal("First run never started")
+		}
+
+		if _, err := scheduler.TriggerProcessing(context.Background()); !errors.Is(err, ErrProcessingInProgress) {
+			t.Errorf("Expected ErrProcessingInProgress, got %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Expected first run to succeed, got %v", err)
+		}
+	})
+}
+
+func TestScheduler_Metrics(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+	m := metrics.NewWithRegistry(prometheus.NewRegistry())
+	mockService := &mockMessageService{processPendingCount: 4}
+	scheduler := NewScheduler(mockService, logger, &Config{
+		ProcessingInterval: time.Hour,
+		RetryInterval:      time.Hour,
+		Metrics:            m,
+	})
+
+	if _, err := scheduler.TriggerProcessing(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	scheduler.ctx = context.Background()
+	scheduler.retryFailedMessagesOnce()
+
+	mockService.mu.Lock()
+	mockService.processPendingError = errors.New("process error")
+	mockService.mu.Unlock()
+	if _, err := scheduler.TriggerProcessing(context.Background()); err == nil {
+		t.Fatal("Expected an error from a failing run")
+	}
+
+	if got := testutil.ToFloat64(m.SchedulerRunsTotal.WithLabelValues("process", "success")); got != 1 {
+		t.Errorf("Expected 1 successful processing run, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.SchedulerRunsTotal.WithLabelValues("process", "error")); got != 1 {
+		t.Errorf("Expected 1 failed processing run, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.SchedulerRunsTotal.WithLabelValues("retry", "success")); got != 1 {
+		t.Errorf("Expected 1 successful retry run, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.SchedulerRunDuration); got != 2 {
+		t.Errorf("Expected run durations for 2 loops, got %d", got)
+	}
+	for _, loop := range []string{"process", "retry"} {
+		if got := testutil.ToFloat64(m.SchedulerLastSuccessTimestamp.WithLabelValues(loop)); got < float64(time.Now().Add(-time.Minute).Unix()) {
+			t.Errorf("Expected a recent last success timestamp for %s, got %v", loop, got)
+		}
+	}
+
+	expected := `
+		# HELP insider_messaging_scheduler_messages_per_run Number of messages processed by each scheduler processing run
+		# TYPE insider_messaging_scheduler_messages_per_run histogram
+		insider_messaging_scheduler_messages_per_run_bucket{le="0"} 1
+		insider_messaging_scheduler_messages_per_run_bucket{le="1"} 1
+		insider_messaging_scheduler_messages_per_run_bucket{le="5"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="10"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="25"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="50"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="100"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="250"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="500"} 2
+		insider_messaging_scheduler_messages_per_run_bucket{le="+Inf"} 2
+		insider_messaging_scheduler_messages_per_run_sum 4
+		insider_messaging_scheduler_messages_per_run_count 2
+	`
+	if err := testutil.CollectAndCompare(m.SchedulerMessagesPerRun, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected messages per run histogram: %v", err)
+	}
+}
+
+func TestScheduler_ArchiveLoop(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("runs when an interval is configured", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: time.Minute,
+			RetryInterval:      time.Minute,
+			ArchiveInterval:    10 * time.Millisecond,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		if calls := mockService.getArchiveCalls(); calls < 2 {
+			t.Errorf("Expected at least 2 ArchiveOldMessages calls, got %d", calls)
+		}
+		if scheduler.GetStatus()["last_archive_at"] == nil {
+			t.Error("Expected last_archive_at to be set after archive runs")
+		}
+	})
+
+	t.Run("disabled without an interval", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: 10 * time.Millisecond,
+			RetryInterval:      10 * time.Millisecond,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		if calls := mockService.getArchiveCalls(); calls != 0 {
+			t.Errorf("Expected no ArchiveOldMessages calls, got %d", calls)
+		}
+	})
+}
+
+func TestScheduler_RetentionLoop(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("runs when an interval is configured", func(t *testing.T) {
+		mockService := &mockMessageService{purgeDeleted: 7}
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: time.Minute,
+			RetryInterval:      time.Minute,
+			RetentionInterval:  10 * time.Millisecond,
+			Metrics:            m,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		calls := mockService.getPurgeCalls()
+		if calls < 2 {
+			t.Errorf("Expected at least 2 PurgeOldMessages calls, got %d", calls)
+		}
+		if scheduler.GetStatus()["last_retention_at"] == nil {
+			t.Error("Expected last_retention_at to be set after retention runs")
+		}
+		expected := fmt.Sprintf(`
+			# HELP insider_messaging_retention_deleted_messages_per_run Number of messages deleted by each retention run
+			# TYPE insider_messaging_retention_deleted_messages_per_run histogram
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="0"} 0
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="10"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="100"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="1000"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="10000"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="100000"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_bucket{le="+Inf"} %[1]d
+			insider_messaging_retention_deleted_messages_per_run_sum %[2]d
+			insider_messaging_retention_deleted_messages_per_run_count %[1]d
+		`, calls, 7*calls)
+		if err := testutil.CollectAndCompare(m.RetentionDeletedPerRun, strings.NewReader(expected)); err != nil {
+			t.Errorf("Unexpected retention histogram: %v", err)
+		}
+	})
+
+	t.Run("disabled without an interval", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: 10 * time.Millisecond,
+			RetryInterval:      10 * time.Millisecond,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		if calls := mockService.getPurgeCalls(); calls != 0 {
+			t.Errorf("Expected no PurgeOldMessages calls, got %d", calls)
+		}
+	})
+
+	t.Run("stopping cancels a run in progress", func(t *testing.T) {
+		mockService := &mockMessageService{purgeBlock: true, purgeCancelled: make(chan struct{})}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: time.Minute,
+			RetryInterval:      time.Minute,
+			RetentionInterval:  10 * time.Millisecond,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		deadline := time.Now().Add(time.Second)
+		for mockService.getPurgeCalls() == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- scheduler.Stop() }()
+		select {
+		case err := <-stopped:
+			if err != nil {
+				t.Fatalf("Failed to stop scheduler: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Stop did not return while a retention run was in progress")
+		}
+
+		select {
+		case <-mockService.purgeCancelled:
+		default:
+			t.Error("Expected the retention run to see its context cancelled")
+		}
+	})
+}
+
+func TestScheduler_Wakeup(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	wakeup := make(chan struct{}, 1)
+	mockService := &mockMessageService{processPendingStart: make(chan struct{}, 1)}
+	scheduler := NewScheduler(mockService, logger, &Config{
+		ProcessingInterval: time.Hour,
+		RetryInterval:      time.Hour,
+		Wakeup:             wakeup,
+	})
+
+	if err := scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start scheduler: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// Nothing runs before the hourly tick without a wakeup
+	select {
+	case <-mockService.processPendingStart:
+		t.Fatal("Expected no processing run before a wakeup")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for i := 1; i <= 2; i++ {
+		wakeup <- struct{}{}
+		select {
+		case <-mockService.processPendingStart:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected wakeup %d to start a processing run", i)
+		}
+	}
+}
+
+func TestScheduler_UpdateConfig(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("new intervals take effect while running", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: time.Hour,
+			RetryInterval:      time.Hour,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		defer scheduler.Stop()
+
+		time.Sleep(30 * time.Millisecond)
+		if processCalls, retryCalls := mockService.getCallCounts(); processCalls != 0 || retryCalls != 0 {
+			t.Fatalf("Expected no runs on hourly intervals, got %d processing and %d retry", processCalls, retryCalls)
+		}
+
+		err := scheduler.UpdateConfig(&Config{
+			ProcessingInterval: 10 * time.Millisecond,
+			RetryInterval:      10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("Failed to update config: %v", err)
+		}
+		if !scheduler.IsRunning() {
+			t.Fatal("Expected scheduler to keep running after a config update")
+		}
+
+		time.Sleep(80 * time.Millisecond)
+
+		processCalls, retryCalls := mockService.getCallCounts()
+		if processCalls < 3 {
+			t.Errorf("Expected at least 3 processing runs at the new interval, got %d", processCalls)
+		}
+		if retryCalls < 3 {
+			t.Errorf("Expected at least 3 retry runs at the new interval, got %d", retryCalls)
+		}
+
+		status := scheduler.GetStatus()
+		if status["processing_interval"] != "10ms" || status["retry_interval"] != "10ms" {
+			t.Errorf("Expected status to report the new intervals, got %v and %v",
+				status["processing_interval"], status["retry_interval"])
+		}
+	})
+
+	t.Run("update while stopped applies on next start", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		scheduler := NewScheduler(mockService, logger, DefaultConfig())
+
+		err := scheduler.UpdateConfig(&Config{
+			ProcessingInterval: 10 * time.Millisecond,
+			RetryInterval:      time.Hour,
+		})
+		if err != nil {
+			t.Fatalf("Failed to update config: %v", err)
+		}
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		if processCalls, _ := mockService.getCallCounts(); processCalls < 2 {
+			t.Errorf("Expected at least 2 processing runs, got %d", processCalls)
+		}
+	})
+
+	t.Run("rejects non-positive intervals", func(t *testing.T) {
+		scheduler := NewScheduler(&mockMessageService{}, logger, DefaultConfig())
+
+		invalid := []*Config{
+			nil,
+			{ProcessingInterval: 0, RetryInterval: time.Minute},
+			{ProcessingInterval: time.Minute, RetryInterval: -time.Second},
+		}
+		for _, config := range invalid {
+			if err := scheduler.UpdateConfig(config); !errors.Is(err, ErrInvalidInterval) {
+				t.Errorf("Expected ErrInvalidInterval for %+v, got %v", config, err)
+			}
+		}
+
+		status := scheduler.GetStatus()
+		if status["processing_interval"] != "30s" || status["retry_interval"] != "5m0s" {
+			t.Errorf("Expected intervals to be unchanged, got %v and %v",
+				status["processing_interval"], status["retry_interval"])
+		}
+	})
+}
+
+func TestScheduler_Jitter(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("offsets stay within the jitter band", func(t *testing.T) {
+		scheduler := NewScheduler(&mockMessageService{}, logger, &Config{
+			ProcessingInterval: time.Minute,
+			RetryInterval:      time.Minute,
+			JitterFraction:     0.1,
+		})
+
+		lower, upper := 54*time.Second, 66*time.Second
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 1000; i++ {
+			d := scheduler.jitter(time.Minute)
+			if d < lower || d > upper {
+				t.Fatalf("Expected jittered interval within [%v, %v], got %v", lower, upper, d)
+			}
+			seen[d] = true
+		}
+		if len(seen) < 2 {
+			t.Error("Expected jittered intervals to vary")
+		}
+	})
+
+	t.Run("zero fraction ticks exactly on the interval", func(t *testing.T) {
+		scheduler := NewScheduler(&mockMessageService{}, logger, &Config{
+			ProcessingInterval: time.Minute,
+			RetryInterval:      time.Minute,
+		})
+
+		if d := scheduler.jitter(time.Minute); d != time.Minute {
+			t.Errorf("Expected no jitter, got %v", d)
+		}
+	})
+
+	t.Run("out of range fraction disables jitter", func(t *testing.T) {
+		for _, fraction := range []float64{-0.1, 1, 2.5} {
+			scheduler := NewScheduler(&mockMessageService{}, logger, &Config{
+				ProcessingInterval: time.Minute,
+				RetryInterval:      time.Minute,
+				JitterFraction:     fraction,
+			})
+			if d := scheduler.jitter(time.Minute); d != time.Minute {
+				t.Errorf("Expected fraction %v to disable jitter, got %v", fraction, d)
+			}
+		}
+	})
+
+	t.Run("inter-tick intervals vary within the band", func(t *testing.T) {
+		mockService := &mockMessageService{}
+		interval := 20 * time.Millisecond
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: interval,
+			RetryInterval:      time.Hour,
+			JitterFraction:     0.5,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		times := mockService.getProcessTimes()
+		if len(times) < 6 {
+			t.Fatalf("Expected at least 6 processing runs, got %d", len(times))
+		}
+
+		// The band is [10ms, 30ms]; allow a little slack for timer latency
+		// on a loaded machine
+		lower, upper := 9*time.Millisecond, 40*time.Millisecond
+		shortest, longest := time.Duration(1<<62), time.Duration(0)
+		for i := 1; i < len(times); i++ {
+			gap := times[i].Sub(times[i-1])
+			if gap < lower || gap > upper {
+				t.Errorf("Tick gap %d was %v, outside [%v, %v]", i, gap, lower, upper)
+			}
+			shortest = min(shortest, gap)
+			longest = max(longest, gap)
+		}
+		if longest-shortest < 2*time.Millisecond {
+			t.Errorf("Expected tick gaps to vary, got all within %v..%v", shortest, longest)
+		}
+	})
+}
+
+func TestScheduler_LeaderLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	lock, err := repo.NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer lock.Close()
+
+	logger := logger.New().WithComponent("scheduler-test")
+	config := &Config{
+		ProcessingInterval: 20 * time.Millisecond,
+		RetryInterval:      20 * time.Millisecond,
+		LeaderLock:         lock,
+		LeaderLockTTL:      150 * time.Millisecond,
+	}
+
+	services := []*mockMessageService{{}, {}}
+	schedulers := []*Scheduler{
+		NewScheduler(services[0], logger, config),
+		NewScheduler(services[1], logger, config),
+	}
+	for _, scheduler := range schedulers {
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	leader, follower := 0, 1
+	if processed, _ := services[1].getCallCounts(); processed > 0 {
+		leader, follower = 1, 0
+	}
+	if processed, retried := services[leader].getCallCounts(); processed == 0 || retried == 0 {
+		t.Errorf("Expected the leader to process and retry, got %d and %d runs", processed, retried)
+	}
+	if processed, retried := services[follower].getCallCounts(); processed != 0 || retried != 0 {
+		t.Errorf("Expected the follower to stay idle, got %d processing and %d retry runs", processed, retried)
+	}
+	if status := schedulers[leader].GetStatus(); status["leader"] != true {
+		t.Errorf("Expected leader status true, got %v", status["leader"])
+	}
+	if status := schedulers[follower].GetStatus(); status["leader"] != false {
+		t.Errorf("Expected follower status false, got %v", status["leader"])
+	}
+
+	// Stopping the leader releases the lock and the follower takes over
+	if err := schedulers[leader].Stop(); err != nil {
+		t.Fatalf("Failed to stop scheduler: %v", err)
+	}
+	defer schedulers[follower].Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if processed, _ := services[follower].getCallCounts(); processed > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the follower to take over processing after the leader stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestScheduler_WithoutLeaderLockOmitsLeaderStatus(t *testing.T) {
+	scheduler := NewScheduler(&mockMessageService{}, logger.New(), DefaultConfig())
+
+	if _, ok := scheduler.GetStatus()["leader"]; ok {
+		t.Error("Expected no leader status without a leader lock")
+	}
+}
+
+func TestScheduler_MaxInFlightRuns(t *testing.T) {
+	logger := logger.New().WithComponent("scheduler-test")
+
+	t.Run("slow runs drop ticks instead of piling up", func(t *testing.T) {
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		mockService := &mockMessageService{processPendingDelay: time.Second}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: 10 * time.Millisecond,
+			RetryInterval:      time.Hour,
+			Metrics:            m,
+		})
+
+		if err := scheduler.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start scheduler: %v", err)
+		}
+		time.Sleep(150 * time.Millisecond)
+		if err := scheduler.Stop(); err != nil {
+			t.Fatalf("Failed to stop scheduler: %v", err)
+		}
+
+		if processPending, _ := mockService.getCallCounts(); processPending != 1 {
+			t.Errorf("Expected 1 processing run while the first was in flight, got %d", processPending)
+		}
+		if got := testutil.ToFloat64(m.SchedulerDroppedRuns); got < 1 {
+			t.Errorf("Expected dropped ticks to be counted, got %v", got)
+		}
+	})
+
+	t.Run("runs overlap up to the limit", func(t *testing.T) {
+		mockService := &mockMessageService{
+			processPendingDelay: 100 * time.Millisecond,
+			processPendingStart: make(chan struct{}, 2),
+		}
+		scheduler := NewScheduler(mockService, logger, &Config{
+			ProcessingInterval: time.Hour,
+			RetryInterval:      time.Hour,
+			MaxInFlightRuns:    2,
+		})
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := scheduler.TriggerProcessing(context.Background())
+			done <- err
+		}()
+
+		select {
+		case <-mockService.processPendingStart:
+		case <-time.After(time.Second):
+			t.Fatal("First run never started")
+		}
+
+		if _, err := scheduler.TriggerProcessing(context.Background()); err != nil {
+			t.Errorf("Expected a second run to fit under the limit, got %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("Expected first run to succeed, got %v", err)
+		}
+	})
+}
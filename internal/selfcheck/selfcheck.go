@@ -0,0 +1,146 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Statuses of a single check
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// Overall statuses of a report
+const (
+	ReportOK       = "ok"       // Every check passed or was skipped
+	ReportDegraded = "degraded" // Only non-critical checks failed
+	ReportFailed   = "failed"   // At least one critical check failed
+)
+
+// skipError marks a check that did not apply, such as migrations without a database
+type skipError struct {
+	reason string
+}
+
+// Error implements the error interface
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip returns an error that reports the check as skipped for reason rather
+// than failed
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Check is a single startup verification
+type Check struct {
+	Name string
+
+	// Critical checks fail the whole report; others only degrade it
+	Critical bool
+
+	Run func(ctx context.Context) error
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report aggregates the results of a self-check run
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Run executes checks in order, so later checks can rely on state set up by
+// earlier ones, and aggregates their results. Every check runs even after a
+// failure so the report is complete.
+func Run(ctx context.Context, checks ...Check) *Report {
+	report := &Report{
+		Status:    ReportOK,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make([]Result, 0, len(checks)),
+	}
+
+	for _, check := range checks {
+		started := time.Now()
+		err := check.Run(ctx)
+
+		result := Result{
+			Name:     check.Name,
+			Status:   StatusOK,
+			Critical: check.Critical,
+			Duration: time.Since(started).Round(time.Millisecond).String(),
+		}
+
+		var skip *skipError
+		switch {
+		case err == nil:
+		case errors.As(err, &skip):
+			result.Status = StatusSkipped
+			result.Error = skip.reason
+		default:
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			if check.Critical {
+				report.Status = ReportFailed
+			} else if report.Status == ReportOK {
+				report.Status = ReportDegraded
+			}
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+// Ready reports whether no critical check failed
+func (r *Report) Ready() bool {
+	return r.Status != ReportFailed
+}
+
+// CriticalFailures returns the failed critical checks
+func (r *Report) CriticalFailures() []Result {
+	var failures []Result
+	for _, result := range r.Checks {
+		if result.Critical && result.Status == StatusFailed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Log writes the report as a single structured log entry, at error level when a
+// critical check failed and warn level when the report is degraded
+func (r *Report) Log(logger *slog.Logger) {
+	attrs := []any{"status", r.Status}
+	for _, result := range r.Checks {
+		value := result.Status
+		if result.Error != "" {
+			value = fmt.Sprintf("%s: %s", result.Status, result.Error)
+		}
+		attrs = append(attrs, result.Name, value)
+	}
+
+	switch r.Status {
+	case ReportFailed:
+		logger.Error("Startup self-check failed", attrs...)
+	case ReportDegraded:
+		logger.Warn("Startup self-check degraded", attrs...)
+	default:
+		logger.Info("Startup self-check passed", attrs...)
+	}
+}
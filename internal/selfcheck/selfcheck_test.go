@@ -0,0 +1,112 @@
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passing(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all checks pass", func(t *testing.T) {
+		report := Run(ctx,
+			Check{Name: "config", Critical: true, Run: passing},
+			Check{Name: "redis", Run: passing},
+		)
+
+		assert.Equal(t, ReportOK, report.Status)
+		assert.True(t, report.Ready())
+		assert.Empty(t, report.CriticalFailures())
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, "config", report.Checks[0].Name)
+		assert.Equal(t, StatusOK, report.Checks[0].Status)
+		assert.True(t, report.Checks[0].Critical)
+		assert.False(t, report.CheckedAt.IsZero())
+	})
+
+	t.Run("non-critical failure degrades", func(t *testing.T) {
+		report := Run(ctx,
+			Check{Name: "config", Critical: true, Run: passing},
+			Check{Name: "redis", Run: failing},
+		)
+
+		assert.Equal(t, ReportDegraded, report.Status)
+		assert.True(t, report.Ready())
+		assert.Empty(t, report.CriticalFailures())
+		assert.Equal(t, StatusFailed, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+	})
+
+	t.Run("critical failure fails regardless of order", func(t *testing.T) {
+		report := Run(ctx,
+			Check{Name: "database", Critical: true, Run: failing},
+			Check{Name: "redis", Run: failing},
+			Check{Name: "webhook_client", Run: passing},
+		)
+
+		assert.Equal(t, ReportFailed, report.Status)
+		assert.False(t, report.Ready())
+		failures := report.CriticalFailures()
+		require.Len(t, failures, 1)
+		assert.Equal(t, "database", failures[0].Name)
+		assert.Len(t, report.Checks, 3, "later checks still run after a failure")
+	})
+
+	t.Run("skipped checks do not fail", func(t *testing.T) {
+		report := Run(ctx,
+			Check{Name: "migrations", Critical: true, Run: func(ctx context.Context) error {
+				return Skip("database unavailable")
+			}},
+		)
+
+		assert.Equal(t, ReportOK, report.Status)
+		assert.Equal(t, StatusSkipped, report.Checks[0].Status)
+		assert.Equal(t, "database unavailable", report.Checks[0].Error)
+	})
+
+	t.Run("checks run in order", func(t *testing.T) {
+		var connected bool
+		report := Run(ctx,
+			Check{Name: "database", Run: func(ctx context.Context) error {
+				connected = true
+				return nil
+			}},
+			Check{Name: "migrations", Critical: true, Run: func(ctx context.Context) error {
+				if !connected {
+					return errors.New("not connected")
+				}
+				return nil
+			}},
+		)
+
+		assert.Equal(t, ReportOK, report.Status)
+	})
+}
+
+func TestReport_Log(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	report := Run(context.Background(),
+		Check{Name: "config", Critical: true, Run: passing},
+		Check{Name: "redis", Run: failing},
+	)
+	report.Log(logger)
+
+	output := buf.String()
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), "summary is a single log entry")
+	assert.Contains(t, output, "level=WARN")
+	assert.Contains(t, output, "status=degraded")
+	assert.Contains(t, output, "config=ok")
+	assert.Contains(t, output, `redis="failed: connection refused"`)
+}
@@ -0,0 +1,36 @@
+package service
+
+import "context"
+
+// commitHooks collects work that has to wait until a transaction commits,
+// such as cache writes and lifecycle events that would otherwise describe a
+// state that may still roll back
+type commitHooks struct {
+	hooks []func(ctx context.Context)
+}
+
+// commitHooksKey keys the commitHooks carried by a transaction's context
+type commitHooksKey struct{}
+
+// withCommitHooks returns a copy of ctx whose afterCommit calls are deferred
+// to hooks
+func withCommitHooks(ctx context.Context, hooks *commitHooks) context.Context {
+	return context.WithValue(ctx, commitHooksKey{}, hooks)
+}
+
+// afterCommit runs fn once the transaction ctx belongs to has committed, or
+// right away when ctx carries no transaction
+func afterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.hooks = append(hooks.hooks, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// run runs the collected hooks in the order they were added
+func (h *commitHooks) run(ctx context.Context) {
+	for _, hook := range h.hooks {
+		hook(ctx)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/insider/insider-messaging/internal/domain"
+)
+
+// Deliverer sends messages over one channel
+type Deliverer interface {
+	// Deliver sends a message and returns the provider's message ID, or ""
+	// when the provider did not report one
+	Deliver(ctx context.Context, message *domain.Message) (string, error)
+}
+
+// DelivererRegistry maps each channel to the Deliverer that sends its
+// messages. It is safe for concurrent use, so deliverers can be registered
+// while the service is running.
+type DelivererRegistry struct {
+	mu         sync.RWMutex
+	deliverers map[domain.Channel]Deliverer
+}
+
+// NewDelivererRegistry returns an empty registry
+func NewDelivererRegistry() *DelivererRegistry {
+	return &DelivererRegistry{deliverers: make(map[domain.Channel]Deliverer)}
+}
+
+// Register sends messages of channel with deliverer, replacing any deliverer
+// already registered for it
+func (r *DelivererRegistry) Register(channel domain.Channel, deliverer Deliverer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliverers[channel] = deliverer
+}
+
+// Lookup returns the deliverer registered for channel
+func (r *DelivererRegistry) Lookup(channel domain.Channel) (Deliverer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deliverer, ok := r.deliverers[channel]
+	return deliverer, ok
+}
+
+// Deliverer returns the deliverer registered for channel, or an error wrapping
+// domain.ErrUnknownChannel when there is none
+func (r *DelivererRegistry) Deliverer(channel domain.Channel) (Deliverer, error) {
+	deliverer, ok := r.Lookup(channel)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", domain.ErrUnknownChannel, channel)
+	}
+	return deliverer, nil
+}
+
+// Channels returns the registered channels in sorted order
+func (r *DelivererRegistry) Channels() []domain.Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channels := make([]domain.Channel, 0, len(r.deliverers))
+	for channel := range r.deliverers {
+		channels = append(channels, channel)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels
+}
+
+// webhookDeliverer delivers webhook channel messages with a WebhookClient
+type webhookDeliverer struct {
+	client WebhookClient
+}
+
+// NewWebhookDeliverer returns the webhook channel Deliverer backed by client
+func NewWebhookDeliverer(client WebhookClient) Deliverer {
+	return webhookDeliverer{client: client}
+}
+
+// Deliver implements Deliverer
+func (d webhookDeliverer) Deliver(ctx context.Context, message *domain.Message) (string, error) {
+	return d.client.SendMessage(ctx, message)
+}
+
+// stubDeliverer stands in for a channel whose provider is not integrated yet.
+// It logs each message and reports it delivered, the way messages are marked
+// sent when no webhook client is configured.
+type stubDeliverer struct {
+	channel domain.Channel
+	logger  *slog.Logger
+}
+
+// NewEmailDeliverer returns a placeholder email Deliverer that only logs messages
+func NewEmailDeliverer(logger *slog.Logger) Deliverer {
+	return stubDeliverer{channel: domain.ChannelEmail, logger: logger}
+}
+
+// NewSMSDeliverer returns a placeholder SMS Deliverer that only logs messages
+func NewSMSDeliverer(logger *slog.Logger) Deliverer {
+	return stubDeliverer{channel: domain.ChannelSMS, logger: logger}
+}
+
+// Deliver implements Deliverer
+func (d stubDeliverer) Deliver(ctx context.Context, message *domain.Message) (string, error) {
+	d.logger.Info("No provider integrated for channel, message logged only",
+		"channel", d.channel,
+		"message_id", message.ID,
+		"recipient", message.Recipient,
+	)
+	return "", nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelivererRegistry(t *testing.T) {
+	t.Run("looks up the registered deliverer", func(t *testing.T) {
+		registry := NewDelivererRegistry()
+		email := &fakeDeliverer{}
+		registry.Register(domain.ChannelEmail, email)
+
+		deliverer, err := registry.Deliverer(domain.ChannelEmail)
+		require.NoError(t, err)
+		assert.Same(t, email, deliverer)
+		assert.Equal(t, []domain.Channel{domain.ChannelEmail}, registry.Channels())
+	})
+
+	t.Run("a later registration replaces the deliverer", func(t *testing.T) {
+		registry := NewDelivererRegistry()
+		registry.Register(domain.ChannelSMS, &fakeDeliverer{})
+		replacement := &fakeDeliverer{}
+		registry.Register(domain.ChannelSMS, replacement)
+
+		deliverer, ok := registry.Lookup(domain.ChannelSMS)
+		require.True(t, ok)
+		assert.Same(t, replacement, deliverer)
+	})
+
+	t.Run("unregistered channel", func(t *testing.T) {
+		_, err := NewDelivererRegistry().Deliverer(domain.ChannelSMS)
+		assert.ErrorIs(t, err, domain.ErrUnknownChannel)
+		assert.EqualError(t, err, `no deliverer for message channel "sms"`)
+	})
+}
+
+func TestMessageService_WithDelivererRegistry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("invokes the deliverer registered for the message's channel", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		email := &fakeDeliverer{ref: "email-1"}
+		sms := &fakeDeliverer{ref: "sms-1"}
+		registry := NewDelivererRegistry()
+		registry.Register(domain.ChannelEmail, email)
+		registry.Register(domain.ChannelSMS, sms)
+		service := NewMessageService(messageRepo, logger, WithDelivererRegistry(registry))
+
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient: "user@example.com",
+			Content:   "Hello",
+			Channel:   domain.ChannelEmail,
+		})
+		require.NoError(t, err)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, []int64{message.ID}, email.delivered)
+		assert.Empty(t, sms.delivered)
+	})
+
+	t.Run("keeps the webhook client for the webhook channel", func(t *testing.T) {
+		registry := NewDelivererRegistry()
+		client := new(MockWebhookClient)
+		NewMessageServiceWithWebhook(repo.NewInMemoryMessageRepository(), client, logger, WithDelivererRegistry(registry))
+
+		deliverer, ok := registry.Lookup(domain.ChannelWebhook)
+		require.True(t, ok)
+		assert.Equal(t, NewWebhookDeliverer(client), deliverer)
+	})
+
+	t.Run("a registered webhook deliverer overrides the client", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		webhook := &fakeDeliverer{}
+		registry := NewDelivererRegistry()
+		registry.Register(domain.ChannelWebhook, webhook)
+		client := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, client, logger, WithDelivererRegistry(registry))
+
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Hello",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+
+		_, err = service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{message.ID}, webhook.delivered)
+		client.AssertNotCalled(t, "SendMessage")
+	})
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errDeliveryCancelled is the cancel cause of a delivery aborted by CancelMessage
+var errDeliveryCancelled = errors.New("delivery cancelled")
+
+// deliveryRegistry tracks the in-flight webhook deliveries on this instance so
+// a cancelled message can abort its request instead of waiting for the timeout.
+type deliveryRegistry struct {
+	mu         sync.Mutex
+	deliveries map[int64]*deliveryEntry
+}
+
+// deliveryEntry is the cancel func of one in-flight delivery
+type deliveryEntry struct {
+	cancel context.CancelCauseFunc
+}
+
+// register records cancel as the abort for messageID's delivery and returns
+// the func that removes it once the delivery has finished
+func (r *deliveryRegistry) register(messageID int64, cancel context.CancelCauseFunc) func() {
+	entry := &deliveryEntry{cancel: cancel}
+
+	r.mu.Lock()
+	if r.deliveries == nil {
+		r.deliveries = make(map[int64]*deliveryEntry)
+	}
+	r.deliveries[messageID] = entry
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		// A newer delivery of the same message may have replaced this entry
+		if r.deliveries[messageID] == entry {
+			delete(r.deliveries, messageID)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// cancel aborts messageID's in-flight delivery and reports whether there was one
+func (r *deliveryRegistry) cancel(messageID int64) bool {
+	r.mu.Lock()
+	entry, ok := r.deliveries[messageID]
+	if ok {
+		delete(r.deliveries, messageID)
+	}
+	r.mu.Unlock()
+
+	if ok {
+		entry.cancel(errDeliveryCancelled)
+	}
+	return ok
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryRegistry(t *testing.T) {
+	t.Run("cancel aborts a registered delivery", func(t *testing.T) {
+		var registry deliveryRegistry
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+
+		done := registry.register(1, cancel)
+		defer done()
+
+		assert.True(t, registry.cancel(1))
+		assert.ErrorIs(t, context.Cause(ctx), errDeliveryCancelled)
+		assert.Empty(t, registry.deliveries)
+		assert.False(t, registry.cancel(1), "a delivery is only cancelled once")
+	})
+
+	t.Run("cancel without a delivery", func(t *testing.T) {
+		var registry deliveryRegistry
+		assert.False(t, registry.cancel(1))
+	})
+
+	t.Run("finished deliveries are removed", func(t *testing.T) {
+		var registry deliveryRegistry
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+
+		done := registry.register(1, cancel)
+		done()
+
+		assert.Empty(t, registry.deliveries)
+		assert.False(t, registry.cancel(1))
+		assert.NoError(t, ctx.Err())
+	})
+
+	t.Run("stale cleanup keeps the newer delivery", func(t *testing.T) {
+		var registry deliveryRegistry
+		_, cancelOld := context.WithCancelCause(context.Background())
+		defer cancelOld(nil)
+		newCtx, cancelNew := context.WithCancelCause(context.Background())
+		defer cancelNew(nil)
+
+		doneOld := registry.register(1, cancelOld)
+		doneNew := registry.register(1, cancelNew)
+		defer doneNew()
+		doneOld()
+
+		assert.True(t, registry.cancel(1))
+		assert.ErrorIs(t, context.Cause(newCtx), errDeliveryCancelled)
+	})
+}
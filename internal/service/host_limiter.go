@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// hostLimiter caps how many webhook requests may be in flight to one host at
+// a time, so a large batch for a single receiver does not hit it with the
+// whole worker pool at once
+type hostLimiter struct {
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{} // Keyed by hostname, created on first use
+}
+
+// newHostLimiter returns a limiter allowing limit requests per host, or nil
+// when limit is not positive, which leaves hosts unlimited
+func newHostLimiter(limit int) *hostLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostLimiter{
+		limit: limit,
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+// acquire waits for a free slot for host and returns the func that frees it.
+// It gives up when ctx is done. A nil limiter never waits.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free request slot for %s: %w", host, ctx.Err())
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import "sync"
+
+// keyLock serializes work on the same key within this process. Entries are
+// dropped as soon as the last holder or waiter for a key releases it.
+type keyLock struct {
+	mu    sync.Mutex
+	locks map[string]*keyLockEntry
+}
+
+// keyLockEntry is the mutex for one key and how many callers hold or wait on it
+type keyLockEntry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Lock blocks until key is free and returns the func that releases it
+func (k *keyLock) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLockEntry)
+	}
+	entry, ok := k.locks[key]
+	if !ok {
+		entry = &keyLockEntry{}
+		k.locks[key] = entry
+	}
+	entry.refs++
+	k.mu.Unlock()
+
+	entry.mu.Lock()
+
+	return func() {
+		entry.mu.Unlock()
+
+		k.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLock(t *testing.T) {
+	t.Run("serializes the same key", func(t *testing.T) {
+		var locks keyLock
+		var wg sync.WaitGroup
+		inside := 0
+		maxInside := 0
+		var mu sync.Mutex
+
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock := locks.Lock("order-42")
+				defer unlock()
+
+				mu.Lock()
+				inside++
+				maxInside = max(maxInside, inside)
+				mu.Unlock()
+
+				mu.Lock()
+				inside--
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, maxInside)
+		assert.Empty(t, locks.locks, "released keys are dropped")
+	})
+
+	t.Run("different keys do not block each other", func(t *testing.T) {
+		var locks keyLock
+
+		unlockA := locks.Lock("a")
+		unlockB := locks.Lock("b")
+		assert.Len(t, locks.locks, 2)
+
+		unlockA()
+		unlockB()
+		assert.Empty(t, locks.locks)
+	})
+}
@@ -2,12 +2,25 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/events"
 	"github.com/insider/insider-messaging/internal/repo"
+	"github.com/insider/insider-messaging/pkg/config"
+	"github.com/insider/insider-messaging/pkg/metrics"
+	"github.com/sethvargo/go-retry"
 )
 
 // MessageService defines the interface for message business logic
@@ -21,245 +34,2588 @@ type MessageService interface {
 	// ProcessPendingMessages processes pending messages (alias for ProcessUnsentMessages for scheduler compatibility)
 	ProcessPendingMessages(ctx context.Context) error
 
+	// CreateMessageIdempotent creates a message unless one was already created
+	// with the same idempotency key, in which case that message is returned and
+	// created is false
+	CreateMessageIdempotent(ctx context.Context, req *domain.CreateMessageRequest, key string) (message *domain.Message, created bool, err error)
+
+	// ValidateMessage runs the checks CreateMessage would and returns the
+	// message it would create, without storing it
+	ValidateMessage(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error)
+
+	// CreateMessages creates every valid request in a single transaction and
+	// reports a result per request, in request order
+	CreateMessages(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]domain.CreateResult, error)
+
+	// ImportMessages creates many messages, committing them in chunks of the
+	// configured batch commit size and reporting progress after each chunk
+	ImportMessages(ctx context.Context, reqs []*domain.CreateMessageRequest, progress func(imported, total int)) ([]*domain.Message, error)
+
 	// GetMessage retrieves a message by ID
 	GetMessage(ctx context.Context, messageID int64) (*domain.Message, error)
 
-	// GetSentMessages retrieves sent messages with pagination
-	GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+	// GetMessageAttempts retrieves the webhook requests made to deliver a
+	// message, oldest first, or domain.ErrMessageNotFound when the message
+	// does not exist
+	GetMessageAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error)
+
+	// GetSentMessages retrieves sent messages in the given order with pagination
+	GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error)
+
+	// ListMessages retrieves messages with the given status, or of every status
+	// when status is empty, newest first with pagination
+	ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error)
+
+	// ListMessagesAfterCursor retrieves up to limit messages with the given
+	// status, or of every status when status is empty, whose ID is below
+	// cursorID, highest ID first. nextCursorID is the cursor for the following
+	// page, or 0 when this page is the last.
+	ListMessagesAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) (messages []*domain.Message, nextCursorID int64, err error)
+
+	// GetMessagesByRecipient retrieves messages sent to a recipient, newest first with pagination
+	GetMessagesByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error)
+
+	// GetMessagesByDateRange retrieves messages created between from and to
+	// inclusive, newest first with pagination
+	GetMessagesByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error)
+
+	// RetryFailedMessages retries failed messages that haven't exceeded max retries
+	RetryFailedMessages(ctx context.Context, batchSize int) (int, error)
+
+	// GetFailedMessages retrieves failed messages with their last error, most
+	// recently failed first with pagination
+	GetFailedMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+
+	// GetDeadLetterMessages retrieves messages that exhausted their retries with pagination
+	GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error)
+
+	// RequeueMessage resets a failed or dead-lettered message back to pending
+	// with a fresh retry budget
+	RequeueMessage(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// CancelMessage withdraws an undelivered message, aborting its delivery
+	// when one is in flight on this instance
+	CancelMessage(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// UpdatePendingMessage edits the recipient, content or webhook URL of a
+	// message that is still pending
+	UpdatePendingMessage(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error)
+
+	// ResendMessage queues a sent message for redelivery as a new pending
+	// message linked to the original, which is left unchanged
+	ResendMessage(ctx context.Context, messageID int64) (*domain.Message, error)
+
+	// SendNow delivers a pending, failed or dead-lettered message immediately
+	// and returns it with the outcome recorded. A sent message is only
+	// delivered again when force is set; a cancelled, expired or claimed one
+	// returns domain.ErrMessageNotSendable.
+	SendNow(ctx context.Context, messageID int64, force bool) (*domain.Message, error)
+
+	// GetMessageCounts counts messages per status, including statuses with none
+	GetMessageCounts(ctx context.Context) (map[domain.MessageStatus]int, error)
+
+	// GetSuccessRate reports the delivery success rate over the given window,
+	// optionally broken down per webhook host
+	GetSuccessRate(ctx context.Context, window time.Duration, byHost bool) (*domain.SuccessRate, error)
+
+	// GetRecentMessages retrieves messages of any status created within the last since duration
+	GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error)
+
+	// PauseHost holds deliveries to a webhook host for duration, or until resumed when zero
+	PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error)
+
+	// ClaimMessages hands up to limit messages due for delivery to an external
+	// worker, moving them to the sending status
+	ClaimMessages(ctx context.Context, limit int) ([]*domain.Message, error)
+
+	// AckMessage records that an external worker delivered a claimed message
+	AckMessage(ctx context.Context, messageID int64, providerMessageID string) (*domain.Message, error)
+
+	// NackMessage records that an external worker failed to deliver a claimed message
+	NackMessage(ctx context.Context, messageID int64, errorMsg string) (*domain.Message, error)
+
+	// RecordDeliveryStatus applies a sent or failed delivery status reported by
+	// the provider for a message it was handed
+	RecordDeliveryStatus(ctx context.Context, messageID int64, status domain.MessageStatus, providerMessageID, errorMsg string) (*domain.Message, error)
+
+	// ResumeHost lets deliveries to a paused webhook host continue
+	ResumeHost(ctx context.Context, host string) error
+
+	// GetPausedHosts lists the webhook hosts whose deliveries are on hold
+	GetPausedHosts(ctx context.Context) ([]*domain.PausedHost, error)
+
+	// Suppress holds deliveries to a recipient or webhook host for duration, or
+	// until removed when zero
+	Suppress(ctx context.Context, key string, duration time.Duration) (*domain.Suppression, error)
+
+	// Unsuppress removes the suppression of a recipient or webhook host
+	Unsuppress(ctx context.Context, key string) error
+
+	// GetSuppressions returns the recipients and webhook hosts currently suppressed
+	GetSuppressions(ctx context.Context) ([]*domain.Suppression, error)
+
+	// ArchiveOldMessages moves messages sent longer ago than the configured
+	// ArchiveAfter to the archive and returns how many were moved
+	ArchiveOldMessages(ctx context.Context) (int, error)
+
+	// PurgeOldMessages deletes sent and dead-lettered messages that reached
+	// that status longer ago than the configured MessageRetention and returns
+	// how many were deleted
+	PurgeOldMessages(ctx context.Context) (int, error)
+}
+
+// successRateCacheTTL is how long a computed success rate is reused before the
+// window is counted again
+const successRateCacheTTL = 30 * time.Second
+
+// defaultBatchCommitSize is used when no batch commit size is configured
+const defaultBatchCommitSize = 500
+
+// getMessageCacheOperation labels the GetMessage cache hit and miss metrics
+const getMessageCacheOperation = "get_message"
+
+// getSentMessagesCacheOperation labels the GetSentMessages cache hit and miss metrics
+const getSentMessagesCacheOperation = "get_sent_messages"
+
+// recentlySentWindow is how many recently sent message IDs are cached; pages
+// reaching past it are read from the repository. It matches the largest page
+// the API serves.
+const recentlySentWindow = 100
+
+// markTimeout bounds how long recording a delivery outcome may take once the
+// run or request that delivered it has been cancelled
+const markTimeout = 10 * time.Second
+
+// idempotencyLockTTL bounds how long a create holds the idempotency lock of
+// its key, so a crashed instance cannot block the key for longer
+const idempotencyLockTTL = 5 * time.Second
+
+// idempotencyLockPoll is how often a create waiting on a held idempotency lock
+// tries it again
+const idempotencyLockPoll = 20 * time.Millisecond
+
+// cachedSuccessRate is a success rate computed at a point in time
+type cachedSuccessRate struct {
+	rate       *domain.SuccessRate
+	computedAt time.Time
+}
+
+// messageService implements MessageService
+type messageService struct {
+	repo         repo.MessageRepository
+	cache        *repo.RedisCacheRepository // Optional Redis cache
+	deliverers   *DelivererRegistry
+	config       *config.Config   // Optional configuration, defaults apply when nil
+	metrics      *metrics.Metrics // Optional metrics
+	eventBus     *events.Bus      // Optional lifecycle event bus
+	hostPauses   repo.HostPauseStore
+	suppressions repo.RecipientSuppressionStore
+	recipients   repo.RecipientCounter  // Optional fast path for the recipient daily limit
+	messageCache repo.MessageCache      // Optional read-through cache for GetMessage
+	recentlySent repo.RecentlySentCache // Optional fast path for the first pages of sent messages
+	keyLocker    repo.IdempotencyLocker // Optional lock serializing idempotent creates across instances
+	logger       *slog.Logger
+
+	successRateMu    sync.Mutex
+	successRateCache map[string]cachedSuccessRate
+
+	// idempotencyLocks serializes creates sharing an idempotency key on this
+	// instance; keyLocker, or else the repository's unique key, covers other
+	// instances
+	idempotencyLocks keyLock
+
+	// deliveries holds the cancel funcs of webhook requests in flight on this
+	// instance so CancelMessage can abort them
+	deliveries deliveryRegistry
+}
+
+// ServiceOption configures optional message service dependencies
+type ServiceOption func(*messageService)
+
+// WithConfig applies operator configuration such as retry timing to the service
+func WithConfig(cfg *config.Config) ServiceOption {
+	return func(s *messageService) {
+		s.config = cfg
+	}
+}
+
+// WithDelivererRegistry delivers messages with the deliverers in registry,
+// looked up by each message's channel; a message whose channel has no
+// deliverer fails with domain.ErrUnknownChannel. The service's webhook client,
+// if any, is registered for the webhook channel unless registry already has a
+// webhook deliverer.
+func WithDelivererRegistry(registry *DelivererRegistry) ServiceOption {
+	return func(s *messageService) {
+		if webhook, ok := s.deliverers.Lookup(domain.ChannelWebhook); ok {
+			if _, exists := registry.Lookup(domain.ChannelWebhook); !exists {
+				registry.Register(domain.ChannelWebhook, webhook)
+			}
+		}
+		s.deliverers = registry
+	}
+}
+
+// WithDeliverer registers deliverer for channel on the service's registry. The
+// webhook channel uses the service's webhook client unless overridden.
+func WithDeliverer(channel domain.Channel, deliverer Deliverer) ServiceOption {
+	return func(s *messageService) {
+		s.deliverers.Register(channel, deliverer)
+	}
+}
+
+// WithMetrics records service-level metrics on the given Metrics
+func WithMetrics(m *metrics.Metrics) ServiceOption {
+	return func(s *messageService) {
+		s.metrics = m
+	}
+}
+
+// WithEventBus publishes message lifecycle events to the given bus
+func WithEventBus(bus *events.Bus) ServiceOption {
+	return func(s *messageService) {
+		s.eventBus = bus
+	}
+}
+
+// WithHostPauseStore overrides where paused webhook hosts are recorded. By default
+// they are kept in Redis when a cache is configured, so every instance honors a
+// pause, and in memory otherwise.
+func WithHostPauseStore(store repo.HostPauseStore) ServiceOption {
+	return func(s *messageService) {
+		s.hostPauses = store
+	}
+}
+
+// WithRecipientSuppressionStore overrides where suppressed recipients are
+// recorded. Like host pauses they are kept in Redis when a cache is configured
+// and in memory otherwise.
+func WithRecipientSuppressionStore(store repo.RecipientSuppressionStore) ServiceOption {
+	return func(s *messageService) {
+		s.suppressions = store
+	}
+}
+
+// WithRecipientCounter overrides where per-recipient daily counts are kept. By
+// default they are kept in Redis when a cache is configured; without one the
+// recipient daily limit counts rows in the repository.
+func WithRecipientCounter(counter repo.RecipientCounter) ServiceOption {
+	return func(s *messageService) {
+		s.recipients = counter
+	}
+}
+
+// WithMessageCache overrides where GetMessage caches sent messages. By default
+// they are kept in Redis when a cache is configured and not cached otherwise.
+func WithMessageCache(cache repo.MessageCache) ServiceOption {
+	return func(s *messageService) {
+		s.messageCache = cache
+	}
+}
+
+// WithRecentlySentCache overrides where the IDs of recently sent messages are
+// kept for GetSentMessages. By default they are kept in Redis when a cache is
+// configured and every page is read from the repository otherwise.
+func WithRecentlySentCache(cache repo.RecentlySentCache) ServiceOption {
+	return func(s *messageService) {
+		s.recentlySent = cache
+	}
+}
+
+// WithIdempotencyLocker overrides the lock that serializes creates sharing an
+// idempotency key across instances. By default it is kept in Redis when a
+// cache is configured; without one concurrent duplicates race on the
+// repository's unique key instead.
+func WithIdempotencyLocker(locker repo.IdempotencyLocker) ServiceOption {
+	return func(s *messageService) {
+		s.keyLocker = locker
+	}
+}
+
+// NewMessageService creates a new message service without cache or webhook client
+func NewMessageService(repo repo.MessageRepository, logger *slog.Logger, opts ...ServiceOption) MessageService {
+	return newMessageService(repo, nil, nil, logger, opts)
+}
+
+// NewMessageServiceWithCache creates a new message service with Redis cache
+func NewMessageServiceWithCache(repo repo.MessageRepository, cache *repo.RedisCacheRepository, logger *slog.Logger, opts ...ServiceOption) MessageService {
+	return newMessageService(repo, cache, nil, logger, opts)
+}
+
+// NewMessageServiceWithWebhook creates a new message service with webhook client
+func NewMessageServiceWithWebhook(repo repo.MessageRepository, webhookClient WebhookClient, logger *slog.Logger, opts ...ServiceOption) MessageService {
+	return newMessageService(repo, nil, webhookClient, logger, opts)
+}
+
+// NewMessageServiceWithCacheAndWebhook creates a new message service with both Redis cache and webhook client
+func NewMessageServiceWithCacheAndWebhook(repo repo.MessageRepository, cache *repo.RedisCacheRepository, webhookClient WebhookClient, logger *slog.Logger, opts ...ServiceOption) MessageService {
+	return newMessageService(repo, cache, webhookClient, logger, opts)
+}
+
+// newMessageService builds the service and applies any options
+func newMessageService(messageRepo repo.MessageRepository, cache *repo.RedisCacheRepository, webhookClient WebhookClient, logger *slog.Logger, opts []ServiceOption) *messageService {
+	s := &messageService{
+		repo:             messageRepo,
+		cache:            cache,
+		deliverers:       NewDelivererRegistry(),
+		logger:           logger,
+		successRateCache: make(map[string]cachedSuccessRate),
+	}
+	if webhookClient != nil {
+		s.deliverers.Register(domain.ChannelWebhook, NewWebhookDeliverer(webhookClient))
+	}
+	if cache != nil {
+		s.hostPauses = cache
+		s.suppressions = cache
+		s.recipients = cache
+		s.messageCache = cache
+		s.recentlySent = cache
+		s.keyLocker = cache
+	} else {
+		s.hostPauses = repo.NewInMemoryHostPauseStore()
+		s.suppressions = repo.NewInMemoryRecipientSuppressionStore()
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// CreateMessage creates a new message
+func (s *messageService) CreateMessage(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error) {
+	req, err := s.validateCreateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseQuota, err := s.reserveRecipientQuota(ctx, req.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Creating new message",
+		"recipient", req.Recipient,
+		"webhook_url", req.WebhookURL,
+		"max_retries", req.MaxRetries,
+	)
+
+	message, err := s.repo.Create(ctx, req)
+	if err != nil {
+		releaseQuota()
+		if errors.Is(err, domain.ErrDuplicateIdempotencyKey) {
+			// A concurrent request with the same key won; the caller looks it up
+			return nil, err
+		}
+		s.logger.Error("Failed to create message",
+			"error", err,
+			"recipient", req.Recipient,
+		)
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	s.logger.Info("Message created successfully",
+		"message_id", message.ID,
+		"recipient", message.Recipient,
+	)
+	s.recordStatus(domain.MessageStatusPending)
+	s.publish(events.NewEvent(events.EventMessageCreated, message))
+
+	return message, nil
+}
+
+// CreateMessageIdempotent creates a message at most once per idempotency key.
+// Requests with the same key are serialized on this instance and, through the
+// idempotency lock, across instances, so a repeat returns the first request's
+// message. Without the lock they race on the repository's unique key and the
+// losers return the winner's message.
+func (s *messageService) CreateMessageIdempotent(ctx context.Context, req *domain.CreateMessageRequest, key string) (*domain.Message, bool, error) {
+	if key == "" {
+		message, err := s.CreateMessage(ctx, req)
+		return message, err == nil, err
+	}
+	if len(key) > domain.MaxIdempotencyKeyLength {
+		return nil, false, domain.NewValidationError(fmt.Sprintf("idempotency key must be at most %d characters", domain.MaxIdempotencyKeyLength))
+	}
+
+	unlock := s.idempotencyLocks.Lock(key)
+	defer unlock()
+
+	release, err := s.lockIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to wait for idempotency key: %w", err)
+	}
+	defer release()
+
+	existing, err := s.repo.GetByIdempotencyKey(ctx, key)
+	if err == nil {
+		s.logger.Info("Returning message for repeated idempotency key",
+			"message_id", existing.ID,
+			"idempotency_key", key,
+		)
+		return existing, false, nil
+	}
+	if !errors.Is(err, domain.ErrMessageNotFound) {
+		return nil, false, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	keyed := *req
+	keyed.IdempotencyKey = &key
+
+	message, err := s.CreateMessage(ctx, &keyed)
+	if err == nil {
+		return message, true, nil
+	}
+
+	// A concurrent request with the same key may have created the message
+	// first, failing this one on the unique key or on the recipient's quota
+	var limitErr *domain.RecipientLimitError
+	if !errors.Is(err, domain.ErrDuplicateIdempotencyKey) && !errors.As(err, &limitErr) {
+		return nil, false, err
+	}
+	existing, lookupErr := s.repo.GetByIdempotencyKey(ctx, key)
+	if lookupErr != nil {
+		if limitErr != nil {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("failed to look up idempotency key: %w", lookupErr)
+	}
+
+	s.logger.Info("Returning message for concurrent idempotency key",
+		"message_id", existing.ID,
+		"idempotency_key", key,
+	)
+	return existing, false, nil
+}
+
+// lockIdempotencyKey takes the idempotency lock for key, waiting while a
+// create on another instance holds it, and returns the func that releases it.
+// Without a locker, or when the lock cannot be reached, the create goes ahead
+// and the repository's unique key still rejects duplicates.
+func (s *messageService) lockIdempotencyKey(ctx context.Context, key string) (func(), error) {
+	if s.keyLocker == nil {
+		return func() {}, nil
+	}
+
+	owner := uuid.NewString()
+	for {
+		acquired, err := s.keyLocker.AcquireIdempotencyLock(ctx, key, owner, idempotencyLockTTL)
+		if err != nil {
+			s.logger.Warn("Failed to take idempotency lock, relying on the unique key",
+				"idempotency_key", key,
+				"error", err,
+			)
+			return func() {}, nil
+		}
+		if acquired {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(idempotencyLockPoll):
+		}
+	}
+
+	return func() {
+		// The lock is released even when the request was cancelled meanwhile
+		if err := s.keyLocker.ReleaseIdempotencyLock(context.WithoutCancel(ctx), key, owner); err != nil {
+			// It expires with its TTL anyway
+			s.logger.Warn("Failed to release idempotency lock",
+				"idempotency_key", key,
+				"error", err,
+			)
+		}
+	}, nil
+}
+
+// ValidateMessage validates req as CreateMessage does and builds the pending
+// message it would store, which has no ID. The recipient's daily limit is not
+// checked because that would reserve quota for a message never created.
+func (s *messageService) ValidateMessage(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error) {
+	req, err := s.validateCreateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRetries := req.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = 3 // Default max retries
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = domain.ChannelWebhook
+	}
+
+	now := time.Now()
+	return &domain.Message{
+		Recipient:  req.Recipient,
+		Content:    req.Content,
+		WebhookURL: req.WebhookURL,
+		Status:     domain.MessageStatusPending,
+		MaxRetries: maxRetries,
+		Priority:   req.Priority,
+		Channel:    channel,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+
+		PayloadTemplate: req.PayloadTemplate,
+		ExpiresAt:       req.ExpiresAt,
+	}, nil
+}
+
+// validateCreateRequest checks a create request, returning the request to store,
+// which has its content sanitized when the sanitize mode asks for it and its
+// TTL resolved to ExpiresAt. Every invalid field is reported in a single
+// ValidationError.
+func (s *messageService) validateCreateRequest(req *domain.CreateMessageRequest) (*domain.CreateMessageRequest, error) {
+	var fields []domain.FieldError
+	reject := func(field, message string) {
+		fields = append(fields, domain.FieldError{Field: field, Message: message})
+	}
+
+	channel := req.Channel
+	if channel == "" {
+		channel = domain.ChannelWebhook
+	}
+	_, hasDeliverer := s.deliverers.Lookup(channel)
+	switch {
+	case !channel.IsValid():
+		reject("channel", "channel must be one of webhook, email, sms")
+	case channel != domain.ChannelWebhook && !hasDeliverer:
+		reject("channel", fmt.Sprintf("channel %s is not available", channel))
+	}
+
+	switch {
+	case req.Recipient == "":
+		reject("recipient", "recipient is required")
+	case channel == domain.ChannelSMS:
+		if !isValidPhoneNumber(req.Recipient) {
+			reject("recipient", "recipient must be a phone number in E.164 format")
+		}
+	case !isValidEmail(req.Recipient):
+		reject("recipient", "recipient must be a valid email address")
+	}
+
+	switch {
+	case req.Content == "":
+		reject("content", "content is required")
+	case !domain.IsSafeContent(req.Content):
+		switch s.contentSanitizeMode() {
+		case config.ContentSanitizeReject:
+			reject("content", "content must be valid UTF-8 without control characters")
+		case config.ContentSanitizeSanitize:
+			sanitized := *req
+			sanitized.Content = domain.SanitizeContent(req.Content)
+			req = &sanitized
+		}
+	}
+	if limit := s.maxContentLength(); utf8.RuneCountInString(req.Content) > limit {
+		reject("content", fmt.Sprintf("content exceeds maximum length of %d characters", limit))
+	}
+
+	// Only webhook messages need a URL, but one given for another channel must
+	// still be well-formed
+	switch {
+	case req.WebhookURL == "" && channel == domain.ChannelWebhook:
+		reject("webhook_url", "webhook URL is required")
+	case req.WebhookURL != "" && !isValidWebhookURL(req.WebhookURL):
+		reject("webhook_url", "webhook URL must be a valid http(s) URL")
+	}
+
+	if req.Priority < 0 || req.Priority > domain.MaxMessagePriority {
+		reject("priority", fmt.Sprintf("priority must be between 0 and %d", domain.MaxMessagePriority))
+	}
+
+	// Zero leaves the default retry budget to the repository
+	if limit := s.maxRetriesLimit(); req.MaxRetries < 0 || req.MaxRetries > limit {
+		reject("max_retries", fmt.Sprintf("max_retries must be between 0 and %d", limit))
+	}
+
+	// A template is parsed and rendered against a sample payload now, so a
+	// broken one is rejected instead of failing every delivery attempt
+	if req.PayloadTemplate != "" {
+		if channel != domain.ChannelWebhook {
+			reject("payload_template", "payload template is only supported for the webhook channel")
+		} else if _, err := NewTemplateTransformer(req.PayloadTemplate); err != nil {
+			reject("payload_template", err.Error())
+		}
+	}
+
+	switch {
+	case req.TTL != 0 && req.ExpiresAt != nil:
+		reject("ttl_seconds", "ttl_seconds and expires_at cannot both be set")
+	case req.TTL < 0:
+		reject("ttl_seconds", "ttl_seconds must be positive")
+	case req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()):
+		reject("expires_at", "expires_at must be in the future")
+	}
+
+	if len(fields) > 0 {
+		return nil, domain.NewFieldValidationError(fields...)
+	}
+
+	// A TTL counts from creation, so it is resolved to an absolute expiry now
+	if req.TTL > 0 {
+		expiresAt := time.Now().Add(req.TTL)
+		resolved := *req
+		resolved.ExpiresAt = &expiresAt
+		resolved.TTL = 0
+		req = &resolved
+	}
+
+	return req, nil
+}
+
+// CreateMessages validates each request and inserts the valid ones in one
+// transaction. Requests that fail validation or exceed their recipient's daily
+// limit are reported in their result instead of failing the batch; any other
+// error fails the whole batch and nothing is stored.
+func (s *messageService) CreateMessages(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]domain.CreateResult, error) {
+	results := make([]domain.CreateResult, len(reqs))
+	valid := make([]*domain.CreateMessageRequest, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+	releases := make([]func(), 0, len(reqs))
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	for i, req := range reqs {
+		validated, err := s.validateCreateRequest(req)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		release, err := s.reserveRecipientQuota(ctx, validated.Recipient)
+		if err != nil {
+			var limitErr *domain.RecipientLimitError
+			if errors.As(err, &limitErr) {
+				results[i].Err = err
+				continue
+			}
+			releaseAll()
+			return nil, err
+		}
+
+		valid = append(valid, validated)
+		positions = append(positions, i)
+		releases = append(releases, release)
+	}
+
+	if len(valid) > 0 {
+		messages, err := s.repo.CreateBatch(ctx, valid)
+		if err != nil {
+			releaseAll()
+			s.logger.Error("Failed to create messages",
+				"error", err,
+				"count", len(valid),
+			)
+			return nil, fmt.Errorf("failed to create messages: %w", err)
+		}
+
+		for j, message := range messages {
+			results[positions[j]].Message = message
+			s.recordStatus(domain.MessageStatusPending)
+			s.publish(events.NewEvent(events.EventMessageCreated, message))
+		}
+	}
+
+	s.logger.Info("Bulk create completed",
+		"requested", len(reqs),
+		"created", len(valid),
+		"rejected", len(reqs)-len(valid),
+	)
+
+	return results, nil
+}
+
+// batchCommitSize returns how many imported messages are inserted per transaction
+func (s *messageService) batchCommitSize() int {
+	if s.config == nil || s.config.BatchCommitSize <= 0 {
+		return defaultBatchCommitSize
+	}
+	return s.config.BatchCommitSize
+}
+
+// ImportMessages validates every request up front and then inserts them in
+// chunks of BatchCommitSize, each chunk in its own transaction. progress, when
+// not nil, is called after each committed chunk. If a chunk fails, the
+// messages committed by earlier chunks are returned along with the error.
+func (s *messageService) ImportMessages(ctx context.Context, reqs []*domain.CreateMessageRequest, progress func(imported, total int)) ([]*domain.Message, error) {
+	total := len(reqs)
+	valid := make([]*domain.CreateMessageRequest, total)
+	for i, req := range reqs {
+		validated, err := s.validateCreateRequest(req)
+		if err != nil {
+			return nil, domain.NewValidationError(fmt.Sprintf("message %d: %s", i, err.Error()))
+		}
+		valid[i] = validated
+	}
+
+	releases := make([]func(), 0, total)
+	releaseFrom := func(start int) {
+		for _, release := range releases[start:] {
+			release()
+		}
+	}
+	for _, req := range valid {
+		release, err := s.reserveRecipientQuota(ctx, req.Recipient)
+		if err != nil {
+			releaseFrom(0)
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	chunkSize := s.batchCommitSize()
+	s.logger.Info("Importing messages",
+		"total", total,
+		"commit_size", chunkSize,
+	)
+
+	imported := make([]*domain.Message, 0, total)
+	for start := 0; start < total; start += chunkSize {
+		end := min(start+chunkSize, total)
+
+		messages, err := s.repo.CreateBatch(ctx, valid[start:end])
+		if err != nil {
+			releaseFrom(start)
+			s.logger.Error("Failed to import messages",
+				"error", err,
+				"imported", len(imported),
+				"total", total,
+			)
+			return imported, fmt.Errorf("failed to import messages after %d of %d: %w", len(imported), total, err)
+		}
+
+		imported = append(imported, messages...)
+		for _, message := range messages {
+			s.recordStatus(domain.MessageStatusPending)
+			s.publish(events.NewEvent(events.EventMessageCreated, message))
+		}
+
+		s.logger.Info("Import progress",
+			"imported", len(imported),
+			"total", total,
+		)
+		if progress != nil {
+			progress(len(imported), total)
+		}
+	}
+
+	return imported, nil
+}
+
+// recipientDailyLimit returns the configured per-recipient daily cap, or zero when disabled
+func (s *messageService) recipientDailyLimit() int {
+	if s.config == nil || s.config.RecipientDailyLimit < 0 {
+		return 0
+	}
+	return s.config.RecipientDailyLimit
+}
+
+// reserveRecipientQuota counts a new message against its recipient's daily
+// limit, returning a *domain.RecipientLimitError when the limit is already
+// reached. The returned release func gives the slot back and must be called if
+// the message is not created. The Redis counter is used when available; if it
+// is missing or failing, today's rows are counted in the repository instead.
+func (s *messageService) reserveRecipientQuota(ctx context.Context, recipient string) (func(), error) {
+	limit := s.recipientDailyLimit()
+	if limit == 0 {
+		return func() {}, nil
+	}
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	limitErr := &domain.RecipientLimitError{Limit: limit, ResetAt: day.AddDate(0, 0, 1)}
+
+	if s.recipients != nil {
+		count, err := s.recipients.IncrementRecipientDailyCount(ctx, recipient, day, 1)
+		if err == nil {
+			if count == 1 {
+				count = s.seedRecipientCount(ctx, recipient, day)
+			}
+
+			release := func() {
+				// The create may have failed because ctx was cancelled
+				if _, err := s.recipients.IncrementRecipientDailyCount(context.WithoutCancel(ctx), recipient, day, -1); err != nil {
+					s.logger.Warn("Failed to release recipient daily count", "recipient", recipient, "error", err)
+				}
+			}
+
+			if count > int64(limit) {
+				release()
+				s.logger.Warn("Recipient daily limit reached", "recipient", recipient, "limit", limit)
+				return nil, limitErr
+			}
+			return release, nil
+		}
+
+		s.logger.Warn("Recipient counter unavailable, counting in the repository",
+			"recipient", recipient,
+			"error", err,
+		)
+	}
+
+	count, err := s.repo.CountByRecipientSince(ctx, recipient, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check recipient daily limit: %w", err)
+	}
+	if count >= limit {
+		s.logger.Warn("Recipient daily limit reached", "recipient", recipient, "limit", limit)
+		return nil, limitErr
+	}
+
+	return func() {}, nil
+}
+
+// seedRecipientCount brings a counter just created by this message up to date
+// with the messages already stored for the day, e.g. after a Redis restart. It
+// returns the resulting count, which includes this message.
+func (s *messageService) seedRecipientCount(ctx context.Context, recipient string, day time.Time) int64 {
+	existing, err := s.repo.CountByRecipientSince(ctx, recipient, day)
+	if err != nil {
+		s.logger.Warn("Failed to seed recipient daily count", "recipient", recipient, "error", err)
+		return 1
+	}
+	if existing == 0 {
+		return 1
+	}
+
+	count, err := s.recipients.IncrementRecipientDailyCount(ctx, recipient, day, int64(existing))
+	if err != nil {
+		s.logger.Warn("Failed to seed recipient daily count", "recipient", recipient, "error", err)
+		return int64(existing) + 1
+	}
+	return count
+}
+
+// contentSanitizeMode returns the configured content sanitization mode
+func (s *messageService) contentSanitizeMode() string {
+	if s.config == nil || s.config.ContentSanitizeMode == "" {
+		return config.ContentSanitizeOff
+	}
+	return s.config.ContentSanitizeMode
+}
+
+// maxRetriesLimit returns the largest max_retries a message may be created with
+func (s *messageService) maxRetriesLimit() int {
+	if s.config != nil && s.config.MaxRetriesLimit > 0 {
+		return s.config.MaxRetriesLimit
+	}
+	return 10
+}
+
+// maxContentLength returns the configured content length cap in characters
+func (s *messageService) maxContentLength() int {
+	if s.config != nil && s.config.MaxContentLength > 0 {
+		return s.config.MaxContentLength
+	}
+	return 10000
+}
+
+// isValidEmail reports whether recipient is a bare email address. Display-name
+// forms like "Jane <jane@example.com>" are rejected so the stored recipient is
+// always the address itself.
+func isValidEmail(recipient string) bool {
+	addr, err := mail.ParseAddress(recipient)
+	return err == nil && addr.Address == recipient
+}
+
+// isValidPhoneNumber reports whether recipient is an E.164 phone number: a +
+// followed by up to 15 digits, the first of them not zero
+func isValidPhoneNumber(recipient string) bool {
+	if len(recipient) < 3 || len(recipient) > 16 || recipient[0] != '+' || recipient[1] == '0' {
+		return false
+	}
+	for _, r := range recipient[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidWebhookURL reports whether webhookURL is an absolute http or https URL with a host
+func isValidWebhookURL(webhookURL string) bool {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// ProcessUnsentMessages processes unsent messages for delivery.
+//
+// Each message is delivered and marked in a transaction of its own, which
+// keeps its row locked against other instances until the new status commits.
+// Delivery is still at-least-once rather than exactly-once: if the process
+// crashes or the commit fails after a webhook succeeded, the status update
+// rolls back and the message is sent again on a later tick. Receivers that
+// must not see duplicates should deduplicate on the message ID.
+func (s *messageService) ProcessUnsentMessages(ctx context.Context, batchSize int) (int, error) {
+	s.logger.Info("Processing unsent messages", "batch_size", batchSize)
+
+	s.expireOverdue(ctx)
+
+	return s.processBatch(ctx, batchSize)
+}
+
+// expireOverdue marks undelivered messages whose expiry passed as expired, so
+// they stop waiting in the queue. A failure is logged and left for the next
+// run, since selection skips expired messages either way.
+func (s *messageService) expireOverdue(ctx context.Context) {
+	expired, err := s.repo.ExpireOverdue(ctx)
+	if err != nil {
+		s.logger.Error("Failed to expire overdue messages", "error", err)
+		return
+	}
+
+	for _, message := range expired {
+		s.invalidateCache(ctx, message.ID)
+		s.logger.Info("Message expired before delivery",
+			"message_id", message.ID,
+			"expires_at", message.ExpiresAt,
+		)
+		s.publish(events.NewEvent(events.EventMessageExpired, message))
+	}
+}
+
+// processBatch selects up to batchSize unsent messages and delivers them with
+// the worker pool. The selection skips messages other instances hold but does
+// not keep them locked; processLocked locks each one before delivering it.
+func (s *messageService) processBatch(ctx context.Context, batchSize int) (int, error) {
+	messages, err := s.repo.SelectUnsentForUpdate(ctx, batchSize)
+	if err != nil {
+		s.logger.Error("Failed to select unsent messages", "error", err)
+		return 0, fmt.Errorf("failed to select unsent messages: %w", err)
+	}
+	selectedAt := time.Now()
+
+	messages = s.skipPausedHosts(ctx, messages)
+	messages = s.skipSuppressedRecipients(ctx, messages)
+	if len(messages) == 0 {
+		s.logger.Debug("No unsent messages found")
+		return 0, nil
+	}
+
+	workers := s.workerPoolSize()
+	if workers > len(messages) {
+		workers = len(messages)
+	}
+
+	// Fan the batch out to a bounded pool of workers; a failure on one message
+	// never stops the others, but cancelling ctx skips every message not yet
+	// started
+	jobs := make(chan *domain.Message)
+	var processed, maxWait int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for message := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				s.recordWorkerWait(time.Since(selectedAt), &maxWait)
+				err := s.processLocked(ctx, message)
+				switch {
+				case errors.Is(err, domain.ErrMessageNotFound):
+					s.logger.Debug("Message no longer due for delivery, skipping",
+						"message_id", message.ID,
+					)
+				case err != nil:
+					s.logger.Error("Failed to process message",
+						"message_id", message.ID,
+						"error", err,
+					)
+				default:
+					atomic.AddInt64(&processed, 1)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, message := range messages {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- message:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		s.logger.Warn("Stopped processing unsent messages early",
+			"total_found", len(messages),
+			"successfully_processed", processed,
+			"error", err,
+		)
+		return int(processed), err
+	}
+
+	s.logger.Info("Processed unsent messages",
+		"total_found", len(messages),
+		"successfully_processed", processed,
+		"workers", workers,
+		"max_worker_wait", time.Duration(maxWait),
+	)
+
+	return int(processed), nil
+}
+
+// recordWorkerWait observes how long a message waited for a worker and keeps
+// the batch maximum in maxWait
+func (s *messageService) recordWorkerWait(wait time.Duration, maxWait *int64) {
+	if s.metrics != nil {
+		s.metrics.RecordWorkerWait(wait)
+	}
+	for {
+		current := atomic.LoadInt64(maxWait)
+		if int64(wait) <= current || atomic.CompareAndSwapInt64(maxWait, current, int64(wait)) {
+			return
+		}
+	}
+}
+
+// processLocked locks message in a transaction of its own, then delivers it
+// and records the outcome in that transaction. The transaction is not bound
+// to ctx: cancelling a run stops deliveries that have not started, but never
+// rolls back the record of one that already happened. Cache updates for the
+// outcome wait until it has committed. A message that is no longer due, or
+// that another instance holds, returns domain.ErrMessageNotFound.
+func (s *messageService) processLocked(ctx context.Context, message *domain.Message) error {
+	txCtx := context.WithoutCancel(ctx)
+	hooks := &commitHooks{}
+
+	var processErr error
+	err := s.repo.WithTx(txCtx, func(txRepo repo.MessageRepository) error {
+		locked, err := txRepo.LockUnsent(txCtx, message.ID)
+		if err != nil {
+			return err
+		}
+
+		// Whatever processMessage managed to record commits, even when it
+		// reports an error
+		processErr = s.processMessage(withCommitHooks(ctx, hooks), txRepo, locked)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	hooks.run(txCtx)
+	return processErr
+}
+
+// deliveryFailedError is returned by processMessage when a delivery failed and
+// the failure was recorded on the message
+type deliveryFailedError struct {
+	channel domain.Channel
+	err     error
+}
+
+func (e *deliveryFailedError) Error() string {
+	return fmt.Sprintf("%s delivery failed: %v", e.channel, e.err)
+}
+
+func (e *deliveryFailedError) Unwrap() error {
+	return e.err
+}
+
+// Is lets callers outside the service match a failed delivery with
+// domain.ErrDeliveryFailed
+func (e *deliveryFailedError) Is(target error) bool {
+	return target == domain.ErrDeliveryFailed
+}
+
+// processMessage delivers a single message and records the outcome on store.
+// The outcome is recorded even when ctx is cancelled once delivery finished,
+// so a stopped run or a disconnected client cannot lose a completed delivery.
+// A message that is already sent is a forced resend: success moves its
+// sent_at, and failure leaves the earlier delivery on record.
+func (s *messageService) processMessage(ctx context.Context, store repo.MessageRepository, message *domain.Message) (err error) {
+	markCtx, cancelMark := markContext(ctx)
+	defer cancelMark()
+
+	start := time.Now()
+	defer func() {
+		s.recordProcessed(err, time.Since(start))
+	}()
+
+	s.logger.Debug("Processing message",
+		"message_id", message.ID,
+		"recipient", message.Recipient,
+		"retry_count", message.RetryCount,
+	)
+
+	channel := message.Channel
+	if channel == "" {
+		channel = domain.ChannelWebhook
+	}
+
+	// Deliver through the channel's deliverer; webhook messages are marked sent
+	// without delivery when no webhook client is configured
+	var providerMessageID string
+	deliverer, lookupErr := s.deliverers.Deliverer(channel)
+	if lookupErr == nil {
+		deliveryCtx, cancel := context.WithCancelCause(ctx)
+		deliveryCtx = withAttemptObserver(deliveryCtx, func(statusCode int, duration time.Duration, err error) {
+			s.recordAttempt(ctx, message.ID, statusCode, duration, err)
+		})
+		done := s.deliveries.register(message.ID, cancel)
+		ref, err := deliverer.Deliver(deliveryCtx, message)
+		done()
+		cancel(nil)
+
+		if err != nil && errors.Is(context.Cause(deliveryCtx), errDeliveryCancelled) {
+			// The message is already cancelled, so it must not be retried
+			s.logger.Info("Delivery cancelled",
+				"message_id", message.ID,
+				"channel", channel,
+				"webhook_url", message.WebhookURL,
+			)
+			return fmt.Errorf("%s delivery cancelled: %w", channel, err)
+		}
+		if err != nil {
+			s.logger.Error("Failed to deliver message",
+				"message_id", message.ID,
+				"channel", channel,
+				"webhook_url", message.WebhookURL,
+				"error", err,
+			)
+
+			// Mark message as failed, dead-lettering it once retries are exhausted
+			if message.Status != domain.MessageStatusSent {
+				if markErr := s.markFailed(markCtx, store, message, err.Error()); markErr != nil {
+					return markErr
+				}
+			}
+			return &deliveryFailedError{channel: channel, err: err}
+		}
+		providerMessageID = ref
+	} else if channel == domain.ChannelWebhook {
+		s.logger.Debug("No webhook client configured, skipping webhook delivery",
+			"message_id", message.ID,
+		)
+	} else {
+		s.logger.Error("Failed to deliver message",
+			"message_id", message.ID,
+			"channel", channel,
+			"error", lookupErr,
+		)
+		if message.Status != domain.MessageStatusSent {
+			if markErr := s.markFailed(markCtx, store, message, lookupErr.Error()); markErr != nil {
+				return markErr
+			}
+		}
+		return lookupErr
+	}
+
+	mark := s.markSent
+	if message.Status == domain.MessageStatusSent {
+		mark = s.markResent
+	}
+	if err := mark(markCtx, store, message, providerMessageID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Message processed successfully",
+		"message_id", message.ID,
+		"recipient", message.Recipient,
+	)
+
+	return nil
+}
+
+// recordAttempt adds a webhook request to the message's audit log. It writes
+// outside the message's transaction, so the log keeps attempts whose status update
+// is rolled back, and a failure to record is logged rather than failing the
+// delivery.
+func (s *messageService) recordAttempt(ctx context.Context, messageID int64, statusCode int, duration time.Duration, attemptErr error) {
+	// A cancelled delivery still made the request, so it is recorded too
+	ctx = context.WithoutCancel(ctx)
+	if err := s.repo.RecordAttempt(ctx, messageID, statusCode, duration.Milliseconds(), attemptErr); err != nil {
+		s.logger.Warn("Failed to record webhook attempt",
+			"message_id", messageID,
+			"status_code", statusCode,
+			"error", err,
+		)
+	}
+}
+
+// markSent records a successful delivery on store, storing the receiver's
+// reference when there is one. Once the status update has committed it is
+// counted, published and cached.
+func (s *messageService) markSent(ctx context.Context, store repo.MessageRepository, message *domain.Message, providerMessageID string) error {
+	err := s.withMarkRetry(ctx, "sent", store, message, func(ctx context.Context, store repo.MessageRepository) error {
+		if providerMessageID != "" {
+			return store.MarkSentWithReference(ctx, message.ID, providerMessageID)
+		}
+		return store.MarkSent(ctx, message.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark message as sent: %w", err)
+	}
+
+	afterCommit(ctx, func(ctx context.Context) {
+		s.recordStatus(domain.MessageStatusSent)
+		s.publish(events.NewEvent(events.EventMessageSent, message))
+		s.cacheSent(ctx, message)
+	})
+
+	return nil
+}
+
+// markResent records on store that a sent message was delivered again. The
+// message keeps its place in the recently sent list, so only its cached
+// copies are dropped once the update has committed.
+func (s *messageService) markResent(ctx context.Context, store repo.MessageRepository, message *domain.Message, providerMessageID string) error {
+	err := s.withMarkRetry(ctx, "resent", store, message, func(ctx context.Context, store repo.MessageRepository) error {
+		return store.MarkResent(ctx, message.ID, providerMessageID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark message as resent: %w", err)
+	}
+
+	afterCommit(ctx, func(ctx context.Context) {
+		s.invalidateCache(ctx, message.ID)
+	})
+
+	return nil
+}
+
+// cacheSent caches the metadata of a message that was just sent and adds it
+// to the recently sent list
+func (s *messageService) cacheSent(ctx context.Context, message *domain.Message) {
+	// Cache message metadata if Redis cache is available
+	if s.cache != nil {
+		metadata := &repo.MessageMetadata{
+			ID:         int(message.ID),
+			Recipient:  message.Recipient,
+			Status:     "sent",
+			SentAt:     time.Now(),
+			RetryCount: message.RetryCount,
+			MaxRetries: message.MaxRetries,
+			WebhookURL: message.WebhookURL,
+		}
+
+		if err := s.cache.CacheMessageMetadata(ctx, metadata); err != nil {
+			// Log error but don't fail the operation
+			s.logger.Warn("Failed to cache message metadata",
+				"message_id", message.ID,
+				"error", err,
+			)
+		}
+	}
+
+	if s.recentlySent != nil {
+		if err := s.recentlySent.AddRecentlySentMessage(ctx, message.ID, recentlySentWindow); err != nil {
+			// A list missing this message would serve wrong pages, so empty it
+			// and let later sends rebuild it
+			s.logger.Warn("Failed to record recently sent message",
+				"message_id", message.ID,
+				"error", err,
+			)
+			if err := s.recentlySent.CacheRecentlySentMessages(ctx, nil); err != nil {
+				s.logger.Warn("Failed to reset recently sent messages", "error", err)
+			}
+		}
+	}
+}
+
+// markFailed records a delivery failure on store and moves the message to the
+// dead-letter state when the failure used up its last retry. Metrics, events
+// and the cache only see the failure once it has committed.
+func (s *messageService) markFailed(ctx context.Context, store repo.MessageRepository, message *domain.Message, errorMsg string) error {
+	retryDelay := s.retryDelay(message.RetryCount)
+	err := s.withMarkRetry(ctx, "failed", store, message, func(ctx context.Context, store repo.MessageRepository) error {
+		return store.MarkFailed(ctx, message.ID, errorMsg, retryDelay)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark message as failed: %w", err)
+	}
+
+	// The cache is dropped only once the failure commits, so a read in
+	// between cannot cache the state it replaces again
+	afterCommit(ctx, func(ctx context.Context) {
+		s.invalidateCache(ctx, message.ID)
+		s.recordStatus(domain.MessageStatusFailed)
+		event := events.NewEvent(events.EventMessageFailed, message)
+		event.RetryCount = message.RetryCount + 1
+		event.Error = errorMsg
+		s.publish(event)
+	})
+
+	// MarkFailed increments retry_count, so this failure exhausts the message
+	// once the incremented count reaches max_retries
+	if message.RetryCount+1 >= message.MaxRetries {
+		return s.markDeadLetter(ctx, store, message)
+	}
+
+	return nil
+}
+
+// invalidateCache drops the cached copies of a message whose state changed. It
+// is a no-op without a cache; failures are only logged because the entries
+// expire with the cache TTL anyway.
+func (s *messageService) invalidateCache(ctx context.Context, messageID int64) {
+	if s.cache != nil {
+		if err := s.cache.DeleteMessageMetadata(ctx, int(messageID)); err != nil {
+			s.logger.Warn("Failed to invalidate cached message metadata",
+				"message_id", messageID,
+				"error", err,
+			)
+		}
+	}
+	if s.messageCache != nil {
+		if err := s.messageCache.DeleteCachedMessage(ctx, messageID); err != nil {
+			s.logger.Warn("Failed to invalidate cached message",
+				"message_id", messageID,
+				"error", err,
+			)
+		}
+	}
+}
+
+// retryDelay returns how long to wait before retrying a message that failed with
+// retryCount previous failures. The first failure waits InitialRetryDelay, or
+// BackoffMin when that is unset, and each later one doubles it, capped at
+// BackoffMax. Without configuration retries are eligible immediately, matching
+// the scheduler's own retry interval.
+func (s *messageService) retryDelay(retryCount int) time.Duration {
+	if s.config == nil {
+		return 0
+	}
+	base := s.config.InitialRetryDelay
+	if base <= 0 {
+		base = s.config.BackoffMin
+	}
+	if base <= 0 {
+		return 0
+	}
+
+	maxDelay := s.config.BackoffMax
+	if maxDelay < base {
+		maxDelay = base
+	}
+
+	delay := base
+	for i := 0; i < retryCount; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	return delay
+}
+
+// publish sends a lifecycle event to the event bus when one is configured
+func (s *messageService) publish(event events.Event) {
+	if s.eventBus != nil {
+		s.eventBus.Publish(event)
+	}
+}
+
+// recordStatus counts a message moving to status on messages_total when metrics
+// are configured
+func (s *messageService) recordStatus(status domain.MessageStatus) {
+	if s.metrics != nil {
+		s.metrics.RecordMessageStatus(string(status))
+	}
+}
+
+// recordProcessed counts a delivery attempt by result and observes how long it
+// took, including marking the outcome
+func (s *messageService) recordProcessed(err error, duration time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	s.metrics.RecordMessageProcessed(result, duration)
+}
+
+// withMarkRetry runs a message status update on store, retrying transient failures
+// with a bounded exponential backoff. Each attempt runs in store.WithTx, a savepoint
+// when store is already in a transaction, so a failed attempt does not leave that
+// transaction aborted for the next one. When every attempt fails the stored status
+// no longer matches what happened to the message, so a critical log and metric are
+// emitted for operators to reconcile it.
+func (s *messageService) withMarkRetry(ctx context.Context, operation string, store repo.MessageRepository, message *domain.Message, mark func(ctx context.Context, store repo.MessageRepository) error) error {
+	attempts, backoffBase := s.markRetryPolicy()
+	backoff := retry.WithMaxRetries(uint64(attempts-1), retry.NewExponential(backoffBase))
+
+	attempt := 0
+	err := retry.Do(ctx, backoff, func(ctx context.Context) error {
+		attempt++
+		err := store.WithTx(ctx, func(txRepo repo.MessageRepository) error {
+			return mark(ctx, txRepo)
+		})
+		if err == nil || errors.Is(err, domain.ErrMessageNotFound) {
+			return err
+		}
+
+		if attempt < attempts {
+			s.logger.Warn("Failed to update message status, retrying",
+				"message_id", message.ID,
+				"operation", operation,
+				"attempt", attempt,
+				"error", err,
+			)
+		}
+		return retry.RetryableError(err)
+	})
+	if err == nil {
+		return nil
+	}
+
+	s.logger.Error("Failed to update message status, manual reconciliation required",
+		"severity", "critical",
+		"message_id", message.ID,
+		"operation", operation,
+		"attempts", attempt,
+		"retry_count", message.RetryCount,
+		"error", err,
+	)
+	if s.metrics != nil {
+		s.metrics.RecordMarkOperationFailure(operation)
+	}
+
+	return err
+}
+
+// markContext returns the context status updates run with: it carries ctx's
+// values but not its cancellation, bounded by markTimeout instead
+func markContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), markTimeout)
+}
+
+// workerPoolSize returns how many messages of a batch are processed concurrently.
+// Without configuration a batch is processed serially.
+func (s *messageService) workerPoolSize() int {
+	if s.config != nil && s.config.WorkerPoolSize > 0 {
+		return s.config.WorkerPoolSize
+	}
+	return 1
+}
+
+// markRetryPolicy returns the number of attempts and initial backoff for status
+// updates. Without configuration a status update is attempted once.
+func (s *messageService) markRetryPolicy() (int, time.Duration) {
+	attempts := 1
+	backoffBase := 100 * time.Millisecond
+
+	if s.config != nil {
+		if s.config.MarkRetryAttempts > 0 {
+			attempts = s.config.MarkRetryAttempts
+		}
+		if s.config.MarkRetryBackoff > 0 {
+			backoffBase = s.config.MarkRetryBackoff
+		}
+	}
+
+	return attempts, backoffBase
+}
+
+// markDeadLetter moves a message that can no longer be retried to the
+// dead-letter state on store, publishing it once that has committed
+func (s *messageService) markDeadLetter(ctx context.Context, store repo.MessageRepository, message *domain.Message) error {
+	err := s.withMarkRetry(ctx, "dead_letter", store, message, func(ctx context.Context, store repo.MessageRepository) error {
+		return store.MarkDeadLetter(ctx, message.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark message as dead-letter: %w", err)
+	}
+	afterCommit(ctx, func(ctx context.Context) {
+		s.publish(events.NewEvent(events.EventMessageDeadLettered, message))
+	})
+
+	s.logger.Warn("Message moved to dead-letter after exhausting retries",
+		"message_id", message.ID,
+		"retry_count", message.RetryCount,
+		"max_retries", message.MaxRetries,
+	)
+
+	return nil
+}
+
+// GetMessage retrieves a message by ID
+func (s *messageService) GetMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	s.logger.Debug("Getting message", "message_id", messageID)
+
+	if s.messageCache != nil {
+		start := time.Now()
+		cached, err := s.messageCache.GetCachedMessage(ctx, messageID)
+		if err != nil {
+			// Fall back to the repository rather than failing the read
+			s.logger.Warn("Failed to read message from cache",
+				"message_id", messageID,
+				"error", err,
+			)
+		}
+		if cached != nil {
+			if s.metrics != nil {
+				s.metrics.RecordCacheHit(getMessageCacheOperation, time.Since(start))
+			}
+			return cached, nil
+		}
+		if s.metrics != nil {
+			s.metrics.RecordCacheMiss(getMessageCacheOperation)
+		}
+	}
+
+	message, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		s.logger.Error("Failed to get message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	// Only sent messages are final; every other status can still change and
+	// would go stale in the cache
+	if s.messageCache != nil && message.Status == domain.MessageStatusSent {
+		if err := s.messageCache.CacheMessage(ctx, message); err != nil {
+			s.logger.Warn("Failed to cache message",
+				"message_id", messageID,
+				"error", err,
+			)
+		}
+	}
+
+	return message, nil
+}
+
+// GetMessageAttempts retrieves a message's webhook attempt history. The
+// message is only looked up when it has none, so the history of archived
+// messages stays readable.
+func (s *messageService) GetMessageAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error) {
+	attempts, err := s.repo.ListAttempts(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook attempts: %w", err)
+	}
+	if len(attempts) > 0 {
+		return attempts, nil
+	}
+
+	if _, err := s.repo.GetByID(ctx, messageID); err != nil {
+		return nil, fmt.Errorf("failed to get webhook attempts: %w", err)
+	}
+	return []*domain.WebhookAttempt{}, nil
+}
+
+// GetSentMessages retrieves sent messages in the given order with pagination.
+// Only the default order, most recently sent first, is served from the
+// recently sent cache.
+func (s *messageService) GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error) {
+	s.logger.Debug("Getting sent messages",
+		"offset", offset,
+		"limit", limit,
+		"sort", order.Field,
+		"order", order.Order,
+	)
+
+	if !order.IsValid() {
+		return nil, 0, domain.NewValidationError("sent messages can only be sorted by sent_at or created_at, asc or desc")
+	}
+
+	if s.recentlySent != nil && order == domain.DefaultSentMessagesSort {
+		start := time.Now()
+		if messages, total, ok := s.recentSentMessages(ctx, offset, limit); ok {
+			if s.metrics != nil {
+				s.metrics.RecordCacheHit(getSentMessagesCacheOperation, time.Since(start))
+			}
+			return messages, total, nil
+		}
+		if s.metrics != nil {
+			s.metrics.RecordCacheMiss(getSentMessagesCacheOperation)
+		}
+	}
+
+	messages, total, err := s.repo.GetSentMessages(ctx, offset, limit, order)
+	if err != nil {
+		s.logger.Error("Failed to get sent messages",
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to get sent messages: %w", err)
+	}
+
+	s.logger.Debug("Retrieved sent messages",
+		"count", len(messages),
+		"total", total,
+	)
+
+	return messages, total, nil
+}
+
+// recentSentMessages serves a page of sent messages from the recently sent
+// list, hydrating the messages from the message cache and loading the rest in
+// one repository query. It reports false when the list is cold or does not
+// cover the page, in which case the caller reads the page from the repository.
+func (s *messageService) recentSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, bool) {
+	if offset+limit > recentlySentWindow {
+		return nil, 0, false
+	}
+
+	ids, err := s.recentlySent.GetRecentlySentMessages(ctx, offset+limit)
+	if err != nil {
+		s.logger.Warn("Failed to read recently sent messages from cache", "error", err)
+		return nil, 0, false
+	}
+	if len(ids) == 0 {
+		return nil, 0, false
+	}
+
+	total, err := s.repo.CountWithStatus(ctx, domain.MessageStatusSent)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	// The list holds the newest sends, so it covers the page only when it
+	// reaches the page's end or the last sent message
+	end := min(offset+limit, total)
+	if offset >= end || len(ids) < end {
+		return nil, 0, false
+	}
+
+	byID, err := s.hydrateSentMessages(ctx, ids[offset:end])
+	if err != nil {
+		s.logger.Warn("Failed to load recently sent messages", "error", err)
+		return nil, 0, false
+	}
+
+	messages := make([]*domain.Message, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		message, ok := byID[int64(id)]
+		if !ok || message.Status != domain.MessageStatusSent {
+			// Archived or requeued since it was listed; the repository has
+			// the current page
+			s.logger.Debug("Recently sent message unavailable, reading page from repository",
+				"message_id", id,
+			)
+			return nil, 0, false
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, total, true
+}
+
+// hydrateSentMessages loads the messages with the given IDs from the message
+// cache, reading the ones it misses from the repository in one query and
+// caching the sent ones among them
+func (s *messageService) hydrateSentMessages(ctx context.Context, ids []int) (map[int64]*domain.Message, error) {
+	byID := make(map[int64]*domain.Message, len(ids))
+	var missing []int64
+	for _, id := range ids {
+		if s.messageCache != nil {
+			if cached, err := s.messageCache.GetCachedMessage(ctx, int64(id)); err == nil && cached != nil {
+				byID[cached.ID] = cached
+				continue
+			}
+		}
+		missing = append(missing, int64(id))
+	}
+	if len(missing) == 0 {
+		return byID, nil
+	}
+
+	loaded, err := s.repo.GetByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range loaded {
+		byID[message.ID] = message
+		if s.messageCache == nil || message.Status != domain.MessageStatusSent {
+			continue
+		}
+		if err := s.messageCache.CacheMessage(ctx, message); err != nil {
+			s.logger.Warn("Failed to cache message",
+				"message_id", message.ID,
+				"error", err,
+			)
+		}
+	}
+
+	return byID, nil
+}
+
+// ListMessages retrieves messages filtered by status with pagination
+func (s *messageService) ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error) {
+	messages, total, err := s.repo.ListMessages(ctx, status, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list messages",
+			"status", status,
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// ListMessagesAfterCursor retrieves a keyset page of messages. One extra row is
+// read so that the last page reports no next cursor.
+func (s *messageService) ListMessagesAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, int64, error) {
+	messages, err := s.repo.ListAfterCursor(ctx, status, cursorID, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list messages after cursor",
+			"status", status,
+			"cursor_id", cursorID,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	if len(messages) <= limit {
+		return messages, 0, nil
+	}
+
+	messages = messages[:limit]
+	return messages, messages[limit-1].ID, nil
+}
+
+// GetMessagesByRecipient retrieves messages sent to a recipient with pagination
+func (s *messageService) GetMessagesByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error) {
+	messages, total, err := s.repo.GetByRecipient(ctx, recipient, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to get messages for recipient",
+			"recipient", recipient,
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to get messages for recipient: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetMessagesByDateRange retrieves messages created within a date range with pagination
+func (s *messageService) GetMessagesByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error) {
+	messages, total, err := s.repo.ListByDateRange(ctx, from, to, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to get messages in date range",
+			"from", from,
+			"to", to,
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to get messages in date range: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetFailedMessages retrieves failed messages with their last error, most
+// recently failed first with pagination
+func (s *messageService) GetFailedMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	s.logger.Debug("Getting failed messages",
+		"offset", offset,
+		"limit", limit,
+	)
+
+	messages, total, err := s.repo.GetFailedMessagesPaginated(ctx, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to get failed messages",
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to get failed messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetDeadLetterMessages retrieves messages that exhausted their retries with pagination
+func (s *messageService) GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	s.logger.Debug("Getting dead-letter messages",
+		"offset", offset,
+		"limit", limit,
+	)
+
+	messages, total, err := s.repo.GetDeadLetterMessages(ctx, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to get dead-letter messages",
+			"offset", offset,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, 0, fmt.Errorf("failed to get dead-letter messages: %w", err)
+	}
+
+	return messages, total, nil
+}
+
+// GetRecentMessages retrieves messages of any status created within the last since duration
+func (s *messageService) GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error) {
+	s.logger.Debug("Getting recent messages",
+		"since", since,
+		"limit", limit,
+	)
+
+	messages, err := s.repo.GetRecentMessages(ctx, since, limit)
+	if err != nil {
+		s.logger.Error("Failed to get recent messages",
+			"since", since,
+			"limit", limit,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get recent messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// ResendMessage queues a sent message for redelivery as a new pending message
+// linked to the original, which is left unchanged
+func (s *messageService) ResendMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	original, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		s.logger.Error("Failed to get message for resend",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	if original.Status != domain.MessageStatusSent {
+		return nil, fmt.Errorf("message with ID %d cannot be resent: %w", messageID, domain.ErrMessageNotSent)
+	}
+
+	// A resend is a new delivery to the recipient and counts towards their cap
+	releaseQuota, err := s.reserveRecipientQuota(ctx, original.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.repo.Create(ctx, &domain.CreateMessageRequest{
+		Recipient:  original.Recipient,
+		Content:    original.Content,
+		WebhookURL: original.WebhookURL,
+		MaxRetries: original.MaxRetries,
+		Priority:   original.Priority,
+		Channel:    original.Channel,
+		ResentFrom: &original.ID,
+
+		PayloadTemplate: original.PayloadTemplate,
+	})
+	if err != nil {
+		releaseQuota()
+		s.logger.Error("Failed to create resent message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to resend message: %w", err)
+	}
+
+	s.logger.Info("Message resent",
+		"message_id", message.ID,
+		"resent_from", messageID,
+	)
+	s.recordStatus(domain.MessageStatusPending)
+	s.publish(events.NewEvent(events.EventMessageCreated, message))
+
+	return message, nil
+}
+
+// SendNow delivers a message outside the processing runs, as support does to
+// push one message through at once. The message is locked while it is sent,
+// so a processing run never delivers it at the same time, and one that is
+// delivering it finishes first. A failed delivery is recorded as usual and is
+// not an error: the returned message reports it. A forced resend that fails
+// leaves the message sent and returns domain.ErrDeliveryFailed instead.
+func (s *messageService) SendNow(ctx context.Context, messageID int64, force bool) (*domain.Message, error) {
+	txCtx := context.WithoutCancel(ctx)
+	hooks := &commitHooks{}
+
+	var resend bool
+	var sendErr error
+	err := s.repo.WithTx(txCtx, func(txRepo repo.MessageRepository) error {
+		message, err := txRepo.LockForSend(txCtx, messageID)
+		if err != nil {
+			return err
+		}
+
+		switch message.Status {
+		case domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusDeadLetter:
+		case domain.MessageStatusSent:
+			if !force {
+				return fmt.Errorf("message with ID %d cannot be sent again without force: %w", messageID, domain.ErrMessageAlreadySent)
+			}
+		default:
+			// A claimed message belongs to its worker until the lease runs
+			// out, and a cancelled or expired one was withdrawn on purpose
+			return fmt.Errorf("message with ID %d is %s: %w", messageID, message.Status, domain.ErrMessageNotSendable)
+		}
+
+		s.logger.Info("Sending message on demand",
+			"message_id", messageID,
+			"status", message.Status,
+			"force", force,
+		)
+
+		// Whatever processMessage managed to record commits, even when it
+		// reports an error
+		resend = message.Status == domain.MessageStatusSent
+		sendErr = s.processMessage(withCommitHooks(ctx, hooks), txRepo, message)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to send message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, err
+	}
+	hooks.run(txCtx)
+
+	var failed *deliveryFailedError
+	if sendErr != nil && (resend || !errors.As(sendErr, &failed)) {
+		return nil, fmt.Errorf("failed to send message: %w", sendErr)
+	}
+
+	updated, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return updated, nil
+}
+
+// RequeueMessage resets a failed or dead-lettered message back to pending with
+// a fresh retry budget
+func (s *messageService) RequeueMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	message, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		s.logger.Error("Failed to get message for requeue",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	switch message.Status {
+	case domain.MessageStatusFailed, domain.MessageStatusDeadLetter:
+	case domain.MessageStatusSent:
+		return nil, fmt.Errorf("message with ID %d cannot be requeued: %w", messageID, domain.ErrMessageAlreadySent)
+	default:
+		// A pending or sending message is still being delivered, and a
+		// cancelled or expired one was withdrawn on purpose
+		return nil, fmt.Errorf("message with ID %d is %s: %w", messageID, message.Status, domain.ErrMessageNotRequeueable)
+	}
+
+	if err := s.repo.Requeue(ctx, messageID); err != nil {
+		s.logger.Error("Failed to requeue message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to requeue message: %w", err)
+	}
+	s.invalidateCache(ctx, messageID)
+
+	previousStatus := message.Status
+	message.Status = domain.MessageStatusPending
+	message.RetryCount = 0
+	message.FailedAt = nil
+	message.ErrorMessage = nil
+	message.NextRetryAt = nil
+	message.UpdatedAt = time.Now()
+
+	s.logger.Info("Message requeued",
+		"message_id", messageID,
+		"previous_status", previousStatus,
+	)
+	s.publish(events.NewEvent(events.EventMessageRequeued, message))
+
+	return message, nil
+}
+
+// CancelMessage withdraws a message that has not been delivered. When its
+// webhook request is in flight on this instance the request is aborted; a
+// delivery that already completed is still recorded as sent.
+func (s *messageService) CancelMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	// Abort an in-flight delivery first: the scheduler keeps the message's row
+	// locked until its outcome commits, so the status update would otherwise wait
+	// for the delivery to finish and then find the message already sent
+	aborted := s.deliveries.cancel(messageID)
+
+	if err := s.repo.Cancel(ctx, messageID); err != nil {
+		s.logger.Error("Failed to cancel message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to cancel message: %w", err)
+	}
+	s.invalidateCache(ctx, messageID)
+
+	message, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	s.logger.Info("Message cancelled",
+		"message_id", messageID,
+		"delivery_aborted", aborted,
+	)
+	s.publish(events.NewEvent(events.EventMessageCancelled, message))
+
+	return message, nil
+}
+
+// UpdatePendingMessage edits a message that has not been picked up for
+// delivery yet. The edited message is validated as a whole, as on create, and
+// a message that is no longer pending returns domain.ErrMessageNotPending.
+func (s *messageService) UpdatePendingMessage(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error) {
+	if update.IsEmpty() {
+		return nil, domain.NewValidationError("at least one of recipient, content or webhook_url is required")
+	}
+
+	current, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if current.Status != domain.MessageStatusPending {
+		return nil, fmt.Errorf("message with ID %d is %s: %w", messageID, current.Status, domain.ErrMessageNotPending)
+	}
+
+	edited := &domain.CreateMessageRequest{
+		Recipient:       current.Recipient,
+		Content:         current.Content,
+		WebhookURL:      current.WebhookURL,
+		Priority:        current.Priority,
+		Channel:         current.Channel,
+		PayloadTemplate: current.PayloadTemplate,
+	}
+	if update.Recipient != nil {
+		edited.Recipient = *update.Recipient
+	}
+	if update.Content != nil {
+		edited.Content = *update.Content
+	}
+	if update.WebhookURL != nil {
+		edited.WebhookURL = *update.WebhookURL
+	}
+
+	validated, err := s.validateCreateRequest(edited)
+	if err != nil {
+		return nil, err
+	}
+	if update.Content != nil {
+		// Store the content as sanitized by validation
+		sanitized := *update
+		sanitized.Content = &validated.Content
+		update = &sanitized
+	}
+
+	message, err := s.repo.UpdatePending(ctx, messageID, update)
+	if err != nil {
+		s.logger.Error("Failed to update message",
+			"message_id", messageID,
+			"error", err,
+		)
+		return nil, fmt.Errorf("failed to update message: %w", err)
+	}
+	s.invalidateCache(ctx, messageID)
+
+	s.logger.Info("Pending message updated",
+		"message_id", messageID,
+		"recipient_changed", update.Recipient != nil,
+		"content_changed", update.Content != nil,
+		"webhook_url_changed", update.WebhookURL != nil,
+	)
 
-	// RetryFailedMessages retries failed messages that haven't exceeded max retries
-	RetryFailedMessages(ctx context.Context, batchSize int) (int, error)
+	return message, nil
 }
 
-// messageService implements MessageService
-type messageService struct {
-	repo          repo.MessageRepository
-	cache         *repo.RedisCacheRepository // Optional Redis cache
-	webhookClient WebhookClient              // Optional webhook client
-	logger        *slog.Logger
+// claimLease returns how long a claimed message stays reserved for its worker
+func (s *messageService) claimLease() time.Duration {
+	if s.config != nil && s.config.ClaimLease > 0 {
+		return s.config.ClaimLease
+	}
+	return 5 * time.Minute
 }
 
-// NewMessageService creates a new message service without cache or webhook client
-func NewMessageService(repo repo.MessageRepository, logger *slog.Logger) MessageService {
-	return &messageService{
-		repo:          repo,
-		cache:         nil,
-		webhookClient: nil,
-		logger:        logger,
+// ClaimMessages moves up to limit due messages to sending for an external worker.
+// Messages whose worker does not report back within the claim lease become
+// claimable again.
+func (s *messageService) ClaimMessages(ctx context.Context, limit int) ([]*domain.Message, error) {
+	messages, err := s.repo.ClaimPending(ctx, limit, s.claimLease())
+	if err != nil {
+		s.logger.Error("Failed to claim messages", "limit", limit, "error", err)
+		return nil, fmt.Errorf("failed to claim messages: %w", err)
+	}
+
+	if len(messages) > 0 {
+		s.logger.Info("Messages claimed by external worker", "count", len(messages))
 	}
+
+	return messages, nil
 }
 
-// NewMessageServiceWithCache creates a new message service with Redis cache
-func NewMessageServiceWithCache(repo repo.MessageRepository, cache *repo.RedisCacheRepository, logger *slog.Logger) MessageService {
-	return &messageService{
-		repo:          repo,
-		cache:         cache,
-		webhookClient: nil,
-		logger:        logger,
+// getClaimedMessage loads a message an external worker is reporting on,
+// returning domain.ErrMessageNotClaimed unless it is in the sending status
+func (s *messageService) getClaimedMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	message, err := s.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
+
+	if message.Status != domain.MessageStatusSending {
+		return nil, fmt.Errorf("message with ID %d is %s: %w", messageID, message.Status, domain.ErrMessageNotClaimed)
+	}
+
+	return message, nil
 }
 
-// NewMessageServiceWithWebhook creates a new message service with webhook client
-func NewMessageServiceWithWebhook(repo repo.MessageRepository, webhookClient WebhookClient, logger *slog.Logger) MessageService {
-	return &messageService{
-		repo:          repo,
-		cache:         nil,
-		webhookClient: webhookClient,
-		logger:        logger,
+// AckMessage marks a claimed message as sent
+func (s *messageService) AckMessage(ctx context.Context, messageID int64, providerMessageID string) (*domain.Message, error) {
+	message, err := s.getClaimedMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.markSent(ctx, s.repo, message, providerMessageID); err != nil {
+		return nil, err
 	}
+
+	s.logger.Info("Claimed message acknowledged", "message_id", messageID)
+	return s.GetMessage(ctx, messageID)
 }
 
-// NewMessageServiceWithCacheAndWebhook creates a new message service with both Redis cache and webhook client
-func NewMessageServiceWithCacheAndWebhook(repo repo.MessageRepository, cache *repo.RedisCacheRepository, webhookClient WebhookClient, logger *slog.Logger) MessageService {
-	return &messageService{
-		repo:          repo,
-		cache:         cache,
-		webhookClient: webhookClient,
-		logger:        logger,
+// NackMessage records a failed delivery of a claimed message. It follows the
+// same retry backoff and dead-lettering as a failed webhook delivery.
+func (s *messageService) NackMessage(ctx context.Context, messageID int64, errorMsg string) (*domain.Message, error) {
+	message, err := s.getClaimedMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.markFailed(ctx, s.repo, message, errorMsg); err != nil {
+		return nil, err
 	}
+
+	s.logger.Info("Claimed message rejected", "message_id", messageID, "error_message", errorMsg)
+	return s.GetMessage(ctx, messageID)
 }
 
-// CreateMessage creates a new message
-func (s *messageService) CreateMessage(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error) {
-	// Validate the request
-	if req.Recipient == "" {
-		return nil, fmt.Errorf("recipient is required")
+// RecordDeliveryStatus applies a delivery status reported by the provider. A
+// sent report marks the message sent; a failed report of a sent or in-flight
+// message fails it with the usual retry backoff and dead-lettering. Reports of
+// a status already recorded are no-ops, so providers may safely redeliver them.
+// Messages never handed to the provider return domain.ErrMessageNotSent. The
+// message is locked while the report is applied, so concurrent reports or a
+// processing run cannot act on a status that has since changed.
+func (s *messageService) RecordDeliveryStatus(ctx context.Context, messageID int64, status domain.MessageStatus, providerMessageID, errorMsg string) (*domain.Message, error) {
+	if status != domain.MessageStatusSent && status != domain.MessageStatusFailed {
+		return nil, domain.NewValidationError("status must be one of sent, failed")
 	}
-	if req.Content == "" {
-		return nil, fmt.Errorf("content is required")
+
+	txCtx := context.WithoutCancel(ctx)
+	hooks := &commitHooks{}
+
+	var unchanged *domain.Message
+	err := s.repo.WithTx(txCtx, func(txRepo repo.MessageRepository) error {
+		message, err := txRepo.LockForSend(txCtx, messageID)
+		if err != nil {
+			return err
+		}
+
+		switch message.Status {
+		case domain.MessageStatusSending, domain.MessageStatusSent, domain.MessageStatusFailed, domain.MessageStatusDeadLetter:
+		default:
+			return fmt.Errorf("message with ID %d is %s: %w", messageID, message.Status, domain.ErrMessageNotSent)
+		}
+
+		hookCtx := withCommitHooks(ctx, hooks)
+		switch {
+		case status == domain.MessageStatusSent && message.Status != domain.MessageStatusSent:
+			return s.markSent(hookCtx, txRepo, message, providerMessageID)
+		case status == domain.MessageStatusFailed && (message.Status == domain.MessageStatusSent || message.Status == domain.MessageStatusSending):
+			return s.markFailed(hookCtx, txRepo, message, errorMsg)
+		default:
+			unchanged = message
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
-	if req.WebhookURL == "" {
-		return nil, fmt.Errorf("webhook URL is required")
+	hooks.run(txCtx)
+
+	if unchanged != nil {
+		s.logger.Debug("Delivery status already recorded", "message_id", messageID, "status", status)
+		return unchanged, nil
 	}
 
-	s.logger.Info("Creating new message",
-		"recipient", req.Recipient,
-		"webhook_url", req.WebhookURL,
-		"max_retries", req.MaxRetries,
-	)
+	s.logger.Info("Delivery status recorded", "message_id", messageID, "status", status)
+	return s.GetMessage(ctx, messageID)
+}
 
-	message, err := s.repo.Create(ctx, req)
+// GetMessageCounts counts messages per status, including statuses with none
+func (s *messageService) GetMessageCounts(ctx context.Context) (map[domain.MessageStatus]int, error) {
+	counts, err := s.repo.CountByStatus(ctx)
 	if err != nil {
-		s.logger.Error("Failed to create message",
+		s.logger.Error("Failed to count messages by status", "error", err)
+		return nil, fmt.Errorf("failed to count messages by status: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetSuccessRate reports the ratio of sent to sent plus dead-lettered messages over
+// the given window. Results are cached briefly so status pages polling the
+// endpoint don't count the window on every request.
+func (s *messageService) GetSuccessRate(ctx context.Context, window time.Duration, byHost bool) (*domain.SuccessRate, error) {
+	cacheKey := fmt.Sprintf("%s:%t", window, byHost)
+
+	s.successRateMu.Lock()
+	cached, ok := s.successRateCache[cacheKey]
+	s.successRateMu.Unlock()
+	if ok && time.Since(cached.computedAt) < successRateCacheTTL {
+		return cached.rate, nil
+	}
+
+	now := time.Now()
+	outcomes, err := s.repo.CountDeliveryOutcomes(ctx, now.Add(-window))
+	if err != nil {
+		s.logger.Error("Failed to count delivery outcomes",
+			"window", window,
 			"error", err,
-			"recipient", req.Recipient,
 		)
-		return nil, fmt.Errorf("failed to create message: %w", err)
+		return nil, fmt.Errorf("failed to count delivery outcomes: %w", err)
 	}
 
-	s.logger.Info("Message created successfully",
-		"message_id", message.ID,
-		"recipient", message.Recipient,
-	)
+	rate := &domain.SuccessRate{Window: window.String()}
+	hosts := make(map[string]*domain.HostSuccessRate)
+	for _, outcome := range outcomes {
+		rate.Sent += outcome.Sent
+		rate.DeadLettered += outcome.DeadLettered
 
-	return message, nil
+		if !byHost {
+			continue
+		}
+
+		host := webhookHost(outcome.WebhookURL)
+		hostRate, exists := hosts[host]
+		if !exists {
+			hostRate = &domain.HostSuccessRate{Host: host}
+			hosts[host] = hostRate
+		}
+		hostRate.Sent += outcome.Sent
+		hostRate.DeadLettered += outcome.DeadLettered
+	}
+	rate.SuccessRate = domain.CalculateSuccessRate(rate.Sent, rate.DeadLettered)
+
+	if byHost {
+		rate.Hosts = make([]domain.HostSuccessRate, 0, len(hosts))
+		for _, hostRate := range hosts {
+			hostRate.SuccessRate = domain.CalculateSuccessRate(hostRate.Sent, hostRate.DeadLettered)
+			rate.Hosts = append(rate.Hosts, *hostRate)
+		}
+		sort.Slice(rate.Hosts, func(i, j int) bool {
+			return rate.Hosts[i].Host < rate.Hosts[j].Host
+		})
+	}
+
+	s.successRateMu.Lock()
+	s.successRateCache[cacheKey] = cachedSuccessRate{rate: rate, computedAt: now}
+	s.successRateMu.Unlock()
+
+	return rate, nil
 }
 
-// ProcessUnsentMessages processes unsent messages for delivery
-func (s *messageService) ProcessUnsentMessages(ctx context.Context, batchSize int) (int, error) {
-	s.logger.Info("Processing unsent messages", "batch_size", batchSize)
+// webhookHost extracts the host used for per-host breakdowns, falling back to
+// the raw URL when it cannot be parsed
+func webhookHost(webhookURL string) string {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Hostname() == "" {
+		return webhookURL
+	}
+	return parsed.Hostname()
+}
 
-	messages, err := s.repo.SelectUnsentForUpdate(ctx, batchSize)
+// PauseHost holds deliveries to a webhook host for duration, or until resumed when zero
+func (s *messageService) PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error) {
+	host, err := normalizeHost(host)
 	if err != nil {
-		s.logger.Error("Failed to select unsent messages", "error", err)
-		return 0, fmt.Errorf("failed to select unsent messages: %w", err)
+		return nil, err
+	}
+	if duration < 0 {
+		return nil, domain.NewValidationError("duration must be positive")
+	}
+
+	paused, err := s.hostPauses.PauseHost(ctx, host, duration)
+	if err != nil {
+		s.logger.Error("Failed to pause webhook host", "host", host, "error", err)
+		return nil, fmt.Errorf("failed to pause host: %w", err)
+	}
+
+	s.logger.Info("Paused webhook host", "host", host, "until", paused.Until)
+	return paused, nil
+}
+
+// ResumeHost lets deliveries to a paused webhook host continue
+func (s *messageService) ResumeHost(ctx context.Context, host string) error {
+	host, err := normalizeHost(host)
+	if err != nil {
+		return err
+	}
+
+	if err := s.hostPauses.ResumeHost(ctx, host); err != nil {
+		if errors.Is(err, domain.ErrHostNotPaused) {
+			return err
+		}
+		s.logger.Error("Failed to resume webhook host", "host", host, "error", err)
+		return fmt.Errorf("failed to resume host: %w", err)
+	}
+
+	s.logger.Info("Resumed webhook host", "host", host)
+	return nil
+}
+
+// GetPausedHosts lists the webhook hosts whose deliveries are on hold
+func (s *messageService) GetPausedHosts(ctx context.Context) ([]*domain.PausedHost, error) {
+	hosts, err := s.hostPauses.ListPausedHosts(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list paused webhook hosts", "error", err)
+		return nil, fmt.Errorf("failed to list paused hosts: %w", err)
+	}
+
+	return hosts, nil
+}
+
+// normalizeHost validates a webhook host given to pause or resume and returns it
+// in the lower-case form used for matching
+func normalizeHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return "", domain.NewValidationError("host is required")
+	}
+	if strings.ContainsAny(host, "/?#@ ") {
+		return "", domain.NewValidationError("host must be a hostname such as api.example.com, not a URL")
 	}
+	return host, nil
+}
 
+// skipPausedHosts drops messages addressed to paused webhook hosts, leaving them
+// untouched in the repository for a later run. If the pause list cannot be read
+// the batch is delivered as-is rather than stalling all deliveries.
+func (s *messageService) skipPausedHosts(ctx context.Context, messages []*domain.Message) []*domain.Message {
 	if len(messages) == 0 {
-		s.logger.Debug("No unsent messages found")
-		return 0, nil
+		return messages
+	}
+
+	paused, err := s.hostPauses.ListPausedHosts(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to read paused webhook hosts, delivering batch", "error", err)
+		return messages
+	}
+	if len(paused) == 0 {
+		return messages
+	}
+
+	pausedHosts := make(map[string]bool, len(paused))
+	for _, p := range paused {
+		pausedHosts[p.Host] = true
 	}
 
-	processed := 0
+	deliverable := make([]*domain.Message, 0, len(messages))
 	for _, message := range messages {
-		if err := s.processMessage(ctx, message); err != nil {
-			s.logger.Error("Failed to process message",
+		if pausedHosts[strings.ToLower(webhookHost(message.WebhookURL))] {
+			s.logger.Debug("Skipping message for paused webhook host",
 				"message_id", message.ID,
-				"error", err,
+				"webhook_url", message.WebhookURL,
 			)
-			// Continue processing other messages even if one fails
 			continue
 		}
-		processed++
+		deliverable = append(deliverable, message)
 	}
 
-	s.logger.Info("Processed unsent messages",
-		"total_found", len(messages),
-		"successfully_processed", processed,
-	)
+	if skipped := len(messages) - len(deliverable); skipped > 0 {
+		s.logger.Info("Skipped messages for paused webhook hosts", "skipped", skipped)
+	}
 
-	return processed, nil
+	return deliverable
 }
 
-// processMessage processes a single message
-func (s *messageService) processMessage(ctx context.Context, message *domain.Message) error {
-	s.logger.Debug("Processing message",
-		"message_id", message.ID,
-		"recipient", message.Recipient,
-		"retry_count", message.RetryCount,
-	)
+// parseSuppressionKey works out whether key names a recipient, an email address
+// or E.164 phone number, or a webhook host, and normalizes it
+func parseSuppressionKey(key string) (kind, normalized string, err error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	switch {
+	case key == "":
+		return "", "", domain.NewValidationError("key is required")
+	case strings.Contains(key, "@"):
+		if !isValidEmail(key) {
+			return "", "", domain.NewValidationError("key must be an email address, a phone number in E.164 format or a webhook host")
+		}
+		return domain.SuppressionRecipient, key, nil
+	case strings.HasPrefix(key, "+"):
+		if !isValidPhoneNumber(key) {
+			return "", "", domain.NewValidationError("key must be an email address, a phone number in E.164 format or a webhook host")
+		}
+		return domain.SuppressionRecipient, key, nil
+	}
 
-	// Use webhook client if available, otherwise skip webhook delivery
-	if s.webhookClient != nil {
-		if err := s.webhookClient.SendMessage(ctx, message); err != nil {
-			s.logger.Error("Failed to send webhook",
-				"message_id", message.ID,
-				"webhook_url", message.WebhookURL,
-				"error", err,
-			)
+	host, err := normalizeHost(key)
+	if err != nil {
+		return "", "", err
+	}
+	return domain.SuppressionHost, host, nil
+}
 
-			// Mark message as failed
-			if markErr := s.repo.MarkFailed(ctx, message.ID, err.Error()); markErr != nil {
-				s.logger.Error("Failed to mark message as failed",
-					"message_id", message.ID,
-					"error", markErr,
-				)
-				return fmt.Errorf("failed to mark message as failed: %w", markErr)
-			}
-			return fmt.Errorf("webhook delivery failed: %w", err)
-		}
-	} else {
-		s.logger.Debug("No webhook client configured, skipping webhook delivery",
-			"message_id", message.ID,
-		)
+// suppressionFromPausedHost presents a host pause as a suppression
+func suppressionFromPausedHost(paused *domain.PausedHost) *domain.Suppression {
+	return &domain.Suppression{
+		Key:       paused.Host,
+		Kind:      domain.SuppressionHost,
+		CreatedAt: paused.PausedAt,
+		Until:     paused.Until,
 	}
+}
 
-	// Mark message as sent
-	if err := s.repo.MarkSent(ctx, message.ID); err != nil {
-		return fmt.Errorf("failed to mark message as sent: %w", err)
+// Suppress holds deliveries to a recipient or webhook host. A host is paused
+// as PauseHost does, so either API can lift it.
+func (s *messageService) Suppress(ctx context.Context, key string, duration time.Duration) (*domain.Suppression, error) {
+	kind, key, err := parseSuppressionKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if duration < 0 {
+		return nil, domain.NewValidationError("duration must be positive")
 	}
 
-	// Cache message metadata if Redis cache is available
-	if s.cache != nil {
-		metadata := &repo.MessageMetadata{
-			ID:         int(message.ID),
-			Recipient:  message.Recipient,
-			Status:     "sent",
-			SentAt:     time.Now(),
-			RetryCount: message.RetryCount,
-			MaxRetries: message.MaxRetries,
-			WebhookURL: message.WebhookURL,
+	if kind == domain.SuppressionHost {
+		paused, err := s.PauseHost(ctx, key, duration)
+		if err != nil {
+			return nil, err
 		}
+		return suppressionFromPausedHost(paused), nil
+	}
 
-		if err := s.cache.CacheMessageMetadata(ctx, metadata); err != nil {
-			// Log error but don't fail the operation
-			s.logger.Warn("Failed to cache message metadata",
-				"message_id", message.ID,
-				"error", err,
-			)
+	suppression, err := s.suppressions.SuppressRecipient(ctx, key, duration)
+	if err != nil {
+		s.logger.Error("Failed to suppress recipient", "recipient", key, "error", err)
+		return nil, fmt.Errorf("failed to suppress recipient: %w", err)
+	}
+
+	s.logger.Info("Suppressed recipient", "recipient", key, "until", suppression.Until)
+	return suppression, nil
+}
+
+// Unsuppress removes the suppression of a recipient or webhook host, returning
+// domain.ErrNotSuppressed if it has none
+func (s *messageService) Unsuppress(ctx context.Context, key string) error {
+	kind, key, err := parseSuppressionKey(key)
+	if err != nil {
+		return err
+	}
+
+	if kind == domain.SuppressionHost {
+		if err := s.ResumeHost(ctx, key); err != nil {
+			if errors.Is(err, domain.ErrHostNotPaused) {
+				return domain.ErrNotSuppressed
+			}
+			return err
 		}
+		return nil
 	}
 
-	s.logger.Info("Message processed successfully",
-		"message_id", message.ID,
-		"recipient", message.Recipient,
-	)
+	if err := s.suppressions.UnsuppressRecipient(ctx, key); err != nil {
+		if errors.Is(err, domain.ErrNotSuppressed) {
+			return err
+		}
+		s.logger.Error("Failed to unsuppress recipient", "recipient", key, "error", err)
+		return fmt.Errorf("failed to unsuppress recipient: %w", err)
+	}
 
+	s.logger.Info("Unsuppressed recipient", "recipient", key)
 	return nil
 }
 
-// GetMessage retrieves a message by ID
-func (s *messageService) GetMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
-	s.logger.Debug("Getting message", "message_id", messageID)
+// GetSuppressions returns the suppressed recipients followed by the paused
+// webhook hosts, each ordered by key
+func (s *messageService) GetSuppressions(ctx context.Context) ([]*domain.Suppression, error) {
+	suppressions, err := s.suppressions.ListSuppressedRecipients(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list suppressed recipients", "error", err)
+		return nil, fmt.Errorf("failed to list suppressions: %w", err)
+	}
 
-	message, err := s.repo.GetByID(ctx, messageID)
+	hosts, err := s.GetPausedHosts(ctx)
 	if err != nil {
-		s.logger.Error("Failed to get message",
-			"message_id", messageID,
-			"error", err,
-		)
-		return nil, fmt.Errorf("failed to get message: %w", err)
+		return nil, err
+	}
+	for _, paused := range hosts {
+		suppressions = append(suppressions, suppressionFromPausedHost(paused))
 	}
 
-	return message, nil
+	return suppressions, nil
 }
 
-// GetSentMessages retrieves sent messages with pagination
-func (s *messageService) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
-	s.logger.Debug("Getting sent messages",
-		"offset", offset,
-		"limit", limit,
-	)
+// skipSuppressedRecipients drops messages addressed to suppressed recipients,
+// leaving them untouched in the repository for a later run. Like
+// skipPausedHosts it delivers the batch as-is if the list cannot be read.
+func (s *messageService) skipSuppressedRecipients(ctx context.Context, messages []*domain.Message) []*domain.Message {
+	if len(messages) == 0 {
+		return messages
+	}
 
-	messages, total, err := s.repo.GetSentMessages(ctx, offset, limit)
+	suppressed, err := s.suppressions.ListSuppressedRecipients(ctx)
 	if err != nil {
-		s.logger.Error("Failed to get sent messages",
-			"offset", offset,
-			"limit", limit,
-			"error", err,
-		)
-		return nil, 0, fmt.Errorf("failed to get sent messages: %w", err)
+		s.logger.Warn("Failed to read suppressed recipients, delivering batch", "error", err)
+		return messages
+	}
+	if len(suppressed) == 0 {
+		return messages
 	}
 
-	s.logger.Debug("Retrieved sent messages",
-		"count", len(messages),
-		"total", total,
-	)
+	suppressedRecipients := make(map[string]bool, len(suppressed))
+	for _, suppression := range suppressed {
+		suppressedRecipients[suppression.Key] = true
+	}
 
-	return messages, total, nil
+	deliverable := make([]*domain.Message, 0, len(messages))
+	for _, message := range messages {
+		if suppressedRecipients[strings.ToLower(message.Recipient)] {
+			s.logger.Debug("Skipping message for suppressed recipient",
+				"message_id", message.ID,
+				"recipient", message.Recipient,
+			)
+			continue
+		}
+		deliverable = append(deliverable, message)
+	}
+
+	if skipped := len(messages) - len(deliverable); skipped > 0 {
+		s.logger.Info("Skipped messages for suppressed recipients", "skipped", skipped)
+	}
+
+	return deliverable
 }
 
-// RetryFailedMessages retries failed messages that haven't exceeded max retries
+// RetryFailedMessages retries failed messages that haven't exceeded max retries.
+// Each one is locked before it is delivered, like a processing run does, so a
+// message that run also selected is sent only once.
 func (s *messageService) RetryFailedMessages(ctx context.Context, batchSize int) (int, error) {
 	s.logger.Info("Retrying failed messages", "batch_size", batchSize)
 
@@ -269,6 +2625,8 @@ func (s *messageService) RetryFailedMessages(ctx context.Context, batchSize int)
 		return 0, fmt.Errorf("failed to get failed messages: %w", err)
 	}
 
+	messages = s.skipPausedHosts(ctx, messages)
+	messages = s.skipSuppressedRecipients(ctx, messages)
 	if len(messages) == 0 {
 		s.logger.Debug("No failed messages found for retry")
 		return 0, nil
@@ -282,24 +2640,29 @@ func (s *messageService) RetryFailedMessages(ctx context.Context, batchSize int)
 				"retry_count", message.RetryCount,
 				"max_retries", message.MaxRetries,
 			)
+			if message.Status == domain.MessageStatusFailed {
+				// markDeadLetter logs its own errors; keep going with the batch
+				_ = s.markDeadLetter(ctx, s.repo, message)
+			}
 			continue
 		}
 
-		if err := s.processMessage(ctx, message); err != nil {
+		// processLocked records the failure (and dead-letters the message when
+		// this was its last retry), so there is nothing left to mark here
+		err := s.processLocked(ctx, message)
+		switch {
+		case errors.Is(err, domain.ErrMessageNotFound):
+			s.logger.Debug("Message no longer due for retry, skipping",
+				"message_id", message.ID,
+			)
+		case err != nil:
 			s.logger.Error("Failed to retry message",
 				"message_id", message.ID,
 				"error", err,
 			)
-			// Mark as failed again with the new error
-			if markErr := s.repo.MarkFailed(ctx, message.ID, err.Error()); markErr != nil {
-				s.logger.Error("Failed to mark message as failed",
-					"message_id", message.ID,
-					"error", markErr,
-				)
-			}
-			continue
+		default:
+			retried++
 		}
-		retried++
 	}
 
 	s.logger.Info("Retried failed messages",
@@ -310,20 +2673,80 @@ func (s *messageService) RetryFailedMessages(ctx context.Context, batchSize int)
 	return retried, nil
 }
 
+// ArchiveOldMessages moves messages sent longer ago than the configured
+// ArchiveAfter to the archive. Without configuration nothing is archived.
+func (s *messageService) ArchiveOldMessages(ctx context.Context) (int, error) {
+	if s.config == nil || s.config.ArchiveAfter <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.config.ArchiveAfter)
+	archived, err := s.repo.ArchiveOlderThan(ctx, cutoff)
+	if err != nil {
+		s.logger.Error("Failed to archive messages",
+			"cutoff", cutoff,
+			"archived", archived,
+			"error", err,
+		)
+		return archived, fmt.Errorf("failed to archive messages: %w", err)
+	}
+
+	if archived > 0 {
+		s.logger.Info("Archived sent messages", "cutoff", cutoff, "archived", archived)
+	}
+
+	return archived, nil
+}
+
+// retainedStatuses are the final statuses whose messages the retention run
+// deletes once they are older than MessageRetention
+var retainedStatuses = []domain.MessageStatus{domain.MessageStatusSent, domain.MessageStatusDeadLetter}
+
+// PurgeOldMessages deletes sent and dead-lettered messages older than the
+// configured MessageRetention. Without configuration nothing is deleted.
+func (s *messageService) PurgeOldMessages(ctx context.Context) (int, error) {
+	if s.config == nil || s.config.MessageRetention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-s.config.MessageRetention)
+	total := 0
+	for _, status := range retainedStatuses {
+		deleted, err := s.repo.DeleteOlderThan(ctx, status, cutoff)
+		total += deleted
+		if err != nil {
+			s.logger.Error("Failed to delete old messages",
+				"status", status,
+				"cutoff", cutoff,
+				"deleted", total,
+				"error", err,
+			)
+			return total, fmt.Errorf("failed to delete old %s messages: %w", status, err)
+		}
+		if deleted > 0 {
+			s.logger.Info("Deleted old messages", "status", status, "cutoff", cutoff, "deleted", deleted)
+		}
+	}
+
+	return total, nil
+}
+
 // ProcessPendingMessages processes pending messages (scheduler compatibility method)
 func (s *messageService) ProcessPendingMessages(ctx context.Context) error {
-	// Use a default batch size for scheduler processing
-	const defaultBatchSize = 10
-
-	_, err := s.ProcessUnsentMessages(ctx, defaultBatchSize)
+	_, err := s.ProcessUnsentMessages(ctx, s.schedulerBatchSize())
 	return err
 }
 
 // RetryFailedMessagesForScheduler retries failed messages (scheduler compatibility method)
 func (s *messageService) RetryFailedMessagesForScheduler(ctx context.Context) error {
-	// Use a default batch size for scheduler processing
-	const defaultBatchSize = 10
-
-	_, err := s.RetryFailedMessages(ctx, defaultBatchSize)
+	_, err := s.RetryFailedMessages(ctx, s.schedulerBatchSize())
 	return err
 }
+
+// schedulerBatchSize returns the configured batch size for scheduler runs
+func (s *messageService) schedulerBatchSize() int {
+	if s.config != nil && s.config.BatchSize > 0 {
+		return s.config.BatchSize
+	}
+	return defaultSchedulerBatchSize
+}
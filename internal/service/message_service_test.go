@@ -2,13 +2,29 @@ package service
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/events"
+	"github.com/insider/insider-messaging/internal/repo"
+	"github.com/insider/insider-messaging/pkg/config"
+	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -27,6 +43,22 @@ func (m *MockMessageRepository) Create(ctx context.Context, req *domain.CreateMe
 	return args.Get(0).(*domain.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) GetByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CreateBatch(ctx context.Context, reqs []*domain.CreateMessageRequest) ([]*domain.Message, error) {
+	args := m.Called(ctx, reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
 func (m *MockMessageRepository) SelectUnsentForUpdate(ctx context.Context, limit int) ([]*domain.Message, error) {
 	args := m.Called(ctx, limit)
 	if args.Get(0) == nil {
@@ -35,13 +67,52 @@ func (m *MockMessageRepository) SelectUnsentForUpdate(ctx context.Context, limit
 	return args.Get(0).([]*domain.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) LockUnsent(ctx context.Context, messageID int64) (*domain.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetByIDs(ctx context.Context, messageIDs []int64) ([]*domain.Message, error) {
+	args := m.Called(ctx, messageIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountWithStatus(ctx context.Context, status domain.MessageStatus) (int, error) {
+	args := m.Called(ctx, status)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) LockForSend(ctx context.Context, messageID int64) (*domain.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkResent(ctx context.Context, messageID int64, providerMessageID string) error {
+	args := m.Called(ctx, messageID, providerMessageID)
+	return args.Error(0)
+}
+
 func (m *MockMessageRepository) MarkSent(ctx context.Context, messageID int64) error {
 	args := m.Called(ctx, messageID)
 	return args.Error(0)
 }
 
-func (m *MockMessageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string) error {
-	args := m.Called(ctx, messageID, errorMsg)
+func (m *MockMessageRepository) MarkSentWithReference(ctx context.Context, messageID int64, providerMessageID string) error {
+	args := m.Called(ctx, messageID, providerMessageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) MarkFailed(ctx context.Context, messageID int64, errorMsg string, retryDelay time.Duration) error {
+	args := m.Called(ctx, messageID, errorMsg, retryDelay)
 	return args.Error(0)
 }
 
@@ -53,8 +124,40 @@ func (m *MockMessageRepository) GetByID(ctx context.Context, messageID int64) (*
 	return args.Get(0).(*domain.Message), args.Error(1)
 }
 
-func (m *MockMessageRepository) GetSentMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
-	args := m.Called(ctx, offset, limit)
+func (m *MockMessageRepository) ListMessages(ctx context.Context, status domain.MessageStatus, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) ListAfterCursor(ctx context.Context, status domain.MessageStatus, cursorID int64, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, status, cursorID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByDateRange(ctx context.Context, from, to time.Time, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, from, to, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) GetByRecipient(ctx context.Context, recipient string, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, recipient, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) GetSentMessages(ctx context.Context, offset, limit int, order domain.MessageSort) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit, order)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
@@ -69,6 +172,133 @@ func (m *MockMessageRepository) GetFailedMessages(ctx context.Context, limit int
 	return args.Get(0).([]*domain.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) MarkDeadLetter(ctx context.Context, messageID int64) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetFailedMessagesPaginated(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) GetDeadLetterMessages(ctx context.Context, offset, limit int) ([]*domain.Message, int, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Message), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) Requeue(ctx context.Context, messageID int64) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) Cancel(ctx context.Context, messageID int64) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ExpireOverdue(ctx context.Context) ([]*domain.Message, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) UpdatePending(ctx context.Context, messageID int64, update *domain.UpdateMessageRequest) (*domain.Message, error) {
+	args := m.Called(ctx, messageID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountByStatus(ctx context.Context) (map[domain.MessageStatus]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.MessageStatus]int), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountDeliveryOutcomes(ctx context.Context, since time.Time) ([]*domain.DeliveryOutcome, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.DeliveryOutcome), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetRecentMessages(ctx context.Context, since time.Duration, limit int) ([]*domain.Message, error) {
+	args := m.Called(ctx, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.Message, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountByRecipientSince(ctx context.Context, recipient string, since time.Time) (int, error) {
+	args := m.Called(ctx, recipient, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) RecordAttempt(ctx context.Context, messageID int64, statusCode int, durationMs int64, attemptErr error) error {
+	args := m.Called(ctx, messageID, statusCode, durationMs, attemptErr)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ListAttempts(ctx context.Context, messageID int64) ([]*domain.WebhookAttempt, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).([]*domain.WebhookAttempt), args.Error(1)
+}
+
+func (m *MockMessageRepository) ArchiveOlderThan(ctx context.Context, cutoff time.Time) (int, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) DeleteOlderThan(ctx context.Context, status domain.MessageStatus, cutoff time.Time) (int, error) {
+	args := m.Called(ctx, status, cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+// WithTx runs fn against the mock itself; transactions are not modelled
+func (m *MockMessageRepository) WithTx(ctx context.Context, fn func(txRepo repo.MessageRepository) error) error {
+	return fn(m)
+}
+
+// expectLocked makes LockUnsent hand each message back, as it does for
+// messages no other instance holds
+func expectLocked(mockRepo *MockMessageRepository, messages ...*domain.Message) {
+	for _, message := range messages {
+		mockRepo.On("LockUnsent", mock.Anything, message.ID).Return(message, nil)
+	}
+}
+
+// MockWebhookClient is a mock implementation of WebhookClient
+type MockWebhookClient struct {
+	mock.Mock
+}
+
+func (m *MockWebhookClient) SendMessage(ctx context.Context, message *domain.Message) (string, error) {
+	args := m.Called(ctx, message)
+	return args.String(0), args.Error(1)
+}
+
 func TestMessageService_CreateMessage(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
@@ -103,6 +333,20 @@ func TestMessageService_CreateMessage(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("http webhook URL with port", func(t *testing.T) {
+		req := &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "http://localhost:8081/webhook",
+		}
+
+		mockRepo.On("Create", ctx, req).Return(&domain.Message{ID: 2, WebhookURL: req.WebhookURL}, nil).Once()
+
+		message, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, req.WebhookURL, message.WebhookURL)
+	})
+
 	t.Run("validation errors", func(t *testing.T) {
 		testCases := []struct {
 			name string
@@ -133,6 +377,89 @@ func TestMessageService_CreateMessage(t *testing.T) {
 				},
 				err: "webhook URL is required",
 			},
+			{
+				name: "recipient without domain",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "not-an-email",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+				},
+				err: "recipient must be a valid email address",
+			},
+			{
+				name: "recipient with multiple @",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "a@b@c",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+				},
+				err: "recipient must be a valid email address",
+			},
+			{
+				name: "ftp webhook URL",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "ftp://x",
+				},
+				err: "webhook URL must be a valid http(s) URL",
+			},
+			{
+				name: "javascript webhook URL",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "javascript:alert(1)",
+				},
+				err: "webhook URL must be a valid http(s) URL",
+			},
+			{
+				name: "relative webhook URL",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "/webhook",
+				},
+				err: "webhook URL must be a valid http(s) URL",
+			},
+			{
+				name: "webhook URL without host",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "https://",
+				},
+				err: "webhook URL must be a valid http(s) URL",
+			},
+			{
+				name: "negative priority",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+					Priority:   -1,
+				},
+				err: "priority must be between 0 and 10",
+			},
+			{
+				name: "priority above maximum",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "test@example.com",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+					Priority:   11,
+				},
+				err: "priority must be between 0 and 10",
+			},
+			{
+				name: "recipient with display name",
+				req: &domain.CreateMessageRequest{
+					Recipient:  "Jane <jane@example.com>",
+					Content:    "Test message",
+					WebhookURL: "https://example.com/webhook",
+				},
+				err: "recipient must be a valid email address",
+			},
 		}
 
 		for _, tc := range testCases {
@@ -141,10 +468,32 @@ func TestMessageService_CreateMessage(t *testing.T) {
 				require.Error(t, err)
 				assert.Nil(t, message)
 				assert.Contains(t, err.Error(), tc.err)
+
+				var validationErr *domain.ValidationError
+				assert.ErrorAs(t, err, &validationErr)
 			})
 		}
 	})
 
+	t.Run("reports every invalid field", func(t *testing.T) {
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "not-an-email",
+			WebhookURL: "ftp://x",
+			Priority:   11,
+		})
+		require.Error(t, err)
+		assert.Nil(t, message)
+
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldError{
+			{Field: "recipient", Message: "recipient must be a valid email address"},
+			{Field: "content", Message: "content is required"},
+			{Field: "webhook_url", Message: "webhook URL must be a valid http(s) URL"},
+			{Field: "priority", Message: "priority must be between 0 and 10"},
+		}, validationErr.Fields)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		req := &domain.CreateMessageRequest{
 			Recipient:  "test@example.com",
@@ -188,9 +537,11 @@ func TestMessageService_ProcessUnsentMessages(t *testing.T) {
 			},
 		}
 
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
 		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(messages, nil)
-		mockRepo.On("MarkSent", ctx, int64(1)).Return(nil)
-		mockRepo.On("MarkSent", ctx, int64(2)).Return(nil)
+		expectLocked(mockRepo, messages...)
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil)
+		mockRepo.On("MarkSent", mock.Anything, int64(2)).Return(nil)
 
 		processed, err := service.ProcessUnsentMessages(ctx, 10)
 		require.NoError(t, err)
@@ -199,39 +550,383 @@ func TestMessageService_ProcessUnsentMessages(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("no messages found", func(t *testing.T) {
+	t.Run("cancellation skips the rest of the batch", func(t *testing.T) {
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
 
-		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{}, nil)
+		var messages []*domain.Message
+		for id := int64(1); id <= 3; id++ {
+			messages = append(messages, &domain.Message{
+				ID:         id,
+				WebhookURL: "https://example.com/webhook",
+				Status:     domain.MessageStatusPending,
+				MaxRetries: 3,
+			})
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(messages, nil)
+		expectLocked(mockRepo, messages[0])
+		// The shutdown lands while the first message is being delivered
+		mockWebhook.On("SendMessage", mock.Anything, messages[0]).
+			Run(func(mock.Arguments) { cancel() }).
+			Return("", nil).Once()
+		// The message delivered before the shutdown is still recorded as sent
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil).Once()
 
 		processed, err := service.ProcessUnsentMessages(ctx, 10)
-		require.NoError(t, err)
-		assert.Equal(t, 0, processed)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, processed)
 
 		mockRepo.AssertExpectations(t)
+
+		mockWebhook.AssertNumberOfCalls(t, "SendMessage", 1)
+		mockRepo.AssertNotCalled(t, "MarkSent", mock.Anything, int64(2))
+		mockRepo.AssertNotCalled(t, "MarkSent", mock.Anything, int64(3))
 	})
 
-	t.Run("repository error", func(t *testing.T) {
+	t.Run("processes batch concurrently with bounded workers", func(t *testing.T) {
+		const poolSize = 3
+
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger,
+			WithConfig(&config.Config{WorkerPoolSize: poolSize}))
 
-		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(nil, errors.New("database error"))
+		var messages []*domain.Message
+		for id := int64(1); id <= 6; id++ {
+			messages = append(messages, &domain.Message{
+				ID:         id,
+				WebhookURL: "https://example.com/webhook",
+				Status:     domain.MessageStatusPending,
+				MaxRetries: 3,
+			})
+		}
+
+		// Every request blocks until poolSize requests are in flight at once, which
+		// can only happen if the batch is being processed concurrently
+		var inFlight, maxInFlight int64
+		allInFlight := make(chan struct{})
+		var once sync.Once
+		block := func(mock.Arguments) {
+			current := atomic.AddInt64(&inFlight, 1)
+			defer atomic.AddInt64(&inFlight, -1)
+			for {
+				observed := atomic.LoadInt64(&maxInFlight)
+				if current <= observed || atomic.CompareAndSwapInt64(&maxInFlight, observed, current) {
+					break
+				}
+			}
+			if current == poolSize {
+				once.Do(func() { close(allInFlight) })
+			}
+			select {
+			case <-allInFlight:
+			case <-time.After(2 * time.Second):
+			}
+		}
+
+		isFailing := func(m *domain.Message) bool { return m.ID == 2 }
+		mockWebhook.On("SendMessage", mock.Anything, mock.MatchedBy(isFailing)).Run(block).Return("", errors.New("webhook returned 500"))
+		mockWebhook.On("SendMessage", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool { return !isFailing(m) })).Run(block).Return("", nil)
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(messages, nil)
+		expectLocked(mockRepo, messages...)
+		mockRepo.On("MarkFailed", mock.Anything, int64(2), "webhook returned 500", mock.Anything).Return(nil)
+		for _, id := range []int64{1, 3, 4, 5, 6} {
+			mockRepo.On("MarkSent", mock.Anything, id).Return(nil)
+		}
 
 		processed, err := service.ProcessUnsentMessages(ctx, 10)
-		require.Error(t, err)
-		assert.Equal(t, 0, processed)
-		assert.Contains(t, err.Error(), "failed to select unsent messages")
+		require.NoError(t, err)
+		assert.Equal(t, 5, processed)
+		assert.Equal(t, int64(poolSize), atomic.LoadInt64(&maxInFlight))
 
 		mockRepo.AssertExpectations(t)
+		mockWebhook.AssertNumberOfCalls(t, "SendMessage", 6)
 	})
-}
 
-func TestMessageService_GetMessage(t *testing.T) {
-	mockRepo := new(MockMessageRepository)
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
-	service := NewMessageService(mockRepo, logger)
-	ctx := context.Background()
+	t.Run("records worker wait under saturation", func(t *testing.T) {
+		const delivery = 20 * time.Millisecond
+
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		registry := prometheus.NewRegistry()
+		m := metrics.NewWithRegistry(registry)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger,
+			WithConfig(&config.Config{WorkerPoolSize: 1}), WithMetrics(m))
+
+		var messages []*domain.Message
+		for id := int64(1); id <= 3; id++ {
+			messages = append(messages, &domain.Message{
+				ID:         id,
+				WebhookURL: "https://example.com/webhook",
+				Status:     domain.MessageStatusPending,
+				MaxRetries: 3,
+			})
+			mockRepo.On("MarkSent", mock.Anything, id).Return(nil)
+		}
+
+		// A single worker with slow deliveries leaves the later messages queued
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(messages, nil)
+		expectLocked(mockRepo, messages...)
+		mockWebhook.On("SendMessage", mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) { time.Sleep(delivery) }).
+			Return("", nil)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, processed)
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		var sampleCount uint64
+		var sampleSum float64
+		for _, family := range families {
+			if family.GetName() == "insider_messaging_worker_wait_seconds" {
+				histogram := family.GetMetric()[0].GetHistogram()
+				sampleCount = histogram.GetSampleCount()
+				sampleSum = histogram.GetSampleSum()
+			}
+		}
+		assert.Equal(t, uint64(3), sampleCount)
+		// The second message waits for one delivery and the third for two
+		assert.GreaterOrEqual(t, sampleSum, (3 * delivery).Seconds())
+	})
+
+	t.Run("no messages found", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{}, nil)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return(nil, errors.New("database error"))
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.Error(t, err)
+		assert.Equal(t, 0, processed)
+		assert.Contains(t, err.Error(), "failed to select unsent messages")
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// sqlmockMessageColumns are the columns the PostgreSQL repository scans a
+// message from, in order
+var sqlmockMessageColumns = []string{
+	"id", "recipient", "content", "webhook_url", "status", "retry_count",
+	"max_retries", "created_at", "updated_at", "sent_at", "failed_at", "error_message",
+	"next_retry_at", "provider_message_id", "priority", "resent_from", "idempotency_key",
+	"channel", "payload_template", "expires_at",
+}
+
+// sqlmockPendingRow is the row of a pending webhook message with the given ID
+func sqlmockPendingRow(id int64) []driver.Value {
+	now := time.Now()
+	return []driver.Value{
+		id, "test@example.com", "Hello", "https://example.com/webhook", domain.MessageStatusPending, 0,
+		3, now, now, nil, nil, nil,
+		nil, nil, 0, nil, nil,
+		string(domain.ChannelWebhook), "", nil,
+	}
+}
+
+// expectSelectUnsent expects a processing run to expire nothing and select
+// the messages with the given IDs
+func expectSelectUnsent(sqlMock sqlmock.Sqlmock, ids ...int64) {
+	sqlMock.ExpectQuery(`UPDATE messages SET status = .+ RETURNING`).
+		WillReturnRows(sqlmock.NewRows(sqlmockMessageColumns))
+	rows := sqlmock.NewRows(sqlmockMessageColumns)
+	for _, id := range ids {
+		rows.AddRow(sqlmockPendingRow(id)...)
+	}
+	sqlMock.ExpectQuery(`SELECT .+ FOR UPDATE SKIP LOCKED`).
+		WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, 10).
+		WillReturnRows(rows)
+}
+
+// expectLockUnsent expects the message's transaction to begin and lock it
+func expectLockUnsent(sqlMock sqlmock.Sqlmock, id int64) {
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`SELECT .+ WHERE id = \$3 .+ FOR UPDATE SKIP LOCKED`).
+		WithArgs(domain.MessageStatusPending, domain.MessageStatusFailed, id).
+		WillReturnRows(sqlmock.NewRows(sqlmockMessageColumns).AddRow(sqlmockPendingRow(id)...))
+}
+
+// markSentQuery matches the status update MarkSent runs
+const markSentQuery = `UPDATE messages SET status = .+, sent_at = NOW\(\)`
+
+func TestMessageService_ProcessUnsentMessages_CancelledRunKeepsDeliveries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mockWebhook := new(MockWebhookClient)
+	service := NewMessageServiceWithWebhook(repo.NewMessageRepository(db), mockWebhook, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expectSelectUnsent(sqlMock, 1, 2)
+	expectLockUnsent(sqlMock, 1)
+	sqlMock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(markSentQuery).
+		WithArgs(domain.MessageStatusSent, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`RELEASE SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	// The run is stopped while the first message is being delivered
+	mockWebhook.On("SendMessage", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return("", nil).Once()
+
+	processed, err := service.ProcessUnsentMessages(ctx, 10)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, processed)
+
+	// The delivered message's status update committed rather than rolling
+	// back with the cancelled run, and the second message was never touched
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	mockWebhook.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestMessageService_ProcessUnsentMessages_RetriesMarkInTransaction(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mockWebhook := new(MockWebhookClient)
+	service := NewMessageServiceWithWebhook(repo.NewMessageRepository(db), mockWebhook, logger,
+		WithConfig(&config.Config{MarkRetryAttempts: 2, MarkRetryBackoff: time.Millisecond}))
+
+	expectSelectUnsent(sqlMock, 1)
+	expectLockUnsent(sqlMock, 1)
+	// The first attempt fails, aborting the transaction up to its savepoint;
+	// rolling back to it lets the retry run in the same transaction
+	sqlMock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(markSentQuery).
+		WithArgs(domain.MessageStatusSent, int64(1)).
+		WillReturnError(errors.New("deadlock detected"))
+	sqlMock.ExpectExec(`ROLLBACK TO SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectExec(markSentQuery).
+		WithArgs(domain.MessageStatusSent, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectExec(`RELEASE SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	mockWebhook.On("SendMessage", mock.Anything, mock.Anything).Return("", nil).Once()
+
+	processed, err := service.ProcessUnsentMessages(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestMessageService_ProcessUnsentMessages_SideEffectsAfterCommit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name      string
+		sendErr   error
+		commitErr error
+		wantEvent events.EventType
+	}{
+		{name: "committed delivery", wantEvent: events.EventMessageSent},
+		{name: "delivery whose commit failed", commitErr: errors.New("connection reset")},
+		{name: "committed failure", sendErr: errors.New("connection refused"), wantEvent: events.EventMessageFailed},
+		{name: "failure whose commit failed", sendErr: errors.New("connection refused"), commitErr: errors.New("connection reset")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, sqlMock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			server := miniredis.RunT(t)
+			cache, err := repo.NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+			require.NoError(t, err)
+			defer cache.Close()
+
+			sink := &recordingSink{}
+			bus := events.NewBus(logger, nil, sink)
+			mockWebhook := new(MockWebhookClient)
+			service := NewMessageServiceWithCacheAndWebhook(repo.NewMessageRepository(db), cache, mockWebhook, logger,
+				WithEventBus(bus))
+
+			// A cached copy from before the delivery, which a failure drops
+			server.Set("message:metadata:1", "{}")
+
+			expectSelectUnsent(sqlMock, 1)
+			expectLockUnsent(sqlMock, 1)
+			sqlMock.ExpectExec(`SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+			if tt.sendErr == nil {
+				sqlMock.ExpectExec(markSentQuery).
+					WithArgs(domain.MessageStatusSent, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			} else {
+				sqlMock.ExpectExec(`UPDATE messages\s+SET status = \$1, error_message = \$2`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			sqlMock.ExpectExec(`RELEASE SAVEPOINT nested_tx`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectCommit().WillReturnError(tt.commitErr)
+
+			mockWebhook.On("SendMessage", mock.Anything, mock.Anything).Return("", tt.sendErr).Once()
+
+			_, err = service.ProcessUnsentMessages(ctx, 10)
+			require.NoError(t, err)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+			bus.Close()
+
+			// A status update that rolled back must not be visible through
+			// the cache or announced to sinks
+			committed := tt.commitErr == nil
+			if tt.sendErr == nil {
+				assert.Equal(t, committed, server.Exists("messages:recently_sent"))
+			} else {
+				assert.Equal(t, !committed, server.Exists("message:metadata:1"))
+			}
+			if tt.wantEvent == "" {
+				assert.Empty(t, sink.events)
+				return
+			}
+			require.Len(t, sink.events, 1)
+			assert.Equal(t, tt.wantEvent, sink.events[0].Type)
+		})
+	}
+}
+
+func TestMessageService_GetMessage(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	service := NewMessageService(mockRepo, logger)
+	ctx := context.Background()
 
 	t.Run("successful get", func(t *testing.T) {
 		expectedMessage := &domain.Message{
@@ -263,113 +958,3592 @@ func TestMessageService_GetMessage(t *testing.T) {
 	})
 }
 
-func TestMessageService_GetSentMessages(t *testing.T) {
+func TestMessageService_GetMessage_Cache(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	ctx := context.Background()
 
-	t.Run("successful get sent messages", func(t *testing.T) {
+	sent := &domain.Message{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: "https://example.com/webhook",
+		Status:     domain.MessageStatusSent,
+	}
+
+	t.Run("hit returns without a repository call", func(t *testing.T) {
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		cache := newFakeMessageCache()
+		cache.messages[1] = sent
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger, WithMessageCache(cache), WithMetrics(m))
 
-		messages := []*domain.Message{
-			{
-				ID:        1,
-				Recipient: "test1@example.com",
-				Status:    domain.MessageStatusSent,
-			},
-			{
-				ID:        2,
-				Recipient: "test2@example.com",
-				Status:    domain.MessageStatusSent,
-			},
-		}
+		message, err := service.GetMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, sent, message)
+
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheHitsTotal.WithLabelValues("get_message")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.CacheMissesTotal.WithLabelValues("get_message")))
+	})
+
+	t.Run("miss falls through to the repository and caches", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		cache := newFakeMessageCache()
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger, WithMessageCache(cache), WithMetrics(m))
 
-		mockRepo.On("GetSentMessages", ctx, 0, 10).Return(messages, 25, nil)
+		mockRepo.On("GetByID", ctx, int64(1)).Return(sent, nil).Once()
 
-		result, total, err := service.GetSentMessages(ctx, 0, 10)
+		message, err := service.GetMessage(ctx, 1)
 		require.NoError(t, err)
-		assert.Equal(t, messages, result)
-		assert.Equal(t, 25, total)
+		assert.Equal(t, sent, message)
+		assert.Equal(t, sent, cache.messages[1])
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheMissesTotal.WithLabelValues("get_message")))
+
+		// The second read is served from the cache
+		message, err = service.GetMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, sent, message)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheHitsTotal.WithLabelValues("get_message")))
 
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("repository error", func(t *testing.T) {
-		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+	t.Run("messages that can still change are not cached", func(t *testing.T) {
+		for _, status := range []domain.MessageStatus{domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusDeadLetter} {
+			mockRepo := new(MockMessageRepository)
+			cache := newFakeMessageCache()
+			service := NewMessageService(mockRepo, logger, WithMessageCache(cache))
 
-		mockRepo.On("GetSentMessages", ctx, 0, 10).Return(([]*domain.Message)(nil), 0, errors.New("database error"))
+			mockRepo.On("GetByID", ctx, int64(2)).Return(&domain.Message{ID: 2, Status: status}, nil)
 
-		result, total, err := service.GetSentMessages(ctx, 0, 10)
-		require.Error(t, err)
-		assert.Nil(t, result)
-		assert.Equal(t, 0, total)
-		assert.Contains(t, err.Error(), "failed to get sent messages")
+			_, err := service.GetMessage(ctx, 2)
+			require.NoError(t, err)
+			assert.Empty(t, cache.messages, status)
+		}
+	})
+
+	t.Run("cache errors fall back to the repository", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		cache := newFakeMessageCache()
+		cache.err = errors.New("redis unavailable")
+		service := NewMessageService(mockRepo, logger, WithMessageCache(cache))
+
+		mockRepo.On("GetByID", ctx, int64(1)).Return(sent, nil)
 
+		message, err := service.GetMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, sent, message)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
-func TestMessageService_RetryFailedMessages(t *testing.T) {
+// fakeMessageCache is an in-memory MessageCache that can be made to fail
+type fakeMessageCache struct {
+	mu       sync.Mutex
+	messages map[int64]*domain.Message
+	err      error
+}
+
+func newFakeMessageCache() *fakeMessageCache {
+	return &fakeMessageCache{messages: make(map[int64]*domain.Message)}
+}
+
+func (f *fakeMessageCache) GetCachedMessage(ctx context.Context, messageID int64) (*domain.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.messages[messageID], nil
+}
+
+func (f *fakeMessageCache) CacheMessage(ctx context.Context, message *domain.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.messages[message.ID] = message
+	return nil
+}
+
+func (f *fakeMessageCache) DeleteCachedMessage(ctx context.Context, messageID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	delete(f.messages, messageID)
+	return nil
+}
+
+// fakeRecentlySentCache is an in-memory RecentlySentCache that can be made to fail
+type fakeRecentlySentCache struct {
+	mu  sync.Mutex
+	ids []int
+	err error
+}
+
+func (f *fakeRecentlySentCache) AddRecentlySentMessage(ctx context.Context, messageID int64, window int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	ids := []int{int(messageID)}
+	for _, id := range f.ids {
+		if id != int(messageID) {
+			ids = append(ids, id)
+		}
+	}
+	f.ids = ids
+	if len(f.ids) > window {
+		f.ids = f.ids[:window]
+	}
+	return nil
+}
+
+func (f *fakeRecentlySentCache) GetRecentlySentMessages(ctx context.Context, limit int) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]int(nil), f.ids[:min(limit, len(f.ids))]...), nil
+}
+
+func (f *fakeRecentlySentCache) CacheRecentlySentMessages(ctx context.Context, messageIDs []int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ids = append([]int(nil), messageIDs...)
+	return nil
+}
+
+func TestMessageService_GetSentMessages_RecentlySentCache(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	ctx := context.Background()
 
-	t.Run("successful retry", func(t *testing.T) {
+	sent := func(id int64) *domain.Message {
+		return &domain.Message{ID: id, Recipient: fmt.Sprintf("user%d@example.com", id), Content: "Hello", Status: domain.MessageStatusSent}
+	}
+
+	t.Run("warm cache serves the first page", func(t *testing.T) {
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		recent := &fakeRecentlySentCache{ids: []int{3, 2, 1}}
+		cache := newFakeMessageCache()
+		cache.messages[3] = sent(3)
+		cache.messages[2] = sent(2)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger,
+			WithRecentlySentCache(recent), WithMessageCache(cache), WithMetrics(m))
 
-		messages := []*domain.Message{
-			{
-				ID:         1,
-				Recipient:  "test1@example.com",
-				Status:     domain.MessageStatusFailed,
-				RetryCount: 1,
-				MaxRetries: 3,
-			},
-			{
-				ID:         2,
-				Recipient:  "test2@example.com",
-				Status:     domain.MessageStatusFailed,
-				RetryCount: 2,
-				MaxRetries: 3,
-			},
+		mockRepo.On("CountWithStatus", ctx, domain.MessageStatusSent).Return(3, nil)
+		// Message 1 is not in the message cache and is loaded once
+		mockRepo.On("GetByIDs", ctx, []int64{1}).Return([]*domain.Message{sent(1)}, nil).Once()
+
+		messages, total, err := service.GetSentMessages(ctx, 0, 50, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, messages, 3)
+		for i, id := range []int64{3, 2, 1} {
+			assert.Equal(t, id, messages[i].ID)
 		}
+		assert.Contains(t, cache.messages, int64(1))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheHitsTotal.WithLabelValues("get_sent_messages")))
 
-		mockRepo.On("GetFailedMessages", ctx, 10).Return(messages, nil)
-		mockRepo.On("MarkSent", ctx, int64(1)).Return(nil)
-		mockRepo.On("MarkSent", ctx, int64(2)).Return(nil)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetSentMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CountByStatus", mock.Anything)
+	})
 
-		retried, err := service.RetryFailedMessages(ctx, 10)
+	t.Run("cache misses are loaded in one query", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		recent := &fakeRecentlySentCache{ids: []int{3, 2, 1}}
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent))
+
+		mockRepo.On("CountWithStatus", ctx, domain.MessageStatusSent).Return(3, nil)
+		mockRepo.On("GetByIDs", ctx, []int64{3, 2, 1}).Return([]*domain.Message{sent(1), sent(3), sent(2)}, nil).Once()
+
+		messages, total, err := service.GetSentMessages(ctx, 0, 50, domain.DefaultSentMessagesSort)
 		require.NoError(t, err)
-		assert.Equal(t, 2, retried)
+		assert.Equal(t, 3, total)
+		require.Len(t, messages, 3)
+		for i, id := range []int64{3, 2, 1} {
+			assert.Equal(t, id, messages[i].ID)
+		}
 
 		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
 	})
 
-	t.Run("no failed messages", func(t *testing.T) {
+	t.Run("message no longer sent falls back to the repository", func(t *testing.T) {
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		recent := &fakeRecentlySentCache{ids: []int{2, 1}}
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent))
 
-		mockRepo.On("GetFailedMessages", ctx, 10).Return([]*domain.Message{}, nil)
+		requeued := sent(2)
+		requeued.Status = domain.MessageStatusPending
+		mockRepo.On("CountWithStatus", ctx, domain.MessageStatusSent).Return(2, nil)
+		mockRepo.On("GetByIDs", ctx, []int64{2, 1}).Return([]*domain.Message{requeued, sent(1)}, nil)
+		mockRepo.On("GetSentMessages", ctx, 0, 50, domain.DefaultSentMessagesSort).Return([]*domain.Message{sent(1)}, 1, nil)
 
-		retried, err := service.RetryFailedMessages(ctx, 10)
+		messages, total, err := service.GetSentMessages(ctx, 0, 50, domain.DefaultSentMessagesSort)
 		require.NoError(t, err)
-		assert.Equal(t, 0, retried)
+		assert.Equal(t, 1, total)
+		require.Len(t, messages, 1)
+		assert.Equal(t, int64(1), messages[0].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("other orders bypass the cache", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		recent := &fakeRecentlySentCache{ids: []int{3, 2, 1}}
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent))
+
+		order := domain.MessageSort{Field: domain.SortByCreatedAt, Order: domain.SortAsc}
+		mockRepo.On("GetSentMessages", ctx, 0, 50, order).Return([]*domain.Message{sent(1), sent(2), sent(3)}, 3, nil)
+
+		messages, total, err := service.GetSentMessages(ctx, 0, 50, order)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, messages, 3)
+		assert.Equal(t, int64(1), messages[0].ID)
 
 		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CountWithStatus", mock.Anything, mock.Anything)
 	})
 
-	t.Run("repository error", func(t *testing.T) {
+	t.Run("cold cache falls back to the repository", func(t *testing.T) {
 		mockRepo := new(MockMessageRepository)
-		service := NewMessageService(mockRepo, logger)
+		recent := &fakeRecentlySentCache{}
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent), WithMetrics(m))
 
-		mockRepo.On("GetFailedMessages", ctx, 10).Return(([]*domain.Message)(nil), errors.New("database error"))
+		mockRepo.On("GetSentMessages", ctx, 0, 50, domain.DefaultSentMessagesSort).Return([]*domain.Message{sent(1)}, 1, nil)
 
-		retried, err := service.RetryFailedMessages(ctx, 10)
-		require.Error(t, err)
-		assert.Equal(t, 0, retried)
-		assert.Contains(t, err.Error(), "failed to get failed messages")
+		messages, total, err := service.GetSentMessages(ctx, 0, 50, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheMissesTotal.WithLabelValues("get_sent_messages")))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("list shorter than the page falls back to the repository", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		recent := &fakeRecentlySentCache{ids: []int{5, 4}}
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent))
+
+		// Messages sent before the cache warmed up are missing from the list
+		mockRepo.On("CountWithStatus", ctx, domain.MessageStatusSent).Return(10, nil)
+		mockRepo.On("GetSentMessages", ctx, 0, 5, domain.DefaultSentMessagesSort).Return([]*domain.Message{sent(5), sent(4), sent(3), sent(2), sent(1)}, 10, nil)
+
+		messages, total, err := service.GetSentMessages(ctx, 0, 5, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Equal(t, 10, total)
+		assert.Len(t, messages, 5)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("offset beyond the cached window falls back to the repository", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		recent := &fakeRecentlySentCache{ids: []int{3, 2, 1}}
+		service := NewMessageService(mockRepo, logger, WithRecentlySentCache(recent))
 
+		mockRepo.On("GetSentMessages", ctx, 100, 50, domain.DefaultSentMessagesSort).Return([]*domain.Message{}, 3, nil)
+
+		_, _, err := service.GetSentMessages(ctx, 100, 50, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CountWithStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sending records the message at the head of the list", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		recent := &fakeRecentlySentCache{}
+		service := NewMessageService(messageRepo, logger, WithRecentlySentCache(recent))
+
+		for i := 0; i < 2; i++ {
+			_, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello"})
+			require.NoError(t, err)
+		}
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, processed)
+		assert.Len(t, recent.ids, 2)
+
+		messages, total, err := service.GetSentMessages(ctx, 0, 10, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, messages, 2)
+		assert.Equal(t, int64(recent.ids[0]), messages[0].ID)
+	})
+
+	t.Run("a failed push empties the list", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		recent := &fakeRecentlySentCache{ids: []int{7}, err: errors.New("redis unavailable")}
+		service := NewMessageService(messageRepo, logger, WithRecentlySentCache(recent))
+
+		_, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello"})
+		require.NoError(t, err)
+		_, err = service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, recent.ids)
 	})
 }
+
+func TestMessageService_RedisOutage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	cache, err := repo.NewRedisCacheRepository("redis://"+server.Addr(), time.Hour,
+		repo.WithOperationTimeout(100*time.Millisecond), repo.WithFailureCooldown(time.Minute))
+	require.NoError(t, err)
+	defer cache.Close()
+
+	messageRepo := repo.NewInMemoryMessageRepository()
+	service := NewMessageServiceWithCache(messageRepo, cache, logger)
+
+	created, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello"})
+	require.NoError(t, err)
+	require.NoError(t, messageRepo.MarkSent(ctx, created.ID))
+
+	server.Close()
+
+	// The first read pays for the failed cache call; the rest skip Redis
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		message, err := service.GetMessage(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, message.ID)
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	messages, total, err := service.GetSentMessages(ctx, 0, 10, domain.DefaultSentMessagesSort)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, messages, 1)
+	assert.Equal(t, created.ID, messages[0].ID)
+}
+
+func TestMessageService_InvalidatesCacheOnTransition(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("requeue removes the cached message", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		cache := newFakeMessageCache()
+		service := NewMessageService(messageRepo, logger, WithMessageCache(cache))
+
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Hello",
+			MaxRetries: 1,
+		})
+		require.NoError(t, err)
+		require.NoError(t, messageRepo.MarkFailed(ctx, message.ID, "timeout", 0))
+		require.NoError(t, messageRepo.MarkDeadLetter(ctx, message.ID))
+
+		stale, err := messageRepo.GetByID(ctx, message.ID)
+		require.NoError(t, err)
+		require.NoError(t, cache.CacheMessage(ctx, stale))
+
+		_, err = service.RequeueMessage(ctx, message.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, cache.messages, message.ID)
+
+		current, err := service.GetMessage(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusPending, current.Status)
+	})
+
+	t.Run("a new failure removes the cached message", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		cache := newFakeMessageCache()
+		service := NewMessageService(mockRepo, logger, WithMessageCache(cache)).(*messageService)
+
+		message := &domain.Message{ID: 3, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3}
+		cache.messages[3] = message
+		mockRepo.On("MarkFailed", mock.Anything, int64(3), "timeout", mock.Anything).Return(nil)
+
+		require.NoError(t, service.markFailed(ctx, mockRepo, message, "timeout"))
+		assert.Empty(t, cache.messages)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no cache configured", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetByID", ctx, int64(4)).Return(&domain.Message{ID: 4, Status: domain.MessageStatusDeadLetter}, nil)
+		mockRepo.On("Requeue", ctx, int64(4)).Return(nil)
+
+		_, err := service.RequeueMessage(ctx, 4)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_GetSentMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("successful get sent messages", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{
+			{
+				ID:        1,
+				Recipient: "test1@example.com",
+				Status:    domain.MessageStatusSent,
+			},
+			{
+				ID:        2,
+				Recipient: "test2@example.com",
+				Status:    domain.MessageStatusSent,
+			},
+		}
+
+		mockRepo.On("GetSentMessages", ctx, 0, 10, domain.DefaultSentMessagesSort).Return(messages, 25, nil)
+
+		result, total, err := service.GetSentMessages(ctx, 0, 10, domain.DefaultSentMessagesSort)
+		require.NoError(t, err)
+		assert.Equal(t, messages, result)
+		assert.Equal(t, 25, total)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetSentMessages", ctx, 0, 10, domain.DefaultSentMessagesSort).Return(([]*domain.Message)(nil), 0, errors.New("database error"))
+
+		result, total, err := service.GetSentMessages(ctx, 0, 10, domain.DefaultSentMessagesSort)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, 0, total)
+		assert.Contains(t, err.Error(), "failed to get sent messages")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid order", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		_, _, err := service.GetSentMessages(ctx, 0, 10, domain.MessageSort{Field: "recipient", Order: domain.SortAsc})
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+
+		mockRepo.AssertNotCalled(t, "GetSentMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_ListMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("passes the status filter through", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{{ID: 1, Status: domain.MessageStatusPending}}
+		mockRepo.On("ListMessages", ctx, domain.MessageStatusPending, 0, 10).Return(messages, 1, nil)
+
+		result, total, err := service.ListMessages(ctx, domain.MessageStatusPending, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, messages, result)
+		assert.Equal(t, 1, total)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("ListMessages", ctx, domain.MessageStatus(""), 0, 10).Return(nil, 0, errors.New("database error"))
+
+		result, _, err := service.ListMessages(ctx, "", 0, 10)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to list messages")
+	})
+}
+
+func TestMessageService_ListMessagesAfterCursor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("more messages remain", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{{ID: 9}, {ID: 8}, {ID: 7}}
+		mockRepo.On("ListAfterCursor", ctx, domain.MessageStatusSent, int64(10), 3).Return(messages, nil)
+
+		result, next, err := service.ListMessagesAfterCursor(ctx, domain.MessageStatusSent, 10, 2)
+		require.NoError(t, err)
+		assert.Equal(t, messages[:2], result)
+		assert.Equal(t, int64(8), next)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("last page has no next cursor", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{{ID: 2}, {ID: 1}}
+		mockRepo.On("ListAfterCursor", ctx, domain.MessageStatus(""), int64(0), 3).Return(messages, nil)
+
+		result, next, err := service.ListMessagesAfterCursor(ctx, "", 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, messages, result)
+		assert.Zero(t, next)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("ListAfterCursor", ctx, domain.MessageStatus(""), int64(0), 11).Return(nil, errors.New("database error"))
+
+		result, _, err := service.ListMessagesAfterCursor(ctx, "", 0, 10)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to list messages")
+	})
+}
+
+func TestMessageService_RetryFailedMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("successful retry", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{
+			{
+				ID:         1,
+				Recipient:  "test1@example.com",
+				Status:     domain.MessageStatusFailed,
+				RetryCount: 1,
+				MaxRetries: 3,
+			},
+			{
+				ID:         2,
+				Recipient:  "test2@example.com",
+				Status:     domain.MessageStatusFailed,
+				RetryCount: 2,
+				MaxRetries: 3,
+			},
+		}
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return(messages, nil)
+		expectLocked(mockRepo, messages...)
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil)
+		mockRepo.On("MarkSent", mock.Anything, int64(2)).Return(nil)
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, retried)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("high priority messages retried first", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+		for _, priority := range []int{0, 10, 3} {
+			message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: "https://example.com/webhook",
+				Priority:   priority,
+			})
+			require.NoError(t, err)
+			require.NoError(t, messageRepo.MarkFailed(ctx, message.ID, "timeout", 0))
+		}
+
+		var order []int
+		mockWebhook.On("SendMessage", mock.Anything, mock.AnythingOfType("*domain.Message")).
+			Run(func(args mock.Arguments) {
+				order = append(order, args.Get(1).(*domain.Message).Priority)
+			}).
+			Return("", nil)
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, retried)
+		assert.Equal(t, []int{10, 3, 0}, order)
+	})
+
+	t.Run("no failed messages", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return([]*domain.Message{}, nil)
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, retried)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return(([]*domain.Message)(nil), errors.New("database error"))
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.Error(t, err)
+		assert.Equal(t, 0, retried)
+		assert.Contains(t, err.Error(), "failed to get failed messages")
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// rowLockingRepository holds the row LockUnsent locks until its transaction
+// ends and skips rows another transaction holds, as FOR UPDATE SKIP LOCKED
+// does. Like the PostgreSQL repository it selects failed messages whose retry
+// is due along with pending ones.
+type rowLockingRepository struct {
+	repo.MessageRepository
+
+	mu     sync.Mutex
+	locked map[int64]bool
+}
+
+func newRowLockingRepository() *rowLockingRepository {
+	return &rowLockingRepository{
+		MessageRepository: repo.NewInMemoryMessageRepository(),
+		locked:            make(map[int64]bool),
+	}
+}
+
+func (r *rowLockingRepository) SelectUnsentForUpdate(ctx context.Context, limit int) ([]*domain.Message, error) {
+	pending, err := r.MessageRepository.SelectUnsentForUpdate(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := r.MessageRepository.GetFailedMessages(ctx, limit-len(pending))
+	if err != nil {
+		return nil, err
+	}
+	return append(pending, failed...), nil
+}
+
+func (r *rowLockingRepository) WithTx(ctx context.Context, fn func(txRepo repo.MessageRepository) error) error {
+	tx := &rowLockingTx{rowLockingRepository: r}
+	defer tx.unlock()
+	return fn(tx)
+}
+
+// rowLockingTx is a transaction of rowLockingRepository and the rows it holds
+type rowLockingTx struct {
+	*rowLockingRepository
+	held []int64
+}
+
+func (t *rowLockingTx) LockUnsent(ctx context.Context, messageID int64) (*domain.Message, error) {
+	t.mu.Lock()
+	if t.locked[messageID] {
+		t.mu.Unlock()
+		return nil, domain.ErrMessageNotFound
+	}
+	t.locked[messageID] = true
+	t.held = append(t.held, messageID)
+	t.mu.Unlock()
+
+	return t.MessageRepository.LockUnsent(ctx, messageID)
+}
+
+func (t *rowLockingTx) unlock() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range t.held {
+		delete(t.locked, id)
+	}
+}
+
+func TestMessageService_RetryFailedMessages_RacesProcessing(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		first, race func(service MessageService) (int, error)
+	}{
+		{
+			name:  "processing run while a retry delivers",
+			first: func(service MessageService) (int, error) { return service.RetryFailedMessages(ctx, 10) },
+			race:  func(service MessageService) (int, error) { return service.ProcessUnsentMessages(ctx, 10) },
+		},
+		{
+			name:  "retry while a processing run delivers",
+			first: func(service MessageService) (int, error) { return service.ProcessUnsentMessages(ctx, 10) },
+			race:  func(service MessageService) (int, error) { return service.RetryFailedMessages(ctx, 10) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageRepo := newRowLockingRepository()
+			mockWebhook := new(MockWebhookClient)
+			service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+			message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: "https://example.com/webhook",
+			})
+			require.NoError(t, err)
+			require.NoError(t, messageRepo.MarkFailed(ctx, message.ID, "timeout", 0))
+
+			// The other path runs while the first one is mid-delivery and
+			// holds the row
+			var raced int
+			var raceErr error
+			var started atomic.Bool
+			mockWebhook.On("SendMessage", mock.Anything, mock.Anything).
+				Run(func(mock.Arguments) {
+					if started.CompareAndSwap(false, true) {
+						raced, raceErr = tt.race(service)
+					}
+				}).
+				Return("", nil)
+
+			delivered, err := tt.first(service)
+			require.NoError(t, err)
+			require.NoError(t, raceErr)
+			assert.Equal(t, 1, delivered)
+			assert.Equal(t, 0, raced)
+
+			mockWebhook.AssertNumberOfCalls(t, "SendMessage", 1)
+			stored, err := messageRepo.GetByID(ctx, message.ID)
+			require.NoError(t, err)
+			assert.Equal(t, domain.MessageStatusSent, stored.Status)
+		})
+	}
+}
+
+func TestMessageService_DeadLetter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+	webhookErr := errors.New("webhook delivery failed with status 500")
+
+	t.Run("last retry failure moves message to dead-letter", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		message := &domain.Message{
+			ID:         1,
+			Recipient:  "test@example.com",
+			WebhookURL: "https://example.com/webhook",
+			Status:     domain.MessageStatusFailed,
+			RetryCount: 2,
+			MaxRetries: 3,
+		}
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", webhookErr)
+		mockRepo.On("MarkFailed", mock.Anything, int64(1), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+		mockRepo.On("MarkDeadLetter", mock.Anything, int64(1)).Return(nil).Once()
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, retried)
+
+		mockRepo.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("failure with retries left stays failed", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		message := &domain.Message{
+			ID:         2,
+			Recipient:  "test@example.com",
+			WebhookURL: "https://example.com/webhook",
+			Status:     domain.MessageStatusPending,
+			RetryCount: 0,
+			MaxRetries: 3,
+		}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", webhookErr)
+		mockRepo.On("MarkFailed", mock.Anything, int64(2), mock.AnythingOfType("string"), mock.Anything).Return(nil).Once()
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkDeadLetter", mock.Anything, int64(2))
+	})
+
+	t.Run("exhausted failed message is dead-lettered without sending", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		message := &domain.Message{
+			ID:         3,
+			Status:     domain.MessageStatusFailed,
+			RetryCount: 3,
+			MaxRetries: 3,
+		}
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return([]*domain.Message{message}, nil)
+		mockRepo.On("MarkDeadLetter", mock.Anything, int64(3)).Return(nil).Once()
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, retried)
+
+		mockRepo.AssertExpectations(t)
+		mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_GetFailedMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("successful get failed messages", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{
+			{ID: 1, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3},
+		}
+
+		mockRepo.On("GetFailedMessagesPaginated", ctx, 10, 10).Return(messages, 11, nil)
+
+		result, total, err := service.GetFailedMessages(ctx, 10, 10)
+		require.NoError(t, err)
+		assert.Equal(t, messages, result)
+		assert.Equal(t, 11, total)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetFailedMessagesPaginated", ctx, 0, 10).Return(nil, 0, errors.New("database error"))
+
+		result, total, err := service.GetFailedMessages(ctx, 0, 10)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, 0, total)
+		assert.Contains(t, err.Error(), "failed to get failed messages")
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_GetDeadLetterMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("successful get dead-letter messages", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		messages := []*domain.Message{
+			{ID: 1, Status: domain.MessageStatusDeadLetter, RetryCount: 3, MaxRetries: 3},
+		}
+
+		mockRepo.On("GetDeadLetterMessages", ctx, 0, 10).Return(messages, 1, nil)
+
+		result, total, err := service.GetDeadLetterMessages(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, messages, result)
+		assert.Equal(t, 1, total)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetDeadLetterMessages", ctx, 0, 10).Return(nil, 0, errors.New("database error"))
+
+		result, total, err := service.GetDeadLetterMessages(ctx, 0, 10)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, 0, total)
+		assert.Contains(t, err.Error(), "failed to get dead-letter messages")
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_SendNow(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	pending := &domain.Message{ID: 1, WebhookURL: "https://example.com/webhook", Status: domain.MessageStatusPending, MaxRetries: 3}
+	sent := &domain.Message{ID: 1, WebhookURL: "https://example.com/webhook", Status: domain.MessageStatusSent, MaxRetries: 3}
+
+	t.Run("pending message is delivered", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(pending, nil)
+		mockRepo.On("GetByID", ctx, int64(1)).Return(sent, nil)
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil)
+		mockWebhook.On("SendMessage", mock.Anything, pending).Return("", nil)
+
+		result, err := service.SendNow(ctx, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, result.Status)
+
+		mockRepo.AssertExpectations(t)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("sent message needs force", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(sent, nil)
+
+		_, err := service.SendNow(ctx, 1, false)
+		assert.ErrorIs(t, err, domain.ErrMessageAlreadySent)
+		mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sent message is delivered again with force", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(sent, nil)
+		mockRepo.On("GetByID", ctx, int64(1)).Return(sent, nil)
+		mockRepo.On("MarkResent", mock.Anything, int64(1), "ref-2").Return(nil)
+		mockWebhook.On("SendMessage", mock.Anything, sent).Return("ref-2", nil)
+
+		result, err := service.SendNow(ctx, 1, true)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, result.Status)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkSentWithReference", mock.Anything, mock.Anything, mock.Anything)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("failed forced resend leaves the message sent", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(sent, nil)
+		mockWebhook.On("SendMessage", mock.Anything, sent).Return("", errors.New("webhook returned 500"))
+
+		_, err := service.SendNow(ctx, 1, true)
+		assert.ErrorIs(t, err, domain.ErrDeliveryFailed)
+
+		mockRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkDeadLetter", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed delivery is reported on the message", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		errorMsg := "webhook returned 500"
+		failed := &domain.Message{ID: 1, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3, ErrorMessage: &errorMsg}
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(pending, nil)
+		mockRepo.On("GetByID", ctx, int64(1)).Return(failed, nil)
+		mockRepo.On("MarkFailed", mock.Anything, int64(1), errorMsg, mock.Anything).Return(nil)
+		mockWebhook.On("SendMessage", mock.Anything, pending).Return("", errors.New(errorMsg))
+
+		result, err := service.SendNow(ctx, 1, false)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, result.Status)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	for _, status := range []domain.MessageStatus{
+		domain.MessageStatusSending,
+		domain.MessageStatusCancelled,
+		domain.MessageStatusExpired,
+	} {
+		t.Run(string(status)+" message is not sent", func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockWebhook := new(MockWebhookClient)
+			service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+			mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(&domain.Message{ID: 1, Status: status}, nil)
+
+			_, err := service.SendNow(ctx, 1, true)
+			assert.ErrorIs(t, err, domain.ErrMessageNotSendable)
+			mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("forced resend moves sent_at without relisting the message", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		recent := &fakeRecentlySentCache{}
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger, WithRecentlySentCache(recent))
+
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+		mockWebhook.On("SendMessage", mock.Anything, mock.Anything).Return("", nil)
+
+		first, err := service.SendNow(ctx, message.ID, false)
+		require.NoError(t, err)
+		require.NotNil(t, first.SentAt)
+
+		time.Sleep(time.Millisecond)
+		second, err := service.SendNow(ctx, message.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, second.Status)
+		assert.True(t, second.SentAt.After(*first.SentAt))
+		assert.Equal(t, []int{int(message.ID)}, recent.ids)
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("LockForSend", mock.Anything, int64(999)).Return(nil, domain.ErrMessageNotFound)
+
+		_, err := service.SendNow(ctx, 999, false)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+}
+
+func TestMessageService_RequeueMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("dead-letter message is requeued", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		errorMsg := "webhook delivery failed"
+		failedAt := time.Now()
+		message := &domain.Message{
+			ID:           1,
+			Status:       domain.MessageStatusDeadLetter,
+			RetryCount:   3,
+			MaxRetries:   3,
+			FailedAt:     &failedAt,
+			ErrorMessage: &errorMsg,
+		}
+
+		mockRepo.On("GetByID", ctx, int64(1)).Return(message, nil)
+		mockRepo.On("Requeue", ctx, int64(1)).Return(nil)
+
+		result, err := service.RequeueMessage(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusPending, result.Status)
+		assert.Equal(t, 0, result.RetryCount)
+		assert.Nil(t, result.FailedAt)
+		assert.Nil(t, result.ErrorMessage)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("sent message cannot be requeued", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetByID", ctx, int64(2)).Return(&domain.Message{ID: 2, Status: domain.MessageStatusSent}, nil)
+
+		result, err := service.RequeueMessage(ctx, 2)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, domain.ErrMessageAlreadySent)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Requeue", ctx, int64(2))
+	})
+
+	for _, status := range []domain.MessageStatus{
+		domain.MessageStatusPending,
+		domain.MessageStatusSending,
+		domain.MessageStatusCancelled,
+		domain.MessageStatusExpired,
+	} {
+		t.Run(string(status)+" message cannot be requeued", func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			service := NewMessageService(mockRepo, logger)
+
+			mockRepo.On("GetByID", ctx, int64(3)).Return(&domain.Message{ID: 3, Status: status}, nil)
+
+			result, err := service.RequeueMessage(ctx, 3)
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, domain.ErrMessageNotRequeueable)
+
+			mockRepo.AssertNotCalled(t, "Requeue", mock.Anything, int64(3))
+		})
+	}
+}
+
+func TestMessageService_CancelMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("pending message is cancelled", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient: "user@example.com",
+			Content:   "Hello",
+		})
+		require.NoError(t, err)
+
+		result, err := service.CancelMessage(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusCancelled, result.Status)
+	})
+
+	t.Run("sent message cannot be cancelled", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("Cancel", ctx, int64(2)).Return(fmt.Errorf("wrapped: %w", domain.ErrMessageNotCancellable))
+
+		result, err := service.CancelMessage(ctx, 2)
+		require.Error(t, err)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, domain.ErrMessageNotCancellable)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_UpdatePendingMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	create := func(t *testing.T, messageRepo repo.MessageRepository) *domain.Message {
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Helo",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+		return message
+	}
+	ptr := func(s string) *string { return &s }
+
+	t.Run("edits only the given fields", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+		message := create(t, messageRepo)
+
+		updated, err := service.UpdatePendingMessage(ctx, message.ID, &domain.UpdateMessageRequest{
+			Content: ptr("Hello"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", updated.Content)
+		assert.Equal(t, "user@example.com", updated.Recipient)
+		assert.Equal(t, "https://example.com/webhook", updated.WebhookURL)
+		assert.Equal(t, domain.MessageStatusPending, updated.Status)
+	})
+
+	t.Run("rejects invalid new values", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+		message := create(t, messageRepo)
+
+		_, err := service.UpdatePendingMessage(ctx, message.ID, &domain.UpdateMessageRequest{
+			Recipient:  ptr("not-an-email"),
+			WebhookURL: ptr(""),
+		})
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []domain.FieldError{
+			{Field: "recipient", Message: "recipient must be a valid email address"},
+			{Field: "webhook_url", Message: "webhook URL is required"},
+		}, validationErr.Fields)
+
+		stored, err := messageRepo.GetByID(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", stored.Recipient)
+	})
+
+	t.Run("rejects an empty update", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+		message := create(t, messageRepo)
+
+		_, err := service.UpdatePendingMessage(ctx, message.ID, &domain.UpdateMessageRequest{})
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("sent message cannot be edited", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+		message := create(t, messageRepo)
+		require.NoError(t, messageRepo.MarkSent(ctx, message.ID))
+
+		_, err := service.UpdatePendingMessage(ctx, message.ID, &domain.UpdateMessageRequest{Content: ptr("Hello")})
+		assert.ErrorIs(t, err, domain.ErrMessageNotPending)
+	})
+
+	t.Run("missing message", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger)
+
+		_, err := service.UpdatePendingMessage(ctx, 999, &domain.UpdateMessageRequest{Content: ptr("Hello")})
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+}
+
+func TestMessageService_CancelMessage_InFlightDelivery(t *testing.T) {
+	slogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	// The receiver holds every request until the test ends, so only the
+	// cancellation can finish the delivery in time
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := &config.Config{BackoffMin: 10 * time.Millisecond, BackoffMax: time.Second}
+	client := NewWebhookClient(cfg, logger.New().WithComponent("webhook-test"))
+
+	messageRepo := repo.NewInMemoryMessageRepository()
+	service := NewMessageServiceWithWebhook(messageRepo, client, slogger).(*messageService)
+
+	message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+		Recipient:  "user@example.com",
+		Content:    "Hello",
+		WebhookURL: server.URL,
+		MaxRetries: 3,
+	})
+	require.NoError(t, err)
+
+	processed := make(chan error, 1)
+	go func() {
+		processed <- service.processMessage(ctx, messageRepo, message)
+	}()
+
+	select {
+	case <-arrived:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery never reached the receiver")
+	}
+
+	start := time.Now()
+	result, err := service.CancelMessage(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusCancelled, result.Status)
+
+	select {
+	case err := <-processed:
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight delivery was not aborted")
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The aborted attempt is neither retried nor counted as a failure
+	stored, err := messageRepo.GetByID(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusCancelled, stored.Status)
+	assert.Equal(t, 0, stored.RetryCount)
+	assert.Nil(t, stored.ErrorMessage)
+	assert.Empty(t, service.deliveries.deliveries)
+
+	// The aborted request is still in the audit log
+	attempts, err := messageRepo.ListAttempts(ctx, message.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Nil(t, attempts[0].StatusCode)
+	assert.NotNil(t, attempts[0].Error)
+}
+
+func TestMessageService_ProcessMessage_RecordsAttempts(t *testing.T) {
+	slogger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	// The receiver rejects the first delivery and accepts the second
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{BackoffMin: 10 * time.Millisecond, BackoffMax: time.Second}
+	client := NewWebhookClient(cfg, logger.New().WithComponent("webhook-test"))
+
+	messageRepo := repo.NewInMemoryMessageRepository()
+	service := NewMessageServiceWithWebhook(messageRepo, client, slogger).(*messageService)
+
+	message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+		Recipient:  "user@example.com",
+		Content:    "Hello",
+		WebhookURL: server.URL,
+		MaxRetries: 3,
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, service.processMessage(ctx, messageRepo, message))
+	assert.NoError(t, service.processMessage(ctx, messageRepo, message))
+
+	attempts, err := service.GetMessageAttempts(ctx, message.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+
+	assert.Equal(t, message.ID, attempts[0].MessageID)
+	require.NotNil(t, attempts[0].StatusCode)
+	assert.Equal(t, http.StatusBadRequest, *attempts[0].StatusCode)
+	require.NotNil(t, attempts[0].Error)
+	assert.Contains(t, *attempts[0].Error, "status 400")
+
+	require.NotNil(t, attempts[1].StatusCode)
+	assert.Equal(t, http.StatusAccepted, *attempts[1].StatusCode)
+	assert.Nil(t, attempts[1].Error)
+}
+
+func TestMessageService_RecordsMessageMetrics(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	mockRepo := new(MockMessageRepository)
+	mockWebhook := new(MockWebhookClient)
+	registry := prometheus.NewRegistry()
+	m := metrics.NewWithRegistry(registry)
+	service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger, WithMetrics(m))
+
+	created := &domain.Message{ID: 1, WebhookURL: "https://example.com/ok", Status: domain.MessageStatusPending, MaxRetries: 3}
+	mockRepo.On("Create", ctx, mock.Anything).Return(created, nil)
+
+	_, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: created.WebhookURL,
+		MaxRetries: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesTotal.WithLabelValues("pending")))
+
+	failing := &domain.Message{ID: 2, WebhookURL: "https://example.com/fail", Status: domain.MessageStatusPending, MaxRetries: 3}
+	mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+	mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{created, failing}, nil)
+	expectLocked(mockRepo, created, failing)
+	mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil)
+	mockRepo.On("MarkFailed", mock.Anything, int64(2), "webhook returned 500", mock.Anything).Return(nil)
+	mockWebhook.On("SendMessage", mock.Anything, created).Return("", nil)
+	mockWebhook.On("SendMessage", mock.Anything, failing).Return("", errors.New("webhook returned 500"))
+
+	_, err = service.ProcessUnsentMessages(ctx, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesTotal.WithLabelValues("sent")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesTotal.WithLabelValues("failed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesProcessed.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.MessagesProcessed.WithLabelValues("error")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var sampleCount uint64
+	for _, family := range families {
+		if family.GetName() == "insider_messaging_message_processing_duration_seconds" {
+			sampleCount = family.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, uint64(2), sampleCount)
+
+	mockRepo.AssertExpectations(t)
+	mockWebhook.AssertExpectations(t)
+}
+
+func TestMessageService_GetMessageAttempts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("history", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+		attempts := []*domain.WebhookAttempt{{ID: 1, MessageID: 7}, {ID: 2, MessageID: 7}}
+		mockRepo.On("ListAttempts", ctx, int64(7)).Return(attempts, nil)
+
+		result, err := service.GetMessageAttempts(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, attempts, result)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("no attempts yet", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+		mockRepo.On("ListAttempts", ctx, int64(7)).Return([]*domain.WebhookAttempt(nil), nil)
+		mockRepo.On("GetByID", ctx, int64(7)).Return(&domain.Message{ID: 7}, nil)
+
+		result, err := service.GetMessageAttempts(ctx, 7)
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+		mockRepo.On("ListAttempts", ctx, int64(7)).Return([]*domain.WebhookAttempt(nil), nil)
+		mockRepo.On("GetByID", ctx, int64(7)).Return(nil, domain.ErrMessageNotFound)
+
+		_, err := service.GetMessageAttempts(ctx, 7)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+}
+
+func TestMessageService_ResendMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("sent message is cloned as a new pending message", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		original, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Your code is 1234",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 5,
+			Priority:   3,
+		})
+		require.NoError(t, err)
+		require.NoError(t, messageRepo.MarkSentWithReference(ctx, original.ID, "provider-1"))
+
+		resent, err := service.ResendMessage(ctx, original.ID)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, original.ID, resent.ID)
+		require.NotNil(t, resent.ResentFrom)
+		assert.Equal(t, original.ID, *resent.ResentFrom)
+		assert.Equal(t, domain.MessageStatusPending, resent.Status)
+		assert.Equal(t, "user@example.com", resent.Recipient)
+		assert.Equal(t, "Your code is 1234", resent.Content)
+		assert.Equal(t, "https://example.com/webhook", resent.WebhookURL)
+		assert.Equal(t, 5, resent.MaxRetries)
+		assert.Equal(t, 3, resent.Priority)
+		assert.Equal(t, 0, resent.RetryCount)
+		assert.Nil(t, resent.SentAt)
+		assert.Nil(t, resent.ProviderMessageID)
+
+		// The original keeps its delivery record
+		stored, err := messageRepo.GetByID(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, stored.Status)
+		assert.NotNil(t, stored.SentAt)
+		require.NotNil(t, stored.ProviderMessageID)
+		assert.Equal(t, "provider-1", *stored.ProviderMessageID)
+		assert.Nil(t, stored.ResentFrom)
+
+		// The clone is picked up for delivery
+		unsent, err := messageRepo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, unsent, 1)
+		assert.Equal(t, resent.ID, unsent[0].ID)
+	})
+
+	t.Run("unsent message cannot be resent", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetByID", ctx, int64(2)).Return(&domain.Message{ID: 2, Status: domain.MessageStatusPending}, nil)
+
+		result, err := service.ResendMessage(ctx, 2)
+		assert.ErrorIs(t, err, domain.ErrMessageNotSent)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing message", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetByID", ctx, int64(3)).Return(nil, fmt.Errorf("wrapped: %w", domain.ErrMessageNotFound))
+
+		_, err := service.ResendMessage(ctx, 3)
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+
+	t.Run("resend counts towards the recipient limit", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(messageRepo, logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		original, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Hello",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+		require.NoError(t, messageRepo.MarkSent(ctx, original.ID))
+
+		_, err = service.ResendMessage(ctx, original.ID)
+		var limitErr *domain.RecipientLimitError
+		assert.ErrorAs(t, err, &limitErr)
+	})
+}
+
+// keylessRepository stores idempotency keys without a unique index, so
+// concurrent creates with the same key only stay deduplicated if they are
+// serialized before reaching it
+type keylessRepository struct {
+	repo.MessageRepository
+
+	mu    sync.Mutex
+	byKey map[string][]*domain.Message
+}
+
+func newKeylessRepository() *keylessRepository {
+	return &keylessRepository{
+		MessageRepository: repo.NewInMemoryMessageRepository(),
+		byKey:             make(map[string][]*domain.Message),
+	}
+}
+
+func (r *keylessRepository) Create(ctx context.Context, req *domain.CreateMessageRequest) (*domain.Message, error) {
+	// Widen the window between a create's lookup and its insert
+	time.Sleep(time.Millisecond)
+
+	unkeyed := *req
+	unkeyed.IdempotencyKey = nil
+	message, err := r.MessageRepository.Create(ctx, &unkeyed)
+	if err != nil || req.IdempotencyKey == nil {
+		return message, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byKey[*req.IdempotencyKey] = append(r.byKey[*req.IdempotencyKey], message)
+	return message, nil
+}
+
+func (r *keylessRepository) GetByIdempotencyKey(ctx context.Context, key string) (*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.byKey[key]) == 0 {
+		return nil, domain.ErrMessageNotFound
+	}
+	return r.byKey[key][0], nil
+}
+
+func (r *keylessRepository) countByKey(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.byKey[key])
+}
+
+func TestMessageService_CreateMessageIdempotent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	request := func() *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Your order shipped",
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+
+	t.Run("first call creates, repeat returns the original", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		first, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		require.NoError(t, err)
+		assert.True(t, created)
+		require.NotNil(t, first.IdempotencyKey)
+		assert.Equal(t, "order-42", *first.IdempotencyKey)
+
+		second, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, first.ID, second.ID)
+
+		other, created, err := service.CreateMessageIdempotent(ctx, request(), "order-43")
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, first.ID, other.ID)
+
+		unsent, err := messageRepo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, unsent, 2)
+	})
+
+	t.Run("concurrent identical requests create one message", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(messageRepo, logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		const requests = 20
+		var wg sync.WaitGroup
+		ids := make([]int64, requests)
+		createdFlags := make([]bool, requests)
+		errs := make([]error, requests)
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				message, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+				errs[i] = err
+				createdFlags[i] = created
+				if message != nil {
+					ids[i] = message.ID
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		createdCount := 0
+		for i := 0; i < requests; i++ {
+			require.NoError(t, errs[i])
+			assert.Equal(t, ids[0], ids[i])
+			if createdFlags[i] {
+				createdCount++
+			}
+		}
+		assert.Equal(t, 1, createdCount)
+		assert.Equal(t, int64(1), counter.count("user@example.com"), "duplicates must not hold recipient quota")
+
+		unsent, err := messageRepo.SelectUnsentForUpdate(ctx, 100)
+		require.NoError(t, err)
+		assert.Len(t, unsent, 1)
+	})
+
+	t.Run("concurrent identical requests on several instances create one message", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		messageRepo := newKeylessRepository()
+
+		// Each instance has its own key lock, so only the idempotency lock in
+		// Redis keeps them from racing past the lookup
+		const instances, requestsPerInstance = 4, 10
+		var wg sync.WaitGroup
+		ids := make(chan int64, instances*requestsPerInstance)
+		for i := 0; i < instances; i++ {
+			cache, err := repo.NewRedisCacheRepository("redis://"+server.Addr(), time.Hour)
+			require.NoError(t, err)
+			defer cache.Close()
+			service := NewMessageService(messageRepo, logger, WithIdempotencyLocker(cache))
+
+			for j := 0; j < requestsPerInstance; j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					message, _, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+					if assert.NoError(t, err) {
+						ids <- message.ID
+					}
+				}()
+			}
+		}
+		wg.Wait()
+		close(ids)
+
+		assert.Equal(t, 1, messageRepo.countByKey("order-42"))
+		first := <-ids
+		for id := range ids {
+			assert.Equal(t, first, id)
+		}
+		assert.False(t, server.Exists("idempotency:lock:order-42"), "the lock is released after the create")
+	})
+
+	t.Run("unreachable lock falls back to the unique key", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		cache, err := repo.NewRedisCacheRepository("redis://"+server.Addr(), time.Hour,
+			repo.WithOperationTimeout(100*time.Millisecond))
+		require.NoError(t, err)
+		defer cache.Close()
+		server.Close()
+
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger, WithIdempotencyLocker(cache))
+
+		first, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		second, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, first.ID, second.ID)
+	})
+
+	t.Run("losing the create race returns the winner", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		winner := &domain.Message{ID: 7, Status: domain.MessageStatusPending}
+		mockRepo.On("GetByIdempotencyKey", ctx, "order-42").Return(nil, domain.ErrMessageNotFound).Once()
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(req *domain.CreateMessageRequest) bool {
+			return req.IdempotencyKey != nil && *req.IdempotencyKey == "order-42"
+		})).Return(nil, fmt.Errorf("failed to create message: %w", domain.ErrDuplicateIdempotencyKey))
+		mockRepo.On("GetByIdempotencyKey", ctx, "order-42").Return(winner, nil).Once()
+
+		message, created, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, int64(7), message.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("GetByIdempotencyKey", ctx, "order-42").Return(nil, errors.New("connection reset"))
+
+		_, _, err := service.CreateMessageIdempotent(ctx, request(), "order-42")
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		_, _, err := service.CreateMessageIdempotent(ctx, request(), strings.Repeat("k", domain.MaxIdempotencyKeyLength+1))
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("without a key every call creates", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		first, created, err := service.CreateMessageIdempotent(ctx, request(), "")
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Nil(t, first.IdempotencyKey)
+
+		second, created, err := service.CreateMessageIdempotent(ctx, request(), "")
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+}
+
+func TestMessageService_ValidateMessage(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("returns the would-be message without storing it", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		message, err := service.ValidateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			TTL:        time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), message.ID)
+		assert.Equal(t, domain.MessageStatusPending, message.Status)
+		assert.Equal(t, domain.ChannelWebhook, message.Channel)
+		assert.Equal(t, 3, message.MaxRetries)
+		require.NotNil(t, message.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *message.ExpiresAt, time.Minute)
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports validation errors", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		_, err := service.ValidateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "not-an-email",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+		})
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_CreateMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	request := func(recipient string) *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  recipient,
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+
+	t.Run("mixed batch creates the valid messages", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		invalidURL := request("c@example.com")
+		invalidURL.WebhookURL = "not-a-url"
+		reqs := []*domain.CreateMessageRequest{
+			request("a@example.com"),
+			request("not-an-email"),
+			request("b@example.com"),
+			invalidURL,
+		}
+
+		results, err := service.CreateMessages(ctx, reqs)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "a@example.com", results[0].Message.Recipient)
+		require.NoError(t, results[2].Err)
+		assert.Equal(t, "b@example.com", results[2].Message.Recipient)
+
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, results[1].Err, &validationErr)
+		assert.Nil(t, results[1].Message)
+		assert.ErrorAs(t, results[3].Err, &validationErr)
+		assert.Nil(t, results[3].Message)
+
+		unsent, err := messageRepo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, unsent, 2)
+	})
+
+	t.Run("recipient over the limit is rejected per message", func(t *testing.T) {
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		results, err := service.CreateMessages(ctx, []*domain.CreateMessageRequest{
+			request("a@example.com"),
+			request("a@example.com"),
+			request("b@example.com"),
+		})
+		require.NoError(t, err)
+
+		assert.NoError(t, results[0].Err)
+		var limitErr *domain.RecipientLimitError
+		assert.ErrorAs(t, results[1].Err, &limitErr)
+		assert.NoError(t, results[2].Err)
+		assert.Equal(t, int64(1), counter.count("a@example.com"))
+	})
+
+	t.Run("repository failure stores nothing", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(mockRepo, logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 5}), WithRecipientCounter(counter))
+
+		mockRepo.On("CountByRecipientSince", ctx, mock.Anything, mock.Anything).Return(0, nil)
+		mockRepo.On("CreateBatch", ctx, mock.Anything).Return(nil, errors.New("connection reset"))
+
+		results, err := service.CreateMessages(ctx, []*domain.CreateMessageRequest{
+			request("a@example.com"),
+			request("b@example.com"),
+		})
+		assert.Error(t, err)
+		assert.Nil(t, results)
+		assert.Equal(t, int64(0), counter.count("a@example.com"), "quota is released")
+		assert.Equal(t, int64(0), counter.count("b@example.com"), "quota is released")
+	})
+
+	t.Run("all invalid skips the insert", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		results, err := service.CreateMessages(ctx, []*domain.CreateMessageRequest{request("")})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Error(t, results[0].Err)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_ImportMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	requests := func(n int) []*domain.CreateMessageRequest {
+		reqs := make([]*domain.CreateMessageRequest, n)
+		for i := range reqs {
+			reqs[i] = &domain.CreateMessageRequest{
+				Recipient:  fmt.Sprintf("user%d@example.com", i),
+				Content:    "Test message",
+				WebhookURL: "https://example.com/webhook",
+			}
+		}
+		return reqs
+	}
+	chunkOf := func(n int) interface{} {
+		return mock.MatchedBy(func(reqs []*domain.CreateMessageRequest) bool { return len(reqs) == n })
+	}
+	created := func(n int) []*domain.Message {
+		messages := make([]*domain.Message, n)
+		for i := range messages {
+			messages[i] = &domain.Message{Status: domain.MessageStatusPending}
+		}
+		return messages
+	}
+
+	tests := []struct {
+		name       string
+		total      int
+		commitSize int
+		chunks     []int
+	}{
+		{"partial last chunk", 5, 2, []int{2, 2, 1}},
+		{"exact multiple", 4, 2, []int{2, 2}},
+		{"single chunk", 3, 500, []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{BatchCommitSize: tt.commitSize}))
+
+			for _, size := range tt.chunks {
+				mockRepo.On("CreateBatch", ctx, chunkOf(size)).Return(created(size), nil).Once()
+			}
+
+			var progress []int
+			messages, err := service.ImportMessages(ctx, requests(tt.total), func(imported, total int) {
+				assert.Equal(t, tt.total, total)
+				progress = append(progress, imported)
+			})
+			require.NoError(t, err)
+			assert.Len(t, messages, tt.total)
+
+			var want []int
+			sum := 0
+			for _, size := range tt.chunks {
+				sum += size
+				want = append(want, sum)
+			}
+			assert.Equal(t, want, progress)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("failed chunk keeps earlier chunks", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{BatchCommitSize: 2}))
+
+		mockRepo.On("CreateBatch", ctx, chunkOf(2)).Return(created(2), nil).Once()
+		mockRepo.On("CreateBatch", ctx, chunkOf(2)).Return(nil, errors.New("connection reset")).Once()
+
+		messages, err := service.ImportMessages(ctx, requests(5), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 2 of 5")
+		assert.Len(t, messages, 2)
+		mockRepo.AssertNumberOfCalls(t, "CreateBatch", 2)
+	})
+
+	t.Run("invalid message rejects the whole import", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		reqs := requests(3)
+		reqs[1].WebhookURL = "not-a-url"
+
+		messages, err := service.ImportMessages(ctx, reqs, nil)
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, err.Error(), "message 1")
+		assert.Nil(t, messages)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("recipient limit releases reserved quota", func(t *testing.T) {
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		reqs := requests(2)
+		reqs = append(reqs, reqs[0])
+
+		_, err := service.ImportMessages(ctx, reqs, nil)
+		var limitErr *domain.RecipientLimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, int64(0), counter.count("user0@example.com"))
+		assert.Equal(t, int64(0), counter.count("user1@example.com"))
+	})
+
+	t.Run("stores messages in the repository", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger, WithConfig(&config.Config{BatchCommitSize: 2}))
+
+		messages, err := service.ImportMessages(ctx, requests(3), nil)
+		require.NoError(t, err)
+		require.Len(t, messages, 3)
+
+		unsent, err := messageRepo.SelectUnsentForUpdate(ctx, 10)
+		require.NoError(t, err)
+		assert.Len(t, unsent, 3)
+	})
+}
+
+func TestMessageService_RetryDelay(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+	cfg := &config.Config{
+		InitialRetryDelay: 30 * time.Second,
+		BackoffMax:        2 * time.Minute,
+	}
+
+	t.Run("first failure waits the initial delay", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger, WithConfig(cfg))
+
+		message := &domain.Message{ID: 1, Status: domain.MessageStatusPending, RetryCount: 0, MaxRetries: 5}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", errors.New("connection refused"))
+		mockRepo.On("MarkFailed", mock.Anything, int64(1), mock.AnythingOfType("string"), 30*time.Second).Return(nil).Once()
+
+		_, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("subsequent failures back off exponentially", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger, WithConfig(cfg))
+
+		message := &domain.Message{ID: 2, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 5}
+
+		mockRepo.On("GetFailedMessages", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", errors.New("connection refused"))
+		mockRepo.On("MarkFailed", mock.Anything, int64(2), mock.AnythingOfType("string"), time.Minute).Return(nil).Once()
+
+		_, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("delay is capped at BackoffMax", func(t *testing.T) {
+		service := newMessageService(new(MockMessageRepository), nil, nil, logger, []ServiceOption{WithConfig(cfg)})
+
+		assert.Equal(t, 30*time.Second, service.retryDelay(0))
+		assert.Equal(t, time.Minute, service.retryDelay(1))
+		assert.Equal(t, 2*time.Minute, service.retryDelay(2))
+		assert.Equal(t, 2*time.Minute, service.retryDelay(10))
+	})
+
+	t.Run("BackoffMin is the base without an initial delay", func(t *testing.T) {
+		cfg := &config.Config{BackoffMin: time.Second, BackoffMax: 5 * time.Second}
+		service := newMessageService(new(MockMessageRepository), nil, nil, logger, []ServiceOption{WithConfig(cfg)})
+
+		assert.Equal(t, time.Second, service.retryDelay(0))
+		assert.Equal(t, 2*time.Second, service.retryDelay(1))
+		assert.Equal(t, 4*time.Second, service.retryDelay(2))
+		assert.Equal(t, 5*time.Second, service.retryDelay(3))
+	})
+
+	t.Run("default configuration backs off exponentially", func(t *testing.T) {
+		for _, key := range []string{"CONFIG_FILE", "INITIAL_RETRY_DELAY", "BACKOFF_MIN", "BACKOFF_MAX"} {
+			t.Setenv(key, "")
+		}
+		cfg, err := config.Load()
+		require.NoError(t, err)
+		service := newMessageService(new(MockMessageRepository), nil, nil, logger, []ServiceOption{WithConfig(cfg)})
+
+		assert.Equal(t, time.Second, service.retryDelay(0))
+		assert.Equal(t, 2*time.Second, service.retryDelay(1))
+		assert.Equal(t, 4*time.Second, service.retryDelay(2))
+		assert.Equal(t, 16*time.Second, service.retryDelay(4))
+		assert.Equal(t, 30*time.Second, service.retryDelay(5))
+	})
+
+	t.Run("no configuration retries immediately", func(t *testing.T) {
+		service := newMessageService(new(MockMessageRepository), nil, nil, logger, nil)
+
+		assert.Equal(t, time.Duration(0), service.retryDelay(0))
+		assert.Equal(t, time.Duration(0), service.retryDelay(3))
+	})
+}
+
+func TestMessageService_GetSuccessRate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	outcomes := []*domain.DeliveryOutcome{
+		{WebhookURL: "https://a.example.com/hook1", Sent: 6, DeadLettered: 0},
+		{WebhookURL: "https://a.example.com/hook2", Sent: 2, DeadLettered: 2},
+		{WebhookURL: "http://b.example.com:8080/hook", Sent: 0, DeadLettered: 2},
+	}
+
+	t.Run("aggregates totals and per-host rates", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("CountDeliveryOutcomes", ctx, mock.MatchedBy(func(since time.Time) bool {
+			return time.Since(since) >= time.Hour && time.Since(since) < time.Hour+time.Minute
+		})).Return(outcomes, nil).Once()
+
+		rate, err := service.GetSuccessRate(ctx, time.Hour, true)
+		require.NoError(t, err)
+		assert.Equal(t, "1h0m0s", rate.Window)
+		assert.Equal(t, 8, rate.Sent)
+		assert.Equal(t, 4, rate.DeadLettered)
+		assert.InDelta(t, 8.0/12.0, rate.SuccessRate, 0.0001)
+
+		require.Len(t, rate.Hosts, 2)
+		assert.Equal(t, domain.HostSuccessRate{Host: "a.example.com", Sent: 8, DeadLettered: 2, SuccessRate: 0.8}, rate.Hosts[0])
+		assert.Equal(t, domain.HostSuccessRate{Host: "b.example.com", Sent: 0, DeadLettered: 2, SuccessRate: 0}, rate.Hosts[1])
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("omits host breakdown unless requested", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("CountDeliveryOutcomes", ctx, mock.Anything).Return(outcomes, nil).Once()
+
+		rate, err := service.GetSuccessRate(ctx, time.Hour, false)
+		require.NoError(t, err)
+		assert.Nil(t, rate.Hosts)
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("caches results briefly", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("CountDeliveryOutcomes", ctx, mock.Anything).Return(outcomes, nil).Once()
+
+		first, err := service.GetSuccessRate(ctx, time.Hour, false)
+		require.NoError(t, err)
+		second, err := service.GetSuccessRate(ctx, time.Hour, false)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		mockRepo.AssertNumberOfCalls(t, "CountDeliveryOutcomes", 1)
+	})
+
+	t.Run("no terminal messages", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("CountDeliveryOutcomes", ctx, mock.Anything).Return([]*domain.DeliveryOutcome{}, nil).Once()
+
+		rate, err := service.GetSuccessRate(ctx, 5*time.Minute, false)
+		require.NoError(t, err)
+		assert.Equal(t, 0, rate.Sent)
+		assert.Equal(t, 0.0, rate.SuccessRate)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		mockRepo.On("CountDeliveryOutcomes", ctx, mock.Anything).Return(nil, errors.New("database error")).Once()
+
+		rate, err := service.GetSuccessRate(ctx, time.Hour, false)
+		require.Error(t, err)
+		assert.Nil(t, rate)
+		assert.Contains(t, err.Error(), "failed to count delivery outcomes")
+	})
+}
+
+func TestMessageService_MarkRetry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+	cfg := &config.Config{
+		MarkRetryAttempts: 3,
+		MarkRetryBackoff:  time.Millisecond,
+	}
+
+	t.Run("transient mark failure is retried", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger, WithConfig(cfg), WithMetrics(m))
+
+		message := &domain.Message{ID: 1, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(errors.New("connection reset")).Twice()
+		mockRepo.On("MarkSent", mock.Anything, int64(1)).Return(nil).Once()
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		mockRepo.AssertExpectations(t)
+		assert.Equal(t, 0.0, testutil.ToFloat64(m.MarkOperationFailures.WithLabelValues("sent")))
+	})
+
+	t.Run("persistent mark failure is reported for reconciliation", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger, WithConfig(cfg), WithMetrics(m))
+
+		message := &domain.Message{ID: 2, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", errors.New("connection refused"))
+		mockRepo.On("MarkFailed", mock.Anything, int64(2), mock.AnythingOfType("string"), mock.Anything).Return(errors.New("connection reset"))
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		mockRepo.AssertNumberOfCalls(t, "MarkFailed", 3)
+		mockRepo.AssertNotCalled(t, "MarkDeadLetter", mock.Anything, mock.Anything)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.MarkOperationFailures.WithLabelValues("failed")))
+	})
+
+	t.Run("missing message is not retried", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		service := NewMessageService(mockRepo, logger, WithConfig(cfg), WithMetrics(m))
+
+		message := &domain.Message{ID: 3, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockRepo.On("MarkSent", mock.Anything, int64(3)).Return(domain.ErrMessageNotFound)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		mockRepo.AssertNumberOfCalls(t, "MarkSent", 1)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.MarkOperationFailures.WithLabelValues("sent")))
+	})
+
+	t.Run("without configuration a mark is attempted once", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		message := &domain.Message{ID: 4, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockRepo.On("MarkSent", mock.Anything, int64(4)).Return(errors.New("connection reset"))
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		mockRepo.AssertNumberOfCalls(t, "MarkSent", 1)
+	})
+}
+
+func TestMessageService_CreateMessage_ContentSanitization(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newRequest := func(content string) *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    content,
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+
+	t.Run("reject mode rejects invalid UTF-8", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{ContentSanitizeMode: config.ContentSanitizeReject}))
+
+		message, err := service.CreateMessage(ctx, newRequest("bad \xff bytes"))
+		require.Error(t, err)
+		assert.Nil(t, message)
+		assert.Equal(t, "content must be valid UTF-8 without control characters", err.Error())
+
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reject mode rejects control characters", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{ContentSanitizeMode: config.ContentSanitizeReject}))
+
+		_, err := service.CreateMessage(ctx, newRequest("hello\x00world"))
+		require.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sanitize mode cleans content before storing", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{ContentSanitizeMode: config.ContentSanitizeSanitize}))
+
+		req := newRequest("hello\x00 \xffworld\x1b")
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(r *domain.CreateMessageRequest) bool {
+			return r.Content == "hello \uFFFDworld"
+		})).Return(&domain.Message{ID: 1, Content: "hello \uFFFDworld"}, nil)
+
+		message, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "hello \uFFFDworld", message.Content)
+		assert.Equal(t, "hello\x00 \xffworld\x1b", req.Content, "caller's request should not be modified")
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("off mode stores content as-is", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		req := newRequest("hello\x00world")
+		mockRepo.On("Create", ctx, req).Return(&domain.Message{ID: 1, Content: req.Content}, nil)
+
+		_, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_CreateMessage_MaxContentLength(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newRequest := func(content string) *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    content,
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+
+	t.Run("content at the limit is accepted", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MaxContentLength: 5}))
+
+		req := newRequest("hello")
+		mockRepo.On("Create", ctx, req).Return(&domain.Message{ID: 1, Content: req.Content}, nil)
+
+		_, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("content one over the limit is rejected", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MaxContentLength: 5}))
+
+		message, err := service.CreateMessage(ctx, newRequest("hello!"))
+		require.Error(t, err)
+		assert.Nil(t, message)
+		assert.Equal(t, "content exceeds maximum length of 5 characters", err.Error())
+
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("length is counted in runes, not bytes", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MaxContentLength: 5}))
+
+		req := newRequest("héllö")
+		mockRepo.On("Create", ctx, req).Return(&domain.Message{ID: 1, Content: req.Content}, nil)
+
+		_, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_CreateMessage_MaxRetries(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newRequest := func(maxRetries int) *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: maxRetries,
+		}
+	}
+
+	accepted := []struct {
+		name       string
+		maxRetries int
+		limit      int
+	}{
+		{"zero uses the default", 0, 0},
+		{"default limit", 10, 0},
+		{"configured limit", 5, 5},
+	}
+	for _, tt := range accepted {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MaxRetriesLimit: tt.limit}))
+
+			req := newRequest(tt.maxRetries)
+			mockRepo.On("Create", ctx, req).Return(&domain.Message{ID: 1, MaxRetries: tt.maxRetries}, nil)
+
+			_, err := service.CreateMessage(ctx, req)
+			require.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	rejected := []struct {
+		name       string
+		maxRetries int
+		limit      int
+		wantErr    string
+	}{
+		{"one over the default limit", 11, 0, "max_retries must be between 0 and 10"},
+		{"one over a configured limit", 6, 5, "max_retries must be between 0 and 5"},
+		{"far out of range", 1_000_000, 0, "max_retries must be between 0 and 10"},
+		{"negative", -1, 0, "max_retries must be between 0 and 10"},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MaxRetriesLimit: tt.limit}))
+
+			message, err := service.CreateMessage(ctx, newRequest(tt.maxRetries))
+			require.Error(t, err)
+			assert.Nil(t, message)
+			assert.Equal(t, tt.wantErr, err.Error())
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "max_retries", validationErr.Fields[0].Field)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_CreateMessage_Expiry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	newRequest := func() *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Hello",
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+
+	t.Run("ttl is resolved to an absolute expiry", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger)
+
+		req := newRequest()
+		req.TTL = time.Hour
+		before := time.Now()
+		message, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+
+		require.NotNil(t, message.ExpiresAt)
+		assert.WithinRange(t, *message.ExpiresAt, before.Add(time.Hour), time.Now().Add(time.Hour))
+	})
+
+	t.Run("absolute expiry is stored as given", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger)
+
+		req := newRequest()
+		expiresAt := time.Now().Add(time.Hour)
+		req.ExpiresAt = &expiresAt
+		message, err := service.CreateMessage(ctx, req)
+		require.NoError(t, err)
+
+		require.NotNil(t, message.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*message.ExpiresAt))
+	})
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		expiresAt *time.Time
+		field     string
+	}{
+		{"ttl and expires_at together", time.Hour, &future, "ttl_seconds"},
+		{"negative ttl", -time.Hour, nil, "ttl_seconds"},
+		{"expiry in the past", 0, &past, "expires_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" is rejected", func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			service := NewMessageService(mockRepo, logger)
+
+			req := newRequest()
+			req.TTL = tt.ttl
+			req.ExpiresAt = tt.expiresAt
+			_, err := service.CreateMessage(ctx, req)
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Len(t, validationErr.Fields, 1)
+			assert.Equal(t, tt.field, validationErr.Fields[0].Field)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_ProcessUnsentMessages_Expiry(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	messageRepo := repo.NewInMemoryMessageRepository()
+	sink := &recordingSink{}
+	bus := events.NewBus(logger, nil, sink)
+	service := NewMessageService(messageRepo, logger, WithEventBus(bus))
+
+	past := time.Now().Add(-time.Minute)
+	stale, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Stale", ExpiresAt: &past})
+	require.NoError(t, err)
+	fresh, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Fresh"})
+	require.NoError(t, err)
+
+	processed, err := service.ProcessUnsentMessages(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	stored, err := messageRepo.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusExpired, stored.Status)
+	assert.Nil(t, stored.SentAt)
+
+	stored, err = messageRepo.GetByID(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MessageStatusSent, stored.Status)
+
+	bus.Close()
+	var expiredEvents []events.Event
+	for _, event := range sink.events {
+		if event.Type == events.EventMessageExpired {
+			expiredEvents = append(expiredEvents, event)
+		}
+	}
+	require.Len(t, expiredEvents, 1)
+	assert.Equal(t, stale.ID, expiredEvents[0].MessageID)
+	assert.Equal(t, domain.MessageStatusExpired, expiredEvents[0].Status)
+}
+
+func TestMessageService_ProcessUnsentMessages_ProviderMessageID(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("stores the provider reference when returned", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		message := &domain.Message{ID: 1, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("abc123", nil)
+		mockRepo.On("MarkSentWithReference", mock.Anything, int64(1), "abc123").Return(nil)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("falls back to MarkSent without a reference", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(mockRepo, mockWebhook, logger)
+
+		message := &domain.Message{ID: 2, Status: domain.MessageStatusPending, MaxRetries: 3}
+
+		mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+		mockRepo.On("SelectUnsentForUpdate", ctx, 10).Return([]*domain.Message{message}, nil)
+		expectLocked(mockRepo, message)
+		mockWebhook.On("SendMessage", mock.Anything, message).Return("", nil)
+		mockRepo.On("MarkSent", mock.Anything, int64(2)).Return(nil)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "MarkSentWithReference", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_PausedHosts(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	createMessage := func(t *testing.T, messageRepo repo.MessageRepository, webhookURL string) *domain.Message {
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: webhookURL,
+		})
+		require.NoError(t, err)
+		return message
+	}
+
+	t.Run("dispatch skips paused hosts and leaves messages pending", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+		paused := createMessage(t, messageRepo, "https://API.Partner.com/hook")
+		active := createMessage(t, messageRepo, "https://other.example.com/hook")
+
+		_, err := service.PauseHost(ctx, " api.partner.com ", 0)
+		require.NoError(t, err)
+
+		mockWebhook.On("SendMessage", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool { return m.ID == active.ID })).Return("", nil).Once()
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		stored, err := messageRepo.GetByID(ctx, paused.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusPending, stored.Status)
+		assert.Equal(t, 0, stored.RetryCount)
+
+		// Once resumed the held message goes out on the next run
+		require.NoError(t, service.ResumeHost(ctx, "api.partner.com"))
+		mockWebhook.On("SendMessage", mock.Anything, mock.MatchedBy(func(m *domain.Message) bool { return m.ID == paused.ID })).Return("", nil).Once()
+
+		processed, err = service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("retries skip paused hosts", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+		message := createMessage(t, messageRepo, "https://api.partner.com/hook")
+		require.NoError(t, messageRepo.MarkFailed(ctx, message.ID, "timeout", 0))
+
+		_, err := service.PauseHost(ctx, "api.partner.com", time.Hour)
+		require.NoError(t, err)
+
+		retried, err := service.RetryFailedMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, retried)
+
+		stored, err := messageRepo.GetByID(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, stored.Status)
+		assert.Equal(t, 1, stored.RetryCount)
+		mockWebhook.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("pause store error delivers the batch", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger,
+			WithHostPauseStore(failingHostPauseStore{}))
+
+		createMessage(t, messageRepo, "https://api.partner.com/hook")
+		mockWebhook.On("SendMessage", mock.Anything, mock.AnythingOfType("*domain.Message")).Return("", nil).Once()
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+	})
+
+	t.Run("pause and resume validation", func(t *testing.T) {
+		service := NewMessageService(new(MockMessageRepository), logger)
+
+		_, err := service.PauseHost(ctx, "", 0)
+		assert.EqualError(t, err, "host is required")
+
+		_, err = service.PauseHost(ctx, "https://api.partner.com/hook", 0)
+		assert.EqualError(t, err, "host must be a hostname such as api.example.com, not a URL")
+
+		_, err = service.PauseHost(ctx, "api.partner.com", -time.Minute)
+		assert.EqualError(t, err, "duration must be positive")
+
+		err = service.ResumeHost(ctx, "api.partner.com")
+		assert.ErrorIs(t, err, domain.ErrHostNotPaused)
+	})
+
+	t.Run("lists paused hosts", func(t *testing.T) {
+		service := NewMessageService(new(MockMessageRepository), logger)
+
+		_, err := service.PauseHost(ctx, "b.example.com", time.Hour)
+		require.NoError(t, err)
+		_, err = service.PauseHost(ctx, "A.example.com", 0)
+		require.NoError(t, err)
+
+		hosts, err := service.GetPausedHosts(ctx)
+		require.NoError(t, err)
+		require.Len(t, hosts, 2)
+		assert.Equal(t, "a.example.com", hosts[0].Host)
+		assert.Nil(t, hosts[0].Until)
+		assert.Equal(t, "b.example.com", hosts[1].Host)
+		assert.NotNil(t, hosts[1].Until)
+	})
+}
+
+// failingHostPauseStore is a HostPauseStore whose backend is unavailable
+type failingHostPauseStore struct{}
+
+func TestMessageService_Suppressions(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	createMessage := func(t *testing.T, messageRepo repo.MessageRepository, recipient, webhookURL string) *domain.Message {
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  recipient,
+			Content:    "Test message",
+			WebhookURL: webhookURL,
+		})
+		require.NoError(t, err)
+		return message
+	}
+	matchesID := func(id int64) interface{} {
+		return mock.MatchedBy(func(m *domain.Message) bool { return m.ID == id })
+	}
+
+	t.Run("suppressed recipients are skipped and resumed after removal", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+		suppressed := createMessage(t, messageRepo, "Busy@Example.com", "https://api.partner.com/hook")
+		active := createMessage(t, messageRepo, "other@example.com", "https://api.partner.com/hook")
+
+		suppression, err := service.Suppress(ctx, " busy@example.com ", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "busy@example.com", suppression.Key)
+		assert.Equal(t, domain.SuppressionRecipient, suppression.Kind)
+
+		mockWebhook.On("SendMessage", mock.Anything, matchesID(active.ID)).Return("", nil).Once()
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		stored, err := messageRepo.GetByID(ctx, suppressed.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusPending, stored.Status)
+		assert.Equal(t, 0, stored.RetryCount)
+
+		// Once removed the held message goes out on the next run
+		require.NoError(t, service.Unsuppress(ctx, "busy@example.com"))
+		mockWebhook.On("SendMessage", mock.Anything, matchesID(suppressed.ID)).Return("", nil).Once()
+
+		processed, err = service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		mockWebhook.AssertExpectations(t)
+		assert.ErrorIs(t, service.Unsuppress(ctx, "busy@example.com"), domain.ErrNotSuppressed)
+	})
+
+	t.Run("suppressed hosts are paused", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		mockWebhook := new(MockWebhookClient)
+		service := NewMessageServiceWithWebhook(messageRepo, mockWebhook, logger)
+
+		message := createMessage(t, messageRepo, "test@example.com", "https://api.partner.com/hook")
+
+		suppression, err := service.Suppress(ctx, "API.Partner.com", time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "api.partner.com", suppression.Key)
+		assert.Equal(t, domain.SuppressionHost, suppression.Kind)
+		require.NotNil(t, suppression.Until)
+
+		hosts, err := service.GetPausedHosts(ctx)
+		require.NoError(t, err)
+		require.Len(t, hosts, 1)
+		assert.Equal(t, "api.partner.com", hosts[0].Host)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, processed)
+
+		require.NoError(t, service.Unsuppress(ctx, "api.partner.com"))
+		mockWebhook.On("SendMessage", mock.Anything, matchesID(message.ID)).Return("", nil).Once()
+
+		processed, err = service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		mockWebhook.AssertExpectations(t)
+	})
+
+	t.Run("list holds recipients then hosts", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger)
+
+		_, err := service.Suppress(ctx, "api.partner.com", 0)
+		require.NoError(t, err)
+		_, err = service.Suppress(ctx, "+905551112233", 0)
+		require.NoError(t, err)
+
+		suppressions, err := service.GetSuppressions(ctx)
+		require.NoError(t, err)
+		require.Len(t, suppressions, 2)
+		assert.Equal(t, "+905551112233", suppressions[0].Key)
+		assert.Equal(t, domain.SuppressionRecipient, suppressions[0].Kind)
+		assert.Equal(t, "api.partner.com", suppressions[1].Key)
+		assert.Equal(t, domain.SuppressionHost, suppressions[1].Kind)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		service := NewMessageService(new(MockMessageRepository), logger)
+
+		_, err := service.Suppress(ctx, " ", 0)
+		assert.EqualError(t, err, "key is required")
+
+		_, err = service.Suppress(ctx, "@example.com", 0)
+		assert.EqualError(t, err, "key must be an email address, a phone number in E.164 format or a webhook host")
+
+		_, err = service.Suppress(ctx, "https://api.partner.com/hook", 0)
+		assert.EqualError(t, err, "host must be a hostname such as api.example.com, not a URL")
+
+		_, err = service.Suppress(ctx, "user@example.com", -time.Minute)
+		assert.EqualError(t, err, "duration must be positive")
+	})
+}
+
+func (failingHostPauseStore) PauseHost(ctx context.Context, host string, duration time.Duration) (*domain.PausedHost, error) {
+	return nil, errors.New("redis unavailable")
+}
+
+func (failingHostPauseStore) ResumeHost(ctx context.Context, host string) error {
+	return errors.New("redis unavailable")
+}
+
+func (failingHostPauseStore) ListPausedHosts(ctx context.Context) ([]*domain.PausedHost, error) {
+	return nil, errors.New("redis unavailable")
+}
+
+func TestMessageService_ArchiveOldMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("disabled without archive age", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{}))
+
+		archived, err := service.ArchiveOldMessages(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, archived)
+		mockRepo.AssertNotCalled(t, "ArchiveOlderThan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("archives messages sent before the cutoff", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{ArchiveAfter: 720 * time.Hour}))
+
+		before := time.Now().Add(-720 * time.Hour)
+		mockRepo.On("ArchiveOlderThan", ctx, mock.MatchedBy(func(cutoff time.Time) bool {
+			return !cutoff.Before(before) && cutoff.Before(time.Now().Add(-719*time.Hour))
+		})).Return(3, nil).Once()
+
+		archived, err := service.ArchiveOldMessages(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, archived)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{ArchiveAfter: time.Hour}))
+
+		mockRepo.On("ArchiveOlderThan", ctx, mock.AnythingOfType("time.Time")).Return(0, errors.New("database error")).Once()
+
+		archived, err := service.ArchiveOldMessages(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 0, archived)
+		assert.Contains(t, err.Error(), "failed to archive messages")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMessageService_PurgeOldMessages(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("disabled without a retention", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{}))
+
+		deleted, err := service.PurgeOldMessages(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+		mockRepo.AssertNotCalled(t, "DeleteOlderThan", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deletes sent and dead-lettered messages before the cutoff", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MessageRetention: 720 * time.Hour}))
+
+		before := time.Now().Add(-720 * time.Hour)
+		isCutoff := mock.MatchedBy(func(cutoff time.Time) bool {
+			return !cutoff.Before(before) && cutoff.Before(time.Now().Add(-719*time.Hour))
+		})
+		mockRepo.On("DeleteOlderThan", ctx, domain.MessageStatusSent, isCutoff).Return(4, nil).Once()
+		mockRepo.On("DeleteOlderThan", ctx, domain.MessageStatusDeadLetter, isCutoff).Return(2, nil).Once()
+
+		deleted, err := service.PurgeOldMessages(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 6, deleted)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{MessageRetention: time.Hour}))
+
+		mockRepo.On("DeleteOlderThan", ctx, domain.MessageStatusSent, mock.AnythingOfType("time.Time")).
+			Return(3, errors.New("database error")).Once()
+
+		deleted, err := service.PurgeOldMessages(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 3, deleted)
+		assert.Contains(t, err.Error(), "failed to delete old sent messages")
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "DeleteOlderThan", ctx, domain.MessageStatusDeadLetter, mock.Anything)
+	})
+}
+
+// fakeRecipientCounter is an in-memory RecipientCounter that can be made to fail
+type fakeRecipientCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+	err    error
+}
+
+func newFakeRecipientCounter() *fakeRecipientCounter {
+	return &fakeRecipientCounter{counts: make(map[string]int64)}
+}
+
+func (f *fakeRecipientCounter) IncrementRecipientDailyCount(ctx context.Context, recipient string, day time.Time, delta int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	key := day.Format("2006-01-02") + ":" + recipient
+	f.counts[key] += delta
+	return f.counts[key], nil
+}
+
+func (f *fakeRecipientCounter) count(recipient string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[time.Now().UTC().Format("2006-01-02")+":"+recipient]
+}
+
+func TestMessageService_RecipientDailyLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	request := func(recipient string) *domain.CreateMessageRequest {
+		return &domain.CreateMessageRequest{
+			Recipient:  recipient,
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+		}
+	}
+	now := time.Now().UTC()
+	nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	assertLimited := func(t *testing.T, err error, limit int) {
+		t.Helper()
+		var limitErr *domain.RecipientLimitError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, limit, limitErr.Limit)
+		assert.Equal(t, nextMidnight, limitErr.ResetAt)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger, WithConfig(&config.Config{}))
+
+		for i := 0; i < 5; i++ {
+			_, err := service.CreateMessage(ctx, request("test@example.com"))
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("counts stored messages without a counter", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 2}))
+
+		for i := 0; i < 2; i++ {
+			_, err := service.CreateMessage(ctx, request("test@example.com"))
+			require.NoError(t, err)
+		}
+
+		_, err := service.CreateMessage(ctx, request("test@example.com"))
+		assertLimited(t, err, 2)
+
+		_, err = service.CreateMessage(ctx, request("other@example.com"))
+		assert.NoError(t, err, "other recipients are not affected")
+	})
+
+	t.Run("counter is seeded from stored messages", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		_, err := messageRepo.Create(ctx, request("test@example.com"))
+		require.NoError(t, err)
+
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(messageRepo, logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 2}), WithRecipientCounter(counter))
+
+		_, err = service.CreateMessage(ctx, request("test@example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), counter.count("test@example.com"))
+
+		_, err = service.CreateMessage(ctx, request("test@example.com"))
+		assertLimited(t, err, 2)
+		assert.Equal(t, int64(2), counter.count("test@example.com"), "rejected create must not stay counted")
+	})
+
+	t.Run("failing counter falls back to the repository", func(t *testing.T) {
+		counter := newFakeRecipientCounter()
+		counter.err = errors.New("redis unavailable")
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		_, err := service.CreateMessage(ctx, request("test@example.com"))
+		require.NoError(t, err)
+
+		_, err = service.CreateMessage(ctx, request("test@example.com"))
+		assertLimited(t, err, 1)
+	})
+
+	t.Run("failed create releases its slot", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockRepo.On("CountByRecipientSince", ctx, "test@example.com", mock.AnythingOfType("time.Time")).Return(0, nil)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.CreateMessageRequest")).Return(nil, errors.New("database error"))
+
+		counter := newFakeRecipientCounter()
+		service := NewMessageService(mockRepo, logger,
+			WithConfig(&config.Config{RecipientDailyLimit: 1}), WithRecipientCounter(counter))
+
+		_, err := service.CreateMessage(ctx, request("test@example.com"))
+		require.Error(t, err)
+		assert.Equal(t, int64(0), counter.count("test@example.com"))
+	})
+
+	t.Run("count error rejects the create", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockRepo.On("CountByRecipientSince", ctx, "test@example.com", mock.AnythingOfType("time.Time")).Return(0, errors.New("database error"))
+
+		service := NewMessageService(mockRepo, logger, WithConfig(&config.Config{RecipientDailyLimit: 1}))
+
+		_, err := service.CreateMessage(ctx, request("test@example.com"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to check recipient daily limit")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_ClaimAckNack(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	setup := func(t *testing.T, count int) (MessageService, []*domain.Message) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		var created []*domain.Message
+		for i := 0; i < count; i++ {
+			message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+				Recipient:  "test@example.com",
+				Content:    fmt.Sprintf("Message %d", i),
+				WebhookURL: "https://example.com/webhook",
+				MaxRetries: 2,
+			})
+			require.NoError(t, err)
+			created = append(created, message)
+		}
+
+		claimed, err := service.ClaimMessages(ctx, count)
+		require.NoError(t, err)
+		require.Len(t, claimed, count)
+		return service, created
+	}
+
+	t.Run("claimed messages are not claimed twice", func(t *testing.T) {
+		service, created := setup(t, 2)
+
+		message, err := service.GetMessage(ctx, created[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSending, message.Status)
+
+		claimed, err := service.ClaimMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("ack marks sent with the provider reference", func(t *testing.T) {
+		service, created := setup(t, 1)
+
+		message, err := service.AckMessage(ctx, created[0].ID, "provider-123")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, message.Status)
+		require.NotNil(t, message.ProviderMessageID)
+		assert.Equal(t, "provider-123", *message.ProviderMessageID)
+
+		_, err = service.AckMessage(ctx, created[0].ID, "")
+		assert.ErrorIs(t, err, domain.ErrMessageNotClaimed)
+	})
+
+	t.Run("nack schedules a retry then dead-letters", func(t *testing.T) {
+		service, created := setup(t, 1)
+
+		message, err := service.NackMessage(ctx, created[0].ID, "receiver returned 503")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, message.Status)
+		assert.Equal(t, 1, message.RetryCount)
+		require.NotNil(t, message.ErrorMessage)
+		assert.Equal(t, "receiver returned 503", *message.ErrorMessage)
+
+		claimed, err := service.ClaimMessages(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1, "a due retry is claimable")
+
+		message, err = service.NackMessage(ctx, created[0].ID, "receiver returned 503")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusDeadLetter, message.Status)
+	})
+
+	t.Run("unclaimed and missing messages", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		pending, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+		})
+		require.NoError(t, err)
+
+		_, err = service.AckMessage(ctx, pending.ID, "")
+		assert.ErrorIs(t, err, domain.ErrMessageNotClaimed)
+		_, err = service.NackMessage(ctx, pending.ID, "failed")
+		assert.ErrorIs(t, err, domain.ErrMessageNotClaimed)
+
+		_, err = service.AckMessage(ctx, 999, "")
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+}
+
+func TestMessageService_RecordDeliveryStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (MessageService, repo.MessageRepository, *domain.Message) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger)
+
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 2,
+		})
+		require.NoError(t, err)
+		return service, messageRepo, message
+	}
+
+	t.Run("sent report marks an in-flight message sent", func(t *testing.T) {
+		service, _, created := setup(t)
+		_, err := service.ClaimMessages(ctx, 1)
+		require.NoError(t, err)
+
+		message, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusSent, "provider-123", "")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, message.Status)
+		require.NotNil(t, message.ProviderMessageID)
+		assert.Equal(t, "provider-123", *message.ProviderMessageID)
+
+		// A redelivered report changes nothing
+		again, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusSent, "provider-456", "")
+		require.NoError(t, err)
+		assert.Equal(t, "provider-123", *again.ProviderMessageID)
+	})
+
+	t.Run("failed report retries a sent message", func(t *testing.T) {
+		service, messageRepo, created := setup(t)
+		require.NoError(t, messageRepo.MarkSent(ctx, created.ID))
+
+		message, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusFailed, "", "recipient unreachable")
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, message.Status)
+		assert.Equal(t, 1, message.RetryCount)
+		require.NotNil(t, message.ErrorMessage)
+		assert.Equal(t, "recipient unreachable", *message.ErrorMessage)
+
+		// Already recorded as failed, so the retry budget is not spent twice
+		again, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusFailed, "", "recipient unreachable")
+		require.NoError(t, err)
+		assert.Equal(t, 1, again.RetryCount)
+	})
+
+	t.Run("messages never handed over are rejected", func(t *testing.T) {
+		service, _, created := setup(t)
+
+		_, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusSent, "", "")
+		assert.ErrorIs(t, err, domain.ErrMessageNotSent)
+
+		_, err = service.RecordDeliveryStatus(ctx, 999, domain.MessageStatusSent, "", "")
+		assert.ErrorIs(t, err, domain.ErrMessageNotFound)
+	})
+
+	t.Run("unsupported status", func(t *testing.T) {
+		service, _, created := setup(t)
+
+		_, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusPending, "", "")
+		var validationErr *domain.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("status is checked under the row lock", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		service := NewMessageService(mockRepo, logger)
+
+		// Another report failed the message after this one was received
+		failed := &domain.Message{ID: 1, Status: domain.MessageStatusFailed, RetryCount: 1, MaxRetries: 3}
+		mockRepo.On("LockForSend", mock.Anything, int64(1)).Return(failed, nil).Once()
+
+		message, err := service.RecordDeliveryStatus(ctx, 1, domain.MessageStatusFailed, "", "recipient unreachable")
+		require.NoError(t, err)
+		assert.Equal(t, 1, message.RetryCount)
+
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent failed reports fail the message once", func(t *testing.T) {
+		messageRepo := &serialTxRepository{MessageRepository: repo.NewInMemoryMessageRepository()}
+		sink := &recordingSink{}
+		bus := events.NewBus(logger, nil, sink)
+		service := NewMessageService(messageRepo, logger, WithEventBus(bus))
+
+		created, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: "https://example.com/webhook",
+			MaxRetries: 5,
+		})
+		require.NoError(t, err)
+		require.NoError(t, messageRepo.MarkSent(ctx, created.ID))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := service.RecordDeliveryStatus(ctx, created.ID, domain.MessageStatusFailed, "", "recipient unreachable")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		bus.Close()
+
+		message, err := messageRepo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, message.Status)
+		assert.Equal(t, 1, message.RetryCount)
+
+		var failures int
+		for _, event := range sink.events {
+			if event.Type == events.EventMessageFailed {
+				failures++
+			}
+		}
+		assert.Equal(t, 1, failures)
+	})
+}
+
+// serialTxRepository runs one transaction at a time, as concurrent
+// transactions locking the same row would. Reads outside a transaction return
+// late, so callers that act on a status read there race on it.
+type serialTxRepository struct {
+	repo.MessageRepository
+
+	mu sync.Mutex
+}
+
+func (r *serialTxRepository) WithTx(ctx context.Context, fn func(txRepo repo.MessageRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fn(r.MessageRepository)
+}
+
+func (r *serialTxRepository) GetByID(ctx context.Context, id int64) (*domain.Message, error) {
+	message, err := r.MessageRepository.GetByID(ctx, id)
+	time.Sleep(5 * time.Millisecond)
+	return message, err
+}
+
+// fakeDeliverer records the messages it is asked to deliver
+type fakeDeliverer struct {
+	mu        sync.Mutex
+	delivered []int64
+	ref       string
+	err       error
+}
+
+func (f *fakeDeliverer) Deliver(ctx context.Context, message *domain.Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delivered = append(f.delivered, message.ID)
+	return f.ref, f.err
+}
+
+func TestMessageService_Channels(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	t.Run("delivers through the channel's deliverer", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		sms := &fakeDeliverer{ref: "sms-123"}
+		service := NewMessageService(messageRepo, logger, WithDeliverer(domain.ChannelSMS, sms))
+
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient: "+905551234567",
+			Content:   "Hello",
+			Channel:   domain.ChannelSMS,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.ChannelSMS, message.Channel)
+
+		processed, err := service.ProcessUnsentMessages(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, []int64{message.ID}, sms.delivered)
+
+		sent, err := messageRepo.GetByID(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusSent, sent.Status)
+		require.NotNil(t, sent.ProviderMessageID)
+		assert.Equal(t, "sms-123", *sent.ProviderMessageID)
+	})
+
+	t.Run("a channel without a deliverer fails the message", func(t *testing.T) {
+		messageRepo := repo.NewInMemoryMessageRepository()
+		service := NewMessageService(messageRepo, logger).(*messageService)
+
+		message, err := messageRepo.Create(ctx, &domain.CreateMessageRequest{
+			Recipient:  "user@example.com",
+			Content:    "Hello",
+			Channel:    domain.ChannelEmail,
+			MaxRetries: 3,
+		})
+		require.NoError(t, err)
+
+		err = service.processMessage(ctx, messageRepo, message)
+		assert.ErrorIs(t, err, domain.ErrUnknownChannel)
+
+		failed, err := messageRepo.GetByID(ctx, message.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.MessageStatusFailed, failed.Status)
+	})
+
+	t.Run("validates the channel and its recipient", func(t *testing.T) {
+		service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+			WithDeliverer(domain.ChannelSMS, &fakeDeliverer{}))
+
+		tests := []struct {
+			name string
+			req  *domain.CreateMessageRequest
+			want domain.FieldError
+		}{
+			{
+				name: "unknown channel",
+				req:  &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello", Channel: "fax"},
+				want: domain.FieldError{Field: "channel", Message: "channel must be one of webhook, email, sms"},
+			},
+			{
+				name: "channel without a deliverer",
+				req:  &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello", Channel: domain.ChannelEmail},
+				want: domain.FieldError{Field: "channel", Message: "channel email is not available"},
+			},
+			{
+				name: "sms recipient is not a phone number",
+				req:  &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello", Channel: domain.ChannelSMS},
+				want: domain.FieldError{Field: "recipient", Message: "recipient must be a phone number in E.164 format"},
+			},
+			{
+				name: "webhook channel needs a URL",
+				req:  &domain.CreateMessageRequest{Recipient: "user@example.com", Content: "Hello", Channel: domain.ChannelWebhook},
+				want: domain.FieldError{Field: "webhook_url", Message: "webhook URL is required"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateMessage(ctx, tt.req)
+				var validationErr *domain.ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, []domain.FieldError{tt.want}, validationErr.Fields)
+			})
+		}
+	})
+}
+
+func TestMessageService_PayloadTemplate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+	service := NewMessageService(repo.NewInMemoryMessageRepository(), logger,
+		WithDeliverer(domain.ChannelSMS, &fakeDeliverer{}))
+
+	t.Run("stores a valid template", func(t *testing.T) {
+		template := `{"to":{{json .Recipient}},"text":{{json .Content}}}`
+		message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+			Recipient:       "user@example.com",
+			Content:         "Hello",
+			WebhookURL:      "https://example.com/webhook",
+			PayloadTemplate: template,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, template, message.PayloadTemplate)
+	})
+
+	tests := []struct {
+		name     string
+		channel  domain.Channel
+		template string
+		want     string
+	}{
+		{"does not parse", domain.ChannelWebhook, `{"text":{{json .Content}`, "invalid payload template"},
+		{"unknown field", domain.ChannelWebhook, `{"text":{{json .Body}}}`, "invalid payload template"},
+		{"output is not JSON", domain.ChannelWebhook, `{"text":{{.Content}}}`, "not valid JSON"},
+		{"not a webhook message", domain.ChannelSMS, `{"text":{{json .Content}}}`, "only supported for the webhook channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &domain.CreateMessageRequest{
+				Recipient:       "user@example.com",
+				Content:         "Hello",
+				WebhookURL:      "https://example.com/webhook",
+				Channel:         tt.channel,
+				PayloadTemplate: tt.template,
+			}
+			if tt.channel == domain.ChannelSMS {
+				req.Recipient = "+905551234567"
+			}
+
+			_, err := service.CreateMessage(ctx, req)
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Len(t, validationErr.Fields, 1)
+			assert.Equal(t, "payload_template", validationErr.Fields[0].Field)
+			assert.Contains(t, validationErr.Fields[0].Message, tt.want)
+		})
+	}
+}
+
+// recordingSink collects the lifecycle events published by the service
+type recordingSink struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Handle(ctx context.Context, event events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func TestMessageService_PublishesLifecycleEvents(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	messageRepo := repo.NewInMemoryMessageRepository()
+	sink := &recordingSink{}
+	bus := events.NewBus(logger, nil, sink)
+	service := NewMessageService(messageRepo, logger, WithEventBus(bus))
+
+	message, err := service.CreateMessage(ctx, &domain.CreateMessageRequest{
+		Recipient:  "user@example.com",
+		Content:    "Hello",
+		WebhookURL: "https://example.com/webhook",
+	})
+	require.NoError(t, err)
+	_, err = service.ProcessUnsentMessages(ctx, 10)
+	require.NoError(t, err)
+	bus.Close()
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, events.EventMessageCreated, sink.events[0].Type)
+	assert.Equal(t, events.EventMessageSent, sink.events[1].Type)
+	for _, event := range sink.events {
+		assert.Equal(t, message.ID, event.MessageID)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// PayloadTransformer renders the webhook request body for a payload, letting a
+// receiver get a differently shaped body without changing the stored message
+type PayloadTransformer interface {
+	Transform(payload WebhookPayload) ([]byte, error)
+}
+
+// PayloadTransformerFunc adapts a function to the PayloadTransformer interface
+type PayloadTransformerFunc func(payload WebhookPayload) ([]byte, error)
+
+// Transform calls f(payload)
+func (f PayloadTransformerFunc) Transform(payload WebhookPayload) ([]byte, error) {
+	return f(payload)
+}
+
+// JSONTransformer sends the payload as-is, marshaled to JSON
+var JSONTransformer PayloadTransformer = PayloadTransformerFunc(func(payload WebhookPayload) ([]byte, error) {
+	return json.Marshal(payload)
+})
+
+// templateFuncs are available to payload templates. json renders a value as a
+// JSON literal, which is how string fields must be embedded.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+// samplePayload is rendered when a template is built to catch templates that
+// fail at execution time or do not produce valid JSON. Its content includes
+// characters that break JSON unless embedded with the json function.
+var samplePayload = WebhookPayload{
+	MessageID: 1,
+	Recipient: "user@example.com",
+	Content:   "Sample \"quoted\" content\nwith a newline",
+	Status:    "pending",
+	CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	SentAt:    time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC),
+}
+
+// TemplateTransformer renders the request body from a text/template executed
+// against the WebhookPayload. The output must be valid JSON.
+type TemplateTransformer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateTransformer parses text and verifies it renders valid JSON for a
+// sample payload, so a broken template is reported at startup rather than on
+// the first delivery
+func NewTemplateTransformer(text string) (*TemplateTransformer, error) {
+	tmpl, err := template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	t := &TemplateTransformer{tmpl: tmpl}
+	if _, err := t.Transform(samplePayload); err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	return t, nil
+}
+
+// Transform executes the template against payload
+func (t *TemplateTransformer) Transform(payload WebhookPayload) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, payload); err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.New("template output is not valid JSON; embed string fields with the json function, e.g. {{json .Content}}")
+	}
+
+	return buf.Bytes(), nil
+}
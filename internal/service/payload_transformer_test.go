@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTransformer(t *testing.T) {
+	body, err := JSONTransformer.Transform(samplePayload)
+	require.NoError(t, err)
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, samplePayload.Content, payload.Content)
+}
+
+func TestTemplateTransformer(t *testing.T) {
+	transformer, err := NewTemplateTransformer(
+		`{"envelope":{"id":{{.MessageID}},"to":{{json .Recipient}},"text":{{json .Content}},` +
+			`"status":{{json (upper .Status)}},"created":{{json (rfc3339 .CreatedAt)}},"length":{{len .Content}}}}`)
+	require.NoError(t, err)
+
+	body, err := transformer.Transform(WebhookPayload{
+		MessageID: 7,
+		Recipient: "user@example.com",
+		Content:   `say "hi"`,
+		Status:    "pending",
+		CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"envelope":{"id":7,"to":"user@example.com","text":"say \"hi\"",`+
+		`"status":"PENDING","created":"2024-03-01T12:00:00Z","length":8}}`, string(body))
+}
+
+func TestNewTemplateTransformer_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"parse error", `{"text":{{json .Content}`},
+		{"unknown field", `{"text":{{json .Body}}}`},
+		{"unescaped string", `{"text":"{{.Content}}"}`},
+		{"not json", `text={{.Content}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer, err := NewTemplateTransformer(tt.template)
+			assert.Error(t, err)
+			assert.Nil(t, transformer)
+			assert.Contains(t, err.Error(), "invalid payload template")
+		})
+	}
+}
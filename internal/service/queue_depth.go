@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/internal/repo"
+	"github.com/insider/insider-messaging/pkg/metrics"
+)
+
+// QueueDepthReporter keeps the messages-in-queue gauge at the number of
+// pending messages. It runs independently of the scheduler so the backlog is
+// still visible while delivery is stopped.
+type QueueDepthReporter struct {
+	repo     repo.MessageRepository
+	metrics  *metrics.Metrics
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewQueueDepthReporter creates a reporter that refreshes the gauge every interval
+func NewQueueDepthReporter(messageRepo repo.MessageRepository, m *metrics.Metrics, interval time.Duration, logger *slog.Logger) *QueueDepthReporter {
+	return &QueueDepthReporter{
+		repo:     messageRepo,
+		metrics:  m,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run updates the gauge immediately and then every interval until ctx is
+// cancelled. A failed count is logged and leaves the last value in place.
+func (r *QueueDepthReporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.report(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to update queue depth", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report sets the gauge to the current pending count
+func (r *QueueDepthReporter) report(ctx context.Context) error {
+	counts, err := r.repo.CountByStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count messages by status: %w", err)
+	}
+
+	r.metrics.SetMessagesInQueue(float64(counts[domain.MessageStatusPending]))
+	return nil
+}
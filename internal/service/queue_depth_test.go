@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/insider/insider-messaging/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQueueDepthReporter_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("sets the gauge to the pending count until cancelled", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+
+		mockRepo.On("CountByStatus", mock.Anything).Return(map[domain.MessageStatus]int{
+			domain.MessageStatusPending: 7,
+			domain.MessageStatusSent:    30,
+			domain.MessageStatusFailed:  2,
+		}, nil).Once()
+		mockRepo.On("CountByStatus", mock.Anything).Return(map[domain.MessageStatus]int{
+			domain.MessageStatusPending: 3,
+		}, nil)
+
+		reporter := NewQueueDepthReporter(mockRepo, m, 10*time.Millisecond, logger)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			reporter.Run(ctx)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool {
+			return testutil.ToFloat64(m.MessagesInQueue) == 3
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("reporter did not stop after cancellation")
+		}
+	})
+
+	t.Run("keeps the last value when counting fails", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		m := metrics.NewWithRegistry(prometheus.NewRegistry())
+		m.SetMessagesInQueue(5)
+
+		mockRepo.On("CountByStatus", mock.Anything).Return(nil, assert.AnError)
+
+		reporter := NewQueueDepthReporter(mockRepo, m, time.Hour, logger)
+		assert.Error(t, reporter.report(context.Background()))
+		assert.Equal(t, 5.0, testutil.ToFloat64(m.MessagesInQueue))
+	})
+}
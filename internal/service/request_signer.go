@@ -0,0 +1,78 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers set on signed webhook requests
+const (
+	SignatureHeader          = "X-Signature-256"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// RequestSigner mutates an outgoing webhook request before it is sent, typically
+// to add authentication headers. body is the exact request body being sent.
+type RequestSigner interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// RequestSignerFunc adapts a function to the RequestSigner interface
+type RequestSignerFunc func(req *http.Request, body []byte) error
+
+// Sign calls f(req, body)
+func (f RequestSignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// NoopSigner leaves requests unchanged
+var NoopSigner RequestSigner = RequestSignerFunc(func(*http.Request, []byte) error {
+	return nil
+})
+
+// BearerSigner authenticates requests with an OAuth-style bearer token
+type BearerSigner struct {
+	Token string
+}
+
+// Sign sets the Authorization header
+func (s BearerSigner) Sign(req *http.Request, _ []byte) error {
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	return nil
+}
+
+// HMACSigner signs the request body with HMAC-SHA256 and adds the signing time
+type HMACSigner struct {
+	Secret string
+}
+
+// Sign sets the X-Signature-256 and X-Signature-Timestamp headers
+func (s HMACSigner) Sign(req *http.Request, body []byte) error {
+	req.Header.Set(SignatureHeader, SignPayload(s.Secret, body))
+	req.Header.Set(SignatureTimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+	return nil
+}
+
+// SignPayload returns the X-Signature-256 header value for body: the hex-encoded
+// HMAC-SHA256 of the body keyed with secret, prefixed with "sha256="
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ChainSigners returns a signer that applies each signer in order, stopping at the first error
+func ChainSigners(signers ...RequestSigner) RequestSigner {
+	return RequestSignerFunc(func(req *http.Request, body []byte) error {
+		for _, signer := range signers {
+			if err := signer.Sign(req, body); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignerTestRequest(t *testing.T) *http.Request {
+	req, err := http.NewRequest("POST", "https://example.com/webhook", nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestNoopSigner(t *testing.T) {
+	req := newSignerTestRequest(t)
+
+	require.NoError(t, NoopSigner.Sign(req, []byte(`{}`)))
+	assert.Empty(t, req.Header)
+}
+
+func TestBearerSigner(t *testing.T) {
+	req := newSignerTestRequest(t)
+
+	require.NoError(t, BearerSigner{Token: "token-123"}.Sign(req, []byte(`{}`)))
+	assert.Equal(t, "Bearer token-123", req.Header.Get("Authorization"))
+}
+
+func TestHMACSigner(t *testing.T) {
+	req := newSignerTestRequest(t)
+	body := []byte(`{"message_id":1}`)
+
+	require.NoError(t, HMACSigner{Secret: "s3cret"}.Sign(req, body))
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get(SignatureHeader))
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(SignatureTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), timestamp, 5)
+}
+
+func TestChainSigners(t *testing.T) {
+	t.Run("applies every signer", func(t *testing.T) {
+		req := newSignerTestRequest(t)
+
+		signer := ChainSigners(BearerSigner{Token: "token-123"}, HMACSigner{Secret: "s3cret"})
+		require.NoError(t, signer.Sign(req, []byte(`{}`)))
+
+		assert.Equal(t, "Bearer token-123", req.Header.Get("Authorization"))
+		assert.NotEmpty(t, req.Header.Get(SignatureHeader))
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		req := newSignerTestRequest(t)
+		signErr := errors.New("credentials expired")
+
+		failing := RequestSignerFunc(func(*http.Request, []byte) error { return signErr })
+		signer := ChainSigners(failing, BearerSigner{Token: "token-123"})
+
+		err := signer.Sign(req, []byte(`{}`))
+		assert.ErrorIs(t, err, signErr)
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+}
@@ -4,28 +4,45 @@ import (
 	"context"
 )
 
+// defaultSchedulerBatchSize is used when no positive batch size is configured
+const defaultSchedulerBatchSize = 10
+
 // SchedulerAdapter adapts MessageService to scheduler.MessageService interface
 type SchedulerAdapter struct {
 	messageService MessageService
+	batchSize      int
 }
 
-// NewSchedulerAdapter creates a new scheduler adapter
-func NewSchedulerAdapter(messageService MessageService) *SchedulerAdapter {
+// NewSchedulerAdapter creates a new scheduler adapter that processes up to
+// batchSize messages per tick
+func NewSchedulerAdapter(messageService MessageService, batchSize int) *SchedulerAdapter {
+	if batchSize <= 0 {
+		batchSize = defaultSchedulerBatchSize
+	}
+
 	return &SchedulerAdapter{
 		messageService: messageService,
+		batchSize:      batchSize,
 	}
 }
 
 // ProcessPendingMessages implements scheduler.MessageService interface
-func (a *SchedulerAdapter) ProcessPendingMessages(ctx context.Context) error {
-	return a.messageService.ProcessPendingMessages(ctx)
+func (a *SchedulerAdapter) ProcessPendingMessages(ctx context.Context) (int, error) {
+	return a.messageService.ProcessUnsentMessages(ctx, a.batchSize)
+}
+
+// ArchiveOldMessages implements scheduler.MessageService interface
+func (a *SchedulerAdapter) ArchiveOldMessages(ctx context.Context) (int, error) {
+	return a.messageService.ArchiveOldMessages(ctx)
+}
+
+// PurgeOldMessages implements scheduler.MessageService interface
+func (a *SchedulerAdapter) PurgeOldMessages(ctx context.Context) (int, error) {
+	return a.messageService.PurgeOldMessages(ctx)
 }
 
 // RetryFailedMessages implements scheduler.MessageService interface
 func (a *SchedulerAdapter) RetryFailedMessages(ctx context.Context) error {
-	// Use a default batch size for scheduler processing
-	const defaultBatchSize = 10
-
-	_, err := a.messageService.RetryFailedMessages(ctx, defaultBatchSize)
+	_, err := a.messageService.RetryFailedMessages(ctx, a.batchSize)
 	return err
 }
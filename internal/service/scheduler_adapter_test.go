@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/insider/insider-messaging/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerAdapter_BatchSize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		batchSize int
+		expected  int
+	}{
+		{name: "configured batch size", batchSize: 2, expected: 2},
+		{name: "zero falls back to default", batchSize: 0, expected: defaultSchedulerBatchSize},
+		{name: "negative falls back to default", batchSize: -5, expected: defaultSchedulerBatchSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			adapter := NewSchedulerAdapter(NewMessageService(mockRepo, logger), tt.batchSize)
+
+			mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+			mockRepo.On("SelectUnsentForUpdate", ctx, tt.expected).Return([]*domain.Message{}, nil).Once()
+			mockRepo.On("GetFailedMessages", ctx, tt.expected).Return([]*domain.Message{}, nil).Once()
+
+			processed, err := adapter.ProcessPendingMessages(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 0, processed)
+			require.NoError(t, adapter.RetryFailedMessages(ctx))
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSchedulerAdapter_PropagatesErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	ctx := context.Background()
+
+	mockRepo := new(MockMessageRepository)
+	adapter := NewSchedulerAdapter(NewMessageService(mockRepo, logger), 2)
+
+	mockRepo.On("ExpireOverdue", ctx).Return(nil, nil)
+	mockRepo.On("SelectUnsentForUpdate", ctx, 2).Return(([]*domain.Message)(nil), assert.AnError)
+	mockRepo.On("GetFailedMessages", ctx, 2).Return(([]*domain.Message)(nil), assert.AnError)
+
+	_, err := adapter.ProcessPendingMessages(ctx)
+	assert.Error(t, err)
+	assert.Error(t, adapter.RetryFailedMessages(ctx))
+}
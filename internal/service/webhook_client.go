@@ -7,23 +7,96 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"time"
+	"unicode/utf8"
 
 	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/insider/insider-messaging/pkg/config"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
 	"github.com/sethvargo/go-retry"
 )
 
 // WebhookClient handles HTTP requests to webhook URLs
 type WebhookClient interface {
-	SendMessage(ctx context.Context, message *domain.Message) error
+	// SendMessage delivers a message and returns the provider's message ID, or ""
+	// when the response did not include one
+	SendMessage(ctx context.Context, message *domain.Message) (string, error)
 }
 
+// attemptObserver is told about every HTTP request a delivery makes: the
+// receiver's status code (0 when no response arrived), how long it took and
+// the error it failed with, nil on success
+type attemptObserver func(statusCode int, duration time.Duration, err error)
+
+// attemptObserverKey keys the attemptObserver carried by a delivery context
+type attemptObserverKey struct{}
+
+// withAttemptObserver returns a copy of ctx whose webhook deliveries report
+// each of their requests to observe
+func withAttemptObserver(ctx context.Context, observe attemptObserver) context.Context {
+	return context.WithValue(ctx, attemptObserverKey{}, observe)
+}
+
+// defaultProviderMessageIDField is the response field read when no field is configured
+const defaultProviderMessageIDField = "messageId"
+
+// defaultMaxResponseBytes caps the response body read when no limit is configured
+const defaultMaxResponseBytes = 64 << 10
+
+// maxErrorBodyBytes caps how much of a failed response's body goes into the
+// delivery error, which is stored with the message and each attempt
+const maxErrorBodyBytes = 1 << 10
+
 type webhookClient struct {
 	httpClient *http.Client
 	logger     *logger.Logger
 	config     *config.Config
+	metrics    *metrics.Metrics // Optional metrics
+
+	signer      RequestSigner            // Default signer for all requests
+	hostSigners map[string]RequestSigner // Per-host overrides, keyed by webhook hostname
+
+	transformer PayloadTransformer // Renders the request body
+
+	hostLimiter *hostLimiter // Caps in-flight requests per host; nil is unlimited
+}
+
+// WebhookClientOption configures optional webhook client dependencies
+type WebhookClientOption func(*webhookClient)
+
+// WithWebhookMetrics records webhook connection metrics on the given Metrics
+func WithWebhookMetrics(m *metrics.Metrics) WebhookClientOption {
+	return func(w *webhookClient) {
+		w.metrics = m
+	}
+}
+
+// WithRequestSigner replaces the default signer derived from configuration
+func WithRequestSigner(signer RequestSigner) WebhookClientOption {
+	return func(w *webhookClient) {
+		w.signer = signer
+	}
+}
+
+// WithPayloadTransformer renders request bodies with transformer instead of
+// sending the payload as-is
+func WithPayloadTransformer(transformer PayloadTransformer) WebhookClientOption {
+	return func(w *webhookClient) {
+		if transformer != nil {
+			w.transformer = transformer
+		}
+	}
+}
+
+// WithHostRequestSigner uses signer instead of the default for webhooks on the
+// given hostname, so partners with different auth schemes can share one client
+func WithHostRequestSigner(host string, signer RequestSigner) WebhookClientOption {
+	return func(w *webhookClient) {
+		w.hostSigners[host] = signer
+	}
 }
 
 // WebhookPayload represents the payload sent to webhook URLs
@@ -37,75 +110,190 @@ type WebhookPayload struct {
 }
 
 // NewWebhookClient creates a new webhook client
-func NewWebhookClient(cfg *config.Config, logger *logger.Logger) WebhookClient {
-	return &webhookClient{
+func NewWebhookClient(cfg *config.Config, logger *logger.Logger, opts ...WebhookClientOption) WebhookClient {
+	w := &webhookClient{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		logger: logger,
-		config: cfg,
+		logger:      logger,
+		config:      cfg,
+		signer:      signerFromConfig(cfg),
+		hostSigners: make(map[string]RequestSigner),
+		transformer: JSONTransformer,
+		hostLimiter: newHostLimiter(cfg.WebhookHostConcurrency),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// signerFromConfig builds the default signer from the configured credentials
+func signerFromConfig(cfg *config.Config) RequestSigner {
+	var signers []RequestSigner
+	if cfg.WebhookAuthToken != "" {
+		signers = append(signers, BearerSigner{Token: cfg.WebhookAuthToken})
+	}
+	if cfg.WebhookSecret != "" {
+		signers = append(signers, HMACSigner{Secret: cfg.WebhookSecret})
+	}
+
+	switch len(signers) {
+	case 0:
+		return NoopSigner
+	case 1:
+		return signers[0]
+	default:
+		return ChainSigners(signers...)
 	}
 }
 
-// SendMessage sends a message to the webhook URL with retry logic
-func (w *webhookClient) SendMessage(ctx context.Context, message *domain.Message) error {
+// signerFor returns the signer for a webhook URL, preferring a per-host override
+func (w *webhookClient) signerFor(webhookURL *url.URL) RequestSigner {
+	if signer, ok := w.hostSigners[webhookURL.Hostname()]; ok {
+		return signer
+	}
+	return w.signer
+}
+
+// SendMessage sends a message to the webhook URL with retry logic and returns the
+// provider's message ID when the receiver reported one
+func (w *webhookClient) SendMessage(ctx context.Context, message *domain.Message) (string, error) {
 	if message.WebhookURL == "" {
 		w.logger.Debug("No webhook URL provided, skipping webhook delivery", "message_id", message.ID)
-		return nil
+		return "", nil
+	}
+
+	content := message.Content
+	if w.config.ContentSanitizeMode != "" && w.config.ContentSanitizeMode != config.ContentSanitizeOff {
+		// Also covers messages stored before sanitization was enabled
+		content = domain.SanitizeContent(content)
 	}
 
 	payload := WebhookPayload{
 		MessageID: message.ID,
 		Recipient: message.Recipient,
-		Content:   message.Content,
+		Content:   content,
 		Status:    string(message.Status),
 		CreatedAt: message.CreatedAt,
 		SentAt:    time.Now(),
 	}
 
-	// Use exponential backoff with jitter for retries
-	backoff := retry.NewExponential(w.config.BackoffMin)
-	backoff = retry.WithMaxRetries(2, backoff) // Allow 2 retries (3 total attempts)
-	backoff = retry.WithMaxDuration(w.config.BackoffMax, backoff)
-	backoff = retry.WithJitter(time.Second, backoff)
+	// A message's own template replaces the client's transformer
+	transformer := w.transformer
+	if message.PayloadTemplate != "" {
+		messageTransformer, err := NewTemplateTransformer(message.PayloadTemplate)
+		if err != nil {
+			return "", fmt.Errorf("failed to build webhook payload: %w", err)
+		}
+		transformer = messageTransformer
+	}
+
+	backoff := newWebhookBackoff(w.config)
+
+	observe, _ := ctx.Value(attemptObserverKey{}).(attemptObserver)
 
-	return retry.Do(ctx, backoff, func(ctx context.Context) error {
-		return w.sendHTTPRequest(ctx, message.WebhookURL, payload)
+	var providerMessageID string
+	err := retry.Do(ctx, backoff, func(ctx context.Context) error {
+		start := time.Now()
+		var (
+			statusCode int
+			err        error
+		)
+		providerMessageID, statusCode, err = w.sendHTTPRequest(ctx, message.WebhookURL, payload, transformer)
+		if observe != nil {
+			observe(statusCode, time.Since(start), err)
+		}
+		return err
 	})
+	if err != nil {
+		return "", err
+	}
+
+	return providerMessageID, nil
+}
+
+// newWebhookBackoff builds the retry schedule for a single delivery:
+// exponential from BackoffMin, bounded by BackoffMax, with up to BackoffJitter
+// of jitter either way
+func newWebhookBackoff(cfg *config.Config) retry.Backoff {
+	backoff := retry.NewExponential(cfg.BackoffMin)
+	backoff = retry.WithMaxRetries(2, backoff) // Allow 2 retries (3 total attempts)
+	backoff = retry.WithMaxDuration(cfg.BackoffMax, backoff)
+	if cfg.BackoffJitter > 0 {
+		backoff = retry.WithJitter(cfg.BackoffJitter, backoff)
+	}
+	return backoff
 }
 
-// sendHTTPRequest performs the actual HTTP request
-func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string, payload WebhookPayload) error {
-	jsonData, err := json.Marshal(payload)
+// sendHTTPRequest performs the actual HTTP request with the body rendered by
+// transformer and returns the provider's message ID from a successful
+// response, if it included one, along with the response status code, 0 when
+// no response arrived
+func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string, payload WebhookPayload, transformer PayloadTransformer) (string, int, error) {
+	jsonData, err := transformer.Transform(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+		return "", 0, fmt.Errorf("failed to build webhook payload: %w", err)
+	}
+
+	if w.metrics != nil {
+		// Track whether the transport reused a keep-alive connection for this request
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				w.metrics.RecordWebhookConnection(info.Reused)
+			},
+		})
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return "", 0, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "insider-messaging/1.0")
+	req.Header.Set("User-Agent", w.userAgent())
+
+	release, err := w.hostLimiter.acquire(ctx, req.URL.Hostname())
+	if err != nil {
+		return "", 0, err
+	}
+	defer release()
+
+	// Sign once the host slot is free, so the signature timestamp is not aged
+	// by the wait and receivers do not reject it as stale
+	if err := w.signerFor(req.URL).Sign(req, jsonData); err != nil {
+		return "", 0, fmt.Errorf("failed to sign webhook request: %w", err)
+	}
 
 	w.logger.Debug("Sending webhook request",
 		"url", webhookURL,
 		"message_id", payload.MessageID,
 		"recipient", payload.Recipient)
 
+	start := time.Now()
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
 		w.logger.Error("HTTP request failed",
 			"url", webhookURL,
 			"error", err,
 			"message_id", payload.MessageID)
-		return retry.RetryableError(fmt.Errorf("HTTP request failed: %w", err))
+		return "", 0, retry.RetryableError(fmt.Errorf("HTTP request failed: %w", err))
 	}
 	defer resp.Body.Close()
 
-	// Read response body for logging
-	body, _ := io.ReadAll(resp.Body)
+	// Read response body for logging, bounded so a misbehaving receiver
+	// cannot exhaust memory
+	body, truncated := w.readResponseBody(resp.Body)
+	if truncated {
+		w.logger.Warn("Webhook response body truncated",
+			"url", webhookURL,
+			"status_code", resp.StatusCode,
+			"message_id", payload.MessageID,
+			"limit_bytes", len(body))
+	}
+	w.checkSlowResponse(req.URL, payload.MessageID, resp.StatusCode, time.Since(start))
 
 	w.logger.Debug("Webhook response received",
 		"url", webhookURL,
@@ -119,7 +307,7 @@ func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string,
 		w.logger.Info("Webhook delivered successfully",
 			"url", webhookURL,
 			"message_id", payload.MessageID)
-		return nil
+		return w.providerMessageID(body), resp.StatusCode, nil
 
 	case resp.StatusCode >= 400 && resp.StatusCode < 500: // 4xx - Non-retryable
 		w.logger.Error("Webhook delivery failed with client error",
@@ -127,7 +315,7 @@ func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string,
 			"status_code", resp.StatusCode,
 			"message_id", payload.MessageID,
 			"response_body", string(body))
-		return fmt.Errorf("webhook delivery failed with status %d: %s", resp.StatusCode, string(body))
+		return "", resp.StatusCode, fmt.Errorf("webhook delivery failed with status %d: %s", resp.StatusCode, errorBody(body))
 
 	case resp.StatusCode >= 500: // 5xx - Retryable
 		w.logger.Warn("Webhook delivery failed with server error, will retry",
@@ -135,7 +323,7 @@ func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string,
 			"status_code", resp.StatusCode,
 			"message_id", payload.MessageID,
 			"response_body", string(body))
-		return retry.RetryableError(fmt.Errorf("webhook delivery failed with status %d: %s", resp.StatusCode, string(body)))
+		return "", resp.StatusCode, retry.RetryableError(fmt.Errorf("webhook delivery failed with status %d: %s", resp.StatusCode, errorBody(body)))
 
 	default:
 		// Other 2xx codes (200, 201, etc.) are also considered success
@@ -144,7 +332,7 @@ func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string,
 				"url", webhookURL,
 				"status_code", resp.StatusCode,
 				"message_id", payload.MessageID)
-			return nil
+			return w.providerMessageID(body), resp.StatusCode, nil
 		}
 
 		// Unexpected status codes
@@ -153,6 +341,91 @@ func (w *webhookClient) sendHTTPRequest(ctx context.Context, webhookURL string,
 			"status_code", resp.StatusCode,
 			"message_id", payload.MessageID,
 			"response_body", string(body))
-		return fmt.Errorf("webhook delivery failed with unexpected status %d: %s", resp.StatusCode, string(body))
+		return "", resp.StatusCode, fmt.Errorf("webhook delivery failed with unexpected status %d: %s", resp.StatusCode, errorBody(body))
+	}
+}
+
+// userAgent returns the configured User-Agent, or the default for this build
+func (w *webhookClient) userAgent() string {
+	if w.config.WebhookUserAgent != "" {
+		return w.config.WebhookUserAgent
+	}
+	return config.DefaultWebhookUserAgent()
+}
+
+// readResponseBody reads up to the configured maximum of body and reports
+// whether more was left unread
+func (w *webhookClient) readResponseBody(body io.Reader) ([]byte, bool) {
+	limit := w.config.WebhookMaxResponseBytes
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
+	}
+
+	// One byte past the limit tells a body of exactly limit bytes from a longer one
+	data, _ := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if len(data) > limit {
+		return data[:limit], true
+	}
+	return data, false
+}
+
+// errorBody renders body for a delivery error, cut to maxErrorBodyBytes on a
+// character boundary so the stored text stays valid UTF-8
+func errorBody(body []byte) string {
+	if len(body) <= maxErrorBodyBytes {
+		return string(body)
+	}
+
+	cut := maxErrorBodyBytes
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return string(body[:cut]) + "... (truncated)"
+}
+
+// checkSlowResponse logs and counts a response that took longer than the
+// configured slow threshold, so degrading receivers show up before they fail
+func (w *webhookClient) checkSlowResponse(webhookURL *url.URL, messageID int64, statusCode int, elapsed time.Duration) {
+	threshold := w.config.WebhookSlowThreshold
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+
+	w.logger.Warn("Slow webhook response",
+		"host", webhookURL.Hostname(),
+		"status_code", statusCode,
+		"duration", elapsed,
+		"threshold", threshold,
+		"message_id", messageID)
+
+	if w.metrics != nil {
+		w.metrics.RecordWebhookSlowResponse(webhookURL.Hostname())
+	}
+}
+
+// providerMessageID extracts the provider's message reference from a success
+// response body. It returns "" when the body is not a JSON object or lacks the
+// configured field; string and numeric IDs are both accepted.
+func (w *webhookClient) providerMessageID(body []byte) string {
+	field := w.config.ProviderMessageIDField
+	if field == "" {
+		field = defaultProviderMessageIDField
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var response map[string]interface{}
+	if err := decoder.Decode(&response); err != nil {
+		return ""
+	}
+
+	switch id := response[field].(type) {
+	case string:
+		return id
+	case json.Number:
+		return id.String()
+	default:
+		return ""
 	}
 }
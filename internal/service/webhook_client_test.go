@@ -2,15 +2,30 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/insider/insider-messaging/internal/domain"
 	"github.com/insider/insider-messaging/pkg/config"
 	"github.com/insider/insider-messaging/pkg/logger"
+	"github.com/insider/insider-messaging/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,7 +57,7 @@ func TestWebhookClient_SendMessage(t *testing.T) {
 			serverResponse: func(w http.ResponseWriter, r *http.Request) {
 				// Verify request headers
 				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
-				assert.Equal(t, "insider-messaging/1.0", r.Header.Get("User-Agent"))
+				assert.Equal(t, config.DefaultWebhookUserAgent(), r.Header.Get("User-Agent"))
 
 				// Verify payload
 				var payload WebhookPayload
@@ -140,7 +155,7 @@ func TestWebhookClient_SendMessage(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			err := client.SendMessage(ctx, tt.message)
+			_, err := client.SendMessage(ctx, tt.message)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -179,15 +194,16 @@ func TestWebhookClient_SendMessage_Timeout(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
-	err := client.SendMessage(ctx, message)
+	_, err := client.SendMessage(ctx, message)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "context deadline exceeded")
 }
 
 func TestWebhookClient_SendMessage_RetryLogic(t *testing.T) {
 	cfg := &config.Config{
-		BackoffMin: 10 * time.Millisecond, // Very short for testing
-		BackoffMax: 100 * time.Millisecond,
+		BackoffMin:    10 * time.Millisecond, // Very short for testing
+		BackoffMax:    100 * time.Millisecond,
+		BackoffJitter: 5 * time.Millisecond,
 	}
 	log := logger.New().WithComponent("webhook-test")
 
@@ -220,12 +236,611 @@ func TestWebhookClient_SendMessage_RetryLogic(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := client.SendMessage(ctx, message)
+	_, err := client.SendMessage(ctx, message)
 	// The webhook client allows 2 retries (3 total attempts), so this should succeed
 	assert.NoError(t, err)
 	assert.Equal(t, 3, requestCount, "Should have made 3 requests (1 initial + 2 retries)")
 }
 
+func TestNewWebhookBackoff_Jitter(t *testing.T) {
+	t.Run("stays within the configured bound", func(t *testing.T) {
+		cfg := &config.Config{
+			BackoffMin:    100 * time.Millisecond,
+			BackoffMax:    time.Minute,
+			BackoffJitter: 20 * time.Millisecond,
+		}
+
+		for i := 0; i < 100; i++ {
+			backoff := newWebhookBackoff(cfg)
+			for _, base := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond} {
+				delay, stop := backoff.Next()
+				require.False(t, stop)
+				assert.GreaterOrEqual(t, delay, base-cfg.BackoffJitter)
+				assert.LessOrEqual(t, delay, base+cfg.BackoffJitter)
+			}
+			_, stop := backoff.Next()
+			assert.True(t, stop, "only two retries are allowed")
+		}
+	})
+
+	t.Run("zero jitter keeps the exponential delays", func(t *testing.T) {
+		backoff := newWebhookBackoff(&config.Config{
+			BackoffMin: 10 * time.Millisecond,
+			BackoffMax: time.Minute,
+		})
+
+		delay, _ := backoff.Next()
+		assert.Equal(t, 10*time.Millisecond, delay)
+		delay, _ = backoff.Next()
+		assert.Equal(t, 20*time.Millisecond, delay)
+	})
+}
+
+func TestWebhookClient_SendMessage_ConnectionReuse(t *testing.T) {
+	cfg := &config.Config{
+		BackoffMin: 10 * time.Millisecond,
+		BackoffMax: 100 * time.Millisecond,
+	}
+	log := logger.New().WithComponent("webhook-test")
+	m := metrics.NewWithRegistry(prometheus.NewRegistry())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status": "accepted"}`))
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(cfg, log, WithWebhookMetrics(m))
+
+	const sends = 3
+	for i := 1; i <= sends; i++ {
+		message := &domain.Message{
+			ID:         int64(i),
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: server.URL,
+			Status:     domain.MessageStatusPending,
+			CreatedAt:  time.Now(),
+		}
+		require.NoError(t, sendMessage(context.Background(), client, message))
+	}
+
+	// The first request dials; the following ones should ride the keep-alive connection
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.WebhookConnectionsNewTotal))
+	assert.Equal(t, float64(sends-1), testutil.ToFloat64(m.WebhookConnectionsReusedTotal))
+}
+
+func TestWebhookClient_SendMessage_HostConcurrency(t *testing.T) {
+	const limit = 2
+	cfg := &config.Config{
+		BackoffMin:             10 * time.Millisecond,
+		BackoffMax:             100 * time.Millisecond,
+		WebhookHostConcurrency: limit,
+	}
+	log := logger.New().WithComponent("webhook-test")
+
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(cfg, log)
+
+	const sends = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, sends)
+	for i := 1; i <= sends; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			errs <- sendMessage(context.Background(), client, &domain.Message{
+				ID:         id,
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: server.URL,
+				Status:     domain.MessageStatusPending,
+				CreatedAt:  time.Now(),
+			})
+		}(int64(i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(limit), peak.Load(), "in-flight requests to one host must reach but never exceed the cap")
+
+	t.Run("waiting respects context cancellation", func(t *testing.T) {
+		blocked := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-blocked
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+		defer close(blocked)
+
+		client := NewWebhookClient(&config.Config{
+			BackoffMin:             10 * time.Millisecond,
+			BackoffMax:             100 * time.Millisecond,
+			WebhookHostConcurrency: 1,
+		}, log)
+		message := &domain.Message{ID: 1, Recipient: "test@example.com", Content: "Test message", WebhookURL: server.URL}
+
+		// Hold the only slot with a request the server never answers
+		go sendMessage(context.Background(), client, message)
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := sendMessage(ctx, client, message)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("requests are signed once they get a slot", func(t *testing.T) {
+		const delay = 50 * time.Millisecond
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		var mu sync.Mutex
+		var signedAt []time.Time
+		signer := RequestSignerFunc(func(*http.Request, []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			signedAt = append(signedAt, time.Now())
+			return nil
+		})
+		client := NewWebhookClient(&config.Config{
+			BackoffMin:             10 * time.Millisecond,
+			BackoffMax:             100 * time.Millisecond,
+			WebhookHostConcurrency: 1,
+		}, log, WithRequestSigner(signer))
+		message := &domain.Message{ID: 1, Recipient: "test@example.com", Content: "Test message", WebhookURL: server.URL}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, sendMessage(context.Background(), client, message))
+			}()
+		}
+		wg.Wait()
+
+		// The second request waited for the first to finish before being
+		// signed, so its signature timestamp is not aged by the wait
+		require.Len(t, signedAt, 2)
+		assert.GreaterOrEqual(t, signedAt[1].Sub(signedAt[0]), delay)
+	})
+}
+
+func TestWebhookClient_SendMessage_SlowResponse(t *testing.T) {
+	log := logger.New().WithComponent("webhook-test")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host := serverURL.Hostname()
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantSlow  float64
+	}{
+		{"slower than threshold", 10 * time.Millisecond, 1},
+		{"within threshold", time.Minute, 0},
+		{"disabled", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				BackoffMin:           10 * time.Millisecond,
+				BackoffMax:           100 * time.Millisecond,
+				WebhookSlowThreshold: tt.threshold,
+			}
+			m := metrics.NewWithRegistry(prometheus.NewRegistry())
+			client := NewWebhookClient(cfg, log, WithWebhookMetrics(m))
+
+			message := &domain.Message{
+				ID:         1,
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: server.URL,
+				Status:     domain.MessageStatusPending,
+				CreatedAt:  time.Now(),
+			}
+			require.NoError(t, sendMessage(context.Background(), client, message), "slow deliveries still succeed")
+
+			assert.Equal(t, tt.wantSlow, testutil.ToFloat64(m.WebhookSlowResponsesTotal.WithLabelValues(host)))
+		})
+	}
+}
+
+func TestWebhookClient_SendMessage_Signature(t *testing.T) {
+	log := logger.New().WithComponent("webhook-test")
+	message := &domain.Message{
+		ID:        1,
+		Recipient: "test@example.com",
+		Content:   "Test message",
+		Status:    domain.MessageStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("signs body when secret is configured", func(t *testing.T) {
+		const secret = "s3cret"
+		cfg := &config.Config{
+			BackoffMin:    10 * time.Millisecond,
+			BackoffMax:    100 * time.Millisecond,
+			WebhookSecret: secret,
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var payload WebhookPayload
+			require.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, int64(1), payload.MessageID)
+
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(body)
+			expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+			assert.Equal(t, expected, r.Header.Get(SignatureHeader))
+
+			timestamp, err := strconv.ParseInt(r.Header.Get(SignatureTimestampHeader), 10, 64)
+			require.NoError(t, err)
+			assert.InDelta(t, time.Now().Unix(), timestamp, 5)
+
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		message.WebhookURL = server.URL
+		client := NewWebhookClient(cfg, log)
+		require.NoError(t, sendMessage(context.Background(), client, message))
+	})
+
+	t.Run("no signature headers without secret", func(t *testing.T) {
+		cfg := &config.Config{
+			BackoffMin: 10 * time.Millisecond,
+			BackoffMax: 100 * time.Millisecond,
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+			assert.Empty(t, r.Header.Get(SignatureTimestampHeader))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		message.WebhookURL = server.URL
+		client := NewWebhookClient(cfg, log)
+		require.NoError(t, sendMessage(context.Background(), client, message))
+	})
+}
+
+func TestWebhookClient_SendMessage_RequestSigner(t *testing.T) {
+	cfg := &config.Config{
+		BackoffMin:    10 * time.Millisecond,
+		BackoffMax:    100 * time.Millisecond,
+		WebhookSecret: "s3cret",
+	}
+	log := logger.New().WithComponent("webhook-test")
+
+	newMessage := func(webhookURL string) *domain.Message {
+		return &domain.Message{
+			ID:         1,
+			Recipient:  "test@example.com",
+			Content:    "Test message",
+			WebhookURL: webhookURL,
+			Status:     domain.MessageStatusPending,
+			CreatedAt:  time.Now(),
+		}
+	}
+
+	t.Run("custom signer replaces the configured one", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "partner-key", r.Header.Get("X-Api-Key"))
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		signer := RequestSignerFunc(func(req *http.Request, body []byte) error {
+			req.Header.Set("X-Api-Key", "partner-key")
+			return nil
+		})
+
+		client := NewWebhookClient(cfg, log, WithRequestSigner(signer))
+		require.NoError(t, sendMessage(context.Background(), client, newMessage(server.URL)))
+	})
+
+	t.Run("host signer overrides the default for that host", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer partner-token", r.Header.Get("Authorization"))
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		client := NewWebhookClient(cfg, log, WithHostRequestSigner("127.0.0.1", BearerSigner{Token: "partner-token"}))
+		require.NoError(t, sendMessage(context.Background(), client, newMessage(server.URL)))
+	})
+
+	t.Run("signer error aborts delivery", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		signer := RequestSignerFunc(func(*http.Request, []byte) error {
+			return errors.New("credentials expired")
+		})
+
+		client := NewWebhookClient(cfg, log, WithRequestSigner(signer))
+		_, err := client.SendMessage(context.Background(), newMessage(server.URL))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to sign webhook request")
+		assert.Equal(t, 0, requests)
+	})
+}
+
+func TestWebhookClient_SendMessage_SanitizesContent(t *testing.T) {
+	cfg := &config.Config{
+		BackoffMin:          10 * time.Millisecond,
+		BackoffMax:          100 * time.Millisecond,
+		ContentSanitizeMode: config.ContentSanitizeSanitize,
+	}
+	log := logger.New().WithComponent("webhook-test")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "hello \uFFFDworld", payload.Content)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	message := &domain.Message{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "hello\x00 \xffworld",
+		WebhookURL: server.URL,
+		Status:     domain.MessageStatusPending,
+		CreatedAt:  time.Now(),
+	}
+
+	client := NewWebhookClient(cfg, log)
+	require.NoError(t, sendMessage(context.Background(), client, message))
+}
+
+func TestWebhookClient_SendMessage_UserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(&config.Config{
+		BackoffMin:       time.Millisecond,
+		BackoffMax:       100 * time.Millisecond,
+		WebhookUserAgent: "acme-notifier/2.3",
+	}, logger.New().WithComponent("webhook-test"))
+
+	_, err := client.SendMessage(context.Background(), &domain.Message{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: server.URL,
+		Status:     domain.MessageStatusPending,
+		CreatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "acme-notifier/2.3", userAgent)
+}
+
+// countingReader is an endless stream of 'x' that counts the bytes read from it
+type countingReader struct {
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+func TestWebhookClient_SendMessage_OversizedResponse(t *testing.T) {
+	const limit = 1024
+	log := logger.New().WithComponent("webhook-test")
+
+	t.Run("reads at most the limit", func(t *testing.T) {
+		client := NewWebhookClient(&config.Config{WebhookMaxResponseBytes: limit}, log).(*webhookClient)
+
+		body := &countingReader{}
+		data, truncated := client.readResponseBody(body)
+		assert.True(t, truncated)
+		assert.Len(t, data, limit)
+		assert.LessOrEqual(t, body.read, limit+1)
+
+		data, truncated = client.readResponseBody(strings.NewReader(strings.Repeat("x", limit)))
+		assert.False(t, truncated, "a body of exactly the limit is not truncated")
+		assert.Len(t, data, limit)
+	})
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+		retryable  bool
+	}{
+		{name: "success", statusCode: http.StatusAccepted},
+		{name: "client error", statusCode: http.StatusBadRequest, wantErr: true},
+		{name: "server error", statusCode: http.StatusServiceUnavailable, wantErr: true, retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.statusCode)
+				// Far more than the client reads; writes fail once it hangs up
+				chunk := []byte(strings.Repeat("x", 64<<10))
+				for i := 0; i < 256; i++ {
+					if _, err := w.Write(chunk); err != nil {
+						return
+					}
+				}
+			}))
+			defer server.Close()
+
+			client := NewWebhookClient(&config.Config{
+				BackoffMin:              time.Millisecond,
+				BackoffMax:              100 * time.Millisecond,
+				WebhookMaxResponseBytes: limit,
+			}, log)
+
+			message := &domain.Message{
+				ID:         1,
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: server.URL,
+				Status:     domain.MessageStatusPending,
+				CreatedAt:  time.Now(),
+			}
+
+			_, err := client.SendMessage(context.Background(), message)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, int32(1), requests.Load())
+				return
+			}
+
+			require.Error(t, err)
+			prefix := fmt.Sprintf("webhook delivery failed with status %d: ", tt.statusCode)
+			assert.Equal(t, prefix+strings.Repeat("x", limit), err.Error())
+			if tt.retryable {
+				assert.Equal(t, int32(3), requests.Load(), "5xx is still retried")
+			} else {
+				assert.Equal(t, int32(1), requests.Load(), "4xx is still not retried")
+			}
+		})
+	}
+}
+
+func TestWebhookClient_SendMessage_ErrorBodyBounded(t *testing.T) {
+	// A multi-byte character straddles the bound, so the cut has to back off
+	body := strings.Repeat("x", maxErrorBodyBytes-1) + strings.Repeat("é", 4<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(&config.Config{
+		BackoffMin: time.Millisecond,
+		BackoffMax: 100 * time.Millisecond,
+	}, logger.New().WithComponent("webhook-test"))
+
+	message := &domain.Message{
+		ID:         1,
+		Recipient:  "test@example.com",
+		Content:    "Test message",
+		WebhookURL: server.URL,
+		Status:     domain.MessageStatusPending,
+		CreatedAt:  time.Now(),
+	}
+
+	_, err := client.SendMessage(context.Background(), message)
+	require.Error(t, err)
+
+	prefix := fmt.Sprintf("webhook delivery failed with status %d: ", http.StatusBadRequest)
+	assert.Equal(t, prefix+strings.Repeat("x", maxErrorBodyBytes-1)+"... (truncated)", err.Error())
+	assert.True(t, utf8.ValidString(err.Error()))
+}
+
+// sendMessage discards the provider message ID for tests that only check delivery
+func sendMessage(ctx context.Context, client WebhookClient, message *domain.Message) error {
+	_, err := client.SendMessage(ctx, message)
+	return err
+}
+
+func TestWebhookClient_SendMessage_ProviderMessageID(t *testing.T) {
+	log := logger.New().WithComponent("webhook-test")
+
+	tests := []struct {
+		name         string
+		field        string
+		statusCode   int
+		responseBody string
+		expectedID   string
+	}{
+		{name: "default field on 202", statusCode: http.StatusAccepted, responseBody: `{"messageId":"abc123"}`, expectedID: "abc123"},
+		{name: "default field on 200", statusCode: http.StatusOK, responseBody: `{"messageId":"abc123","status":"queued"}`, expectedID: "abc123"},
+		{name: "numeric ID", statusCode: http.StatusAccepted, responseBody: `{"messageId":12345678901234}`, expectedID: "12345678901234"},
+		{name: "custom field", field: "id", statusCode: http.StatusAccepted, responseBody: `{"id":"xyz","messageId":"ignored"}`, expectedID: "xyz"},
+		{name: "field absent", statusCode: http.StatusAccepted, responseBody: `{"status":"accepted"}`, expectedID: ""},
+		{name: "non-JSON body", statusCode: http.StatusAccepted, responseBody: `accepted`, expectedID: ""},
+		{name: "empty body", statusCode: http.StatusAccepted, responseBody: ``, expectedID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				BackoffMin:             10 * time.Millisecond,
+				BackoffMax:             100 * time.Millisecond,
+				ProviderMessageIDField: tt.field,
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			message := &domain.Message{
+				ID:         1,
+				Recipient:  "test@example.com",
+				Content:    "Test message",
+				WebhookURL: server.URL,
+				Status:     domain.MessageStatusPending,
+				CreatedAt:  time.Now(),
+			}
+
+			client := NewWebhookClient(cfg, log)
+			providerMessageID, err := client.SendMessage(context.Background(), message)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, providerMessageID)
+		})
+	}
+}
+
 func TestWebhookPayload_JSON(t *testing.T) {
 	now := time.Now()
 	payload := WebhookPayload{
@@ -251,3 +866,109 @@ func TestWebhookPayload_JSON(t *testing.T) {
 	assert.True(t, payload.CreatedAt.Equal(decoded.CreatedAt))
 	assert.True(t, payload.SentAt.Equal(decoded.SentAt))
 }
+
+func TestWebhookClient_SendMessage_PayloadTransformer(t *testing.T) {
+	cfg := &config.Config{
+		BackoffMin:    10 * time.Millisecond,
+		BackoffMax:    100 * time.Millisecond,
+		WebhookSecret: "s3cret",
+	}
+	log := logger.New().WithComponent("webhook-test")
+
+	transformer, err := NewTemplateTransformer(`{"data":{"to":{{json .Recipient}},"text":{{json .Content}}},"ref":{{.MessageID}}}`)
+	require.NoError(t, err)
+
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		// The signature covers the transformed body actually sent
+		assert.Equal(t, SignPayload("s3cret", body), r.Header.Get(SignatureHeader))
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	message := &domain.Message{
+		ID:         42,
+		Recipient:  "test@example.com",
+		Content:    "Hello",
+		WebhookURL: server.URL,
+		Status:     domain.MessageStatusPending,
+		CreatedAt:  time.Now(),
+	}
+
+	client := NewWebhookClient(cfg, log, WithPayloadTransformer(transformer))
+	require.NoError(t, sendMessage(context.Background(), client, message))
+
+	assert.Equal(t, map[string]interface{}{
+		"data": map[string]interface{}{"to": "test@example.com", "text": "Hello"},
+		"ref":  float64(42),
+	}, received)
+	assert.Equal(t, "Hello", message.Content, "stored content is not changed")
+
+	t.Run("transform failure is not retried", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		failing := PayloadTransformerFunc(func(WebhookPayload) ([]byte, error) {
+			return nil, errors.New("boom")
+		})
+		client := NewWebhookClient(cfg, log, WithPayloadTransformer(failing))
+
+		err := sendMessage(context.Background(), client, &domain.Message{ID: 1, WebhookURL: server.URL})
+		assert.ErrorContains(t, err, "failed to build webhook payload")
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func TestWebhookClient_SendMessage_MessagePayloadTemplate(t *testing.T) {
+	cfg := &config.Config{
+		BackoffMin: 10 * time.Millisecond,
+		BackoffMax: 100 * time.Millisecond,
+	}
+	log := logger.New().WithComponent("webhook-test")
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// The message's template takes precedence over the client's transformer
+	clientTransformer, err := NewTemplateTransformer(`{"client":true}`)
+	require.NoError(t, err)
+	client := NewWebhookClient(cfg, log, WithPayloadTransformer(clientTransformer))
+
+	message := &domain.Message{
+		ID:              7,
+		Recipient:       "test@example.com",
+		Content:         "Hello \"there\"",
+		WebhookURL:      server.URL,
+		Status:          domain.MessageStatusPending,
+		CreatedAt:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		PayloadTemplate: `{"recipient":{"email":{{json .Recipient}}},"message":{"body":{{json .Content}},"id":{{.MessageID}},"queued_at":{{json (rfc3339 .CreatedAt)}}}}`,
+	}
+	require.NoError(t, sendMessage(context.Background(), client, message))
+	assert.JSONEq(t, `{"recipient":{"email":"test@example.com"},"message":{"body":"Hello \"there\"","id":7,"queued_at":"2024-05-01T12:00:00Z"}}`, string(body))
+
+	t.Run("without a template the client's transformer is used", func(t *testing.T) {
+		require.NoError(t, sendMessage(context.Background(), client, &domain.Message{ID: 8, WebhookURL: server.URL}))
+		assert.JSONEq(t, `{"client":true}`, string(body))
+	})
+
+	t.Run("a broken stored template fails without sending", func(t *testing.T) {
+		body = nil
+		err := sendMessage(context.Background(), client, &domain.Message{ID: 9, WebhookURL: server.URL, PayloadTemplate: `{{.Missing`})
+		assert.ErrorContains(t, err, "failed to build webhook payload")
+		assert.Nil(t, body)
+	})
+}
@@ -0,0 +1,3 @@
+-- Track when a failed message becomes eligible for its next retry
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_messages_status_next_retry ON messages (status, next_retry_at);
@@ -0,0 +1,3 @@
+-- Support time-windowed counts of sent and dead-lettered messages
+CREATE INDEX IF NOT EXISTS idx_messages_status_sent_at ON messages (status, sent_at);
+CREATE INDEX IF NOT EXISTS idx_messages_status_updated_at ON messages (status, updated_at);
@@ -0,0 +1,2 @@
+-- Store the receiver's reference for delivered messages
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS provider_message_id VARCHAR(255);
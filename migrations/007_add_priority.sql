@@ -0,0 +1,3 @@
+-- Retry higher-priority failed messages first
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_messages_retry_priority ON messages (priority DESC, next_retry_at ASC) WHERE status = 'failed';
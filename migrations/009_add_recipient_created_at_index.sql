@@ -0,0 +1,2 @@
+-- Count a recipient's messages for the day when enforcing the daily limit
+CREATE INDEX IF NOT EXISTS idx_messages_recipient_created_at ON messages (recipient, created_at);
@@ -0,0 +1,4 @@
+-- Allow messages to be claimed by external delivery workers
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter', 'sending'));
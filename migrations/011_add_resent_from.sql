@@ -0,0 +1,5 @@
+-- Link a resent message to the message it was cloned from. There is no foreign
+-- key because the original may be moved to messages_archive.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS resent_from BIGINT;
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS resent_from BIGINT;
+CREATE INDEX IF NOT EXISTS idx_messages_resent_from ON messages (resent_from) WHERE resent_from IS NOT NULL;
@@ -0,0 +1,6 @@
+-- Store the client's Idempotency-Key with the message it created so a retried
+-- create returns the original. NULLs are distinct, so keyless messages are
+-- unaffected by the unique index.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_idempotency_key ON messages (idempotency_key);
@@ -0,0 +1,4 @@
+-- Allow messages to be cancelled before they are delivered
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter', 'sending', 'cancelled'));
@@ -0,0 +1,11 @@
+-- Audit log of every webhook request made to deliver a message. There is no
+-- foreign key so the history outlives messages moved to the archive.
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL,
+    status_code INTEGER,
+    duration_ms BIGINT NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_message_id ON webhook_attempts (message_id, created_at);
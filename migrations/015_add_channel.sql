@@ -0,0 +1,5 @@
+-- Record how each message is delivered. Existing messages were all delivered
+-- by webhook, which stays the default.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel VARCHAR(20) NOT NULL DEFAULT 'webhook';
+ALTER TABLE messages ADD CONSTRAINT messages_channel_check CHECK (channel IN ('webhook', 'email', 'sms'));
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS channel VARCHAR(20) NOT NULL DEFAULT 'webhook';
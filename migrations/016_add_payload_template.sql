@@ -0,0 +1,4 @@
+-- Let a message render its webhook body from its own template. Empty means
+-- the default payload, as for every existing message.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS payload_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS payload_template TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Let messages expire: an undelivered message whose expires_at has passed is
+-- marked expired instead of being sent. NULL never expires, as for every
+-- existing message.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE messages_archive ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter', 'sending', 'cancelled', 'expired'));
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages (expires_at)
+    WHERE expires_at IS NOT NULL AND status IN ('pending', 'failed');
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey keys the values this package stores in a context
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	requestIDContextKey
+)
+
+// WithContext returns a copy of ctx carrying l, for FromContext to return
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the logger stored by WithContext, falling back to one
+// wrapping slog's default logger
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*Logger); ok && l != nil {
+		return l
+	}
+	return &Logger{Logger: slog.New(contextHandler{slog.Default().Handler()})}
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID, which the
+// logger's Context methods (InfoContext, ErrorContext, ...) attach to records
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by ContextWithRequestID,
+// or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// contextHandler adds the request ID carried by the logging context to each
+// record
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
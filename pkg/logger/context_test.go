@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := NewWithOptions(Options{Level: slog.LevelInfo, Output: &buf})
+	require.NoError(t, err)
+
+	entry := func(t *testing.T) map[string]interface{} {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+		buf.Reset()
+		return fields
+	}
+
+	t.Run("context methods attach the request id", func(t *testing.T) {
+		ctx := ContextWithRequestID(context.Background(), "req-123")
+
+		log.WithComponent("api").InfoContext(ctx, "handled")
+		fields := entry(t)
+		assert.Equal(t, "req-123", fields["request_id"])
+		assert.Equal(t, "api", fields["component"])
+
+		log.ErrorContext(ctx, "failed")
+		assert.Equal(t, "req-123", entry(t)["request_id"])
+	})
+
+	t.Run("no request id in context", func(t *testing.T) {
+		log.InfoContext(context.Background(), "handled")
+		assert.NotContains(t, entry(t), "request_id")
+	})
+
+	t.Run("logger stored in context", func(t *testing.T) {
+		ctx := WithContext(ContextWithRequestID(context.Background(), "req-456"), log)
+
+		assert.Same(t, log, FromContext(ctx))
+		FromContext(ctx).WarnContext(ctx, "slow")
+		assert.Equal(t, "req-456", entry(t)["request_id"])
+	})
+
+	t.Run("falls back without a stored logger", func(t *testing.T) {
+		assert.NotNil(t, FromContext(context.Background()))
+		assert.Empty(t, RequestIDFromContext(context.Background()))
+	})
+}
@@ -1,10 +1,31 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 )
 
+// Log output formats for Options.Format
+const (
+	FormatJSON = "json" // One JSON object per line
+	FormatText = "text" // Human-readable key=value lines
+)
+
+// Options configures a logger built by NewWithOptions
+type Options struct {
+	// Level is the minimum level that is logged
+	Level slog.Level
+
+	// Format is FormatJSON or FormatText; empty means FormatJSON
+	Format string
+
+	// Output is where log lines are written; nil means os.Stdout
+	Output io.Writer
+}
+
 // Logger wraps slog.Logger with additional functionality
 type Logger struct {
 	*slog.Logger
@@ -17,7 +38,7 @@ func New() *Logger {
 		Level: slog.LevelInfo,
 	})
 
-	logger := slog.New(handler)
+	logger := slog.New(contextHandler{handler})
 	return &Logger{Logger: logger}
 }
 
@@ -27,10 +48,61 @@ func NewWithLevel(level slog.Level) *Logger {
 		Level: level,
 	})
 
-	logger := slog.New(handler)
+	logger := slog.New(contextHandler{handler})
 	return &Logger{Logger: logger}
 }
 
+// NewWithOptions creates a new logger with the given level, format and output
+func NewWithOptions(opts Options) (*Logger, error) {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(output, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected json or text", opts.Format)
+	}
+
+	return &Logger{Logger: slog.New(contextHandler{handler})}, nil
+}
+
+// NewFromEnv creates a new logger configured by LOG_LEVEL (debug, info, warn,
+// error; default info) and LOG_FORMAT (json, text; default json)
+func NewFromEnv() (*Logger, error) {
+	opts := Options{Level: slog.LevelInfo, Format: os.Getenv("LOG_FORMAT")}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		opts.Level = level
+	}
+	return NewWithOptions(opts)
+}
+
+// ParseLevel parses a level name: debug, info, warn or error, in any case
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", value)
+	}
+}
+
 // WithComponent adds a component field to the logger
 func (l *Logger) WithComponent(component string) *Logger {
 	return &Logger{
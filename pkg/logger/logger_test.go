@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"bytes"
+	"context"
 	"log/slog"
 	"testing"
 
@@ -19,6 +21,76 @@ func TestNewWithLevel(t *testing.T) {
 	assert.NotNil(t, logger.Logger)
 }
 
+func TestNewWithOptions_Format(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := NewWithOptions(Options{Level: slog.LevelInfo, Output: &buf})
+		assert.NoError(t, err)
+
+		logger.Info("hello", "key", "value")
+		assert.Contains(t, buf.String(), `"msg":"hello"`)
+		assert.Contains(t, buf.String(), `"key":"value"`)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := NewWithOptions(Options{Level: slog.LevelInfo, Format: FormatText, Output: &buf})
+		assert.NoError(t, err)
+
+		logger.Info("hello", "key", "value")
+		assert.Contains(t, buf.String(), "msg=hello")
+		assert.Contains(t, buf.String(), "key=value")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := NewWithOptions(Options{Format: "xml"})
+		assert.ErrorContains(t, err, `unknown log format "xml"`)
+	})
+}
+
+func TestNewFromEnv(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		level   string
+		enabled slog.Level
+		below   slog.Level
+	}{
+		{name: "default", level: "", enabled: slog.LevelInfo, below: slog.LevelDebug},
+		{name: "debug", level: "debug", enabled: slog.LevelDebug, below: slog.LevelDebug - 1},
+		{name: "info", level: "INFO", enabled: slog.LevelInfo, below: slog.LevelDebug},
+		{name: "warn", level: "warn", enabled: slog.LevelWarn, below: slog.LevelInfo},
+		{name: "error", level: "error", enabled: slog.LevelError, below: slog.LevelWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_LEVEL", tt.level)
+
+			logger, err := NewFromEnv()
+			assert.NoError(t, err)
+			assert.True(t, logger.Enabled(ctx, tt.enabled))
+			assert.False(t, logger.Enabled(ctx, tt.below))
+		})
+	}
+
+	t.Run("unknown level", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "verbose")
+
+		_, err := NewFromEnv()
+		assert.ErrorContains(t, err, `unknown log level "verbose"`)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Setenv("LOG_LEVEL", "")
+		t.Setenv("LOG_FORMAT", "xml")
+
+		_, err := NewFromEnv()
+		assert.Error(t, err)
+	})
+}
+
 func TestWithComponent(t *testing.T) {
 	logger := New()
 	componentLogger := logger.WithComponent("test-component")
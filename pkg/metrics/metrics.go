@@ -15,12 +15,42 @@ type Metrics struct {
 	MessagesProcessed         *prometheus.CounterVec
 	MessageProcessingDuration *prometheus.HistogramVec
 	MessagesInQueue           prometheus.Gauge
+	MarkOperationFailures     *prometheus.CounterVec
+
+	// WorkerWaitSeconds is the time a selected message waited for a free
+	// delivery worker
+	WorkerWaitSeconds prometheus.Histogram
+
+	// Event bus metrics
+	EventSinkDeliveries *prometheus.CounterVec
+
+	// Scheduler metrics, labelled by loop: process or retry
+	SchedulerRunsTotal            *prometheus.CounterVec
+	SchedulerRunDuration          *prometheus.HistogramVec
+	SchedulerLastSuccessTimestamp *prometheus.GaugeVec
+
+	// SchedulerMessagesPerRun is how many messages each processing run delivered
+	SchedulerMessagesPerRun prometheus.Histogram
+
+	// SchedulerDroppedRuns counts processing ticks skipped because the
+	// in-flight run limit was reached
+	SchedulerDroppedRuns prometheus.Counter
+
+	// RetentionDeletedPerRun is how many messages each retention run deleted
+	RetentionDeletedPerRun prometheus.Histogram
 
 	// Webhook metrics
 	WebhookRequestsTotal   *prometheus.CounterVec
 	WebhookRequestDuration *prometheus.HistogramVec
 	WebhookRetries         *prometheus.CounterVec
 
+	WebhookConnectionsReusedTotal prometheus.Counter
+	WebhookConnectionsNewTotal    prometheus.Counter
+
+	// WebhookSlowResponsesTotal counts webhook responses slower than the
+	// configured threshold, successful or not, per receiver host
+	WebhookSlowResponsesTotal *prometheus.CounterVec
+
 	// Database metrics
 	DatabaseConnectionsActive prometheus.Gauge
 	DatabaseQueryDuration     *prometheus.HistogramVec
@@ -37,13 +67,51 @@ type Metrics struct {
 	ActiveConnections   prometheus.Gauge
 }
 
+// Default histogram buckets, in seconds, used unless MetricsOptions overrides them
+var (
+	DefaultWebhookBuckets  = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	DefaultDatabaseBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1}
+	DefaultHTTPBuckets     = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+)
+
+// MetricsOptions overrides the bucket boundaries, in seconds, of the latency
+// histograms. A nil slice keeps the default buckets.
+type MetricsOptions struct {
+	// WebhookBuckets are the buckets of the webhook request duration histogram
+	WebhookBuckets []float64
+
+	// DatabaseBuckets are the buckets of the database query duration histogram
+	DatabaseBuckets []float64
+
+	// HTTPBuckets are the buckets of the HTTP request duration histogram
+	HTTPBuckets []float64
+}
+
 // New creates a new Metrics instance with all Prometheus metrics
-func New() *Metrics {
-	return NewWithRegistry(prometheus.DefaultRegisterer)
+func New(opts ...MetricsOptions) *Metrics {
+	return NewWithRegistry(prometheus.DefaultRegisterer, opts...)
 }
 
-// NewWithRegistry creates a new Metrics instance with a custom registry
-func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
+// NewWithRegistry creates a new Metrics instance with a custom registry. Bucket
+// overrides in opts are applied in order, so a later one wins.
+func NewWithRegistry(registerer prometheus.Registerer, opts ...MetricsOptions) *Metrics {
+	buckets := MetricsOptions{
+		WebhookBuckets:  DefaultWebhookBuckets,
+		DatabaseBuckets: DefaultDatabaseBuckets,
+		HTTPBuckets:     DefaultHTTPBuckets,
+	}
+	for _, opt := range opts {
+		if opt.WebhookBuckets != nil {
+			buckets.WebhookBuckets = opt.WebhookBuckets
+		}
+		if opt.DatabaseBuckets != nil {
+			buckets.DatabaseBuckets = opt.DatabaseBuckets
+		}
+		if opt.HTTPBuckets != nil {
+			buckets.HTTPBuckets = opt.HTTPBuckets
+		}
+	}
+
 	m := &Metrics{
 		// Message metrics
 		MessagesTotal: prometheus.NewCounterVec(
@@ -78,6 +146,80 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 			},
 		),
 
+		MarkOperationFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_mark_operation_failures_total",
+				Help: "Total number of message status updates that failed after all retries and need reconciliation",
+			},
+			[]string{"operation"}, // sent, failed, dead_letter
+		),
+
+		WorkerWaitSeconds: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "insider_messaging_worker_wait_seconds",
+				Help:    "Time between a message being selected for delivery and a worker starting it; high values mean the worker pool is too small",
+				Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+		),
+
+		// Event bus metrics
+		EventSinkDeliveries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_event_sink_deliveries_total",
+				Help: "Total number of lifecycle events handled by each event sink by result",
+			},
+			[]string{"sink", "result"}, // result: success, error, dropped
+		),
+
+		// Scheduler metrics
+		SchedulerRunsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_scheduler_runs_total",
+				Help: "Total number of scheduler runs by loop and result",
+			},
+			[]string{"loop", "result"}, // loop: process, retry; result: success, error
+		),
+
+		SchedulerRunDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "insider_messaging_scheduler_run_duration_seconds",
+				Help:    "Time spent on a scheduler run",
+				Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"loop"},
+		),
+
+		SchedulerLastSuccessTimestamp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "insider_messaging_scheduler_last_success_timestamp_seconds",
+				Help: "Unix time the last successful scheduler run of each loop finished",
+			},
+			[]string{"loop"},
+		),
+
+		SchedulerMessagesPerRun: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "insider_messaging_scheduler_messages_per_run",
+				Help:    "Number of messages processed by each scheduler processing run",
+				Buckets: []float64{0, 1, 5, 10, 25, 50, 100, 250, 500},
+			},
+		),
+
+		SchedulerDroppedRuns: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_scheduler_dropped_runs_total",
+				Help: "Total number of scheduler processing ticks skipped because too many runs were in flight",
+			},
+		),
+
+		RetentionDeletedPerRun: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "insider_messaging_retention_deleted_messages_per_run",
+				Help:    "Number of messages deleted by each retention run",
+				Buckets: []float64{0, 10, 100, 1000, 10000, 100000},
+			},
+		),
+
 		// Webhook metrics
 		WebhookRequestsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -91,7 +233,7 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 			prometheus.HistogramOpts{
 				Name:    "insider_messaging_webhook_request_duration_seconds",
 				Help:    "Time spent on webhook requests",
-				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+				Buckets: buckets.WebhookBuckets,
 			},
 			[]string{"status_code"},
 		),
@@ -104,6 +246,28 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 			[]string{"reason"}, // timeout, server_error, client_error
 		),
 
+		WebhookConnectionsReusedTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_webhook_connections_reused_total",
+				Help: "Total number of webhook requests served over a reused keep-alive connection",
+			},
+		),
+
+		WebhookSlowResponsesTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_webhook_slow_responses_total",
+				Help: "Total number of webhook responses that took longer than WEBHOOK_SLOW_THRESHOLD, by receiver host",
+			},
+			[]string{"host"},
+		),
+
+		WebhookConnectionsNewTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "insider_messaging_webhook_connections_new_total",
+				Help: "Total number of webhook requests that had to open a new connection",
+			},
+		),
+
 		// Database metrics
 		DatabaseConnectionsActive: prometheus.NewGauge(
 			prometheus.GaugeOpts{
@@ -116,7 +280,7 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 			prometheus.HistogramOpts{
 				Name:    "insider_messaging_database_query_duration_seconds",
 				Help:    "Time spent on database queries",
-				Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1},
+				Buckets: buckets.DatabaseBuckets,
 			},
 			[]string{"operation"}, // select, insert, update, delete
 		),
@@ -168,7 +332,7 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 			prometheus.HistogramOpts{
 				Name:    "insider_messaging_http_request_duration_seconds",
 				Help:    "Time spent on HTTP requests",
-				Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+				Buckets: buckets.HTTPBuckets,
 			},
 			[]string{"method", "endpoint"},
 		),
@@ -187,9 +351,21 @@ func NewWithRegistry(registerer prometheus.Registerer) *Metrics {
 		m.MessagesProcessed,
 		m.MessageProcessingDuration,
 		m.MessagesInQueue,
+		m.MarkOperationFailures,
+		m.WorkerWaitSeconds,
+		m.EventSinkDeliveries,
+		m.SchedulerRunsTotal,
+		m.SchedulerRunDuration,
+		m.SchedulerLastSuccessTimestamp,
+		m.SchedulerMessagesPerRun,
+		m.SchedulerDroppedRuns,
+		m.RetentionDeletedPerRun,
 		m.WebhookRequestsTotal,
 		m.WebhookRequestDuration,
 		m.WebhookRetries,
+		m.WebhookConnectionsReusedTotal,
+		m.WebhookConnectionsNewTotal,
+		m.WebhookSlowResponsesTotal,
 		m.DatabaseConnectionsActive,
 		m.DatabaseQueryDuration,
 		m.DatabaseQueriesTotal,
@@ -220,6 +396,47 @@ func (m *Metrics) RecordMessageStatus(status string) {
 	m.MessagesTotal.WithLabelValues(status).Inc()
 }
 
+// RecordMarkOperationFailure records a message status update that could not be persisted
+func (m *Metrics) RecordMarkOperationFailure(operation string) {
+	m.MarkOperationFailures.WithLabelValues(operation).Inc()
+}
+
+// RecordWorkerWait records how long a message waited for a delivery worker
+func (m *Metrics) RecordWorkerWait(wait time.Duration) {
+	m.WorkerWaitSeconds.Observe(wait.Seconds())
+}
+
+// RecordEventSinkDelivery records the outcome of delivering an event to a sink
+func (m *Metrics) RecordEventSinkDelivery(sink, result string) {
+	m.EventSinkDeliveries.WithLabelValues(sink, result).Inc()
+}
+
+// RecordSchedulerRun records a finished scheduler run of loop, stamping the
+// last success time when result is success
+func (m *Metrics) RecordSchedulerRun(loop, result string, duration time.Duration) {
+	m.SchedulerRunsTotal.WithLabelValues(loop, result).Inc()
+	m.SchedulerRunDuration.WithLabelValues(loop).Observe(duration.Seconds())
+	if result == "success" {
+		m.SchedulerLastSuccessTimestamp.WithLabelValues(loop).SetToCurrentTime()
+	}
+}
+
+// RecordSchedulerMessagesProcessed records how many messages a processing run delivered
+func (m *Metrics) RecordSchedulerMessagesProcessed(count int) {
+	m.SchedulerMessagesPerRun.Observe(float64(count))
+}
+
+// RecordSchedulerDroppedRun records a processing tick skipped for lack of a
+// free run slot
+func (m *Metrics) RecordSchedulerDroppedRun() {
+	m.SchedulerDroppedRuns.Inc()
+}
+
+// RecordRetentionDeleted records how many messages a retention run deleted
+func (m *Metrics) RecordRetentionDeleted(count int) {
+	m.RetentionDeletedPerRun.Observe(float64(count))
+}
+
 // RecordWebhookRequest records a webhook request
 func (m *Metrics) RecordWebhookRequest(statusCode string, duration time.Duration) {
 	m.WebhookRequestsTotal.WithLabelValues(statusCode).Inc()
@@ -231,6 +448,20 @@ func (m *Metrics) RecordWebhookRetry(reason string) {
 	m.WebhookRetries.WithLabelValues(reason).Inc()
 }
 
+// RecordWebhookConnection records whether a webhook request reused a pooled connection
+func (m *Metrics) RecordWebhookConnection(reused bool) {
+	if reused {
+		m.WebhookConnectionsReusedTotal.Inc()
+		return
+	}
+	m.WebhookConnectionsNewTotal.Inc()
+}
+
+// RecordWebhookSlowResponse records a webhook response slower than the threshold
+func (m *Metrics) RecordWebhookSlowResponse(host string) {
+	m.WebhookSlowResponsesTotal.WithLabelValues(host).Inc()
+}
+
 // RecordDatabaseQuery records a database query
 func (m *Metrics) RecordDatabaseQuery(operation, result string, duration time.Duration) {
 	m.DatabaseQueriesTotal.WithLabelValues(operation, result).Inc()
@@ -34,6 +34,9 @@ func TestNew(t *testing.T) {
 	if m.MessagesInQueue == nil {
 		t.Error("MessagesInQueue not initialized")
 	}
+	if m.WorkerWaitSeconds == nil {
+		t.Error("WorkerWaitSeconds not initialized")
+	}
 	if m.WebhookRequestsTotal == nil {
 		t.Error("WebhookRequestsTotal not initialized")
 	}
@@ -43,6 +46,9 @@ func TestNew(t *testing.T) {
 	if m.WebhookRetries == nil {
 		t.Error("WebhookRetries not initialized")
 	}
+	if m.WebhookSlowResponsesTotal == nil {
+		t.Error("WebhookSlowResponsesTotal not initialized")
+	}
 	if m.DatabaseConnectionsActive == nil {
 		t.Error("DatabaseConnectionsActive not initialized")
 	}
@@ -198,6 +204,58 @@ func TestRecordWebhookRequest(t *testing.T) {
 	}
 }
 
+func TestNewWithRegistry_CustomBuckets(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry, MetricsOptions{
+		WebhookBuckets: []float64{2, 4, 6, 8, 10},
+		HTTPBuckets:    []float64{0.5, 1},
+	})
+
+	m.RecordWebhookRequest("200", 5*time.Second)
+	m.RecordHTTPRequest("GET", "200", "/healthz", 100*time.Millisecond)
+	m.RecordDatabaseQuery("select", "success", 2*time.Millisecond)
+
+	expected := `
+		# HELP insider_messaging_webhook_request_duration_seconds Time spent on webhook requests
+		# TYPE insider_messaging_webhook_request_duration_seconds histogram
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="2"} 0
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="4"} 0
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="6"} 1
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="8"} 1
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="10"} 1
+		insider_messaging_webhook_request_duration_seconds_bucket{status_code="200",le="+Inf"} 1
+		insider_messaging_webhook_request_duration_seconds_sum{status_code="200"} 5
+		insider_messaging_webhook_request_duration_seconds_count{status_code="200"} 1
+		# HELP insider_messaging_http_request_duration_seconds Time spent on HTTP requests
+		# TYPE insider_messaging_http_request_duration_seconds histogram
+		insider_messaging_http_request_duration_seconds_bucket{endpoint="/healthz",method="GET",le="0.5"} 1
+		insider_messaging_http_request_duration_seconds_bucket{endpoint="/healthz",method="GET",le="1"} 1
+		insider_messaging_http_request_duration_seconds_bucket{endpoint="/healthz",method="GET",le="+Inf"} 1
+		insider_messaging_http_request_duration_seconds_sum{endpoint="/healthz",method="GET"} 0.1
+		insider_messaging_http_request_duration_seconds_count{endpoint="/healthz",method="GET"} 1
+		# HELP insider_messaging_database_query_duration_seconds Time spent on database queries
+		# TYPE insider_messaging_database_query_duration_seconds histogram
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.001"} 0
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.005"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.01"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.05"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.1"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.25"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="0.5"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="1"} 1
+		insider_messaging_database_query_duration_seconds_bucket{operation="select",le="+Inf"} 1
+		insider_messaging_database_query_duration_seconds_sum{operation="select"} 0.002
+		insider_messaging_database_query_duration_seconds_count{operation="select"} 1
+	`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"insider_messaging_webhook_request_duration_seconds",
+		"insider_messaging_http_request_duration_seconds",
+		"insider_messaging_database_query_duration_seconds",
+	); err != nil {
+		t.Errorf("Unexpected histogram buckets: %v", err)
+	}
+}
+
 func TestRecordWebhookRetry(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	m := NewWithRegistry(registry)
@@ -216,6 +274,125 @@ func TestRecordWebhookRetry(t *testing.T) {
 	}
 }
 
+func TestRecordWebhookConnection(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordWebhookConnection(false)
+	m.RecordWebhookConnection(true)
+	m.RecordWebhookConnection(true)
+
+	if got := testutil.ToFloat64(m.WebhookConnectionsNewTotal); got != 1 {
+		t.Errorf("Expected 1 new connection, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.WebhookConnectionsReusedTotal); got != 2 {
+		t.Errorf("Expected 2 reused connections, got %v", got)
+	}
+}
+
+func TestRecordWebhookSlowResponse(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordWebhookSlowResponse("a.example.com")
+	m.RecordWebhookSlowResponse("a.example.com")
+	m.RecordWebhookSlowResponse("b.example.com")
+
+	if got := testutil.ToFloat64(m.WebhookSlowResponsesTotal.WithLabelValues("a.example.com")); got != 2 {
+		t.Errorf("Expected 2 slow responses for a.example.com, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.WebhookSlowResponsesTotal.WithLabelValues("b.example.com")); got != 1 {
+		t.Errorf("Expected 1 slow response for b.example.com, got %v", got)
+	}
+}
+
+func TestRecordMarkOperationFailure(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordMarkOperationFailure("sent")
+	m.RecordMarkOperationFailure("sent")
+	m.RecordMarkOperationFailure("failed")
+
+	if got := testutil.ToFloat64(m.MarkOperationFailures.WithLabelValues("sent")); got != 2 {
+		t.Errorf("Expected 2 sent mark failures, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.MarkOperationFailures.WithLabelValues("failed")); got != 1 {
+		t.Errorf("Expected 1 failed mark failure, got %v", got)
+	}
+}
+
+func TestRecordWorkerWait(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordWorkerWait(0)
+	m.RecordWorkerWait(300 * time.Millisecond)
+
+	expected := `
+		# HELP insider_messaging_worker_wait_seconds Time between a message being selected for delivery and a worker starting it; high values mean the worker pool is too small
+		# TYPE insider_messaging_worker_wait_seconds histogram
+		insider_messaging_worker_wait_seconds_bucket{le="0.001"} 1
+		insider_messaging_worker_wait_seconds_bucket{le="0.01"} 1
+		insider_messaging_worker_wait_seconds_bucket{le="0.05"} 1
+		insider_messaging_worker_wait_seconds_bucket{le="0.1"} 1
+		insider_messaging_worker_wait_seconds_bucket{le="0.25"} 1
+		insider_messaging_worker_wait_seconds_bucket{le="0.5"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="1"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="2.5"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="5"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="10"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="30"} 2
+		insider_messaging_worker_wait_seconds_bucket{le="+Inf"} 2
+		insider_messaging_worker_wait_seconds_sum 0.3
+		insider_messaging_worker_wait_seconds_count 2
+	`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "insider_messaging_worker_wait_seconds"); err != nil {
+		t.Errorf("Unexpected histogram metric value: %v", err)
+	}
+}
+
+func TestRecordRetentionDeleted(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordRetentionDeleted(0)
+	m.RecordRetentionDeleted(250)
+
+	expected := `
+		# HELP insider_messaging_retention_deleted_messages_per_run Number of messages deleted by each retention run
+		# TYPE insider_messaging_retention_deleted_messages_per_run histogram
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="0"} 1
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="10"} 1
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="100"} 1
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="1000"} 2
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="10000"} 2
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="100000"} 2
+		insider_messaging_retention_deleted_messages_per_run_bucket{le="+Inf"} 2
+		insider_messaging_retention_deleted_messages_per_run_sum 250
+		insider_messaging_retention_deleted_messages_per_run_count 2
+	`
+	if err := testutil.CollectAndCompare(m.RetentionDeletedPerRun, strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected retention metric: %v", err)
+	}
+}
+
+func TestRecordEventSinkDelivery(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewWithRegistry(registry)
+
+	m.RecordEventSinkDelivery("audit", "success")
+	m.RecordEventSinkDelivery("callback", "error")
+	m.RecordEventSinkDelivery("callback", "error")
+
+	if got := testutil.ToFloat64(m.EventSinkDeliveries.WithLabelValues("audit", "success")); got != 1 {
+		t.Errorf("Expected 1 audit success, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.EventSinkDeliveries.WithLabelValues("callback", "error")); got != 2 {
+		t.Errorf("Expected 2 callback errors, got %v", got)
+	}
+}
+
 func TestRecordDatabaseQuery(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	m := NewWithRegistry(registry)
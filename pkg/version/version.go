@@ -0,0 +1,17 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/insider/insider-messaging/pkg/version.Version=v1.2.3"
+//
+// Unset values keep the development defaults below.
+package version
+
+var (
+	// Version is the release version of the build
+	Version = "dev"
+
+	// Commit is the git commit the build was made from
+	Commit = "unknown"
+
+	// BuildDate is when the build was made, in RFC 3339
+	BuildDate = "unknown"
+)